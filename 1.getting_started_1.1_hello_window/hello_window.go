@@ -1,10 +1,8 @@
 package main
 
 import (
-	"learn_opengl/gl"
+	"learn_opengl/app"
 	"log"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -13,30 +11,12 @@ const (
 )
 
 func main() {
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Printf("glfw create window err: %v", err)
-		glfw.Terminate()
-		return
-	}
-
-	window.MakeContextCurrent()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width int, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
-
-	for !window.ShouldClose() {
-		if window.GetKey(glfw.KeyEscape) == glfw.Press {
-			window.SetShouldClose(true)
-		}
-		window.SwapBuffers()
-		glfw.PollEvents()
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
 	}
-
-	glfw.Terminate()
 }
@@ -1,11 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -30,25 +30,26 @@ void main()
     FragColor = vec4(1.0f, 0.5f, 0.2f, 1.0f);
 }
 ` + "\x00"
+
+	shaderProgram uint32
+	vbo, vao      uint32
 )
 
 func main() {
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("glfw create window err: %v", err)
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width int, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// build and compile our shader program
 	// ------------------------------------------
 	// vertex shader
@@ -59,7 +60,7 @@ func main() {
 	var success int32
 	gl.GetShaderiv(vertexShader, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::VERTEX::COMPILATION_FAILED: %v", gl.GetShaderInfoLog(vertexShader))
+		return fmt.Errorf("ERROR::SHADER::VERTEX::COMPILATION_FAILED: %v", gl.GetShaderInfoLog(vertexShader))
 	}
 
 	// fragment shader
@@ -69,18 +70,18 @@ func main() {
 	// check for shader compile error
 	gl.GetShaderiv(fragmentShader, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::FRAGMENT::COMPILATION_FAILED: %v\n", gl.GetShaderInfoLog(fragmentShader))
+		return fmt.Errorf("ERROR::SHADER::FRAGMENT::COMPILATION_FAILED: %v", gl.GetShaderInfoLog(fragmentShader))
 	}
 
 	// link shaders
-	shaderProgram := gl.CreateProgram()
+	shaderProgram = gl.CreateProgram()
 	gl.AttachShader(shaderProgram, vertexShader)
 	gl.AttachShader(shaderProgram, fragmentShader)
 	gl.LinkProgram(shaderProgram)
 	// check for link errors
 	gl.GetProgramiv(shaderProgram, gl.LINK_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::PROGRAM::LINKING_FAILED: %v\n", gl.GetProgramInfoLog(shaderProgram))
+		return fmt.Errorf("ERROR::SHADER::PROGRAM::LINKING_FAILED: %v", gl.GetProgramInfoLog(shaderProgram))
 	}
 	gl.DeleteShader(vertexShader)
 	gl.DeleteShader(fragmentShader)
@@ -100,7 +101,6 @@ func main() {
 		0.45, 0.5, 0.0, // top
 	}
 
-	var vbo, vao uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 	// bind the Vertex Array Object first, then bind and set vertex buffer(s), and then configure vertex attribute(s)
@@ -118,29 +118,21 @@ func main() {
 	// You can unbind the VAO afterwards so other VAO calls won't accidentally modify this VAO, but this rarely happens. Modifying other
 	// VAOs requires a call to gl.BindVertexArray anyways so we generally don't unbind VAOs (nor VBOs) when it's not directly necessary
 	gl.BindVertexArray(0)
+	return nil
+}
 
-	for !window.ShouldClose() {
-		// input
-		if window.GetKey(glfw.KeyEscape) == glfw.Press {
-			window.SetShouldClose(true)
-		}
-
-		// render
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-
-		// draw our first triangle
-		gl.UseProgram(shaderProgram)
-		gl.BindVertexArray(vao)
-		gl.DrawArrays(gl.TRIANGLES, 0, 6) // set the count to 6 since we're drawing 6 vertices now (2 triangles); not 3!
+func render(a *app.App) {
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
 
-		window.SwapBuffers()
-		glfw.PollEvents()
-	}
+	// draw our first triangle
+	gl.UseProgram(shaderProgram)
+	gl.BindVertexArray(vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6) // set the count to 6 since we're drawing 6 vertices now (2 triangles); not 3!
+}
 
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &vao)
 	gl.DeleteBuffers(1, &vbo)
 	gl.DeleteProgram(shaderProgram)
-
-	glfw.Terminate()
 }
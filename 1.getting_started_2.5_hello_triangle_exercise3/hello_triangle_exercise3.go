@@ -1,11 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -35,56 +35,55 @@ void main() {
 	FragColor = vec4(1.0f, 1.0f, 0.0f, 1.0f);
 }
 ` + "\x00"
+
+	shaderProgramOrange, shaderProgramYellow uint32
+	vaos, vbos                               [2]uint32
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// build and compile our shader program
 	// ------------------------------------
 	// we skipped compile log checks this time for readability (if you do encounter issues, add the compile-checks! see previous code samples)
 	vertexShader := gl.CreateShader(gl.VERTEX_SHADER)
 	fragmentShaderOrange := gl.CreateShader(gl.FRAGMENT_SHADER)
 	fragmentShaderYellow := gl.CreateShader(gl.FRAGMENT_SHADER)
-	shaderProgramOrange := gl.CreateProgram()
-	shaderProgramYellow := gl.CreateProgram()
+	shaderProgramOrange = gl.CreateProgram()
+	shaderProgramYellow = gl.CreateProgram()
 	gl.ShaderSource(vertexShader, vertexShaderSource)
 	gl.CompileShader(vertexShader)
 	// check for shader compile error
 	var success int32
 	gl.GetShaderiv(vertexShader, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::VERTEX::COMPILATION_FAILED: %v", gl.GetShaderInfoLog(vertexShader))
+		return fmt.Errorf("ERROR::SHADER::VERTEX::COMPILATION_FAILED: %v", gl.GetShaderInfoLog(vertexShader))
 	}
 	gl.ShaderSource(fragmentShaderOrange, fragmentShaderSourceOrange)
 	gl.CompileShader(fragmentShaderOrange)
 	// check for shader compile error
 	gl.GetShaderiv(fragmentShaderOrange, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::FRAGMENTORANGE::COMPILATION_FAILED: %v\n", gl.GetShaderInfoLog(fragmentShaderOrange))
+		return fmt.Errorf("ERROR::SHADER::FRAGMENTORANGE::COMPILATION_FAILED: %v", gl.GetShaderInfoLog(fragmentShaderOrange))
 	}
 	gl.ShaderSource(fragmentShaderYellow, fragmentShaderSourceYellow)
 	gl.CompileShader(fragmentShaderYellow)
 	// check for shader compile error
 	gl.GetShaderiv(fragmentShaderYellow, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::FRAGMENTYELLOW::COMPILATION_FAILED: %v\n", gl.GetShaderInfoLog(fragmentShaderYellow))
+		return fmt.Errorf("ERROR::SHADER::FRAGMENTYELLOW::COMPILATION_FAILED: %v", gl.GetShaderInfoLog(fragmentShaderYellow))
 	}
 	// link the first program object
 	gl.AttachShader(shaderProgramOrange, vertexShader)
@@ -113,7 +112,6 @@ func main() {
 		0.45, 0.5, 0.0, // top
 	}
 
-	var vaos, vbos [2]uint32
 	gl.GenVertexArrays(2, &vaos[0]) // we can also generate multiple vaos or buffers at the same time
 	gl.GenBuffers(2, &vbos[0])
 	// first triangle setup
@@ -130,46 +128,32 @@ func main() {
 	gl.BufferData(gl.ARRAY_BUFFER, len(secondTriangle)*4, unsafe.Pointer(&secondTriangle[0]), gl.STATIC_DRAW)
 	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, 0) // because the vertex data is tightly packed we can also specify 0 as the vertex attribute's stride to let OpenGL figure it out
 	gl.EnableVertexAttribArray(0)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		if window.GetKey(glfw.KeyEscape) == glfw.Press {
-			window.SetShouldClose(true)
-		}
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-
-		// now when we draw the triangle we first use the vertex and orange fragment shader from the first program
-		gl.UseProgram(shaderProgramOrange)
-		// draw the first triangle using the data from our first vao
-		gl.BindVertexArray(vaos[0])
-		gl.DrawArrays(gl.TRIANGLES, 0, 3) // this call should output an orange triangle
-		// then we draw the second triangle using the data from second vao
-		// when we draw the second triangle we want to use a different shader program so we switch to the shader program with our yellow fragment shader.
-		gl.UseProgram(shaderProgramYellow)
-		gl.BindVertexArray(vaos[1])
-		gl.DrawArrays(gl.TRIANGLES, 0, 3) // this call should output a yellow triangle
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
 
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		glfw.PollEvents()
-	}
+	// now when we draw the triangle we first use the vertex and orange fragment shader from the first program
+	gl.UseProgram(shaderProgramOrange)
+	// draw the first triangle using the data from our first vao
+	gl.BindVertexArray(vaos[0])
+	gl.DrawArrays(gl.TRIANGLES, 0, 3) // this call should output an orange triangle
+	// then we draw the second triangle using the data from second vao
+	// when we draw the second triangle we want to use a different shader program so we switch to the shader program with our yellow fragment shader.
+	gl.UseProgram(shaderProgramYellow)
+	gl.BindVertexArray(vaos[1])
+	gl.DrawArrays(gl.TRIANGLES, 0, 3) // this call should output a yellow triangle
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(2, &vaos[0])
 	gl.DeleteBuffers(2, &vbos[0])
 	gl.DeleteProgram(shaderProgramOrange)
 	gl.DeleteProgram(shaderProgramYellow)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
 }
@@ -1,12 +1,12 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"math"
 	"unsafe"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -30,27 +30,26 @@ void main() {
 	FragColor = ourColor;
 }
 ` + "\x00"
+
+	vbo, vao      uint32
+	shaderProgram uint32
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// build and compile our shader program
 	// ------------------------------------
 	// vertex shader
@@ -61,7 +60,7 @@ func main() {
 	var success int32
 	gl.GetShaderiv(vertexShader, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::VERTEX::COMPILATION_FAILED\n%v", gl.GetShaderInfoLog(vertexShader))
+		return fmt.Errorf("ERROR::SHADER::VERTEX::COMPILATION_FAILED\n%v", gl.GetShaderInfoLog(vertexShader))
 	}
 
 	// fragment shader
@@ -71,18 +70,18 @@ func main() {
 	// check for shader compile error
 	gl.GetShaderiv(fragmentShader, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::FRAGMENT::COMPILATION_FAILED\n%v", gl.GetShaderInfoLog(fragmentShader))
+		return fmt.Errorf("ERROR::SHADER::FRAGMENT::COMPILATION_FAILED\n%v", gl.GetShaderInfoLog(fragmentShader))
 	}
 
 	// link shaders
-	shaderProgram := gl.CreateProgram()
+	shaderProgram = gl.CreateProgram()
 	gl.AttachShader(shaderProgram, vertexShader)
 	gl.AttachShader(shaderProgram, fragmentShader)
 	gl.LinkProgram(shaderProgram)
 	// check for link errors
 	gl.GetProgramiv(shaderProgram, gl.LINK_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::PROGRAM::LINKING_FAILED\n%v", gl.GetProgramInfoLog(shaderProgram))
+		return fmt.Errorf("ERROR::SHADER::PROGRAM::LINKING_FAILED\n%v", gl.GetProgramInfoLog(shaderProgram))
 	}
 
 	gl.DeleteShader(vertexShader)
@@ -96,7 +95,6 @@ func main() {
 		0.0, 0.5, 0.0, // top
 	}
 
-	var vbo, vao uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 	gl.BindVertexArray(vao)
@@ -108,46 +106,32 @@ func main() {
 	gl.EnableVertexAttribArray(0)
 
 	gl.BindVertexArray(vao)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		if window.GetKey(glfw.KeyEscape) == glfw.Press {
-			window.SetShouldClose(true)
-		}
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-
-		//be sure to activate the shader before any calls to glUniform
-		gl.UseProgram(shaderProgram)
-
-		// update shader uniform
-		timeValue := glfw.GetTime()
-		greenValue := math.Sin(timeValue)/2.0 + 0.5
-		vertexColorLocation := gl.GetUniformLocation(shaderProgram, "ourColor\x00")
-		gl.Uniform4f(vertexColorLocation, 0.0, float32(greenValue), 0.0, 1.0)
-
-		// render the triangle
-		gl.DrawArrays(gl.TRIANGLES, 0, 3)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		glfw.PollEvents()
-	}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+
+	//be sure to activate the shader before any calls to glUniform
+	gl.UseProgram(shaderProgram)
+
+	// update shader uniform
+	timeValue := a.Time()
+	greenValue := math.Sin(timeValue)/2.0 + 0.5
+	vertexColorLocation := gl.GetUniformLocation(shaderProgram, "ourColor\x00")
+	gl.Uniform4f(vertexColorLocation, 0.0, float32(greenValue), 0.0, 1.0)
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+	// render the triangle
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+}
+
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &vao)
 	gl.DeleteBuffers(1, &vbo)
 	gl.DeleteProgram(shaderProgram)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
 }
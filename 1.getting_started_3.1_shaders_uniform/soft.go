@@ -0,0 +1,20 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.RegisterSource(vertexShaderSource, fragmentShaderSource, &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return in[0].Vec3().Vec4(1)
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return s.Vec4("ourColor"), true
+		},
+	})
+}
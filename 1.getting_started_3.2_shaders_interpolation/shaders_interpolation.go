@@ -1,11 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -32,29 +32,26 @@ void main() {
 	FragColor = vec4(ourColor, 1.0);
 }
 ` + "\x00"
+
+	vao, vbo      uint32
+	shaderProgram uint32
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw window creation
-	// --------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// build and compile our shader program
 	// ------------------------------------
 	// vertex shader
@@ -65,7 +62,7 @@ func main() {
 	var success int32
 	gl.GetShaderiv(vertexShader, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::VERTEX::COMPILAION_FAILED\n%v", gl.GetShaderInfoLog(vertexShader))
+		return fmt.Errorf("ERROR::SHADER::VERTEX::COMPILAION_FAILED\n%v", gl.GetShaderInfoLog(vertexShader))
 	}
 	// fragment shader
 	fragmentShader := gl.CreateShader(gl.FRAGMENT_SHADER)
@@ -74,17 +71,17 @@ func main() {
 	// check for shader compile errors
 	gl.GetShaderiv(fragmentShader, gl.COMPILE_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::FRAGMENT::COMPILATION_FAILED\n%v", gl.GetShaderInfoLog(fragmentShader))
+		return fmt.Errorf("ERROR::SHADER::FRAGMENT::COMPILATION_FAILED\n%v", gl.GetShaderInfoLog(fragmentShader))
 	}
 	// link shader
-	shaderProgram := gl.CreateProgram()
+	shaderProgram = gl.CreateProgram()
 	gl.AttachShader(shaderProgram, vertexShader)
 	gl.AttachShader(shaderProgram, fragmentShader)
 	gl.LinkProgram(shaderProgram)
 	// check for linking errors
 	gl.GetProgramiv(shaderProgram, gl.LINK_STATUS, &success)
 	if success == 0 {
-		log.Fatalf("ERROR::SHADER::PROGRAM::LINKING_FAILED\n%v", gl.GetProgramInfoLog(shaderProgram))
+		return fmt.Errorf("ERROR::SHADER::PROGRAM::LINKING_FAILED\n%v", gl.GetProgramInfoLog(shaderProgram))
 	}
 	gl.DeleteShader(vertexShader)
 	gl.DeleteShader(fragmentShader)
@@ -98,7 +95,6 @@ func main() {
 		0.0, 0.5, 0.0, 0.0, 0.0, 1.0,
 	}
 
-	var vao, vbo uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 	// bind the Vertex Array Object first, then bind and set vertex buffer(s), and then configure vertex attributes.
@@ -116,37 +112,24 @@ func main() {
 
 	// as we only have a single shader, we should also just activate our shader once beforehead if we want to
 	gl.UseProgram(shaderProgram)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		if window.GetKey(glfw.KeyEscape) == glfw.Press {
-			window.SetShouldClose(true)
-		}
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-
-		// render the triangle
-		gl.BindVertexArray(vao)
-		gl.DrawArrays(gl.TRIANGLES, 0, 3)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
 
-		//glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		window.SwapBuffers()
-		glfw.PollEvents()
-	}
+	// render the triangle
+	gl.BindVertexArray(vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &vao)
 	gl.DeleteBuffers(1, &vbo)
 	gl.DeleteProgram(shaderProgram)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources.
-	// ------------------------------------------------------------------
-	glfw.Terminate()
 }
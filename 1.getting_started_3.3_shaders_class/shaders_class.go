@@ -1,11 +1,10 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -13,28 +12,33 @@ const (
 	SRC_HEIGHT = 600
 )
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
+var (
+	ourShader gl.Shader
+	vao, vbo  uint32
+)
 
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+func main() {
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// build and compile our shader program
 	// ------------------------------------
-	ourShader := gl.NewShader("3.3.shader.vs", "3.3.shader.fs")
+	var err error
+	ourShader, err = gl.LoadShader("3.3.shader.vs", "3.3.shader.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -45,7 +49,6 @@ func main() {
 		0.0, 0.5, 0.0, 0.0, 0.0, 1.0, // top
 	}
 
-	var vao, vbo uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 
@@ -61,36 +64,24 @@ func main() {
 	// color attribute
 	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 6*4, 3*4)
 	gl.EnableVertexAttribArray(1)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		if window.GetKey(glfw.KeyEscape) == glfw.Press {
-			window.SetShouldClose(true)
-		}
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-
-		// render the triangle
-		ourShader.Use()
-		gl.BindVertexArray(vao)
-		gl.DrawArrays(gl.TRIANGLES, 0, 3)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
 
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		window.SwapBuffers()
-		glfw.PollEvents()
-	}
+	// render the triangle
+	ourShader.Use()
+	gl.BindVertexArray(vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &vao)
 	gl.DeleteBuffers(1, &vbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	glfw.Terminate()
 }
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("3.3.shader.vs", "3.3.shader.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aColor": 1},
+		Varyings:   3,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			// ourColor = aColor
+			copy(out, in[1][:3])
+			return in[0].Vec3().Vec4(1)
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{in[0], in[1], in[2], 1.0}, true
+		},
+	})
+}
@@ -1,13 +1,13 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
 	"github.com/huoshan017/go-stbi"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -15,30 +15,34 @@ const (
 	SRC_HEIGHT = 600
 )
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
+var (
+	shader        gl.Shader
+	vbo, vao, ebo uint32
+	texture       uint32
+)
 
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+func main() {
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("4.1.texture.vs", "4.1.texture.fs")
+	var err error
+	shader, err = gl.LoadShader("4.1.texture.vs", "4.1.texture.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -54,7 +58,6 @@ func main() {
 		1, 2, 3, // second triangle
 	}
 
-	var vbo, vao, ebo uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 	gl.GenBuffers(1, &ebo)
@@ -81,7 +84,6 @@ func main() {
 
 	// load and create texture
 	// -----------------------
-	var texture uint32
 	gl.GenTextures(1, &texture)
 	gl.BindTexture(gl.TEXTURE_2D, texture)
 	// set the texture wrapping parameters
@@ -95,49 +97,34 @@ func main() {
 	var nChannels int32
 	image, err := stbi.Load("../resources/textures/container.jpg", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width := image.Rect.Dx()
 	height := image.Rect.Dy()
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGB, int32(width), int32(height), 0, gl.RGB, gl.UNSIGNED_BYTE, unsafe.Pointer(&image.Pix[0]))
 	gl.GenerateMipmap(gl.TEXTURE_2D)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		if window.GetKey(glfw.KeyEscape) == glfw.Press {
-			window.SetShouldClose(true)
-		}
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-
-		// bind texture
-		gl.BindTexture(gl.TEXTURE_2D, texture)
-
-		// render container
-		shader.Use()
-		gl.BindVertexArray(vao)
-		gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, 0)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.001)
-	}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+
+	// bind texture
+	gl.BindTexture(gl.TEXTURE_2D, texture)
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+	// render container
+	shader.Use()
+	gl.BindVertexArray(vao)
+	gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, 0)
+}
+
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &vao)
 	gl.DeleteBuffers(1, &vbo)
 	gl.DeleteBuffers(1, &ebo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
 }
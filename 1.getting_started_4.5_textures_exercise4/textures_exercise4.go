@@ -1,13 +1,13 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
 	"github.com/huoshan017/go-stbi"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -17,32 +17,35 @@ const (
 
 var (
 	mixValue float32 = 0.2
+
+	shader             gl.Shader
+	vbo, vao, ebo      uint32
+	texture1, texture2 uint32
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Input = processInput
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("4.5.texture.vs", "4.5.texture.fs")
+	var err error
+	shader, err = gl.LoadShader("4.5.texture.vs", "4.5.texture.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -58,7 +61,6 @@ func main() {
 		1, 2, 3, // second triangle
 	}
 
-	var vbo, vao, ebo uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 	gl.GenBuffers(1, &ebo)
@@ -85,7 +87,6 @@ func main() {
 
 	// load and create texture
 	// -----------------------
-	var texture1, texture2 uint32
 	// texture1
 	gl.GenTextures(1, &texture1)
 	gl.BindTexture(gl.TEXTURE_2D, texture1)
@@ -101,7 +102,7 @@ func main() {
 	var nChannels int32
 	image, err := stbi.Load("../resources/textures/container.jpg", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width := image.Rect.Dx()
 	height := image.Rect.Dy()
@@ -119,7 +120,7 @@ func main() {
 	// load image, create texture and generate mipmaps
 	image, err = stbi.Load("../resources/textures/awesomeface.png", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width = image.Rect.Dx()
 	height = image.Rect.Dy()
@@ -133,64 +134,49 @@ func main() {
 	gl.Uniform1f(gl.GetUniformLocation(shader.Id(), "texture1\x00"), 0)
 	// or set it via the texture class
 	shader.SetInt32("texture2\x00", 1)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-
-		// bind texture
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, texture1)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, texture2)
-
-		// set the texture mix value in the shader
-		shader.SetFloat32("mixValue\x00", mixValue)
-
-		// render container
-		shader.Use()
-		gl.BindVertexArray(vao)
-		gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, 0)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.001)
-	}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+	// bind texture
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture1)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, texture2)
+
+	// set the texture mix value in the shader
+	shader.SetFloat32("mixValue\x00", mixValue)
+
+	// render container
+	shader.Use()
+	gl.BindVertexArray(vao)
+	gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, 0)
+}
+
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &vao)
 	gl.DeleteBuffers(1, &vbo)
 	gl.DeleteBuffers(1, &ebo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyUp) == glfw.Press {
+// process all input: query whether the up and down keys are pressed this frame and react accordingly
+// -------------------------------------------------------------------------------------------------
+func processInput(a *app.App) {
+	if a.KeyPressed(app.KEY_UP) {
 		mixValue += 0.001 // change this value accordingly (might be too slow or too fast based on system hardware)
 		if mixValue >= 1.0 {
 			mixValue = 1.0
 		}
 	}
 
-	if window.GetKey(glfw.KeyDown) == glfw.Press {
+	if a.KeyPressed(app.KEY_DOWN) {
 		mixValue -= 0.001 // change this value accordingly (might be too slow or too fast based on system hardware)
 		if mixValue <= 0.0 {
 			mixValue = 0.0
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("5.1.transform.vs", "5.1.transform.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoord": 1},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[1].X(), in[1].Y()
+			return s.Mat4("transform").Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			texCoord := mgl32.Vec2{in[0], in[1]}
+			// linearly interpolate between both textures (80% container, 20% awesomeface)
+			return soft.Mix(s.Texture("texture1", texCoord), s.Texture("texture2", texCoord), 0.2), true
+		},
+	})
+}
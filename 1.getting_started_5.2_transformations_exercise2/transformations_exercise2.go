@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"math"
@@ -8,7 +10,6 @@ import (
 
 	"github.com/huoshan017/go-stbi"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -17,30 +18,34 @@ const (
 	SRC_HEIGHT = 600
 )
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
+var (
+	shader             gl.Shader
+	vbo, vao, ebo      uint32
+	texture1, texture2 uint32
+)
 
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+func main() {
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("5.2.transform.vs", "5.2.transform.fs")
+	var err error
+	shader, err = gl.LoadShader("5.2.transform.vs", "5.2.transform.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -56,7 +61,6 @@ func main() {
 		1, 2, 3, // second triangle
 	}
 
-	var vbo, vao, ebo uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 	gl.GenBuffers(1, &ebo)
@@ -79,7 +83,6 @@ func main() {
 
 	// load and create texture
 	// -----------------------
-	var texture1, texture2 uint32
 	// texture1
 	gl.GenTextures(1, &texture1)
 	gl.BindTexture(gl.TEXTURE_2D, texture1)
@@ -95,7 +98,7 @@ func main() {
 	var nChannels int32
 	image, err := stbi.Load("../resources/textures/container.jpg", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width := image.Rect.Dx()
 	height := image.Rect.Dy()
@@ -113,7 +116,7 @@ func main() {
 	// load image, create texture and generate mipmaps
 	image, err = stbi.Load("../resources/textures/awesomeface.png", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width = image.Rect.Dx()
 	height = image.Rect.Dy()
@@ -125,72 +128,52 @@ func main() {
 	shader.Use()
 	shader.SetInt32("texture1\x00", 0)
 	shader.SetInt32("texture2\x00", 1)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-
-		// bind texture
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, texture1)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, texture2)
-
-		// create transformations
-		transform := mgl32.Ident4()
-		translate := mgl32.Translate3D(0.5, -0.5, 0.0)
-		rotate := mgl32.HomogRotate3D(float32(glfw.GetTime()), mgl32.Vec3{0.0, 0.0, 1.0})
-		transform = transform.Mul4(translate).Mul4(rotate)
-
-		// render container
-		shader.Use()
-		transformLoc := gl.GetUniformLocation(shader.Id(), "transform\x00")
-		a := &transform[0]
-		gl.UniformMatrix4fv(transformLoc, 1, false, a)
-
-		// with the uniform matrix set, draw the first container
-		gl.BindVertexArray(vao)
-		gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, 0)
-
-		// second transformation
-		// ---------------------
-		transform = mgl32.Ident4() // reset it to identity matrix
-		transform = transform.Mul4(mgl32.Translate3D(-0.5, 0.5, 0.0))
-		scaleAmount := float32(math.Sin(glfw.GetTime()))
-		transform = transform.Mul4(mgl32.Scale3D(scaleAmount, scaleAmount, scaleAmount))
-		gl.UniformMatrix4fv(transformLoc, 1, false, &transform[0])
-
-		// now with the uniform matrix being replaced with new transformations, draw it again
-		gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, 0)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.001)
-	}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &vao)
-	gl.DeleteBuffers(1, &vbo)
-	gl.DeleteBuffers(1, &ebo)
+	// bind texture
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture1)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, texture2)
+
+	// create transformations
+	transform := mgl32.Ident4()
+	translate := mgl32.Translate3D(0.5, -0.5, 0.0)
+	rotate := mgl32.HomogRotate3D(float32(a.Time()), mgl32.Vec3{0.0, 0.0, 1.0})
+	transform = transform.Mul4(translate).Mul4(rotate)
 
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
+	// render container
+	shader.Use()
+	transformLoc := gl.GetUniformLocation(shader.Id(), "transform\x00")
+	gl.UniformMatrix4fv(transformLoc, 1, false, &transform[0])
+
+	// with the uniform matrix set, draw the first container
+	gl.BindVertexArray(vao)
+	gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, 0)
+
+	// second transformation
+	// ---------------------
+	transform = mgl32.Ident4() // reset it to identity matrix
+	transform = transform.Mul4(mgl32.Translate3D(-0.5, 0.5, 0.0))
+	scaleAmount := float32(math.Sin(a.Time()))
+	transform = transform.Mul4(mgl32.Scale3D(scaleAmount, scaleAmount, scaleAmount))
+	gl.UniformMatrix4fv(transformLoc, 1, false, &transform[0])
+
+	// now with the uniform matrix being replaced with new transformations, draw it again
+	gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, 0)
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteVertexArrays(1, &vao)
+	gl.DeleteBuffers(1, &vbo)
+	gl.DeleteBuffers(1, &ebo)
 }
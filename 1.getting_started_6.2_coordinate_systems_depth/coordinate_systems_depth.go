@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"math"
@@ -8,7 +10,6 @@ import (
 
 	"github.com/huoshan017/go-stbi"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -17,34 +18,38 @@ const (
 	SRC_HEIGHT = 600
 )
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
+var (
+	shader             gl.Shader
+	vbo, vao           uint32
+	texture1, texture2 uint32
+)
 
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+func main() {
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("6.2.coordinate_systems.vs", "6.2.coordinate_systems.fs")
+	var err error
+	shader, err = gl.LoadShader("6.2.coordinate_systems.vs", "6.2.coordinate_systems.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -93,7 +98,6 @@ func main() {
 		-0.5, 0.5, -0.5, 0.0, 1.0,
 	}
 
-	var vbo, vao uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 
@@ -112,7 +116,6 @@ func main() {
 
 	// load and create texture
 	// -----------------------
-	var texture1, texture2 uint32
 	// texture1
 	gl.GenTextures(1, &texture1)
 	gl.BindTexture(gl.TEXTURE_2D, texture1)
@@ -128,7 +131,7 @@ func main() {
 	var nChannels int32
 	image, err := stbi.Load("../resources/textures/container.jpg", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width := image.Rect.Dx()
 	height := image.Rect.Dy()
@@ -146,7 +149,7 @@ func main() {
 	// load image, create texture and generate mipmaps
 	image, err = stbi.Load("../resources/textures/awesomeface.png", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width = image.Rect.Dx()
 	height = image.Rect.Dy()
@@ -158,67 +161,48 @@ func main() {
 	shader.Use()
 	shader.SetInt32("texture1\x00", 0)
 	shader.SetInt32("texture2\x00", 1)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// bind texture
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, texture1)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, texture2)
-
-		// active shader
-		shader.Use()
-
-		// create transformations
-		model := mgl32.Ident4()
-		view := mgl32.Ident4()
-		projection := mgl32.Ident4()
-		model = model.Mul4(mgl32.HomogRotate3D(float32(glfw.GetTime()), mgl32.Vec3{0.5, 1.0, 0.0}))
-		view = view.Mul4(mgl32.Translate3D(0.0, 0.0, -3.0))
-		projection = mgl32.Perspective(45.0*math.Pi/180, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		// retrieve the matrix uniform locations
-		modelLoc := gl.GetUniformLocation(shader.Id(), "model\x00")
-		viewLoc := gl.GetUniformLocation(shader.Id(), "view\x00")
-		// pass them to the shaders (3 different ways)
-		gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
-		gl.UniformMatrix4fv(viewLoc, 1, false, &view[0])
-		// note: currently we set the projection matrix each frame, but since the projection matrix rarely changes it's often best practice to set it outside the main loop only once.
-		shader.SetMat4("projection\x00", &projection)
-
-		// render container
-		gl.BindVertexArray(vao)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
-	}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &vao)
-	gl.DeleteBuffers(1, &vbo)
+	// bind texture
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture1)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, texture2)
+
+	// active shader
+	shader.Use()
 
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
+	// create transformations
+	model := mgl32.Ident4()
+	view := mgl32.Ident4()
+	projection := mgl32.Ident4()
+	model = model.Mul4(mgl32.HomogRotate3D(float32(a.Time()), mgl32.Vec3{0.5, 1.0, 0.0}))
+	view = view.Mul4(mgl32.Translate3D(0.0, 0.0, -3.0))
+	projection = mgl32.Perspective(45.0*math.Pi/180, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	// retrieve the matrix uniform locations
+	modelLoc := gl.GetUniformLocation(shader.Id(), "model\x00")
+	viewLoc := gl.GetUniformLocation(shader.Id(), "view\x00")
+	// pass them to the shaders (3 different ways)
+	gl.UniformMatrix4fv(modelLoc, 1, false, &model[0])
+	gl.UniformMatrix4fv(viewLoc, 1, false, &view[0])
+	// note: currently we set the projection matrix each frame, but since the projection matrix rarely changes it's often best practice to set it outside the main loop only once.
+	shader.SetMat4("projection\x00", &projection)
+
+	// render container
+	gl.BindVertexArray(vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteVertexArrays(1, &vao)
+	gl.DeleteBuffers(1, &vbo)
 }
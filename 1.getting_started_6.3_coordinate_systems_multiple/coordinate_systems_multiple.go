@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"math"
@@ -8,7 +10,6 @@ import (
 
 	"github.com/huoshan017/go-stbi"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -17,34 +18,51 @@ const (
 	SRC_HEIGHT = 600
 )
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
+var (
+	shader             gl.Shader
+	vbo, vao           uint32
+	texture1, texture2 uint32
+	// world space positions of our cubes
+	cubePositions = []mgl32.Vec3{
+		{0.0, 0.0, 0.0},
+		{2.0, 5.0, -15.0},
+		{-1.5, -2.2, -2.5},
+		{-3.8, -2.0, -12.3},
+		{2.4, -0.4, -3.5},
+		{-1.7, 3.0, -7.5},
+		{1.3, -2.0, -2.5},
+		{1.5, 2.0, -2.5},
+		{1.5, 0.2, -1.5},
+		{-1.3, 1.0, -1.5},
 	}
+)
 
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+func main() {
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("6.3.coordinate_systems.vs", "6.3.coordinate_systems.fs")
+	var err error
+	shader, err = gl.LoadShader("6.3.coordinate_systems.vs", "6.3.coordinate_systems.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -92,21 +110,7 @@ func main() {
 		-0.5, 0.5, 0.5, 0.0, 0.0,
 		-0.5, 0.5, -0.5, 0.0, 1.0,
 	}
-	// world space positions of our cubes
-	cubePositions := []mgl32.Vec3{
-		{0.0, 0.0, 0.0},
-		{2.0, 5.0, -15.0},
-		{-1.5, -2.2, -2.5},
-		{-3.8, -2.0, -12.3},
-		{2.4, -0.4, -3.5},
-		{-1.7, 3.0, -7.5},
-		{1.3, -2.0, -2.5},
-		{1.5, 2.0, -2.5},
-		{1.5, 0.2, -1.5},
-		{-1.3, 1.0, -1.5},
-	}
 
-	var vbo, vao uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 
@@ -125,7 +129,6 @@ func main() {
 
 	// load and create texture
 	// -----------------------
-	var texture1, texture2 uint32
 	// texture1
 	gl.GenTextures(1, &texture1)
 	gl.BindTexture(gl.TEXTURE_2D, texture1)
@@ -141,7 +144,7 @@ func main() {
 	var nChannels int32
 	image, err := stbi.Load("../resources/textures/container.jpg", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width := image.Rect.Dx()
 	height := image.Rect.Dy()
@@ -159,7 +162,7 @@ func main() {
 	// load image, create texture and generate mipmaps
 	image, err = stbi.Load("../resources/textures/awesomeface.png", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width = image.Rect.Dx()
 	height = image.Rect.Dy()
@@ -169,71 +172,51 @@ func main() {
 	// tell opengl for each sampler to which texture unit it belongs to (only has to done once)
 	// ----------------------------------------------------------------------------------------
 	shader.Use()
-	shader.SetInt32("texture1\x00", 0)
-	shader.SetInt32("texture2\x00", 1)
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// bind texture
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, texture1)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, texture2)
-
-		// active shader
-		shader.Use()
-
-		// create transformations
-		view := mgl32.Ident4()
-		projection := mgl32.Ident4()
-		view = view.Mul4(mgl32.Translate3D(0.0, 0.0, -3.0))
-		projection = mgl32.Perspective(45.0*math.Pi/180, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		// note: currently we set the projection matrix each frame, but since the projection matrix rarely changes it's often best practice to set it outside the main loop only once.
-		shader.SetMat4("projection\x00", &projection)
-		shader.SetMat4("view\x00", &view)
-
-		// render container
-		gl.BindVertexArray(vao)
-		for i := 0; i < 10; i++ {
-			model := mgl32.Ident4()
-			cp := &cubePositions[i]
-			model = model.Mul4(mgl32.Translate3D(cp.X(), cp.Y(), cp.Z()))
-			angle := float32(20.0 * i)
-			model = model.Mul4(mgl32.HomogRotate3D(angle*math.Pi/180, mgl32.Vec3{1.0, 0.3, 0.5}))
-			shader.SetMat4("model\x00", &model)
-
-			gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		}
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
-	}
+	shader.SetInt32("texture1", 0)
+	shader.SetInt32("texture2", 1)
+	return nil
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &vao)
-	gl.DeleteBuffers(1, &vbo)
+func render(a *app.App) {
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
-}
+	// bind texture
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture1)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, texture2)
+
+	// active shader
+	shader.Use()
+
+	// create transformations
+	view := mgl32.Ident4()
+	projection := mgl32.Ident4()
+	view = view.Mul4(mgl32.Translate3D(0.0, 0.0, -3.0))
+	projection = mgl32.Perspective(45.0*math.Pi/180, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	// note: currently we set the projection matrix each frame, but since the projection matrix rarely changes it's often best practice to set it outside the main loop only once.
+	shader.SetMat4("projection", &projection)
+	shader.SetMat4("view", &view)
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
+	// render container
+	gl.BindVertexArray(vao)
+	for i := 0; i < 10; i++ {
+		model := mgl32.Ident4()
+		cp := &cubePositions[i]
+		model = model.Mul4(mgl32.Translate3D(cp.X(), cp.Y(), cp.Z()))
+		angle := float32(20.0 * i)
+		model = model.Mul4(mgl32.HomogRotate3D(angle*math.Pi/180, mgl32.Vec3{1.0, 0.3, 0.5}))
+		shader.SetMat4("model", &model)
+
+		gl.DrawArrays(gl.TRIANGLES, 0, 36)
 	}
 }
+
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteVertexArrays(1, &vao)
+	gl.DeleteBuffers(1, &vbo)
+	shader.Delete()
+}
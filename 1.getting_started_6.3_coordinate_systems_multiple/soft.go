@@ -0,0 +1,24 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("6.3.coordinate_systems.vs", "6.3.coordinate_systems.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoord": 1},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			// TexCoord = vec2(aTexCoord.x, 1.0-aTexCoord.y)
+			out[0], out[1] = in[1].X(), 1-in[1].Y()
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			texCoord := mgl32.Vec2{in[0], in[1]}
+			return soft.Mix(s.Texture("texture1", texCoord), s.Texture("texture2", texCoord), 0.2), true
+		},
+	})
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"math"
@@ -8,7 +10,6 @@ import (
 
 	"github.com/huoshan017/go-stbi"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -17,34 +18,52 @@ const (
 	SRC_HEIGHT = 600
 )
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
+var (
+	shader             gl.Shader
+	vbo, vao           uint32
+	texture1, texture2 uint32
+	// world space positions of our cubes
+	cubePositions = []mgl32.Vec3{
+		{0.0, 0.0, 0.0},
+		{2.0, 5.0, -15.0},
+		{-1.5, -2.2, -2.5},
+		{-3.8, -2.0, -12.3},
+		{2.4, -0.4, -3.5},
+		{-1.7, 3.0, -7.5},
+		{1.3, -2.0, -2.5},
+		{1.5, 2.0, -2.5},
+		{1.5, 0.2, -1.5},
+		{-1.3, 1.0, -1.5},
 	}
+	projection mgl32.Mat4
+)
 
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+func main() {
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("7.1.camera.vs", "7.1.camera.fs")
+	var err error
+	shader, err = gl.LoadShader("7.1.camera.vs", "7.1.camera.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -92,21 +111,7 @@ func main() {
 		-0.5, 0.5, 0.5, 0.0, 0.0,
 		-0.5, 0.5, -0.5, 0.0, 1.0,
 	}
-	// world space positions of our cubes
-	cubePositions := []mgl32.Vec3{
-		{0.0, 0.0, 0.0},
-		{2.0, 5.0, -15.0},
-		{-1.5, -2.2, -2.5},
-		{-3.8, -2.0, -12.3},
-		{2.4, -0.4, -3.5},
-		{-1.7, 3.0, -7.5},
-		{1.3, -2.0, -2.5},
-		{1.5, 2.0, -2.5},
-		{1.5, 0.2, -1.5},
-		{-1.3, 1.0, -1.5},
-	}
 
-	var vbo, vao uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 
@@ -125,7 +130,6 @@ func main() {
 
 	// load and create texture
 	// -----------------------
-	var texture1, texture2 uint32
 	// texture1
 	gl.GenTextures(1, &texture1)
 	gl.BindTexture(gl.TEXTURE_2D, texture1)
@@ -141,7 +145,7 @@ func main() {
 	var nChannels int32
 	image, err := stbi.Load("../resources/textures/container.jpg", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width := image.Rect.Dx()
 	height := image.Rect.Dy()
@@ -159,7 +163,7 @@ func main() {
 	// load image, create texture and generate mipmaps
 	image, err = stbi.Load("../resources/textures/awesomeface.png", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width = image.Rect.Dx()
 	height = image.Rect.Dy()
@@ -173,72 +177,53 @@ func main() {
 	shader.SetInt32("texture2\x00", 1)
 
 	// pass projection matrix to shader (as projection matrix rarely changes there's no need to do this per frame)
-	projection := mgl32.Perspective(45*math.Pi/360, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	projection = mgl32.Perspective(45*math.Pi/360, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
 	shader.SetMat4("projection\x00", &projection)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// bind texture
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, texture1)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, texture2)
-
-		// active shader
-		shader.Use()
-
-		// create transformations
-		view := mgl32.Ident4()
-		radius := 10.0
-		camX := math.Sin(glfw.GetTime()) * radius
-		camZ := math.Cos(glfw.GetTime()) * radius
-		view = mgl32.LookAtV(mgl32.Vec3{float32(camX), 0, float32(camZ)}, mgl32.Vec3{0.0, 0.0, 0.0}, mgl32.Vec3{0.0, 1.0, 0.0})
-		projection = mgl32.Perspective(45.0*math.Pi/360, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		// note: currently we set the projection matrix each frame, but since the projection matrix rarely changes it's often best practice to set it outside the main loop only once.
-		shader.SetMat4("view\x00", &view)
-
-		// render container
-		gl.BindVertexArray(vao)
-		for i := 0; i < 10; i++ {
-			model := mgl32.Ident4()
-			cp := &cubePositions[i]
-			model = model.Mul4(mgl32.Translate3D(cp.X(), cp.Y(), cp.Z()))
-			angle := float32(20.0 * i)
-			model = model.Mul4(mgl32.HomogRotate3D(angle*math.Pi/360, mgl32.Vec3{1.0, 0.3, 0.5}))
-			shader.SetMat4("model\x00", &model)
-
-			gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		}
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
-	}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &vao)
-	gl.DeleteBuffers(1, &vbo)
+	// bind texture
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture1)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, texture2)
 
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
-}
+	// active shader
+	shader.Use()
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
+	// create transformations
+	view := mgl32.Ident4()
+	radius := 10.0
+	camX := math.Sin(a.Time()) * radius
+	camZ := math.Cos(a.Time()) * radius
+	view = mgl32.LookAtV(mgl32.Vec3{float32(camX), 0, float32(camZ)}, mgl32.Vec3{0.0, 0.0, 0.0}, mgl32.Vec3{0.0, 1.0, 0.0})
+	projection = mgl32.Perspective(45.0*math.Pi/360, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	// note: currently we set the projection matrix each frame, but since the projection matrix rarely changes it's often best practice to set it outside the main loop only once.
+	shader.SetMat4("view\x00", &view)
+
+	// render container
+	gl.BindVertexArray(vao)
+	for i := 0; i < 10; i++ {
+		model := mgl32.Ident4()
+		cp := &cubePositions[i]
+		model = model.Mul4(mgl32.Translate3D(cp.X(), cp.Y(), cp.Z()))
+		angle := float32(20.0 * i)
+		model = model.Mul4(mgl32.HomogRotate3D(angle*math.Pi/360, mgl32.Vec3{1.0, 0.3, 0.5}))
+		shader.SetMat4("model\x00", &model)
+
+		gl.DrawArrays(gl.TRIANGLES, 0, 36)
 	}
 }
+
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteVertexArrays(1, &vao)
+	gl.DeleteBuffers(1, &vbo)
+}
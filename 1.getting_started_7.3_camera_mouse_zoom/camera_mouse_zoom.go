@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"math"
@@ -8,7 +10,6 @@ import (
 
 	"github.com/huoshan017/go-stbi"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -18,48 +19,60 @@ const (
 )
 
 var (
-	cameraPos                    = mgl32.Vec3{0.0, 0.0, 3.0}
-	cameraFront                  = mgl32.Vec3{0.0, 0.0, -1.0}
-	cameraUp                     = mgl32.Vec3{0.0, 1.0, 0.0}
-	firstMouse           bool    = true
-	yaw                  float64 = -90.0
-	pitch                float64 = 0.0
-	lastX                float64 = 800.0 / 2.0
-	lastY                float64 = 600.0 / 2.0
-	fov                  float64 = 45.0
-	deltaTime, lastFrame float64
+	cameraPos           = mgl32.Vec3{0.0, 0.0, 3.0}
+	cameraFront         = mgl32.Vec3{0.0, 0.0, -1.0}
+	cameraUp            = mgl32.Vec3{0.0, 1.0, 0.0}
+	yaw         float64 = -90.0
+	pitch       float64 = 0.0
+	fov         float64 = 45.0
+
+	shader             gl.Shader
+	vbo, vao           uint32
+	texture1, texture2 uint32
+	// world space positions of our cubes
+	cubePositions = []mgl32.Vec3{
+		{0.0, 0.0, 0.0},
+		{2.0, 5.0, -15.0},
+		{-1.5, -2.2, -2.5},
+		{-3.8, -2.0, -12.3},
+		{2.4, -0.4, -3.5},
+		{-1.7, 3.0, -7.5},
+		{1.3, -2.0, -2.5},
+		{1.5, 2.0, -2.5},
+		{1.5, 0.2, -1.5},
+		{-1.3, 1.0, -1.5},
+	}
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
+	a.Init = setup
+	a.Input = processInput
+	a.Mouse = mouseCallback
+	a.Scroll = scrollCallback
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("7.3.camera.vs", "7.3.camera.fs")
+	var err error
+	shader, err = gl.LoadShader("7.3.camera.vs", "7.3.camera.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -107,21 +120,7 @@ func main() {
 		-0.5, 0.5, 0.5, 0.0, 0.0,
 		-0.5, 0.5, -0.5, 0.0, 1.0,
 	}
-	// world space positions of our cubes
-	cubePositions := []mgl32.Vec3{
-		{0.0, 0.0, 0.0},
-		{2.0, 5.0, -15.0},
-		{-1.5, -2.2, -2.5},
-		{-3.8, -2.0, -12.3},
-		{2.4, -0.4, -3.5},
-		{-1.7, 3.0, -7.5},
-		{1.3, -2.0, -2.5},
-		{1.5, 2.0, -2.5},
-		{1.5, 0.2, -1.5},
-		{-1.3, 1.0, -1.5},
-	}
 
-	var vbo, vao uint32
 	gl.GenVertexArrays(1, &vao)
 	gl.GenBuffers(1, &vbo)
 
@@ -140,7 +139,6 @@ func main() {
 
 	// load and create texture
 	// -----------------------
-	var texture1, texture2 uint32
 	// texture1
 	gl.GenTextures(1, &texture1)
 	gl.BindTexture(gl.TEXTURE_2D, texture1)
@@ -156,7 +154,7 @@ func main() {
 	var nChannels int32
 	image, err := stbi.Load("../resources/textures/container.jpg", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width := image.Rect.Dx()
 	height := image.Rect.Dy()
@@ -174,7 +172,7 @@ func main() {
 	// load image, create texture and generate mipmaps
 	image, err = stbi.Load("../resources/textures/awesomeface.png", &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Failed to load texture, err: %v", err)
+		return fmt.Errorf("failed to load texture: %w", err)
 	}
 	width = image.Rect.Dx()
 	height = image.Rect.Dy()
@@ -186,108 +184,75 @@ func main() {
 	shader.Use()
 	shader.SetInt32("texture1\x00", 0)
 	shader.SetInt32("texture2\x00", 1)
+	return nil
+}
+
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.2, 0.3, 0.3, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// bind texture
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture1)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, texture2)
+
+	// active shader
+	shader.Use()
+
+	// pass projection matrix to shader (as projection matrix rarely changes there's no need to do this per frame)
+	projection := mgl32.Perspective(float32(fov)*math.Pi/180, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	shader.SetMat4("projection\x00", &projection)
+
+	// create transformations
+	view := mgl32.LookAtV(cameraPos, cameraPos.Add(cameraFront), cameraUp)
+	// note: currently we set the projection matrix each frame, but since the projection matrix rarely changes it's often best practice to set it outside the main loop only once.
+	shader.SetMat4("view\x00", &view)
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// bind texture
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, texture1)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, texture2)
-
-		// active shader
-		shader.Use()
-
-		// pass projection matrix to shader (as projection matrix rarely changes there's no need to do this per frame)
-		projection := mgl32.Perspective(float32(fov)*math.Pi/180, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		shader.SetMat4("projection\x00", &projection)
-
-		// create transformations
-		view := mgl32.LookAtV(cameraPos, cameraPos.Add(cameraFront), cameraUp)
-		// note: currently we set the projection matrix each frame, but since the projection matrix rarely changes it's often best practice to set it outside the main loop only once.
-		shader.SetMat4("view\x00", &view)
-
-		// render container
-		gl.BindVertexArray(vao)
-		for i := 0; i < 10; i++ {
-			model := mgl32.Ident4()
-			cp := &cubePositions[i]
-			model = model.Mul4(mgl32.Translate3D(cp.X(), cp.Y(), cp.Z()))
-			angle := float32(20.0 * i)
-			model = model.Mul4(mgl32.HomogRotate3D(angle*math.Pi/180, mgl32.Vec3{1.0, 0.3, 0.5}))
-			shader.SetMat4("model\x00", &model)
-
-			gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		}
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.001)
+	// render container
+	gl.BindVertexArray(vao)
+	for i := 0; i < 10; i++ {
+		model := mgl32.Ident4()
+		cp := &cubePositions[i]
+		model = model.Mul4(mgl32.Translate3D(cp.X(), cp.Y(), cp.Z()))
+		angle := float32(20.0 * i)
+		model = model.Mul4(mgl32.HomogRotate3D(angle*math.Pi/180, mgl32.Vec3{1.0, 0.3, 0.5}))
+		shader.SetMat4("model\x00", &model)
+
+		gl.DrawArrays(gl.TRIANGLES, 0, 36)
 	}
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &vao)
 	gl.DeleteBuffers(1, &vbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
+// process all input: query whether relevant keys are pressed/released this frame and react accordingly
+// -----------------------------------------------------------------------------------------------------
+func processInput(a *app.App) {
+	cameraSpeed := float32(2.5 * a.DeltaTime())
+	if a.KeyPressed(app.KEY_W) {
+		cameraPos = cameraPos.Add(cameraFront.Mul(cameraSpeed))
 	}
-
-	cameraSpeed := 2.5 * deltaTime
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		cameraPos = cameraPos.Add(cameraFront.Mul(float32(cameraSpeed)))
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		cameraPos = cameraPos.Sub(cameraFront.Mul(float32(cameraSpeed)))
+	if a.KeyPressed(app.KEY_S) {
+		cameraPos = cameraPos.Sub(cameraFront.Mul(cameraSpeed))
 	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		cameraPos = cameraPos.Sub(cameraFront.Cross(cameraUp).Normalize().Mul(float32(cameraSpeed)))
+	if a.KeyPressed(app.KEY_A) {
+		cameraPos = cameraPos.Sub(cameraFront.Cross(cameraUp).Normalize().Mul(cameraSpeed))
 	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		cameraPos = cameraPos.Add(cameraFront.Cross(cameraUp).Normalize().Mul(float32(cameraSpeed)))
+	if a.KeyPressed(app.KEY_D) {
+		cameraPos = cameraPos.Add(cameraFront.Cross(cameraUp).Normalize().Mul(cameraSpeed))
 	}
 }
 
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos
-	lastX = xpos
-	lastY = ypos
-
+// whenever the mouse moves, this callback is called with the offset since the last move
+// --------------------------------------------------------------------------------------
+func mouseCallback(a *app.App, xoffset, yoffset float64) {
 	var sensitivity = 0.1
 	xoffset *= sensitivity
 	yoffset *= sensitivity
@@ -310,7 +275,9 @@ func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
 	cameraFront = front.Normalize()
 }
 
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
+// whenever the mouse scroll wheel scrolls, this callback is called
+// ----------------------------------------------------------------
+func scrollCallback(a *app.App, yoffset float64) {
 	fov -= yoffset
 	if fov < 1.0 {
 		fov = 1.0
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("1.colors.vs", "1.colors.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			lightColor, objectColor := s.Vec3("lightColor"), s.Vec3("objectColor")
+			return soft.Mul3(lightColor, objectColor).Vec4(1), true
+		},
+	})
+	soft.Register("1.light_cube.vs", "1.light_cube.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{1, 1, 1, 1}, true
+		},
+	})
+}
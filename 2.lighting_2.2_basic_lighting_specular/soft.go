@@ -0,0 +1,46 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("2.2.basic_lighting.vs", "2.2.basic_lighting.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aNormal": 1},
+		Varyings:   6,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			model := s.Mat4("model")
+			fragPos := model.Mul4x1(in[0].Vec3().Vec4(1)).Vec3()
+			copy(out[0:3], fragPos[:])
+			copy(out[3:6], in[1][:3])
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(model).Mul4x1(fragPos.Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			fragPos, normal := mgl32.Vec3{in[0], in[1], in[2]}, mgl32.Vec3{in[3], in[4], in[5]}
+			lightColor, objectColor := s.Vec3("lightColor"), s.Vec3("objectColor")
+			// ambient
+			ambientStrength := float32(0.1)
+			ambient := lightColor.Mul(ambientStrength)
+
+			// diffuse
+			norm := normal.Normalize()
+			lightDir := s.Vec3("lightPos").Sub(fragPos).Normalize()
+			diff := soft.Max(norm.Dot(lightDir), 0)
+			diffuse := lightColor.Mul(diff)
+
+			return soft.Mul3(ambient.Add(diffuse), objectColor).Vec4(1), true
+		},
+	})
+	soft.Register("2.2.light_cube.vs", "2.2.light_cube.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{1, 1, 1, 1}, true
+		},
+	})
+}
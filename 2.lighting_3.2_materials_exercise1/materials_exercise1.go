@@ -1,13 +1,13 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"math"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -18,48 +18,47 @@ const (
 
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
 	// lighting
 	lightPos = mgl32.Vec3{1.2, 1.0, 2.0}
+
+	lightingShader, lightCubeShader gl.Shader
+	vbo, cubeVao                    uint32
+	lightCubeVAO                    uint32
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var lightingShader = gl.NewShader("3.2.materials.vs", "3.2.materials.fs")
-	var lightCubeShader = gl.NewShader("3.2.light_cube.vs", "3.2.light_cube.fs")
+	var err error
+	lightingShader, err = gl.LoadShader("3.2.materials.vs", "3.2.materials.fs")
+	if err != nil {
+		return err
+	}
+	lightCubeShader, err = gl.LoadShader("3.2.light_cube.vs", "3.2.light_cube.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -107,7 +106,6 @@ func main() {
 		-0.5, 0.5, -0.5, 0.0, 1.0, 0.0,
 	}
 
-	var vbo, cubeVao uint32
 	gl.GenVertexArrays(1, &cubeVao)
 	gl.GenBuffers(1, &vbo)
 
@@ -124,7 +122,6 @@ func main() {
 	gl.EnableVertexAttribArray(1)
 
 	// second, configure the light's VAO (VBO stays the same; the vertices are the same for the light object which is also a 3D cube)
-	var lightCubeVAO uint32
 	gl.GenVertexArrays(1, &lightCubeVAO)
 	gl.BindVertexArray(lightCubeVAO)
 
@@ -132,127 +129,62 @@ func main() {
 	// note that we update the lamp's position attribute's stride to reflect the updated buffer data
 	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 6*4, 0)
 	gl.EnableVertexAttribArray(0)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// be sure to activate shader when setting uniforms/drawing objects
-		lightingShader.Use()
-		lightingShader.SetVec3("light.position\x00", &lightPos)
-		viewPos := camera.Position()
-		lightingShader.SetVec3("viewPos\x00", &viewPos)
-
-		// light properties
-		lightingShader.SetVec3("light.ambient\x00", &mgl32.Vec3{1.0, 1.0, 1.0}) // note that all light colors are set at full intensity
-		lightingShader.SetVec3("light.diffuse\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
-		lightingShader.SetVec3("light.specular\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
-
-		// material properties
-		lightingShader.SetVec3("material.ambient\x00", &mgl32.Vec3{0.0, 0.1, 0.06})
-		lightingShader.SetVec3("material.diffuse\x00", &mgl32.Vec3{0.0, 0.50980392, 0.50980392})
-		lightingShader.SetVec3("material.specular\x00", &mgl32.Vec3{0.50196078, 0.50196078, 0.50196078})
-		lightingShader.SetFloat32("material.shininess\x00", 32.0)
-
-		// view/projection transformations
-		projection := mgl32.Perspective(float32(camera.Zoom())*math.Pi/180, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		view := camera.GetViewMatrix()
-		lightingShader.SetMat4("projection\x00", &projection)
-		lightingShader.SetMat4("view\x00", &view)
-
-		// world transformation
-		model := mgl32.Ident4()
-		lightingShader.SetMat4("model\x00", &model)
-
-		// render the cube
-		gl.BindVertexArray(cubeVao)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-
-		// also draw the lamp object
-		lightCubeShader.Use()
-		lightCubeShader.SetMat4("projection\x00", &projection)
-		lightCubeShader.SetMat4("view\x00", &view)
-		model = mgl32.Translate3D(lightPos.X(), lightPos.Y(), lightPos.Z())
-		model = model.Mul4(mgl32.Scale3D(0.2, 0.2, 0.2))
-		lightCubeShader.SetMat4("model\x00", &model)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// be sure to activate shader when setting uniforms/drawing objects
+	lightingShader.Use()
+	lightingShader.SetVec3("light.position\x00", &lightPos)
+	viewPos := camera.Position()
+	lightingShader.SetVec3("viewPos\x00", &viewPos)
+
+	// light properties
+	lightingShader.SetVec3("light.ambient\x00", &mgl32.Vec3{1.0, 1.0, 1.0}) // note that all light colors are set at full intensity
+	lightingShader.SetVec3("light.diffuse\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
+	lightingShader.SetVec3("light.specular\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
+
+	// material properties
+	lightingShader.SetVec3("material.ambient\x00", &mgl32.Vec3{0.0, 0.1, 0.06})
+	lightingShader.SetVec3("material.diffuse\x00", &mgl32.Vec3{0.0, 0.50980392, 0.50980392})
+	lightingShader.SetVec3("material.specular\x00", &mgl32.Vec3{0.50196078, 0.50196078, 0.50196078})
+	lightingShader.SetFloat32("material.shininess\x00", 32.0)
+
+	// view/projection transformations
+	projection := mgl32.Perspective(float32(camera.Zoom())*math.Pi/180, SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	view := camera.GetViewMatrix()
+	lightingShader.SetMat4("projection\x00", &projection)
+	lightingShader.SetMat4("view\x00", &view)
+
+	// world transformation
+	model := mgl32.Ident4()
+	lightingShader.SetMat4("model\x00", &model)
+
+	// render the cube
+	gl.BindVertexArray(cubeVao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
 
-		gl.BindVertexArray(lightCubeVAO)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	// also draw the lamp object
+	lightCubeShader.Use()
+	lightCubeShader.SetMat4("projection\x00", &projection)
+	lightCubeShader.SetMat4("view\x00", &view)
+	model = mgl32.Translate3D(lightPos.X(), lightPos.Y(), lightPos.Z())
+	model = model.Mul4(mgl32.Scale3D(0.2, 0.2, 0.2))
+	lightCubeShader.SetMat4("model\x00", &model)
 
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.001)
-	}
+	gl.BindVertexArray(lightCubeVAO)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &cubeVao)
 	gl.DeleteVertexArrays(1, &lightCubeVAO)
 	gl.DeleteBuffers(1, &vbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
-}
-
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
-}
-
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("5.2.light_casters.vs", "5.2.light_casters.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aNormal": 1, "aTexCoords": 2},
+		Varyings:   8,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			model := s.Mat4("model")
+			fragPos := model.Mul4x1(in[0].Vec3().Vec4(1)).Vec3()
+			normal := model.Inv().Transpose().Mat3().Mul3x1(in[1].Vec3())
+			copy(out[0:3], fragPos[:])
+			copy(out[3:6], normal[:])
+			out[6], out[7] = in[2].X(), in[2].Y()
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4x1(fragPos.Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			fragPos, normal, texCoords := mgl32.Vec3{in[0], in[1], in[2]}, mgl32.Vec3{in[3], in[4], in[5]}, mgl32.Vec2{in[6], in[7]}
+			diffuseColor := s.Texture("material.diffuse", texCoords).Vec3()
+			// ambient
+			ambient := soft.Mul3(s.Vec3("light.ambient"), diffuseColor)
+
+			// diffuse
+			norm := normal.Normalize()
+			lightDir := s.Vec3("light.position").Sub(fragPos).Normalize()
+			diff := soft.Max(norm.Dot(lightDir), 0)
+			diffuse := soft.Mul3(s.Vec3("light.diffuse").Mul(diff), diffuseColor)
+
+			// specular
+			viewDir := s.Vec3("viewPos").Sub(fragPos).Normalize()
+			reflectDir := soft.Reflect(lightDir.Mul(-1), norm)
+			spec := soft.Pow(soft.Max(viewDir.Dot(reflectDir), 0), s.Float("material.shininess"))
+			specular := soft.Mul3(s.Vec3("light.specular").Mul(spec), s.Texture("material.specular", texCoords).Vec3())
+
+			// attenuation
+			distance := s.Vec3("light.position").Sub(fragPos).Len()
+			attenuation := 1 / (s.Float("light.constant") + s.Float("light.linear")*distance + s.Float("light.quadratic")*(distance*distance))
+
+			ambient = ambient.Mul(attenuation)
+			diffuse = diffuse.Mul(attenuation)
+			specular = specular.Mul(attenuation)
+
+			return ambient.Add(diffuse).Add(specular).Vec4(1), true
+		},
+	})
+	soft.Register("5.2.light_cube.vs", "5.2.light_cube.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{1, 1, 1, 1}, true
+		},
+	})
+}
@@ -1,13 +1,13 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"math"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
@@ -19,46 +19,60 @@ const (
 
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
-)
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
+	lightingShader, lightCubeShader gl.Shader
+	vbo, cubeVao                    uint32
+	lightCubeVAO                    uint32
+	// positions all containers
+	cubePositions = []mgl32.Vec3{
+		{0.0, 0.0, 0.0},
+		{2.0, 5.0, -15.0},
+		{-1.5, -2.2, -2.5},
+		{-3.8, -2.0, -12.3},
+		{2.4, -0.4, -3.5},
+		{-1.7, 3.0, -7.5},
+		{1.3, -2.0, -2.5},
+		{1.5, 2.0, -2.5},
+		{1.5, 0.2, -1.5},
+		{-1.3, 1.0, -1.5},
 	}
+	diffuseMap  uint32
+	specularMap uint32
+)
 
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+func main() {
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var lightingShader = gl.NewShader("5.3.light_casters.vs", "5.3.light_casters.fs")
-	var lightCubeShader = gl.NewShader("5.3.light_cube.vs", "5.3.light_cube.fs")
+	var err error
+	lightingShader, err = gl.LoadShader("5.3.light_casters.vs", "5.3.light_casters.fs")
+	if err != nil {
+		return err
+	}
+	lightCubeShader, err = gl.LoadShader("5.3.light_cube.vs", "5.3.light_cube.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -106,22 +120,8 @@ func main() {
 		-0.5, 0.5, 0.5, 0.0, 1.0, 0.0, 0.0, 0.0,
 		-0.5, 0.5, -0.5, 0.0, 1.0, 0.0, 0.0, 1.0,
 	}
-	// positions all containers
-	cubePositions := []mgl32.Vec3{
-		{0.0, 0.0, 0.0},
-		{2.0, 5.0, -15.0},
-		{-1.5, -2.2, -2.5},
-		{-3.8, -2.0, -12.3},
-		{2.4, -0.4, -3.5},
-		{-1.7, 3.0, -7.5},
-		{1.3, -2.0, -2.5},
-		{1.5, 2.0, -2.5},
-		{1.5, 0.2, -1.5},
-		{-1.3, 1.0, -1.5},
-	}
 
 	// first, configure the cube's VAO (and VBO)
-	var vbo, cubeVao uint32
 	gl.GenVertexArrays(1, &cubeVao)
 	gl.GenBuffers(1, &vbo)
 
@@ -140,7 +140,6 @@ func main() {
 	gl.EnableVertexAttribArray(2)
 
 	// second, configure the light's VAO (VBO stays the same; the vertices are the same for the light object which is also a 3D cube)
-	var lightCubeVAO uint32
 	gl.GenVertexArrays(1, &lightCubeVAO)
 	gl.BindVertexArray(lightCubeVAO)
 
@@ -151,146 +150,81 @@ func main() {
 
 	// load textures (we now use a utility function to keep the code more organized)
 	// -----------------------------------------------------------------------------
-	diffuseMap := loadTexture("../resources/textures/container2.png")
-	specularMap := loadTexture("../resources/textures/container2_specular.png")
+	diffuseMap = loadTexture("../resources/textures/container2.png")
+	specularMap = loadTexture("../resources/textures/container2_specular.png")
 
 	// shader configuration
 	// --------------------
 	lightingShader.Use()
 	lightingShader.SetInt32("material.diffuse\x00", 0)
 	lightingShader.SetInt32("material.specular\x00", 1)
+	return nil
+}
+
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// be sure to activate shader when setting uniforms/drawing objects
-		lightingShader.Use()
-		cameraPos := camera.Position()
-		lightingShader.SetVec3("light.position\x00", &cameraPos)
-		cameraFront := camera.Front()
-		lightingShader.SetVec3("light.direction\x00", &cameraFront)
-		lightingShader.SetFloat32("light.cutOff\x00", float32(math.Cos(float64(common.Degree2Radian(12.5)))))
-		lightingShader.SetVec3("viewPos\x00", &cameraPos)
-
-		// light properties
-		lightingShader.SetVec3("light.ambient\x00", &mgl32.Vec3{0.1, 0.1, 0.1})
-		// we configure the diffuse intensity slightly highter; the right lighting conditions differ with each lighting method and environment.
-		// each environment and lighting type requires some tweaking to get the best out of your environment.
-		lightingShader.SetVec3("light.diffuse\x00", &mgl32.Vec3{0.8, 0.8, 0.8})
-		lightingShader.SetVec3("light.specular\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
-		lightingShader.SetFloat32("light.constant\x00", 1.0)
-		lightingShader.SetFloat32("light.linear\x00", 0.09)
-		lightingShader.SetFloat32("light.quadratic\x00", 0.032)
-
-		// material properties
-		lightingShader.SetFloat32("material.shininess\x00", 32.0)
-
-		// view/projection transformations
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		view := camera.GetViewMatrix()
-		lightingShader.SetMat4("projection\x00", &projection)
-		lightingShader.SetMat4("view\x00", &view)
-
-		// world transformation
-		model := mgl32.Ident4()
+	// be sure to activate shader when setting uniforms/drawing objects
+	lightingShader.Use()
+	cameraPos := camera.Position()
+	lightingShader.SetVec3("light.position\x00", &cameraPos)
+	cameraFront := camera.Front()
+	lightingShader.SetVec3("light.direction\x00", &cameraFront)
+	lightingShader.SetFloat32("light.cutOff\x00", float32(math.Cos(float64(common.Degree2Radian(12.5)))))
+	lightingShader.SetVec3("viewPos\x00", &cameraPos)
+
+	// light properties
+	lightingShader.SetVec3("light.ambient\x00", &mgl32.Vec3{0.1, 0.1, 0.1})
+	// we configure the diffuse intensity slightly highter; the right lighting conditions differ with each lighting method and environment.
+	// each environment and lighting type requires some tweaking to get the best out of your environment.
+	lightingShader.SetVec3("light.diffuse\x00", &mgl32.Vec3{0.8, 0.8, 0.8})
+	lightingShader.SetVec3("light.specular\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
+	lightingShader.SetFloat32("light.constant\x00", 1.0)
+	lightingShader.SetFloat32("light.linear\x00", 0.09)
+	lightingShader.SetFloat32("light.quadratic\x00", 0.032)
+
+	// material properties
+	lightingShader.SetFloat32("material.shininess\x00", 32.0)
+
+	// view/projection transformations
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	view := camera.GetViewMatrix()
+	lightingShader.SetMat4("projection\x00", &projection)
+	lightingShader.SetMat4("view\x00", &view)
+
+	// world transformation
+	model := mgl32.Ident4()
+	lightingShader.SetMat4("model\x00", &model)
+
+	// bind diffuse map
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, diffuseMap)
+	// bind specular map
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, specularMap)
+
+	// render container
+	gl.BindVertexArray(cubeVao)
+	for i := 0; i < 10; i++ {
+		cp := &cubePositions[i]
+		model = mgl32.Translate3D(cp.X(), cp.Y(), cp.Z())
+		angle := float32(20.0 * i)
+		model = model.Mul4(mgl32.HomogRotate3D(common.Degree2Radian(angle), mgl32.Vec3{1.0, 0.3, 0.5}))
 		lightingShader.SetMat4("model\x00", &model)
 
-		// bind diffuse map
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, diffuseMap)
-		// bind specular map
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, specularMap)
-
-		// render container
-		gl.BindVertexArray(cubeVao)
-		for i := 0; i < 10; i++ {
-			cp := &cubePositions[i]
-			model = mgl32.Translate3D(cp.X(), cp.Y(), cp.Z())
-			angle := float32(20.0 * i)
-			model = model.Mul4(mgl32.HomogRotate3D(common.Degree2Radian(angle), mgl32.Vec3{1.0, 0.3, 0.5}))
-			lightCubeShader.SetMat4("model\x00", &model)
-
-			gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		}
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
+		gl.DrawArrays(gl.TRIANGLES, 0, 36)
 	}
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &cubeVao)
 	gl.DeleteVertexArrays(1, &lightCubeVAO)
 	gl.DeleteBuffers(1, &vbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
-}
-
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
-}
-
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
 }
 
 // utility function for loading a 2D texture from file
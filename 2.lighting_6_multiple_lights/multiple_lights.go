@@ -1,14 +1,13 @@
 package main
 
 import (
-	"fmt"
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"math"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
@@ -18,50 +17,82 @@ const (
 	SRC_HEIGHT = 600
 )
 
+// PointLight mirrors the PointLight struct of 6.multiple_lights.fs
+type PointLight struct {
+	Position  mgl32.Vec3 `glsl:"position"`
+	Constant  float32    `glsl:"constant"`
+	Linear    float32    `glsl:"linear"`
+	Quadratic float32    `glsl:"quadratic"`
+	Ambient   mgl32.Vec3 `glsl:"ambient"`
+	Diffuse   mgl32.Vec3 `glsl:"diffuse"`
+	Specular  mgl32.Vec3 `glsl:"specular"`
+}
+
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
 	// lighting
 	lightPos = mgl32.Vec3{1.2, 1.0, 2.0}
-)
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
+	lightingShader, lightCubeShader gl.Shader
+	vbo, cubeVao                    uint32
+	lightCubeVAO                    uint32
+	// positions all containers
+	cubePositions = []mgl32.Vec3{
+		{0.0, 0.0, 0.0},
+		{2.0, 5.0, -15.0},
+		{-1.5, -2.2, -2.5},
+		{-3.8, -2.0, -12.3},
+		{2.4, -0.4, -3.5},
+		{-1.7, 3.0, -7.5},
+		{1.3, -2.0, -2.5},
+		{1.5, 2.0, -2.5},
+		{1.5, 0.2, -1.5},
+		{-1.3, 1.0, -1.5},
+	}
+	// positions of the point lights
+	pointLightPositions = []mgl32.Vec3{
+		{0.7, 0.2, 2.0},
+		{2.3, -3.3, -4.0},
+		{-4.0, 2.0, -12.0},
+		{0.0, 0.0, -3.0},
 	}
+	diffuseMap  uint32
+	specularMap uint32
+)
 
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+func main() {
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var lightingShader = gl.NewShader("6.multiple_lights.vs", "6.multiple_lights.fs")
-	var lightCubeShader = gl.NewShader("6.light_cube.vs", "6.light_cube.fs")
+	var err error
+	lightingShader, err = gl.LoadShader("6.multiple_lights.vs", "6.multiple_lights.fs")
+	if err != nil {
+		return err
+	}
+	lightCubeShader, err = gl.LoadShader("6.light_cube.vs", "6.light_cube.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -109,29 +140,8 @@ func main() {
 		-0.5, 0.5, 0.5, 0.0, 1.0, 0.0, 0.0, 0.0,
 		-0.5, 0.5, -0.5, 0.0, 1.0, 0.0, 0.0, 1.0,
 	}
-	// positions all containers
-	cubePositions := []mgl32.Vec3{
-		{0.0, 0.0, 0.0},
-		{2.0, 5.0, -15.0},
-		{-1.5, -2.2, -2.5},
-		{-3.8, -2.0, -12.3},
-		{2.4, -0.4, -3.5},
-		{-1.7, 3.0, -7.5},
-		{1.3, -2.0, -2.5},
-		{1.5, 2.0, -2.5},
-		{1.5, 0.2, -1.5},
-		{-1.3, 1.0, -1.5},
-	}
-	// positions of the point lights
-	pointLightPositions := []mgl32.Vec3{
-		{0.7, 0.2, 2.0},
-		{2.3, -3.3, -4.0},
-		{-4.0, 2.0, -12.0},
-		{0.0, 0.0, -3.0},
-	}
 
 	// first, configure the cube's VAO (and VBO)
-	var vbo, cubeVao uint32
 	gl.GenVertexArrays(1, &cubeVao)
 	gl.GenBuffers(1, &vbo)
 
@@ -150,7 +160,6 @@ func main() {
 	gl.EnableVertexAttribArray(2)
 
 	// second, configure the light's VAO (VBO stays the same; the vertices are the same for the light object which is also a 3D cube)
-	var lightCubeVAO uint32
 	gl.GenVertexArrays(1, &lightCubeVAO)
 	gl.BindVertexArray(lightCubeVAO)
 
@@ -161,181 +170,121 @@ func main() {
 
 	// load textures (we now use a utility function to keep the code more organized)
 	// -----------------------------------------------------------------------------
-	diffuseMap := loadTexture("../resources/textures/container2.png")
-	specularMap := loadTexture("../resources/textures/container2_specular.png")
+	diffuseMap = loadTexture("../resources/textures/container2.png")
+	specularMap = loadTexture("../resources/textures/container2_specular.png")
 
 	// shader configuration
 	// --------------------
 	lightingShader.Use()
 	lightingShader.SetInt32("material.diffuse\x00", 0)
 	lightingShader.SetInt32("material.specular\x00", 1)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// be sure to activate shader when setting uniforms/drawing objects
-		lightingShader.Use()
-		cameraPos := camera.Position()
-		lightingShader.SetVec3("viewPos\x00", &cameraPos)
-		lightingShader.SetFloat32("material.shininess\x00", 32.0)
-
-		/*
-			Here we set all the uniforms for the 5/6 types of lights we have. we have to set them manually and index
-			the proper PointLight struct in the array to set each uniform variable. This can be done more code-friendly
-			by defining light types as classes and set their values in there, or by using a more efficient uniform approach
-			by using 'Uniform buffer objects', but that is something we'll discuss in the 'Advanced GLSL' tutorial.
-		*/
-		// directional light
-		lightingShader.SetVec3("dirLight.direction\x00", &mgl32.Vec3{-0.2, -1.0, -0.3})
-		lightingShader.SetVec3("dirLight.ambient\x00", &mgl32.Vec3{0.05, 0.05, 0.05})
-		lightingShader.SetVec3("dirLight.diffuse\x00", &mgl32.Vec3{0.4, 0.4, 0.4})
-		lightingShader.SetVec3("dirLight.specular\x00", &mgl32.Vec3{0.5, 0.5, 0.5})
-		// point lights
-		for i := 0; i < 4; i++ {
-			ls := fmt.Sprintf("pointLights[%v]", i)
-			lightingShader.SetVec3(ls+".position\x00", &(pointLightPositions[i]))
-			lightingShader.SetVec3(ls+".ambient\x00", &mgl32.Vec3{0.05, 0.05, 0.05})
-			lightingShader.SetVec3(ls+".diffuse\x00", &mgl32.Vec3{0.8, 0.8, 0.8})
-			lightingShader.SetVec3(ls+".specular\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
-			lightingShader.SetFloat32(ls+".constant\x00", 1.0)
-			lightingShader.SetFloat32(ls+".linear\x00", 0.09)
-			lightingShader.SetFloat32(ls+".quadratic\x00", 0.032)
-		}
-		// spot light
-		lightingShader.SetVec3("spotLight.position\x00", &cameraPos)
-		cameraFront := camera.Front()
-		lightingShader.SetVec3("spotLight.direction\x00", &cameraFront)
-		lightingShader.SetVec3("spotLight.ambient\x00", &mgl32.Vec3{0.0, 0.0, 0.0})
-		lightingShader.SetVec3("spotLight.diffuse\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
-		lightingShader.SetVec3("spotLight.specular\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
-		lightingShader.SetFloat32("spotLight.constant\x00", 1.0)
-		lightingShader.SetFloat32("spotLight.linear\x00", 0.09)
-		lightingShader.SetFloat32("spotLight.quadratic\x00", 0.032)
-		lightingShader.SetFloat32("spotLight.cutOff\x00", float32(math.Cos(float64(common.Degree2Radian(12.5)))))
-		lightingShader.SetFloat32("spotLight.outerCutOff\x00", float32(math.Cos(float64(common.Degree2Radian(15.0)))))
-
-		// view/projection transformations
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		view := camera.GetViewMatrix()
-		lightingShader.SetMat4("projection\x00", &projection)
-		lightingShader.SetMat4("view\x00", &view)
-
-		// world transformation
-		model := mgl32.Ident4()
-		lightingShader.SetMat4("model\x00", &model)
-
-		// bind diffuse map
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, diffuseMap)
-		// bind specular map
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, specularMap)
-
-		// render container
-		gl.BindVertexArray(cubeVao)
-		for i := 0; i < 10; i++ {
-			cp := &cubePositions[i]
-			// calculate the model matrix for each object and pass it to shader before drawing
-			model = mgl32.Translate3D(cp.X(), cp.Y(), cp.Z())
-			angle := float32(20.0 * i)
-			model = model.Mul4(mgl32.HomogRotate3D(common.Degree2Radian(angle), mgl32.Vec3{1.0, 0.3, 0.5}))
-			lightCubeShader.SetMat4("model\x00", &model)
-
-			gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// also draw the lamp object(s)
-		lightCubeShader.Use()
-		lightCubeShader.SetMat4("projection\x00", &projection)
-		lightCubeShader.SetMat4("view\x00", &view)
-
-		// we now draw as many light bulbs as we have point lights.
-		gl.BindVertexArray(lightCubeVAO)
-		for i := 0; i < 4; i++ {
-			pos := &pointLightPositions[i]
-			model = mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
-			model = model.Mul4(mgl32.Scale3D(0.2, 0.2, 0.2))
-			lightCubeShader.SetMat4("model\x00", &model)
-			gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	// be sure to activate shader when setting uniforms/drawing objects
+	lightingShader.Use()
+	cameraPos := camera.Position()
+	lightingShader.SetVec3("viewPos\x00", &cameraPos)
+	lightingShader.SetFloat32("material.shininess\x00", 32.0)
+
+	/*
+		Here we set all the uniforms for the 5/6 types of lights we have. we have to set them manually and index
+		the proper PointLight struct in the array to set each uniform variable. This can be done more code-friendly
+		by defining light types as classes and set their values in there, or by using a more efficient uniform approach
+		by using 'Uniform buffer objects', but that is something we'll discuss in the 'Advanced GLSL' tutorial.
+	*/
+	// directional light
+	lightingShader.SetVec3("dirLight.direction\x00", &mgl32.Vec3{-0.2, -1.0, -0.3})
+	lightingShader.SetVec3("dirLight.ambient\x00", &mgl32.Vec3{0.05, 0.05, 0.05})
+	lightingShader.SetVec3("dirLight.diffuse\x00", &mgl32.Vec3{0.4, 0.4, 0.4})
+	lightingShader.SetVec3("dirLight.specular\x00", &mgl32.Vec3{0.5, 0.5, 0.5})
+	// point lights
+	pointLights := make([]PointLight, len(pointLightPositions))
+	for i := 0; i < len(pointLights); i++ {
+		pointLights[i] = PointLight{
+			Position:  pointLightPositions[i],
+			Constant:  1.0,
+			Linear:    0.09,
+			Quadratic: 0.032,
+			Ambient:   mgl32.Vec3{0.05, 0.05, 0.05},
+			Diffuse:   mgl32.Vec3{0.8, 0.8, 0.8},
+			Specular:  mgl32.Vec3{1.0, 1.0, 1.0},
 		}
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
 	}
-
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &cubeVao)
-	gl.DeleteVertexArrays(1, &lightCubeVAO)
-	gl.DeleteBuffers(1, &vbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
-}
-
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
+	if err := lightingShader.SetStruct("pointLights", pointLights); err != nil {
+		log.Fatalf("Failed to set point lights, err: %v", err)
 	}
+	// spot light
+	lightingShader.SetVec3("spotLight.position\x00", &cameraPos)
+	cameraFront := camera.Front()
+	lightingShader.SetVec3("spotLight.direction\x00", &cameraFront)
+	lightingShader.SetVec3("spotLight.ambient\x00", &mgl32.Vec3{0.0, 0.0, 0.0})
+	lightingShader.SetVec3("spotLight.diffuse\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
+	lightingShader.SetVec3("spotLight.specular\x00", &mgl32.Vec3{1.0, 1.0, 1.0})
+	lightingShader.SetFloat32("spotLight.constant\x00", 1.0)
+	lightingShader.SetFloat32("spotLight.linear\x00", 0.09)
+	lightingShader.SetFloat32("spotLight.quadratic\x00", 0.032)
+	lightingShader.SetFloat32("spotLight.cutOff\x00", float32(math.Cos(float64(common.Degree2Radian(12.5)))))
+	lightingShader.SetFloat32("spotLight.outerCutOff\x00", float32(math.Cos(float64(common.Degree2Radian(15.0)))))
+
+	// view/projection transformations
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	view := camera.GetViewMatrix()
+	lightingShader.SetMat4("projection\x00", &projection)
+	lightingShader.SetMat4("view\x00", &view)
+
+	// world transformation
+	model := mgl32.Ident4()
+	lightingShader.SetMat4("model\x00", &model)
+
+	// bind diffuse map
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, diffuseMap)
+	// bind specular map
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, specularMap)
+
+	// render container
+	gl.BindVertexArray(cubeVao)
+	for i := 0; i < 10; i++ {
+		cp := &cubePositions[i]
+		// calculate the model matrix for each object and pass it to shader before drawing
+		model = mgl32.Translate3D(cp.X(), cp.Y(), cp.Z())
+		angle := float32(20.0 * i)
+		model = model.Mul4(mgl32.HomogRotate3D(common.Degree2Radian(angle), mgl32.Vec3{1.0, 0.3, 0.5}))
+		lightingShader.SetMat4("model\x00", &model)
 
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
+		gl.DrawArrays(gl.TRIANGLES, 0, 36)
 	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-}
 
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
+	// also draw the lamp object(s)
+	lightCubeShader.Use()
+	lightCubeShader.SetMat4("projection\x00", &projection)
+	lightCubeShader.SetMat4("view\x00", &view)
 
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
+	// we now draw as many light bulbs as we have point lights.
+	gl.BindVertexArray(lightCubeVAO)
+	for i := 0; i < 4; i++ {
+		pos := &pointLightPositions[i]
+		model = mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
+		model = model.Mul4(mgl32.Scale3D(0.2, 0.2, 0.2))
+		lightCubeShader.SetMat4("model\x00", &model)
+		gl.DrawArrays(gl.TRIANGLES, 0, 36)
 	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
 }
 
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteVertexArrays(1, &cubeVao)
+	gl.DeleteVertexArrays(1, &lightCubeVAO)
+	gl.DeleteBuffers(1, &vbo)
 }
 
 // utility function for loading a 2D texture from file
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const NR_POINT_LIGHTS = 4
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("6.multiple_lights.vs", "6.multiple_lights.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aNormal": 1, "aTexCoords": 2},
+		Varyings:   8,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			model := s.Mat4("model")
+			fragPos := model.Mul4x1(in[0].Vec3().Vec4(1)).Vec3()
+			normal := model.Inv().Transpose().Mat3().Mul3x1(in[1].Vec3())
+			copy(out[0:3], fragPos[:])
+			copy(out[3:6], normal[:])
+			out[6], out[7] = in[2].X(), in[2].Y()
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4x1(fragPos.Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			fragPos, normal, texCoords := mgl32.Vec3{in[0], in[1], in[2]}, mgl32.Vec3{in[3], in[4], in[5]}, mgl32.Vec2{in[6], in[7]}
+			// properties
+			norm := normal.Normalize()
+			viewDir := s.Vec3("viewPos").Sub(fragPos).Normalize()
+			m := material{
+				diffuse:   s.Texture("material.diffuse", texCoords).Vec3(),
+				specular:  s.Texture("material.specular", texCoords).Vec3(),
+				shininess: s.Float("material.shininess"),
+			}
+
+			// phase 1: directional lighting
+			result := calcDirLight(s, m, norm, viewDir)
+			// phase 2: point lights
+			for i := 0; i < NR_POINT_LIGHTS; i++ {
+				result = result.Add(calcPointLight(s, fmt.Sprintf("pointLights[%v]", i), m, norm, fragPos, viewDir))
+			}
+			// phase 3: spot light
+			result = result.Add(calcSpotLight(s, m, norm, fragPos, viewDir))
+			return result.Vec4(1), true
+		},
+	})
+	soft.Register("6.light_cube.vs", "6.light_cube.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{1, 1, 1, 1}, true
+		},
+	})
+}
+
+// material holds the texels of the material maps at the fragment, the GLSL functions sample them each time.
+type material struct {
+	diffuse   mgl32.Vec3
+	specular  mgl32.Vec3
+	shininess float32
+}
+
+// phong returns the ambient, diffuse and specular terms of the light called name.
+func phong(s *soft.Stage, name string, m material, normal, lightDir, viewDir mgl32.Vec3) (mgl32.Vec3, mgl32.Vec3, mgl32.Vec3) {
+	// diffuse shading
+	diff := soft.Max(normal.Dot(lightDir), 0)
+	// specular shading
+	reflectDir := soft.Reflect(lightDir.Mul(-1), normal)
+	spec := soft.Pow(soft.Max(viewDir.Dot(reflectDir), 0), m.shininess)
+	// combine results
+	ambient := soft.Mul3(s.Vec3(name+".ambient"), m.diffuse)
+	diffuse := soft.Mul3(s.Vec3(name+".diffuse").Mul(diff), m.diffuse)
+	specular := soft.Mul3(s.Vec3(name+".specular").Mul(spec), m.specular)
+	return ambient, diffuse, specular
+}
+
+// attenuation of the light called name at fragPos.
+func attenuation(s *soft.Stage, name string, fragPos mgl32.Vec3) float32 {
+	distance := s.Vec3(name + ".position").Sub(fragPos).Len()
+	return 1 / (s.Float(name+".constant") + s.Float(name+".linear")*distance + s.Float(name+".quadratic")*(distance*distance))
+}
+
+// calculates the color when using a directional light.
+func calcDirLight(s *soft.Stage, m material, normal, viewDir mgl32.Vec3) mgl32.Vec3 {
+	lightDir := s.Vec3("dirLight.direction").Mul(-1).Normalize()
+	ambient, diffuse, specular := phong(s, "dirLight", m, normal, lightDir, viewDir)
+	return ambient.Add(diffuse).Add(specular)
+}
+
+// calculates the color when using a point light.
+func calcPointLight(s *soft.Stage, name string, m material, normal, fragPos, viewDir mgl32.Vec3) mgl32.Vec3 {
+	lightDir := s.Vec3(name + ".position").Sub(fragPos).Normalize()
+	ambient, diffuse, specular := phong(s, name, m, normal, lightDir, viewDir)
+	return ambient.Add(diffuse).Add(specular).Mul(attenuation(s, name, fragPos))
+}
+
+// calculates the color when using a spot light.
+func calcSpotLight(s *soft.Stage, m material, normal, fragPos, viewDir mgl32.Vec3) mgl32.Vec3 {
+	lightDir := s.Vec3("spotLight.position").Sub(fragPos).Normalize()
+	ambient, diffuse, specular := phong(s, "spotLight", m, normal, lightDir, viewDir)
+	// spotlight intensity
+	theta := lightDir.Dot(s.Vec3("spotLight.direction").Mul(-1).Normalize())
+	epsilon := s.Float("spotLight.cutOff") - s.Float("spotLight.outerCutOff")
+	intensity := soft.Clamp((theta-s.Float("spotLight.outerCutOff"))/epsilon, 0, 1)
+	return ambient.Add(diffuse).Add(specular).Mul(attenuation(s, "spotLight", fragPos) * intensity)
+}
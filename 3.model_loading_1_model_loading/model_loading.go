@@ -1,12 +1,12 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/assimp"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
@@ -18,38 +18,29 @@ const (
 
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
+
+	ourShader gl.Shader
+	ourModel  *assimp.Model
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// tell stb_image.h to flip loaded texture's on the y-axis (before loading model).
 	stbi.SetFlipVerticallyOnLoad(true)
 
@@ -59,96 +50,42 @@ func main() {
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var ourShader = gl.NewShader("1.model_loading.vs", "1.model_loading.fs")
+	var err error
+	ourShader, err = gl.LoadShader("1.model_loading.vs", "1.model_loading.fs")
+	if err != nil {
+		return err
+	}
 
 	// load models
 	// -----------
-	ourModel := assimp.NewModelDefault("../resources/objects/backpack/backpack.obj")
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.05, 0.05, 0.05, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// don't forget to enable shader before setting uniforms
-		ourShader.Use()
-
-		// view/projection transformations
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		view := camera.GetViewMatrix()
-		ourShader.SetMat4("projection\x00", &projection)
-		ourShader.SetMat4("view\x00", &view)
-
-		// render the loaded model
-		model := mgl32.Translate3D(0.0, 0.0, 0.0) // translate it down so it's at the center of the scene
-		model = model.Mul4(mgl32.Scale3D(1.0, 1.0, 1.0))
-		ourShader.SetMat4("model\x00", &model)
-		ourModel.Draw(&ourShader)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
-	}
-
-	glfw.Terminate()
-}
-
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
+	ourModel, err = assimp.LoadModel("../resources/objects/backpack/backpack.obj", assimp.LoadOptions{Logger: log.Default(), CacheDir: "../resources/cache"})
+	return err
 }
 
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.05, 0.05, 0.05, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// don't forget to enable shader before setting uniforms
+	ourShader.Use()
+
+	// view/projection transformations
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	view := camera.GetViewMatrix()
+	ourShader.SetMat4("projection\x00", &projection)
+	ourShader.SetMat4("view\x00", &view)
+
+	// render the loaded model
+	model := mgl32.Translate3D(0.0, 0.0, 0.0) // translate it down so it's at the center of the scene
+	model = model.Mul4(mgl32.Scale3D(1.0, 1.0, 1.0))
+	ourModel.Draw(&ourShader, model)
 }
 
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	ourModel.Release()
+	ourShader.Delete()
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	near float32 = 0.1
+	far  float32 = 100.0
+)
+
+func linearizeDepth(depth float32) float32 {
+	z := depth*2.0 - 1.0 // back to NDC
+	return (2.0 * near * far) / (far + near - z*(far-near))
+}
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("1.2.depth_testing.vs", "1.2.depth_testing.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			// divide by far to get depth in range [0,1] for visualization purposes
+			depth := linearizeDepth(s.FragCoord.Z()) / far
+			return mgl32.Vec4{depth, depth, depth, 1}, true
+		},
+	})
+}
@@ -1,11 +1,11 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -14,28 +14,38 @@ const (
 	SRC_HEIGHT = 600
 )
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+var (
+	shader           gl.Shader
+	quadVao, quadVbo uint32
+	instanceVbo      uint32
+)
 
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window, err %v", err)
+func main() {
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
+	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
 	}
-	window.MakeContextCurrent()
-	gl.Init()
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile shaders
 	// -------------------------
-	shader := gl.NewShader("10.1.instancing.vs", "10.1.instancing.fs")
+	var err error
+	shader, err = gl.LoadShader("10.1.instancing.vs", "10.1.instancing.fs")
+	if err != nil {
+		return err
+	}
 
 	// generate a list of 100 quad location/translation-vectors
 	// --------------------------------------------------------
@@ -44,14 +54,13 @@ func main() {
 	var offset float32 = 0.1
 	for y := -10; y < 10; y += 2 {
 		for x := -10; x < 10; x += 2 {
-			translations[index] = mgl32.Vec2{float32(x/10.0) + offset, float32(y/10.0) + offset}
+			translations[index] = mgl32.Vec2{float32(x)/10.0 + offset, float32(y)/10.0 + offset}
 			index += 1
 		}
 	}
 
 	// store instance data in an array buffer
 	// --------------------------------------
-	var instanceVbo uint32
 	gl.GenBuffers(1, &instanceVbo)
 	gl.BindBuffer(gl.ARRAY_BUFFER, instanceVbo)
 	gl.BufferData(gl.ARRAY_BUFFER, len(translations)*int(unsafe.Sizeof(mgl32.Vec2{})), unsafe.Pointer(&translations[0]), gl.STATIC_DRAW)
@@ -70,7 +79,6 @@ func main() {
 		0.05, 0.05, 0.0, 1.0, 1.0,
 	}
 
-	var quadVao, quadVbo uint32
 	gl.GenVertexArrays(1, &quadVao)
 	gl.GenBuffers(1, &quadVbo)
 	gl.BindVertexArray(quadVao)
@@ -86,31 +94,27 @@ func main() {
 	gl.VertexAttribPointer(2, 2, gl.FLOAT, false, 2*4, 0)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 	gl.VertexAttribDivisor(2, 1) // tell OpenGL this is an instanced vertex attribute
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// draw 100 instanced quads
-		shader.Use()
-		gl.BindVertexArray(quadVao)
-		gl.DrawArraysInstanced(gl.TRIANGLES, 0, 6, 100) // 100 triangles of 6 vertices each
-		gl.BindVertexArray(0)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
-	}
+	// draw 100 instanced quads
+	shader.Use()
+	gl.BindVertexArray(quadVao)
+	gl.DrawArraysInstanced(gl.TRIANGLES, 0, 6, 100) // 100 triangles of 6 vertices each
+	gl.BindVertexArray(0)
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &quadVao)
 	gl.DeleteBuffers(1, &quadVbo)
-
-	glfw.Terminate()
+	gl.DeleteBuffers(1, &instanceVbo)
+	shader.Delete()
 }
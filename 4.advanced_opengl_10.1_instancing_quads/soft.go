@@ -0,0 +1,23 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("10.1.instancing.vs", "10.1.instancing.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aColor": 1, "aOffset": 2},
+		Varyings:   3,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			copy(out, in[1][:3])
+			pos := in[0].Vec2().Add(in[2].Vec2())
+			return mgl32.Vec4{pos.X(), pos.Y(), 0, 1}
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{in[0], in[1], in[2], 1}, true
+		},
+	})
+}
@@ -1,74 +1,76 @@
 package main
 
 import (
+	"context"
+	"learn_opengl/app"
 	"learn_opengl/assimp"
+	"learn_opengl/async"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"math"
 	"math/rand"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
 const (
 	SRC_WIDTH  = 800
 	SRC_HEIGHT = 600
+	// size of the progress bar drawn while the models load, in pixels
+	PROGRESS_WIDTH  = 400
+	PROGRESS_HEIGHT = 20
 )
 
 var (
-	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	shader        gl.Shader
+	rock, planet  *assimp.Model
+	rockFuture    *async.Future[*assimp.Model]
+	planetFuture  *async.Future[*assimp.Model]
+	amount        uint32 = 1000
+	modelMatrices []mgl32.Mat4
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window and the render loop, the loader parses the models and decodes their textures on
+	// worker goroutines and the app uploads them a few at a time every frame
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0}),
+		FlyCamera: true,
+		Loader:    async.NewLoader(0),
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile shaders
 	// -------------------------
-	shader := gl.NewShader("10.2.instancing.vs", "10.2.instancing.fs")
+	var err error
+	shader, err = gl.LoadShader("10.2.instancing.vs", "10.2.instancing.fs")
+	if err != nil {
+		return err
+	}
 
-	// load models
-	rock := assimp.NewModelDefault("../resources/objects/rock/rock.obj")
-	planet := assimp.NewModelDefault("../resources/objects/planet/planet.obj")
+	// load models in the background, the cooked meshes in resources/cache skip assimp after the first launch
+	ctx := context.Background()
+	rockFuture = assimp.LoadModelAsync(ctx, a.Loader, "../resources/objects/rock/rock.obj", assimp.LoadOptions{CacheDir: "../resources/cache"})
+	planetFuture = assimp.LoadModelAsync(ctx, a.Loader, "../resources/objects/planet/planet.obj", assimp.LoadOptions{CacheDir: "../resources/cache"})
 
 	// generate a large list of semi-random model transformation matrices
 	// ------------------------------------------------------------------
-	var amount uint32 = 1000
-	modelMatrices := make([]mgl32.Mat4, amount)
-	rand.Seed(int64(glfw.GetTime()))
+	modelMatrices = make([]mgl32.Mat4, amount)
+	rand.Seed(0)
 	var radius float32 = 50.0
 	var offset float32 = 2.5
 	for i := uint32(0); i < amount; i++ {
@@ -93,94 +95,82 @@ func main() {
 		// 4. now add to list of matrices
 		modelMatrices[i] = model
 	}
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// configure transformation matrices
-		projection := mgl32.Perspective(common.Degree2Radian(45.0), SRC_WIDTH/SRC_HEIGHT, 1.0, 100.0)
-		view := camera.GetViewMatrix()
-		shader.Use()
-		shader.SetMat4("projection\x00", &projection)
-		shader.SetMat4("view\x00", &view)
-
-		// draw planet
-		model := mgl32.Translate3D(0.0, -3.0, 0.0)
-		model = model.Mul4(mgl32.Scale3D(4.0, 4.0, 4.0))
-		shader.SetMat4("model\x00", &model)
-		planet.Draw(&shader)
-
-		// draw meteorites
-		for i := uint32(0); i < amount; i++ {
-			shader.SetMat4("model\x00", (&modelMatrices[i]))
-			rock.Draw(&shader)
-		}
+func render(a *app.App) {
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
+	// keep drawing the loading screen until both models are uploaded
+	if rock == nil || planet == nil {
+		if err := loaded(); err != nil {
+			log.Fatalf("%v", err)
+		}
+		if rock == nil || planet == nil {
+			drawProgress(a, (rockFuture.Progress()+planetFuture.Progress())/2)
+			return
+		}
 	}
 
-	glfw.Terminate()
-}
+	// configure transformation matrices
+	projection := a.Projection(1.0, 100.0)
+	view := a.View()
+	shader.Use()
+	shader.SetMat4("projection\x00", &projection)
+	shader.SetMat4("view\x00", &view)
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
+	// draw planet
+	model := mgl32.Translate3D(0.0, -3.0, 0.0)
+	model = model.Mul4(mgl32.Scale3D(4.0, 4.0, 4.0))
+	planet.Draw(&shader, model)
 
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
+	// draw meteorites
+	for i := uint32(0); i < amount; i++ {
+		rock.Draw(&shader, modelMatrices[i])
 	}
 }
 
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
+// loaded picks up the models whose loading finished.
+func loaded() error {
+	var err error
+	if rock == nil && rockFuture.Ready() {
+		if rock, err = rockFuture.Result(); err != nil {
+			return err
+		}
 	}
+	if planet == nil && planetFuture.Ready() {
+		if planet, err = planetFuture.Result(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
+// drawProgress draws a bar filled up to progress, by clearing scissor rectangles.
+func drawProgress(a *app.App, progress float32) {
+	width, height := a.Size()
+	x := int32(width-PROGRESS_WIDTH) / 2
+	y := int32(height-PROGRESS_HEIGHT) / 2
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(x, y, PROGRESS_WIDTH, PROGRESS_HEIGHT)
+	gl.ClearColor(0.25, 0.25, 0.25, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	gl.Scissor(x, y, int32(progress*PROGRESS_WIDTH), PROGRESS_HEIGHT)
+	gl.ClearColor(0.9, 0.9, 0.9, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	gl.Disable(gl.SCISSOR_TEST)
 }
 
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+func cleanup(_ *app.App) {
+	// the app closed the loader by now, the futures are resolved. They are nil if Init failed before the loads
+	for _, f := range []*async.Future[*assimp.Model]{rockFuture, planetFuture} {
+		if f == nil {
+			continue
+		}
+		if model, err := f.Result(); err == nil {
+			model.Release()
+		}
+	}
+	shader.Delete()
 }
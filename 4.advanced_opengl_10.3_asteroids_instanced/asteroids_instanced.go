@@ -1,15 +1,15 @@
 package main
 
 import (
-	"learn_opengl/assimp"
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
+	"learn_opengl/obj"
 	"log"
 	"math"
 	"math/rand"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -20,57 +20,62 @@ const (
 
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
+
+	asteroidsShader, planetShader gl.Shader
+	rock, planet                  *obj.Model
+	buffer                        uint32
+	amount                        int32 = 100000
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile shaders
 	// -------------------------
-	asteroidsShader := gl.NewShader("10.3.asteroids.vs", "10.3.asteroids.fs")
-	planetShader := gl.NewShader("10.3.planet.vs", "10.3.planet.fs")
+	var err error
+	asteroidsShader, err = gl.LoadShader("10.3.asteroids.vs", "10.3.asteroids.fs")
+	if err != nil {
+		return err
+	}
+	planetShader, err = gl.LoadShader("10.3.planet.vs", "10.3.planet.fs")
+	if err != nil {
+		return err
+	}
 
-	// load models
-	rock := assimp.NewModelDefault("../resources/objects/rock/rock.obj")
-	planet := assimp.NewModelDefault("../resources/objects/planet/planet.obj")
+	// load models, the OBJ files don't need assimp. The rocks only need 20 bytes per vertex, and leave the
+	// locations of the tangents and bones to the instance matrices
+	rock, err = obj.LoadModel("../resources/objects/rock/rock.obj", obj.LoadOptions{Logger: log.Default(), Compact: true})
+	if err != nil {
+		return err
+	}
+	planet, err = obj.LoadModel("../resources/objects/planet/planet.obj", obj.LoadOptions{Logger: log.Default()})
+	if err != nil {
+		return err
+	}
 
 	// generate a large list of semi-random model transformation matrices
 	// ------------------------------------------------------------------
-	var amount int32 = 100000
 	modelMatrices := make([]mgl32.Mat4, amount)
-	rand.Seed(int64(glfw.GetTime()))
+	rand.Seed(0)
 	var radius float32 = 150.0
 	var offset float32 = 25.0
 	for i := int32(0); i < amount; i++ {
@@ -98,7 +103,6 @@ func main() {
 
 	// configure instanced array
 	// -------------------------
-	var buffer uint32
 	gl.GenBuffers(1, &buffer)
 	gl.BindBuffer(gl.ARRAY_BUFFER, buffer)
 	gl.BufferData(gl.ARRAY_BUFFER, int(amount)*int(unsafe.Sizeof(mgl32.Mat4{})), unsafe.Pointer(&modelMatrices[0]), gl.STATIC_DRAW)
@@ -127,101 +131,47 @@ func main() {
 
 		gl.BindVertexArray(0)
 	}
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// configure transformation matrices
-		projection := mgl32.Perspective(common.Degree2Radian(45.0), SRC_WIDTH/SRC_HEIGHT, 1.0, 100.0)
-		view := camera.GetViewMatrix()
-		asteroidsShader.Use()
-		asteroidsShader.SetMat4("projection\x00", &projection)
-		asteroidsShader.SetMat4("view\x00", &view)
-		planetShader.Use()
-		planetShader.SetMat4("projection\x00", &projection)
-		planetShader.SetMat4("view\x00", &view)
-
-		// draw planet
-		model := mgl32.Translate3D(0.0, -3.0, 0.0)
-		model = model.Mul4(mgl32.Scale3D(4.0, 4.0, 4.0))
-		planetShader.SetMat4("model\x00", &model)
-		planet.Draw(&planetShader)
-
-		// draw meteorites
-		asteroidsShader.Use()
-		asteroidsShader.SetInt32("texture_diffuse1\x00", 0)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, rock.TextureLoaded()[0].Id()) // note: we also made the textures_loaded vector public (instead of private) from the model class.
-		for i := 0; i < len(rock.Meshes()); i++ {
-			gl.BindVertexArray(rock.Meshes()[i].Vao())
-			gl.DrawElementsInstanced(gl.TRIANGLES, int32(len(rock.Meshes()[i].Indices())), gl.UNSIGNED_INT, nil, amount)
-		}
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
-	}
-
-	glfw.Terminate()
+	return nil
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// configure transformation matrices
+	projection := mgl32.Perspective(common.Degree2Radian(45.0), SRC_WIDTH/SRC_HEIGHT, 1.0, 100.0)
+	view := camera.GetViewMatrix()
+	asteroidsShader.Use()
+	asteroidsShader.SetMat4("projection\x00", &projection)
+	asteroidsShader.SetMat4("view\x00", &view)
+	planetShader.Use()
+	planetShader.SetMat4("projection\x00", &projection)
+	planetShader.SetMat4("view\x00", &view)
+
+	// draw planet
+	model := mgl32.Translate3D(0.0, -3.0, 0.0)
+	model = model.Mul4(mgl32.Scale3D(4.0, 4.0, 4.0))
+	planet.Draw(&planetShader, model)
+
+	// draw meteorites
+	asteroidsShader.Use()
+	asteroidsShader.SetInt32("texture_diffuse1\x00", 0)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, rock.TextureLoaded()[0].Id()) // note: we also made the textures_loaded vector public (instead of private) from the model class.
+	for i := 0; i < len(rock.Meshes()); i++ {
+		gl.BindVertexArray(rock.Meshes()[i].Vao())
+		gl.DrawElementsInstanced(gl.TRIANGLES, int32(len(rock.Meshes()[i].Indices())), gl.UNSIGNED_INT, nil, amount)
 	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
 }
 
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteBuffers(1, &buffer)
+	rock.Release()
+	planet.Release()
+	asteroidsShader.Delete()
+	planetShader.Delete()
 }
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	fragment := func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+		return s.Texture("texture_diffuse1", mgl32.Vec2{in[0], in[1]}), true
+	}
+	soft.Register("10.3.asteroids.vs", "10.3.asteroids.fs", &soft.Program{
+		// the instance matrix takes the locations 2 to 5, a column each
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 1, "aInstanceMatrix": 2},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[1].X(), in[1].Y()
+			instanceMatrix := mgl32.Mat4FromCols(in[2], in[3], in[4], in[5])
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(instanceMatrix).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: fragment,
+	})
+	soft.Register("10.3.planet.vs", "10.3.planet.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 1},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[1].X(), in[1].Y()
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: fragment,
+	})
+}
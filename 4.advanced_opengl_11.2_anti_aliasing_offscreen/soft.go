@@ -0,0 +1,34 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1), its multisample framebuffers have a
+// single sample so the edges stay aliased
+func init() {
+	soft.Register("11.2.anti_aliasing.vs", "11.2.anti_aliasing.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{0, 1, 0, 1}, true
+		},
+	})
+	soft.Register("11.2.aa_post.vs", "11.2.aa_post.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 1},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[1].X(), in[1].Y()
+			return mgl32.Vec4{in[0].X(), in[0].Y(), 0, 1}
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			col := s.Texture("screenTexture", mgl32.Vec2{in[0], in[1]})
+			grayscale := 0.2126*col.X() + 0.7152*col.Y() + 0.0722*col.Z()
+			return mgl32.Vec4{grayscale, grayscale, grayscale, 1}, true
+		},
+	})
+}
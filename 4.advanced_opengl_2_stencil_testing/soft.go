@@ -0,0 +1,31 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	vertex := func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+		out[0], out[1] = in[1].X(), in[1].Y()
+		return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+	}
+	soft.Register("2.stencil_testing.vs", "2.stencil_testing.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 1},
+		Varyings:   2,
+		Vertex:     vertex,
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return s.Texture("texture1", mgl32.Vec2{in[0], in[1]}), true
+		},
+	})
+	soft.Register("2.stencil_testing.vs", "2.stencil_single_color.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 1},
+		Varyings:   2,
+		Vertex:     vertex,
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{0.04, 0.28, 0.26, 1.0}, true
+		},
+	})
+}
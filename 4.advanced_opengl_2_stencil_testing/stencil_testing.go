@@ -1,12 +1,12 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
@@ -18,40 +18,34 @@ const (
 
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
 	// lighting
 	lightPos = mgl32.Vec3{1.2, 1.0, 2.0}
+
+	shader, shaderSingleColor gl.Shader
+	cubeVao, cubeVbo          uint32
+	planeVao, planeVbo        uint32
+	cubeTexture               uint32
+	floorTexture              uint32
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
@@ -62,8 +56,15 @@ func main() {
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("2.stencil_testing.vs", "2.stencil_testing.fs")
-	var shaderSingleColor = gl.NewShader("2.stencil_testing.vs", "2.stencil_single_color.fs")
+	var err error
+	shader, err = gl.LoadShader("2.stencil_testing.vs", "2.stencil_testing.fs")
+	if err != nil {
+		return err
+	}
+	shaderSingleColor, err = gl.LoadShader("2.stencil_testing.vs", "2.stencil_single_color.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -121,7 +122,6 @@ func main() {
 	}
 
 	// first, configure the cube's VAO (and VBO)
-	var cubeVao, cubeVbo uint32
 	gl.GenVertexArrays(1, &cubeVao)
 	gl.GenBuffers(1, &cubeVbo)
 	gl.BindVertexArray(cubeVao)
@@ -135,7 +135,6 @@ func main() {
 	gl.EnableVertexAttribArray(1)
 	gl.BindVertexArray(0)
 
-	var planeVao, planeVbo uint32
 	gl.GenVertexArrays(1, &planeVao)
 	gl.GenBuffers(1, &planeVbo)
 	gl.BindVertexArray(planeVao)
@@ -151,159 +150,94 @@ func main() {
 
 	// load textures (we now use a utility function to keep the code more organized)
 	// -----------------------------------------------------------------------------
-	cubeTexture := loadTexture("../resources/textures/marble.jpg")
-	floorTexture := loadTexture("../resources/textures/metal.png")
+	cubeTexture = loadTexture("../resources/textures/marble.jpg")
+	floorTexture = loadTexture("../resources/textures/metal.png")
 
 	// shader configuration
 	// --------------------
 	shader.Use()
 	shader.SetInt32("texture1\x00", 0)
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
-
-		// be sure to activate shader when setting uniforms/drawing objects
-		shaderSingleColor.Use()
-		model := mgl32.Ident4()
-		view := camera.GetViewMatrix()
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100)
-		shaderSingleColor.SetMat4("view\x00", &view)
-		shaderSingleColor.SetMat4("projection\x00", &projection)
-
-		shader.Use()
-		shader.SetMat4("view\x00", &view)
-		shader.SetMat4("projection\x00", &projection)
-
-		// draw floor as normal, but don't write the floor to the stencil buffer, we only care about the containers. We set its mask to 0x00 to not write to the stencil buffer.
-		gl.StencilMask(0x00)
-		// floor
-		gl.BindVertexArray(planeVao)
-		gl.BindTexture(gl.TEXTURE_2D, floorTexture)
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 6)
-		gl.BindVertexArray(0)
-
-		// 1st. render pass, draw objects as normal, writing to the stencil buffer
-		// -----------------------------------------------------------------------
-		gl.StencilFunc(gl.ALWAYS, 1, 0xff)
-		gl.StencilMask(0xff)
-		// cubes
-		gl.BindVertexArray(cubeVao)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
-		model = mgl32.Translate3D(-1.0, 0.0, -1.0)
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		model = mgl32.Ident4()
-		model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 0.0))
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-
-		// 2nd. render pass, now draw slightly scaled versions of the objects, this time disabling stencil writing.
-		// Because the stencil buffer is now filled with several 1s. The parts of the buffer that are 1 are not drawn, thus only drawing
-		// the objects' size differences, making it look like borders.
-		// -----------------------------------------------------------------------------------------------------------------------------
-		gl.StencilFunc(gl.NOTEQUAL, 1, 0xff)
-		gl.StencilMask(0x00)
-		gl.Disable(gl.DEPTH_TEST)
-		shaderSingleColor.Use()
-		var scale float32 = 1.1
-		// cubes
-		gl.BindVertexArray(cubeVao)
-		gl.BindTexture(gl.TEXTURE0, cubeTexture)
-		model = mgl32.Translate3D(-1.0, 0.0, -1.0)
-		model = model.Mul4(mgl32.Scale3D(scale, scale, scale))
-		shaderSingleColor.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		// floor
-		model = mgl32.Translate3D(2.0, 0.0, 0.0)
-		model = model.Mul4(mgl32.Scale3D(scale, scale, scale))
-		shaderSingleColor.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		gl.BindVertexArray(0)
-
-		gl.StencilMask(0xff)
-		gl.StencilFunc(gl.ALWAYS, 0, 0xff)
-		gl.Enable(gl.DEPTH_TEST)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
-	}
-
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &cubeVao)
-	gl.DeleteVertexArrays(1, &planeVao)
-	gl.DeleteBuffers(1, &cubeVbo)
-	gl.DeleteBuffers(1, &planeVbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
+	return nil
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
 
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
+	// be sure to activate shader when setting uniforms/drawing objects
+	shaderSingleColor.Use()
+	model := mgl32.Ident4()
+	view := camera.GetViewMatrix()
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100)
+	shaderSingleColor.SetMat4("view\x00", &view)
+	shaderSingleColor.SetMat4("projection\x00", &projection)
 
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
+	shader.Use()
+	shader.SetMat4("view\x00", &view)
+	shader.SetMat4("projection\x00", &projection)
 
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
+	// draw floor as normal, but don't write the floor to the stencil buffer, we only care about the containers. We set its mask to 0x00 to not write to the stencil buffer.
+	gl.StencilMask(0x00)
+	// floor
+	gl.BindVertexArray(planeVao)
+	gl.BindTexture(gl.TEXTURE_2D, floorTexture)
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+	gl.BindVertexArray(0)
 
-	lastX = xpos
-	lastY = ypos
+	// 1st. render pass, draw objects as normal, writing to the stencil buffer
+	// -----------------------------------------------------------------------
+	gl.StencilFunc(gl.ALWAYS, 1, 0xff)
+	gl.StencilMask(0xff)
+	// cubes
+	gl.BindVertexArray(cubeVao)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
+	model = mgl32.Translate3D(-1.0, 0.0, -1.0)
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	model = mgl32.Ident4()
+	model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 0.0))
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+
+	// 2nd. render pass, now draw slightly scaled versions of the objects, this time disabling stencil writing.
+	// Because the stencil buffer is now filled with several 1s. The parts of the buffer that are 1 are not drawn, thus only drawing
+	// the objects' size differences, making it look like borders.
+	// -----------------------------------------------------------------------------------------------------------------------------
+	gl.StencilFunc(gl.NOTEQUAL, 1, 0xff)
+	gl.StencilMask(0x00)
+	gl.Disable(gl.DEPTH_TEST)
+	shaderSingleColor.Use()
+	var scale float32 = 1.1
+	// cubes
+	gl.BindVertexArray(cubeVao)
+	gl.BindTexture(gl.TEXTURE0, cubeTexture)
+	model = mgl32.Translate3D(-1.0, 0.0, -1.0)
+	model = model.Mul4(mgl32.Scale3D(scale, scale, scale))
+	shaderSingleColor.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	// floor
+	model = mgl32.Translate3D(2.0, 0.0, 0.0)
+	model = model.Mul4(mgl32.Scale3D(scale, scale, scale))
+	shaderSingleColor.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	gl.BindVertexArray(0)
 
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
+	gl.StencilMask(0xff)
+	gl.StencilFunc(gl.ALWAYS, 0, 0xff)
+	gl.Enable(gl.DEPTH_TEST)
 }
 
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteVertexArrays(1, &cubeVao)
+	gl.DeleteVertexArrays(1, &planeVao)
+	gl.DeleteBuffers(1, &cubeVbo)
+	gl.DeleteBuffers(1, &planeVbo)
 }
 
 // utility function for loading a 2D texture from file
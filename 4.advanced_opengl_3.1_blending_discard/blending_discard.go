@@ -1,12 +1,12 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
@@ -18,47 +18,48 @@ const (
 
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
 	// lighting
 	lightPos = mgl32.Vec3{1.2, 1.0, 2.0}
+
+	shader                         gl.Shader
+	cubeVao, cubeVbo               uint32
+	planeVao, planeVbo             uint32
+	transparentVao, transparentVbo uint32
+	cubeTexture                    uint32
+	floorTexture                   uint32
+	transparentTexture             uint32
+	vegetation                     []mgl32.Vec3
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("3.1.blending.vs", "3.1.blending.fs")
+	var err error
+	shader, err = gl.LoadShader("3.1.blending.vs", "3.1.blending.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -124,7 +125,6 @@ func main() {
 	}
 
 	// cube
-	var cubeVao, cubeVbo uint32
 	gl.GenVertexArrays(1, &cubeVao)
 	gl.GenBuffers(1, &cubeVbo)
 	gl.BindVertexArray(cubeVao)
@@ -137,7 +137,6 @@ func main() {
 	gl.BindVertexArray(0)
 
 	// plane
-	var planeVao, planeVbo uint32
 	gl.GenVertexArrays(1, &planeVao)
 	gl.GenBuffers(1, &planeVbo)
 	gl.BindVertexArray(planeVao)
@@ -150,7 +149,6 @@ func main() {
 	gl.BindVertexArray(0)
 
 	// transparent
-	var transparentVao, transparentVbo uint32
 	gl.GenVertexArrays(1, &transparentVao)
 	gl.GenBuffers(1, &transparentVbo)
 	gl.BindVertexArray(transparentVao)
@@ -164,13 +162,13 @@ func main() {
 
 	// load textures (we now use a utility function to keep the code more organized)
 	// -----------------------------------------------------------------------------
-	cubeTexture := loadTexture("../resources/textures/marble.jpg")
-	floorTexture := loadTexture("../resources/textures/metal.png")
-	transparentTexture := loadTexture("../resources/textures/grass.png")
+	cubeTexture = loadTexture("../resources/textures/marble.jpg")
+	floorTexture = loadTexture("../resources/textures/metal.png")
+	transparentTexture = loadTexture("../resources/textures/grass.png")
 
 	// transparent vegetation locations
 	// --------------------------------
-	vegetation := []mgl32.Vec3{
+	vegetation = []mgl32.Vec3{
 		{-1.5, 0.0, -0.48},
 		{1.5, 0.0, 0.51},
 		{0.0, 0.0, 0.7},
@@ -182,122 +180,57 @@ func main() {
 	// --------------------
 	shader.Use()
 	shader.SetInt32("texture1\x00", 0)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// be sure to activate shader when setting uniforms/drawing objects
-		model := mgl32.Ident4()
-		view := camera.GetViewMatrix()
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100)
-		shader.SetMat4("view\x00", &view)
-		shader.SetMat4("projection\x00", &projection)
+	// be sure to activate shader when setting uniforms/drawing objects
+	model := mgl32.Ident4()
+	view := camera.GetViewMatrix()
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100)
+	shader.SetMat4("view\x00", &view)
+	shader.SetMat4("projection\x00", &projection)
 
-		// cubes
-		gl.BindVertexArray(cubeVao)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
-		model = mgl32.Translate3D(-1.0, 0.0, -1.0)
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		model = mgl32.Ident4()
-		model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 0.0))
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		// floor
-		gl.BindVertexArray(planeVao)
-		gl.BindTexture(gl.TEXTURE_2D, floorTexture)
-		model = mgl32.Ident4()
+	// cubes
+	gl.BindVertexArray(cubeVao)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
+	model = mgl32.Translate3D(-1.0, 0.0, -1.0)
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	model = mgl32.Ident4()
+	model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 0.0))
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	// floor
+	gl.BindVertexArray(planeVao)
+	gl.BindTexture(gl.TEXTURE_2D, floorTexture)
+	model = mgl32.Ident4()
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+	// vegetation
+	gl.BindVertexArray(transparentVao)
+	gl.BindTexture(gl.TEXTURE_2D, transparentTexture)
+	for i := 0; i < len(vegetation); i++ {
+		v := &vegetation[i]
+		model = mgl32.Translate3D(v.X(), v.Y(), v.Z())
 		shader.SetMat4("model\x00", &model)
 		gl.DrawArrays(gl.TRIANGLES, 0, 6)
-		// vegetation
-		gl.BindVertexArray(transparentVao)
-		gl.BindTexture(gl.TEXTURE_2D, transparentTexture)
-		for i := 0; i < len(vegetation); i++ {
-			a := &vegetation[i]
-			model = mgl32.Translate3D(a.X(), a.Y(), a.Z())
-			shader.SetMat4("model\x00", &model)
-			gl.DrawArrays(gl.TRIANGLES, 0, 6)
-		}
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
 	}
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &cubeVao)
 	gl.DeleteVertexArrays(1, &planeVao)
 	gl.DeleteBuffers(1, &cubeVbo)
 	gl.DeleteBuffers(1, &planeVbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
-}
-
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
-}
-
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
 }
 
 // utility function for loading a 2D texture from file
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"errors"
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
@@ -18,46 +19,50 @@ const (
 
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
+
+	shader, screenShader gl.Shader
+	cubeVao, cubeVbo     uint32
+	planeVao, planeVbo   uint32
+	quadVao, quadVbo     uint32
+	framebuffer          uint32
+	textureColorbuffer   uint32
+	cubeTexture          uint32
+	floorTexture         uint32
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("5.2.framebuffers.vs", "5.2.framebuffers.fs")
-	var screenShader = gl.NewShader("5.2.framebuffers_screen.vs", "5.2.framebuffers_screen.fs")
+	var err error
+	shader, err = gl.LoadShader("5.2.framebuffers.vs", "5.2.framebuffers.fs")
+	if err != nil {
+		return err
+	}
+	screenShader, err = gl.LoadShader("5.2.framebuffers_screen.vs", "5.2.framebuffers_screen.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -125,7 +130,6 @@ func main() {
 	}
 
 	// cube
-	var cubeVao, cubeVbo uint32
 	gl.GenVertexArrays(1, &cubeVao)
 	gl.GenBuffers(1, &cubeVbo)
 	gl.BindVertexArray(cubeVao)
@@ -138,7 +142,6 @@ func main() {
 	gl.BindVertexArray(0)
 
 	// plane
-	var planeVao, planeVbo uint32
 	gl.GenVertexArrays(1, &planeVao)
 	gl.GenBuffers(1, &planeVbo)
 	gl.BindVertexArray(planeVao)
@@ -151,7 +154,6 @@ func main() {
 	gl.BindVertexArray(0)
 
 	// screen quad
-	var quadVao, quadVbo uint32
 	gl.GenVertexArrays(1, &quadVao)
 	gl.GenBuffers(1, &quadVbo)
 	gl.BindVertexArray(quadVao)
@@ -165,8 +167,8 @@ func main() {
 
 	// load textures
 	// -------------
-	cubeTexture := loadTexture("../resources/textures/container.jpg")
-	floorTexture := loadTexture("../resources/textures/metal.png")
+	cubeTexture = loadTexture("../resources/textures/container.jpg")
+	floorTexture = loadTexture("../resources/textures/metal.png")
 
 	// shader configuration
 	// --------------------
@@ -178,11 +180,9 @@ func main() {
 
 	// framebuffer configuration
 	// -------------------------
-	var framebuffer uint32
 	gl.GenFramebuffers(1, &framebuffer)
 	gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer)
 	// create a color attachment texture
-	var textureColorbuffer uint32
 	gl.GenTextures(1, &textureColorbuffer)
 	gl.BindTexture(gl.TEXTURE_2D, textureColorbuffer)
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGB, SRC_WIDTH, SRC_HEIGHT, 0, gl.RGB, gl.UNSIGNED_BYTE, nil)
@@ -197,168 +197,103 @@ func main() {
 	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_STENCIL_ATTACHMENT, gl.RENDERBUFFER, rbo) // now actually attach it
 	// now that we actually created the framebuffer and added all attachments we want to check if it is actually complete now
 	if gl.CheckFramebufferStatus(gl.FRAMEBUFFER) != gl.FRAMEBUFFER_COMPLETE {
-		log.Fatalf("ERROR::FRAMEBUFFER:: Framebuffer is not complete!")
+		return errors.New("ERROR::FRAMEBUFFER:: Framebuffer is not complete!")
 	}
 	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// first render pass: mirror texture.
-		// bind to framebuffer and draw to color texture as we normally
-		// would, but with the view camera reversed.
-		// bind to framebuffer and draw scene as we normally would to color texture
-		// ------------------------------------------------------------------------
-		gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer)
-		gl.Enable(gl.DEPTH_TEST) // enable depth testing (is disabled for rendering screen-space quad)
-
-		// make sure we clear the framebuffer's content
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		shader.Use()
-		model := mgl32.Ident4()
-		camera.YawAdd(180.0)                     // rotate the camera's yaw 180 degrees around
-		camera.ProcessMouseMovement(0, 0, false) // call this to make sure it updates its camera vectors, note that we disable pitch constrains for this specific case (otherwise we can't reverse camera's pitch values)
-		view := camera.GetViewMatrix()
-		camera.YawAdd(-180.0) // reset it back to its original orientation
-		camera.ProcessMouseMovement(0, 0, true)
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100)
-		shader.SetMat4("view\x00", &view)
-		shader.SetMat4("projection\x00", &projection)
-
-		// cubes
-		gl.BindVertexArray(cubeVao)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
-		model = mgl32.Translate3D(-1.0, 0.0, -1.0)
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		model = mgl32.Ident4()
-		model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 0.0))
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		// floor
-		gl.BindVertexArray(planeVao)
-		gl.BindTexture(gl.TEXTURE_2D, floorTexture)
-		model = mgl32.Ident4()
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 6)
-		gl.BindVertexArray(0)
-
-		// second render pass: draw as normal
-		// ----------------------------------
-		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-		// clear all relevant buffers
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0) // set clear color to white (not really necessary actually, since we won't be able to see the behind the quad anyways)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		view = camera.GetViewMatrix()
-		shader.SetMat4("view\x00", &view)
-
-		// cubes
-		gl.BindVertexArray(cubeVao)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
-		model = model.Mul4(mgl32.Translate3D(-1.0, 0.0, -1.0))
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		model = mgl32.Ident4()
-		model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 0.0))
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		// floor
-		gl.BindVertexArray(planeVao)
-		gl.BindTexture(gl.TEXTURE_2D, floorTexture)
-		model = mgl32.Ident4()
-		shader.SetMat4("model\x00", &model)
-		gl.DrawArrays(gl.TRIANGLES, 0, 6)
-		gl.BindVertexArray(0)
-
-		// now draw the mirror quad with screen texture
-		// --------------------------------------------
-		gl.Disable(gl.DEPTH_TEST) // disable depth test so screen-space quad isn't discarded due to depth test.
-
-		screenShader.Use()
-		gl.BindVertexArray(quadVao)
-		gl.BindTexture(gl.TEXTURE_2D, textureColorbuffer) // use the color attachment texture as the texture of the quad plane
-		gl.DrawArrays(gl.TRIANGLES, 0, 6)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
-	}
-
-	// optional: de-allocate all resources once they've outlived their purpose:
+func render(a *app.App) {
+	// first render pass: mirror texture.
+	// bind to framebuffer and draw to color texture as we normally
+	// would, but with the view camera reversed.
+	// bind to framebuffer and draw scene as we normally would to color texture
 	// ------------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &cubeVao)
-	gl.DeleteVertexArrays(1, &planeVao)
-	gl.DeleteVertexArrays(1, &quadVao)
-	gl.DeleteBuffers(1, &cubeVbo)
-	gl.DeleteBuffers(1, &planeVbo)
-	gl.DeleteBuffers(1, &quadVbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
-}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer)
+	gl.Enable(gl.DEPTH_TEST) // enable depth testing (is disabled for rendering screen-space quad)
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
+	// make sure we clear the framebuffer's content
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-}
+	shader.Use()
+	model := mgl32.Ident4()
+	camera.YawAdd(180.0)                     // rotate the camera's yaw 180 degrees around
+	camera.ProcessMouseMovement(0, 0, false) // call this to make sure it updates its camera vectors, note that we disable pitch constrains for this specific case (otherwise we can't reverse camera's pitch values)
+	view := camera.GetViewMatrix()
+	camera.YawAdd(-180.0) // reset it back to its original orientation
+	camera.ProcessMouseMovement(0, 0, true)
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100)
+	shader.SetMat4("view\x00", &view)
+	shader.SetMat4("projection\x00", &projection)
+
+	// cubes
+	gl.BindVertexArray(cubeVao)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
+	model = mgl32.Translate3D(-1.0, 0.0, -1.0)
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	model = mgl32.Ident4()
+	model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 0.0))
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	// floor
+	gl.BindVertexArray(planeVao)
+	gl.BindTexture(gl.TEXTURE_2D, floorTexture)
+	model = mgl32.Ident4()
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+	gl.BindVertexArray(0)
 
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
+	// second render pass: draw as normal
+	// ----------------------------------
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	// clear all relevant buffers
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0) // set clear color to white (not really necessary actually, since we won't be able to see the behind the quad anyways)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
+	view = camera.GetViewMatrix()
+	shader.SetMat4("view\x00", &view)
 
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
+	// cubes
+	gl.BindVertexArray(cubeVao)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
+	model = model.Mul4(mgl32.Translate3D(-1.0, 0.0, -1.0))
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	model = mgl32.Ident4()
+	model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 0.0))
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	// floor
+	gl.BindVertexArray(planeVao)
+	gl.BindTexture(gl.TEXTURE_2D, floorTexture)
+	model = mgl32.Ident4()
+	shader.SetMat4("model\x00", &model)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+	gl.BindVertexArray(0)
 
-	lastX = xpos
-	lastY = ypos
+	// now draw the mirror quad with screen texture
+	// --------------------------------------------
+	gl.Disable(gl.DEPTH_TEST) // disable depth test so screen-space quad isn't discarded due to depth test.
 
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
+	screenShader.Use()
+	gl.BindVertexArray(quadVao)
+	gl.BindTexture(gl.TEXTURE_2D, textureColorbuffer) // use the color attachment texture as the texture of the quad plane
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
 }
 
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteVertexArrays(1, &cubeVao)
+	gl.DeleteVertexArrays(1, &planeVao)
+	gl.DeleteVertexArrays(1, &quadVao)
+	gl.DeleteBuffers(1, &cubeVbo)
+	gl.DeleteBuffers(1, &planeVbo)
+	gl.DeleteBuffers(1, &quadVbo)
 }
 
 // utility function for loading a 2D texture from file
@@ -1,12 +1,12 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
@@ -18,47 +18,47 @@ const (
 
 var (
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
+
+	shader, skyboxShader gl.Shader
+	cubeVao, cubeVbo     uint32
+	skyboxVao, skyboxVbo uint32
+	cubeTexture          uint32
+	cubemapTexture       uint32
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile our shader zprogram
 	// -------------------------------------
-	var shader = gl.NewShader("6.1.cubemaps.vs", "6.1.cubemaps.fs")
-	var skyboxShader = gl.NewShader("6.1.skybox.vs", "6.1.skybox.fs")
+	var err error
+	shader, err = gl.LoadShader("6.1.cubemaps.vs", "6.1.cubemaps.fs")
+	if err != nil {
+		return err
+	}
+	skyboxShader, err = gl.LoadShader("6.1.skybox.vs", "6.1.skybox.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -152,7 +152,6 @@ func main() {
 	}
 
 	// cube
-	var cubeVao, cubeVbo uint32
 	gl.GenVertexArrays(1, &cubeVao)
 	gl.GenBuffers(1, &cubeVbo)
 	gl.BindVertexArray(cubeVao)
@@ -163,7 +162,6 @@ func main() {
 	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 5*4, 3*4)
 	gl.EnableVertexAttribArray(1)
 	// skybox
-	var skyboxVao, skyboxVbo uint32
 	gl.GenVertexArrays(1, &skyboxVao)
 	gl.GenBuffers(1, &skyboxVbo)
 	gl.BindVertexArray(skyboxVao)
@@ -174,7 +172,7 @@ func main() {
 
 	// load textures
 	// -------------
-	cubeTexture := loadTexture("../resources/textures/container.jpg")
+	cubeTexture = loadTexture("../resources/textures/container.jpg")
 
 	faces := []string{
 		"../resources/textures/skybox/right.jpg",
@@ -184,7 +182,7 @@ func main() {
 		"../resources/textures/skybox/front.jpg",
 		"../resources/textures/skybox/back.jpg",
 	}
-	cubemapTexture := loadCubemap(faces)
+	cubemapTexture = loadCubemap(faces)
 
 	// shader configuration
 	// --------------------
@@ -193,119 +191,54 @@ func main() {
 
 	skyboxShader.Use()
 	skyboxShader.SetInt32("skybox\x00", 0)
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// draw scene as normal
-		shader.Use()
-		model := mgl32.Ident4()
-		view := camera.GetViewMatrix()
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100)
-		shader.SetMat4("model\x00", &model)
-		shader.SetMat4("view\x00", &view)
-		shader.SetMat4("projection\x00", &projection)
-
-		// cubes
-		gl.BindVertexArray(cubeVao)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		gl.BindVertexArray(0)
-
-		// draw skybox as last
-		gl.DepthFunc(gl.LEQUAL) // change depth function so depth test passes when values are equal to depth buffer's content
-		skyboxShader.Use()
-		view = camera.GetViewMatrix().Mat3().Mat4() // remove translation from the view matrix
-		skyboxShader.SetMat4("view\x00", &view)
-		skyboxShader.SetMat4("projection\x00", &projection)
-
-		// skybox cube
-		gl.BindVertexArray(skyboxVao)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_CUBE_MAP, cubemapTexture)
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
-		gl.BindVertexArray(0)
-		gl.DepthFunc(gl.LESS) // set depth function back to default
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		//glfw.PollEvents()
-		glfw.WaitEventsTimeout(0.01)
-	}
-
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &cubeVao)
-	gl.DeleteVertexArrays(1, &skyboxVao)
-	gl.DeleteBuffers(1, &cubeVbo)
-	gl.DeleteBuffers(1, &skyboxVbo)
-
-	// glfw: terminate, clearing all previously allocated GLFW resources
-	// -----------------------------------------------------------------
-	glfw.Terminate()
+	return nil
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
+	// draw scene as normal
+	shader.Use()
+	model := mgl32.Ident4()
+	view := camera.GetViewMatrix()
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100)
+	shader.SetMat4("model\x00", &model)
+	shader.SetMat4("view\x00", &view)
+	shader.SetMat4("projection\x00", &projection)
+
+	// cubes
+	gl.BindVertexArray(cubeVao)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, cubeTexture)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	gl.BindVertexArray(0)
 
-	lastX = xpos
-	lastY = ypos
+	// draw skybox as last
+	gl.DepthFunc(gl.LEQUAL) // change depth function so depth test passes when values are equal to depth buffer's content
+	skyboxShader.Use()
+	view = camera.GetViewMatrix().Mat3().Mat4() // remove translation from the view matrix
+	skyboxShader.SetMat4("view\x00", &view)
+	skyboxShader.SetMat4("projection\x00", &projection)
 
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
+	// skybox cube
+	gl.BindVertexArray(skyboxVao)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, cubemapTexture)
+	gl.DrawArrays(gl.TRIANGLES, 0, 36)
+	gl.BindVertexArray(0)
+	gl.DepthFunc(gl.LESS) // set depth function back to default
 }
 
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	gl.DeleteVertexArrays(1, &cubeVao)
+	gl.DeleteVertexArrays(1, &skyboxVao)
+	gl.DeleteBuffers(1, &cubeVbo)
+	gl.DeleteBuffers(1, &skyboxVbo)
 }
 
 // utility function for loading a 2D texture from file
@@ -354,7 +287,7 @@ func loadTexture(path string) uint32 {
 func loadCubemap(faces []string) uint32 {
 	var textureId uint32
 	gl.GenTextures(1, &textureId)
-	gl.BindTexture(gl.TEXTURE_2D, textureId)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, textureId)
 
 	var width, height, nrChannel int32
 	for i := 0; i < len(faces); i++ {
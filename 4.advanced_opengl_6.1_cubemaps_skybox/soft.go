@@ -0,0 +1,35 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("6.1.cubemaps.vs", "6.1.cubemaps.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 1},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[1].X(), in[1].Y()
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return s.Texture("texture1", mgl32.Vec2{in[0], in[1]}), true
+		},
+	})
+	soft.Register("6.1.skybox.vs", "6.1.skybox.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Varyings:   3,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			copy(out, in[0][:3])
+			pos := s.Mat4("projection").Mul4(s.Mat4("view")).Mul4x1(in[0].Vec3().Vec4(1))
+			// z = w, the skybox is at the far plane
+			return mgl32.Vec4{pos.X(), pos.Y(), pos.W(), pos.W()}
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return s.TextureCube("skybox", mgl32.Vec3{in[0], in[1], in[2]}), true
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("6.2.cubemaps.vs", "6.2.cubemaps.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aNormal": 1},
+		Varyings:   6,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			model := s.Mat4("model")
+			normal := model.Inv().Transpose().Mat3().Mul3x1(in[1].Vec3())
+			position := model.Mul4x1(in[0].Vec3().Vec4(1)).Vec3()
+			copy(out[0:3], normal[:])
+			copy(out[3:6], position[:])
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4x1(position.Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			normal, position := mgl32.Vec3{in[0], in[1], in[2]}, mgl32.Vec3{in[3], in[4], in[5]}
+			i := position.Sub(s.Vec3("cameraPos")).Normalize()
+			r := soft.Reflect(i, normal.Normalize())
+			return s.TextureCube("skybox", r).Vec3().Vec4(1), true
+		},
+	})
+	soft.Register("6.2.skybox.vs", "6.2.skybox.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Varyings:   3,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			copy(out, in[0][:3])
+			pos := s.Mat4("projection").Mul4(s.Mat4("view")).Mul4x1(in[0].Vec3().Vec4(1))
+			// z = w, the skybox is at the far plane
+			return mgl32.Vec4{pos.X(), pos.Y(), pos.W(), pos.W()}
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return s.TextureCube("skybox", mgl32.Vec3{in[0], in[1], in[2]}), true
+		},
+	})
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	for fragment, color := range map[string]mgl32.Vec4{
+		"8.red.fs":    {1, 0, 0, 1},
+		"8.green.fs":  {0, 1, 0, 1},
+		"8.blue.fs":   {0, 0, 1, 1},
+		"8.yellow.fs": {1, 1, 0, 1},
+	} {
+		color := color
+		soft.Register("8.advanced_glsl.vs", fragment, &soft.Program{
+			Attributes: map[string]int{"aPos": 0},
+			Blocks:     []string{"Matrices"},
+			Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+				// std140: projection at offset 0, view at 64
+				matrices := s.Block("Matrices")
+				projection, view := soft.Mat4At(matrices, 0), soft.Mat4At(matrices, 64)
+				return projection.Mul4(view).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+			},
+			Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+				return color, true
+			},
+		})
+	}
+}
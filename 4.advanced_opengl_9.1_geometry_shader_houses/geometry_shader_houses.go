@@ -1,11 +1,10 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
-
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const (
@@ -13,33 +12,37 @@ const (
 	SRC_HEIGHT = 600
 )
 
-func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+var (
+	shader   gl.Shader
+	vao, vbo uint32
+)
 
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
+func main() {
+	// the app owns the window and the render loop
+	a := app.New(app.Config{
+		Width:  SRC_WIDTH,
+		Height: SRC_HEIGHT,
+	})
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
 	}
+}
 
-	window.MakeContextCurrent()
-	gl.Init()
-
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
-
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile shaders
 	// -------------------------
-	shader := gl.NewShader2("9.1.geometry_shader.vs", "9.1.geometry_shader.fs", "9.1.geometry_shader.gs")
+	var err error
+	shader, err = gl.LoadShader2("9.1.geometry_shader.vs", "9.1.geometry_shader.fs", "9.1.geometry_shader.gs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -49,7 +52,6 @@ func main() {
 		0.5, -0.5, 0.0, 0.0, 1.0, // bottom-right
 		-0.5, -0.5, 1.0, 1.0, 0.0, // bottom-left
 	}
-	var vao, vbo uint32
 	gl.GenBuffers(1, &vbo)
 	gl.GenVertexArrays(1, &vao)
 	gl.BindVertexArray(vao)
@@ -60,29 +62,25 @@ func main() {
 	gl.EnableVertexAttribArray(1)
 	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 5*4, 2*4)
 	gl.BindVertexArray(0)
+	return nil
+}
 
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// draw points
-		shader.Use()
-		gl.BindVertexArray(vao)
-		gl.DrawArrays(gl.POINTS, 0, 4)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
-	}
+	// draw points
+	shader.Use()
+	gl.BindVertexArray(vao)
+	gl.DrawArrays(gl.POINTS, 0, 4)
+}
 
-	// optional: de-allocate all resources once they've outlived their purpose:
-	// ------------------------------------------------------------------------
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	gl.DeleteVertexArrays(1, &vao)
 	gl.DeleteBuffers(1, &vbo)
-
-	glfw.Terminate()
+	shader.Delete()
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"learn_opengl/gl"
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.RegisterGeometry("9.1.geometry_shader.vs", "9.1.geometry_shader.gs", "9.1.geometry_shader.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aColor": 1},
+		Varyings:   3,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			copy(out, in[1][:3])
+			return mgl32.Vec4{in[0].X(), in[0].Y(), 0, 1}
+		},
+		Geometry: func(s *soft.Stage, in []soft.Vertex) {
+			// in[0] since there's only one input vertex
+			position, color := in[0].Position, in[0].Varyings
+			s.EmitVertex(position.Add(mgl32.Vec4{-0.2, -0.2, 0, 0}), color) // 1:bottom-left
+			s.EmitVertex(position.Add(mgl32.Vec4{0.2, -0.2, 0, 0}), color)  // 2:bottom-right
+			s.EmitVertex(position.Add(mgl32.Vec4{-0.2, 0.2, 0, 0}), color)  // 3:top-left
+			s.EmitVertex(position.Add(mgl32.Vec4{0.2, 0.2, 0, 0}), color)   // 4:top-right
+			s.EmitVertex(position.Add(mgl32.Vec4{0, 0.4, 0, 0}), []float32{1, 1, 1})
+			s.EndPrimitive()
+		},
+		GeometryInput:    gl.POINTS,
+		GeometryOutput:   gl.TRIANGLE_STRIP,
+		GeometryVaryings: 3,
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{in[0], in[1], in[2], 1}, true
+		},
+	})
+}
@@ -3,8 +3,19 @@ out vec4 FragColor;
 
 in vec2 TexCoords;
 
+struct Material {
+    vec3 diffuse;
+    float opacity;
+    bool hasDiffuseMap;
+};
+
+uniform Material material;
 uniform sampler2D texture_diffuse1;
 
 void main() {
-    FragColor = texture(texture_diffuse1, TexCoords);
+    // parts without a diffuse map take the diffuse color of their material
+    if (material.hasDiffuseMap)
+        FragColor = texture(texture_diffuse1, TexCoords);
+    else
+        FragColor = vec4(material.diffuse, material.opacity);
 }
\ No newline at end of file
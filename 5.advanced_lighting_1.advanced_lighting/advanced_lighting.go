@@ -1,12 +1,12 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
@@ -20,39 +20,31 @@ var (
 	blinn           = false
 	blinnKeyPressed = false
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
+
+	shader             gl.Shader
+	planeVao, planeVbo uint32
+	floorTexture       uint32
+	lightPos           mgl32.Vec3
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(w *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
@@ -61,7 +53,11 @@ func main() {
 
 	// build and compile shaders
 	// -------------------------
-	shader := gl.NewShader("1.advanced_lighting.vs", "1.advanced_lighting.fs")
+	var err error
+	shader, err = gl.LoadShader("1.advanced_lighting.vs", "1.advanced_lighting.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -77,7 +73,6 @@ func main() {
 	}
 
 	// plane vao
-	var planeVao, planeVbo uint32
 	gl.GenVertexArrays(1, &planeVao)
 	gl.GenBuffers(1, &planeVbo)
 	gl.BindVertexArray(planeVao)
@@ -93,7 +88,7 @@ func main() {
 
 	// load texture
 	// ------------
-	floorTexture := loadTexture("../resources/textures/wood.png")
+	floorTexture = loadTexture("../resources/textures/wood.png")
 
 	// shader configuration
 	// --------------------
@@ -102,114 +97,47 @@ func main() {
 
 	// lighting info
 	// -------------
-	lightPos := mgl32.Vec3{0.0, 0.0, 0.0}
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// draw objects
-		shader.Use()
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		view := camera.GetViewMatrix()
-		shader.SetMat4("projection\x00", &projection)
-		shader.SetMat4("view\x00", &view)
-		// set light uniforms
-		cameraPos := camera.Position()
-		shader.SetVec3("viewPos\x00", &cameraPos)
-		shader.SetVec3("lightPos\x00", &lightPos)
-		shader.SetBool("blinn\x00", blinn)
-		// floor
-		gl.BindVertexArray(planeVao)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, floorTexture)
-		gl.DrawArrays(gl.TRIANGLES, 0, 6)
-
-		//if blinn {
-		//	log.Printf("Blinn-Phong")
-		//} else {
-		//	log.Printf("Phong")
-		//}
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
-	}
-
-	// optional: de-allcate all resources once they've outlived their purpose:
-	// -----------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &planeVao)
-	gl.DeleteBuffers(1, &planeVbo)
-
-	glfw.Terminate()
+	lightPos = mgl32.Vec3{0.0, 0.0, 0.0}
+	return nil
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-
-	if window.GetKey(glfw.KeyB) == glfw.Press && !blinnKeyPressed {
-		blinn = !blinn
-		blinnKeyPressed = true
-	}
-	if window.GetKey(glfw.KeyB) == glfw.Release {
-		blinnKeyPressed = false
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(window *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
+	// draw objects
+	shader.Use()
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	view := camera.GetViewMatrix()
+	shader.SetMat4("projection\x00", &projection)
+	shader.SetMat4("view\x00", &view)
+	// set light uniforms
+	cameraPos := camera.Position()
+	shader.SetVec3("viewPos\x00", &cameraPos)
+	shader.SetVec3("lightPos\x00", &lightPos)
+	shader.SetBool("blinn\x00", blinn)
+	// floor
+	gl.BindVertexArray(planeVao)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, floorTexture)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+
+	//if blinn {
+	//	log.Printf("Blinn-Phong")
+	//} else {
+	//	log.Printf("Phong")
+	//}
 }
 
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(window *glfw.Window, xoffset, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	// optional: de-allcate all resources once they've outlived their purpose:
+	// -----------------------------------------------------------------------
+	gl.DeleteVertexArrays(1, &planeVao)
+	gl.DeleteBuffers(1, &planeVbo)
 }
 
 // utility function for loading a 2D texture from file
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("1.advanced_lighting.vs", "1.advanced_lighting.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aNormal": 1, "aTexCoords": 2},
+		Varyings:   8,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			copy(out[0:3], in[0][:3])
+			copy(out[3:6], in[1][:3])
+			out[6], out[7] = in[2].X(), in[2].Y()
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			fragPos, normal, texCoords := mgl32.Vec3{in[0], in[1], in[2]}, mgl32.Vec3{in[3], in[4], in[5]}, mgl32.Vec2{in[6], in[7]}
+			color := s.Texture("floorTexture", texCoords).Vec3()
+			// ambient
+			ambient := color.Mul(0.05)
+			// diffuse
+			lightDir := s.Vec3("lightPos").Sub(fragPos).Normalize()
+			normal = normal.Normalize()
+			diff := soft.Max(lightDir.Dot(normal), 0)
+			diffuse := color.Mul(diff)
+			// specular
+			viewDir := s.Vec3("viewPos").Sub(fragPos).Normalize()
+			var spec float32
+			if s.Bool("blinn") {
+				halfwayDir := lightDir.Add(viewDir).Normalize()
+				spec = soft.Pow(soft.Max(normal.Dot(halfwayDir), 0), 32)
+			} else {
+				reflectDir := soft.Reflect(lightDir.Mul(-1), normal)
+				spec = soft.Pow(soft.Max(viewDir.Dot(reflectDir), 0), 8)
+			}
+			specular := mgl32.Vec3{0.3, 0.3, 0.3}.Mul(spec) // assuming bright white light color
+			return ambient.Add(diffuse).Add(specular).Vec4(1), true
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("2.gamma_correction.vs", "2.gamma_correction.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aNormal": 1, "aTexCoords": 2},
+		Varyings:   8,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			copy(out[0:3], in[0][:3])
+			copy(out[3:6], in[1][:3])
+			out[6], out[7] = in[2].X(), in[2].Y()
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			fragPos, normal, texCoords := mgl32.Vec3{in[0], in[1], in[2]}, mgl32.Vec3{in[3], in[4], in[5]}, mgl32.Vec2{in[6], in[7]}
+			color := s.Texture("floorTexture", texCoords).Vec3()
+			var lighting mgl32.Vec3
+			for i := 0; i < 4; i++ {
+				lightPos, lightColor := s.Vec3(fmt.Sprintf("lightPositions[%v]", i)), s.Vec3(fmt.Sprintf("lightColors[%v]", i))
+				lighting = lighting.Add(blinnPhong(s, normal.Normalize(), fragPos, lightPos, lightColor))
+			}
+			color = soft.Mul3(color, lighting)
+			if s.Bool("gamma") {
+				color = mgl32.Vec3{soft.Pow(color.X(), 1/2.2), soft.Pow(color.Y(), 1/2.2), soft.Pow(color.Z(), 1/2.2)}
+			}
+			return color.Vec4(1), true
+		},
+	})
+}
+
+func blinnPhong(s *soft.Stage, normal, fragPos, lightPos, lightColor mgl32.Vec3) mgl32.Vec3 {
+	// diffuse
+	lightDir := lightPos.Sub(fragPos).Normalize()
+	diff := soft.Max(lightDir.Dot(normal), 0)
+	diffuse := lightColor.Mul(diff)
+	// specular
+	viewDir := s.Vec3("viewPos").Sub(fragPos).Normalize()
+	halfwayDir := lightDir.Add(viewDir).Normalize()
+	spec := soft.Pow(soft.Max(normal.Dot(halfwayDir), 0), 64)
+	specular := lightColor.Mul(spec)
+	// simple attenuation
+	distance := lightPos.Sub(fragPos).Len()
+	attenuation := 1 / distance
+	if s.Bool("gamma") {
+		attenuation = 1 / (distance * distance)
+	}
+	return diffuse.Add(specular).Mul(attenuation)
+}
@@ -1,111 +1,86 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
+	"learn_opengl/geometry"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
 
 const (
-	SRC_WIDTH  = 800
-	SRC_HEIGHT = 600
+	SRC_WIDTH     = 800
+	SRC_HEIGHT    = 600
+	SHADOW_WIDTH  = 1024
+	SHADOW_HEIGHT = 1024
 )
 
 var (
 	gammaEnabled    = false
 	gammaKeyPressed = false
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
 	// meshes
-	planeVao uint32
+	plane, cube, quad gl.Mesh
+
+	simpleDepthShader, debugDepthQuad gl.Shader
+	depthMapFbo                       uint32
+	depthMap                          uint32
+	woodTexture                       uint32
+	lightPos                          mgl32.Vec3
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile shaders
 	// -------------------------
-	simpleDepthShader := gl.NewShader("3.1.1.shadow_mapping_depth.vs", "3.1.1.shadow_mapping_depth.fs")
-	debugDepthQuad := gl.NewShader("3.1.1.debug_quad.vs", "3.1.1.debug_quad_depth.fs")
+	var err error
+	simpleDepthShader, err = gl.LoadShader("3.1.1.shadow_mapping_depth.vs", "3.1.1.shadow_mapping_depth.fs")
+	if err != nil {
+		return err
+	}
+	debugDepthQuad, err = gl.LoadShader("3.1.1.debug_quad.vs", "3.1.1.debug_quad_depth.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
-	planeVertices := []float32{
-		// positions            // normals         // texcoords
-		25.0, -0.5, 25.0, 0.0, 1.0, 0.0, 25.0, 0.0,
-		-25.0, -0.5, 25.0, 0.0, 1.0, 0.0, 0.0, 0.0,
-		-25.0, -0.5, -25.0, 0.0, 1.0, 0.0, 0.0, 25.0,
-
-		25.0, -0.5, 25.0, 0.0, 1.0, 0.0, 25.0, 0.0,
-		-25.0, -0.5, -25.0, 0.0, 1.0, 0.0, 0.0, 25.0,
-		25.0, -0.5, -25.0, 0.0, 1.0, 0.0, 25.0, 25.0,
-	}
-
-	// plane vao
-	var planeVbo uint32
-	gl.GenVertexArrays(1, &planeVao)
-	gl.GenBuffers(1, &planeVbo)
-	gl.BindVertexArray(planeVao)
-	gl.BindBuffer(gl.ARRAY_BUFFER, planeVbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(planeVertices)*4, unsafe.Pointer(&planeVertices[0]), gl.STATIC_DRAW)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 8*4, 0)
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 8*4, 3*4)
-	gl.EnableVertexAttribArray(2)
-	gl.VertexAttribPointer(2, 2, gl.FLOAT, false, 8*4, 6*4)
-	gl.BindVertexArray(0)
+	plane = geometry.Plane(50, 50, 1, mgl32.Vec2{25, 25}).Upload(gl.DefaultMaterial())
+	cube = geometry.Cube(2, 1).Upload(gl.DefaultMaterial())
+	quad = geometry.ScreenQuad().Upload(gl.DefaultMaterial())
 
 	// load texture
 	// ------------
-	woodTexture := loadTexture("../resources/textures/wood.png")
+	woodTexture = loadTexture("../resources/textures/wood.png")
 
 	// configure depth map FBO
 	// -----------------------
-	const (
-		SHADOW_WIDTH  int32 = 1024
-		SHADOW_HEIGHT int32 = 1024
-	)
-	var depthMapFbo uint32
 	gl.GenFramebuffers(1, &depthMapFbo)
 	// create depth texture
-	var depthMap uint32
 	gl.GenTextures(1, &depthMap)
 	gl.BindTexture(gl.TEXTURE_2D, depthMap)
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT, SHADOW_WIDTH, SHADOW_HEIGHT, 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
@@ -127,259 +102,85 @@ func main() {
 
 	// lighting info
 	// -------------
-	lightPos := mgl32.Vec3{-2.0, 4.0, -1.0}
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// 1. render depth of scene to texture (from light's perspective)
-		// --------------------------------------------------------------
-		var (
-			lightProjection, lightView, lightSpaceMatrix mgl32.Mat4
-			near_plane, far_plane                        float32
-		)
-		lightProjection = mgl32.Ortho(-10.0, 10.0, -10.0, 10.0, near_plane, far_plane)
-		lightView = mgl32.LookAtV(lightPos, mgl32.Vec3{0.0}, mgl32.Vec3{0.0, 1.0, 0.0})
-		lightSpaceMatrix = lightProjection.Mul4(lightView)
-		// render scene from light's point of view
-		simpleDepthShader.Use()
-		simpleDepthShader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
-
-		gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
-		gl.BindFramebuffer(gl.FRAMEBUFFER, depthMapFbo)
-		gl.Clear(gl.DEPTH_BUFFER_BIT)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, woodTexture)
-		renderScene(&simpleDepthShader)
-		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	lightPos = mgl32.Vec3{-2.0, 4.0, -1.0}
+	return nil
+}
 
-		// reset viewport
-		gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// 1. render depth of scene to texture (from light's perspective)
+	// --------------------------------------------------------------
+	var (
+		lightProjection, lightView, lightSpaceMatrix mgl32.Mat4
+		near_plane, far_plane                        float32 = 1.0, 7.5
+	)
+	lightProjection = mgl32.Ortho(-10.0, 10.0, -10.0, 10.0, near_plane, far_plane)
+	lightView = mgl32.LookAtV(lightPos, mgl32.Vec3{0.0}, mgl32.Vec3{0.0, 1.0, 0.0})
+	lightSpaceMatrix = lightProjection.Mul4(lightView)
+	// render scene from light's point of view
+	simpleDepthShader.Use()
+	simpleDepthShader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
+
+	gl.Viewport(0, 0, SHADOW_WIDTH, SHADOW_HEIGHT)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, depthMapFbo)
+	gl.Clear(gl.DEPTH_BUFFER_BIT)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, woodTexture)
+	renderScene(&simpleDepthShader)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
 
-		// render depth map to quad for visual debugging
-		// ---------------------------------------------
-		debugDepthQuad.Use()
-		debugDepthQuad.SetFloat32("near_plane\x00", near_plane)
-		debugDepthQuad.SetFloat32("far_plane\x00", far_plane)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, depthMap)
-		renderQuad()
+	// reset viewport
+	gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
-	}
+	// render depth map to quad for visual debugging
+	// ---------------------------------------------
+	debugDepthQuad.Use()
+	debugDepthQuad.SetFloat32("near_plane\x00", near_plane)
+	debugDepthQuad.SetFloat32("far_plane\x00", far_plane)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, depthMap)
+	quad.Draw(&debugDepthQuad)
+}
 
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	// optional: de-allcate all resources once they've outlived their purpose:
 	// -----------------------------------------------------------------------
-	gl.DeleteVertexArrays(1, &planeVao)
-	gl.DeleteBuffers(1, &planeVbo)
-
-	glfw.Terminate()
+	plane.Delete()
+	cube.Delete()
+	quad.Delete()
 }
 
 // render the 3D scene
 // -------------------
 func renderScene(shader *gl.Shader) {
 	// floor
-	model := mgl32.Ident4()
+	model := mgl32.Translate3D(0.0, -0.5, 0.0)
 	shader.SetMat4("model\x00", &model)
-	gl.BindVertexArray(planeVao)
-	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+	plane.Draw(shader)
 	// cubes
 	model = mgl32.Ident4()
 	model = model.Mul4(mgl32.Translate3D(0.0, 1.5, 0.0))
 	model = model.Mul4(mgl32.Scale3D(0.5, 0.5, 0.5))
 	shader.SetMat4("model\x00", &model)
-	renderCube()
+	cube.Draw(shader)
 	model = mgl32.Ident4()
 	model = model.Mul4(mgl32.Translate3D(2.0, 0.0, 1.0))
 	model = model.Mul4(mgl32.Scale3D(0.5, 0.5, 0.5))
 	shader.SetMat4("model\x00", &model)
-	renderCube()
+	cube.Draw(shader)
 	model = mgl32.Ident4()
 	model = model.Mul4(mgl32.Translate3D(-1.0, 0.0, 2.0))
 	model = model.Mul4(mgl32.HomogRotate3D(common.Degree2Radian(60.0), mgl32.Vec3{1.0, 0.0, 1.0}.Normalize()))
 	model = model.Mul4(mgl32.Scale3D(0.25, 0.25, 0.25))
 	shader.SetMat4("model\x00", &model)
-	renderCube()
-}
-
-// renderCube() renders a 1x1 3D cube in NDC.
-// ------------------------------------------
-var (
-	cubeVao, cubeVbo uint32
-)
-
-func renderCube() {
-	// initialize (if necessary)
-	if cubeVao == 0 {
-		vertices := []float32{
-			// back face
-			-1.0, -1.0, -1.0, 0.0, 0.0, -1.0, 0.0, 0.0, // bottom-left
-			1.0, 1.0, -1.0, 0.0, 0.0, -1.0, 1.0, 1.0, // top-right
-			1.0, -1.0, -1.0, 0.0, 0.0, -1.0, 1.0, 0.0, // bottom-right
-			1.0, 1.0, -1.0, 0.0, 0.0, -1.0, 1.0, 1.0, // top-right
-			-1.0, -1.0, -1.0, 0.0, 0.0, -1.0, 0.0, 0.0, // bottom-left
-			-1.0, 1.0, -1.0, 0.0, 0.0, -1.0, 0.0, 1.0, // top-left
-			// front face
-			-1.0, -1.0, 1.0, 0.0, 0.0, 1.0, 0.0, 0.0, // bottom-left
-			1.0, -1.0, 1.0, 0.0, 0.0, 1.0, 1.0, 0.0, // bottom-right
-			1.0, 1.0, 1.0, 0.0, 0.0, 1.0, 1.0, 1.0, // top-right
-			1.0, 1.0, 1.0, 0.0, 0.0, 1.0, 1.0, 1.0, // top-right
-			-1.0, 1.0, 1.0, 0.0, 0.0, 1.0, 0.0, 1.0, // top-left
-			-1.0, -1.0, 1.0, 0.0, 0.0, 1.0, 0.0, 0.0, // bottom-left
-			// left face
-			-1.0, 1.0, 1.0, -1.0, 0.0, 0.0, 1.0, 0.0, // top-right
-			-1.0, 1.0, -1.0, -1.0, 0.0, 0.0, 1.0, 1.0, // top-left
-			-1.0, -1.0, -1.0, -1.0, 0.0, 0.0, 0.0, 1.0, // bottom-left
-			-1.0, -1.0, -1.0, -1.0, 0.0, 0.0, 0.0, 1.0, // bottom-left
-			-1.0, -1.0, 1.0, -1.0, 0.0, 0.0, 0.0, 0.0, // bottom-right
-			-1.0, 1.0, 1.0, -1.0, 0.0, 0.0, 1.0, 0.0, // top-right
-			// right face
-			1.0, 1.0, 1.0, 1.0, 0.0, 0.0, 1.0, 0.0, // top-left
-			1.0, -1.0, -1.0, 1.0, 0.0, 0.0, 0.0, 1.0, // bottom-right
-			1.0, 1.0, -1.0, 1.0, 0.0, 0.0, 1.0, 1.0, // top-right
-			1.0, -1.0, -1.0, 1.0, 0.0, 0.0, 0.0, 1.0, // bottom-right
-			1.0, 1.0, 1.0, 1.0, 0.0, 0.0, 1.0, 0.0, // top-left
-			1.0, -1.0, 1.0, 1.0, 0.0, 0.0, 0.0, 0.0, // bottom-left
-			// bottom face
-			-1.0, -1.0, -1.0, 0.0, -1.0, 0.0, 0.0, 1.0, // top-right
-			1.0, -1.0, -1.0, 0.0, -1.0, 0.0, 1.0, 1.0, // top-left
-			1.0, -1.0, 1.0, 0.0, -1.0, 0.0, 1.0, 0.0, // bottom-left
-			1.0, -1.0, 1.0, 0.0, -1.0, 0.0, 1.0, 0.0, // bottom-left
-			-1.0, -1.0, 1.0, 0.0, -1.0, 0.0, 0.0, 0.0, // bottom-right
-			-1.0, -1.0, -1.0, 0.0, -1.0, 0.0, 0.0, 1.0, // top-right
-			// top face
-			-1.0, 1.0, -1.0, 0.0, 1.0, 0.0, 0.0, 1.0, // top-left
-			1.0, 1.0, 1.0, 0.0, 1.0, 0.0, 1.0, 0.0, // bottom-right
-			1.0, 1.0, -1.0, 0.0, 1.0, 0.0, 1.0, 1.0, // top-right
-			1.0, 1.0, 1.0, 0.0, 1.0, 0.0, 1.0, 0.0, // bottom-right
-			-1.0, 1.0, -1.0, 0.0, 1.0, 0.0, 0.0, 1.0, // top-left
-			-1.0, 1.0, 1.0, 0.0, 1.0, 0.0, 0.0, 0.0, // bottom-left
-		}
-		gl.GenVertexArrays(1, &cubeVao)
-		gl.GenBuffers(1, &cubeVbo)
-		// fill buffer
-		gl.BindBuffer(gl.ARRAY_BUFFER, cubeVbo)
-		gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, unsafe.Pointer(&vertices[0]), gl.STATIC_DRAW)
-		// link vertex attributes
-		gl.BindVertexArray(cubeVao)
-		gl.EnableVertexAttribArray(0)
-		gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 8*4, 0)
-		gl.EnableVertexAttribArray(1)
-		gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 8*4, 3*4)
-		gl.EnableVertexAttribArray(2)
-		gl.VertexAttribPointer(2, 2, gl.FLOAT, false, 8*4, 6*4)
-		gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-		gl.BindVertexArray(0)
-	}
-	// render Cube
-	gl.BindVertexArray(cubeVao)
-	gl.DrawArrays(gl.TRIANGLES, 0, 36)
-	gl.BindVertexArray(0)
-}
-
-// renderQuad() renders a 1x1 XY quad in NDC
-// -----------------------------------------
-var (
-	quadVao, quadVbo uint32
-)
-
-func renderQuad() {
-	if quadVao == 0 {
-		quadVertices := []float32{
-			// positions        // texture Coords
-			-1.0, 1.0, 0.0, 0.0, 1.0,
-			-1.0, -1.0, 0.0, 0.0, 0.0,
-			1.0, 1.0, 0.0, 1.0, 1.0,
-			1.0, -1.0, 0.0, 1.0, 0.0,
-		}
-		// setup plane VAO
-		gl.GenVertexArrays(1, &quadVao)
-		gl.GenBuffers(1, &quadVbo)
-		gl.BindVertexArray(quadVao)
-		gl.BindBuffer(gl.ARRAY_BUFFER, quadVbo)
-		gl.BufferData(gl.ARRAY_BUFFER, len(quadVertices)*4, unsafe.Pointer(&quadVertices[0]), gl.STATIC_DRAW)
-		gl.EnableVertexAttribArray(0)
-		gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 5*4, 0)
-		gl.EnableVertexAttribArray(1)
-		gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 5*4, 3*4)
-	}
-	gl.BindVertexArray(quadVao)
-	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
-	gl.BindVertexArray(0)
-}
-
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-
-	if window.GetKey(glfw.KeyB) == glfw.Press && !gammaKeyPressed {
-		gammaEnabled = !gammaEnabled
-		gammaKeyPressed = true
-	}
-	if window.GetKey(glfw.KeyB) == glfw.Release {
-		gammaKeyPressed = false
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
-}
-
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
+	cube.Draw(shader)
 }
 
 // utility function for loading a 2D texture from file
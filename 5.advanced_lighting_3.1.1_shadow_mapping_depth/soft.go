@@ -0,0 +1,32 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("3.1.1.shadow_mapping_depth.vs", "3.1.1.shadow_mapping_depth.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("lightSpaceMatrix").Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{}, true
+		},
+	})
+	soft.Register("3.1.1.debug_quad.vs", "3.1.1.debug_quad_depth.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 2},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[2].X(), in[2].Y()
+			return in[0].Vec3().Vec4(1)
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			depthValue := s.Texture("depthMap", mgl32.Vec2{in[0], in[1]}).X()
+			return mgl32.Vec4{depthValue, depthValue, depthValue, 1}, true // orthographic
+		},
+	})
+}
@@ -1,69 +1,75 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
 
 const (
-	SRC_WIDTH  = 800
-	SRC_HEIGHT = 600
+	SRC_WIDTH     = 800
+	SRC_HEIGHT    = 600
+	SHADOW_WIDTH  = 1024
+	SHADOW_HEIGHT = 1024
 )
 
 var (
 	gammaEnabled    = false
 	gammaKeyPressed = false
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
 	// meshes
 	planeVao uint32
+
+	shader, simpleDepthShader, debugDepthQuad gl.Shader
+	planeVbo                                  uint32
+	depthMapFbo                               uint32
+	depthMap                                  uint32
+	woodTexture                               uint32
+	lightPos                                  mgl32.Vec3
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile shaders
 	// -------------------------
-	shader := gl.NewShader("3.1.2.shadow_mapping.vs", "3.1.2.shadow_mapping.fs")
-	simpleDepthShader := gl.NewShader("3.1.2.shadow_mapping_depth.vs", "3.1.2.shadow_mapping_depth.fs")
-	debugDepthQuad := gl.NewShader("3.1.2.debug_quad.vs", "3.1.2.debug_quad_depth.fs")
+	var err error
+	shader, err = gl.LoadShader("3.1.2.shadow_mapping.vs", "3.1.2.shadow_mapping.fs")
+	if err != nil {
+		return err
+	}
+	simpleDepthShader, err = gl.LoadShader("3.1.2.shadow_mapping_depth.vs", "3.1.2.shadow_mapping_depth.fs")
+	if err != nil {
+		return err
+	}
+	debugDepthQuad, err = gl.LoadShader("3.1.2.debug_quad.vs", "3.1.2.debug_quad_depth.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -79,7 +85,6 @@ func main() {
 	}
 
 	// plane vao
-	var planeVbo uint32
 	gl.GenVertexArrays(1, &planeVao)
 	gl.GenBuffers(1, &planeVbo)
 	gl.BindVertexArray(planeVao)
@@ -95,18 +100,12 @@ func main() {
 
 	// load texture
 	// ------------
-	woodTexture := loadTexture("../resources/textures/wood.png")
+	woodTexture = loadTexture("../resources/textures/wood.png")
 
 	// configure depth map FBO
 	// -----------------------
-	const (
-		SHADOW_WIDTH  int32 = 1024
-		SHADOW_HEIGHT int32 = 1024
-	)
-	var depthMapFbo uint32
 	gl.GenFramebuffers(1, &depthMapFbo)
 	// create depth texture
-	var depthMap uint32
 	gl.GenTextures(1, &depthMap)
 	gl.BindTexture(gl.TEXTURE_2D, depthMap)
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT, SHADOW_WIDTH, SHADOW_HEIGHT, 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
@@ -131,90 +130,76 @@ func main() {
 
 	// lighting info
 	// -------------
-	lightPos := mgl32.Vec3{-2.0, 4.0, -1.0}
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// 1. render depth of scene to texture (from light's perspective)
-		// --------------------------------------------------------------
-		var (
-			lightProjection, lightView, lightSpaceMatrix mgl32.Mat4
-			near_plane, far_plane                        float32 = 1.0, 7.5
-		)
-		lightProjection = mgl32.Ortho(-10.0, 10.0, -10.0, 10.0, near_plane, far_plane)
-		lightView = mgl32.LookAtV(lightPos, mgl32.Vec3{0.0}, mgl32.Vec3{0.0, 1.0, 0.0})
-		lightSpaceMatrix = lightView.Mul4(lightProjection)
-		// render scene from light's point of view
-		simpleDepthShader.Use()
-		simpleDepthShader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
-
-		gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
-		gl.BindFramebuffer(gl.FRAMEBUFFER, depthMapFbo)
-		gl.Clear(gl.DEPTH_BUFFER_BIT)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, woodTexture)
-		renderScene(&simpleDepthShader)
-		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	lightPos = mgl32.Vec3{-2.0, 4.0, -1.0}
+	return nil
+}
 
-		// reset viewport
-		gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// 1. render depth of scene to texture (from light's perspective)
+	// --------------------------------------------------------------
+	var (
+		lightProjection, lightView, lightSpaceMatrix mgl32.Mat4
+		near_plane, far_plane                        float32 = 1.0, 7.5
+	)
+	lightProjection = mgl32.Ortho(-10.0, 10.0, -10.0, 10.0, near_plane, far_plane)
+	lightView = mgl32.LookAtV(lightPos, mgl32.Vec3{0.0}, mgl32.Vec3{0.0, 1.0, 0.0})
+	lightSpaceMatrix = lightProjection.Mul4(lightView)
+	// render scene from light's point of view
+	simpleDepthShader.Use()
+	simpleDepthShader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
+
+	gl.Viewport(0, 0, SHADOW_WIDTH, SHADOW_HEIGHT)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, depthMapFbo)
+	gl.Clear(gl.DEPTH_BUFFER_BIT)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, woodTexture)
+	renderScene(&simpleDepthShader)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
 
-		// 2. render scene as normal using the generated depth/shadow map
-		// --------------------------------------------------------------
-		shader.Use()
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		view := camera.GetViewMatrix()
-		shader.SetMat4("projection\x00", &projection)
-		shader.SetMat4("view\x00", &view)
-		// set light uniforms
-		cameraPos := camera.Position()
-		shader.SetVec3("viewPos\x00", &cameraPos)
-		shader.SetVec3("lightPos\x00", &lightPos)
-		shader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, woodTexture)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, depthMap)
-		renderScene(&shader)
+	// reset viewport
+	gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// render depth map to quad for visual debugging
-		// ---------------------------------------------
-		debugDepthQuad.Use()
-		debugDepthQuad.SetFloat32("near_plane\x00", near_plane)
-		debugDepthQuad.SetFloat32("far_plane\x00", far_plane)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, depthMap)
-		//renderQuad()
+	// 2. render scene as normal using the generated depth/shadow map
+	// --------------------------------------------------------------
+	shader.Use()
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	view := camera.GetViewMatrix()
+	shader.SetMat4("projection\x00", &projection)
+	shader.SetMat4("view\x00", &view)
+	// set light uniforms
+	cameraPos := camera.Position()
+	shader.SetVec3("viewPos\x00", &cameraPos)
+	shader.SetVec3("lightPos\x00", &lightPos)
+	shader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, woodTexture)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, depthMap)
+	renderScene(&shader)
 
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
-	}
+	// render depth map to quad for visual debugging
+	// ---------------------------------------------
+	debugDepthQuad.Use()
+	debugDepthQuad.SetFloat32("near_plane\x00", near_plane)
+	debugDepthQuad.SetFloat32("far_plane\x00", far_plane)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, depthMap)
+	//renderQuad()
+}
 
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	// optional: de-allcate all resources once they've outlived their purpose:
 	// -----------------------------------------------------------------------
 	gl.DeleteVertexArrays(1, &planeVao)
 	gl.DeleteBuffers(1, &planeVbo)
-
-	glfw.Terminate()
 }
 
 // render the 3D scene
@@ -319,60 +304,6 @@ func renderCube() {
 	gl.BindVertexArray(0)
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-
-	if window.GetKey(glfw.KeyB) == glfw.Press && !gammaKeyPressed {
-		gammaEnabled = !gammaEnabled
-		gammaKeyPressed = true
-	}
-	if window.GetKey(glfw.KeyB) == glfw.Release {
-		gammaKeyPressed = false
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
-}
-
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
-}
-
 // utility function for loading a 2D texture from file
 func loadTexture(path string) uint32 {
 	var textureId uint32
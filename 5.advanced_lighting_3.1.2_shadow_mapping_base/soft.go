@@ -0,0 +1,81 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("3.1.2.shadow_mapping.vs", "3.1.2.shadow_mapping.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aNormal": 1, "aTexCoords": 2},
+		Varyings:   12,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			model := s.Mat4("model")
+			fragPos := model.Mul4x1(in[0].Vec3().Vec4(1)).Vec3()
+			normal := model.Mat3().Inv().Transpose().Mul3x1(in[1].Vec3())
+			fragPosLightSpace := s.Mat4("lightSpaceMatrix").Mul4x1(fragPos.Vec4(1))
+			copy(out[0:3], fragPos[:])
+			copy(out[3:6], normal[:])
+			out[6], out[7] = in[2].X(), in[2].Y()
+			copy(out[8:12], fragPosLightSpace[:])
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(model).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			fragPos, normal, texCoords := mgl32.Vec3{in[0], in[1], in[2]}, mgl32.Vec3{in[3], in[4], in[5]}, mgl32.Vec2{in[6], in[7]}
+			fragPosLightSpace := mgl32.Vec4{in[8], in[9], in[10], in[11]}
+			color := s.Texture("diffuseTexture", texCoords).Vec3()
+			normal = normal.Normalize()
+			lightColor := mgl32.Vec3{0.3, 0.3, 0.3}
+			// ambient
+			ambient := lightColor.Mul(0.3)
+			// diffuse
+			lightDir := s.Vec3("lightPos").Sub(fragPos).Normalize()
+			diff := soft.Max(lightDir.Dot(normal), 0)
+			diffuse := lightColor.Mul(diff)
+			// specular
+			viewDir := s.Vec3("viewPos").Sub(fragPos).Normalize()
+			halfwayDir := lightDir.Add(viewDir).Normalize()
+			spec := soft.Pow(soft.Max(normal.Dot(halfwayDir), 0), 64)
+			specular := lightColor.Mul(spec)
+			// calculate shadow
+			shadow := shadowCalculation(s, fragPosLightSpace)
+			lighting := soft.Mul3(ambient.Add(diffuse.Add(specular).Mul(1-shadow)), color)
+			return lighting.Vec4(1), true
+		},
+	})
+	soft.Register("3.1.2.shadow_mapping_depth.vs", "3.1.2.shadow_mapping_depth.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("lightSpaceMatrix").Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{}, true
+		},
+	})
+	soft.Register("3.1.2.debug_quad.vs", "3.1.2.debug_quad_depth.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 1},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[1].X(), in[1].Y()
+			return in[0].Vec3().Vec4(1)
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			depthValue := s.Texture("depthMap", mgl32.Vec2{in[0], in[1]}).X()
+			return mgl32.Vec4{depthValue, depthValue, depthValue, 1}, true // orthographic
+		},
+	})
+}
+
+func shadowCalculation(s *soft.Stage, fragPosLightSpace mgl32.Vec4) float32 {
+	// perform perspective divide, and transform to [0, 1] range
+	projCoords := fragPosLightSpace.Vec3().Mul(1 / fragPosLightSpace.W()).Mul(0.5).Add(mgl32.Vec3{0.5, 0.5, 0.5})
+	// get closest depth value from light's perspective (using [0,1] range fragPosLight as coords)
+	closestDepth := s.Texture("shadowMap", projCoords.Vec2()).X()
+	// check whether current frag pos is in shadow
+	if projCoords.Z() > closestDepth {
+		return 1
+	}
+	return 0
+}
@@ -1,69 +1,91 @@
 package main
 
 import (
+	"learn_opengl/app"
 	"learn_opengl/common"
 	"learn_opengl/gl"
 	"log"
 	"unsafe"
 
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/go-stbi"
 )
 
 const (
-	SRC_WIDTH  = 800
-	SRC_HEIGHT = 600
+	SRC_WIDTH     = 800
+	SRC_HEIGHT    = 600
+	SHADOW_WIDTH  = 1024
+	SHADOW_HEIGHT = 1024
 )
 
+// debugVertex is a vertex of the debug lines.
+type debugVertex struct {
+	Position mgl32.Vec3
+	Color    mgl32.Vec3
+}
+
 var (
 	gammaEnabled    = false
 	gammaKeyPressed = false
+	// the light frustum is shown as debug lines while toggled on with space
+	showFrustum       = false
+	frustumKeyPressed = false
 	// camera
-	camera     *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
-	lastX      float64        = SRC_WIDTH / 2.0
-	lastY      float64        = SRC_HEIGHT / 2.0
-	firstMouse bool           = true
-	// timing
-	deltaTime, lastFrame float64
+	camera *common.Camera = common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0})
 	// meshes
 	planeVao uint32
+
+	shader, simpleDepthShader, debugDepthQuad gl.Shader
+	debugLinesShader                          gl.Shader
+	debugLines                                *gl.DynamicMesh
+	planeVbo                                  uint32
+	depthMapFbo                               uint32
+	depthMap                                  uint32
+	woodTexture                               uint32
+	lightPos                                  mgl32.Vec3
 )
 
 func main() {
-	// glfw: initialize and configure
-	// ------------------------------
-	glfw.Init()
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	// glfw: window creation
-	// ---------------------
-	window, err := glfw.CreateWindow(SRC_WIDTH, SRC_HEIGHT, "LearnOpenGL", nil, nil)
-	if err != nil {
-		log.Fatalf("Failed to create GLFW window")
-	}
-
-	window.MakeContextCurrent()
-	gl.Init()
-	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
-		gl.Viewport(0, 0, int32(width), int32(height))
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    camera,
+		FlyCamera: true,
 	})
-	window.SetCursorPosCallback(mouseCallback)
-	window.SetScrollCallback(scrollCallback)
-
-	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	a.Init = setup
+	a.Input = processInput
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
 
+func setup(a *app.App) error {
 	// configure global opengl state
 	// -----------------------------
 	gl.Enable(gl.DEPTH_TEST)
 
 	// build and compile shaders
 	// -------------------------
-	shader := gl.NewShader("3.1.3.shadow_mapping.vs", "3.1.3.shadow_mapping.fs")
-	simpleDepthShader := gl.NewShader("3.1.3.shadow_mapping_depth.vs", "3.1.3.shadow_mapping_depth.fs")
-	debugDepthQuad := gl.NewShader("3.1.3.debug_quad.vs", "3.1.3.debug_quad_depth.fs")
+	var err error
+	shader, err = gl.LoadShader("3.1.3.shadow_mapping.vs", "3.1.3.shadow_mapping.fs")
+	if err != nil {
+		return err
+	}
+	simpleDepthShader, err = gl.LoadShader("3.1.3.shadow_mapping_depth.vs", "3.1.3.shadow_mapping_depth.fs")
+	if err != nil {
+		return err
+	}
+	debugDepthQuad, err = gl.LoadShader("3.1.3.debug_quad.vs", "3.1.3.debug_quad_depth.fs")
+	if err != nil {
+		return err
+	}
+	debugLinesShader, err = gl.LoadShader("3.1.3.debug_lines.vs", "3.1.3.debug_lines.fs")
+	if err != nil {
+		return err
+	}
 
 	// set up vertex data (and buffer(s)) and configure vertex attributes
 	// ------------------------------------------------------------------
@@ -79,7 +101,6 @@ func main() {
 	}
 
 	// plane vao
-	var planeVbo uint32
 	gl.GenVertexArrays(1, &planeVao)
 	gl.GenBuffers(1, &planeVbo)
 	gl.BindVertexArray(planeVao)
@@ -93,20 +114,25 @@ func main() {
 	gl.VertexAttribPointer(2, 2, gl.FLOAT, false, 8*4, 6*4)
 	gl.BindVertexArray(0)
 
+	// debug lines, rebuilt every frame they are shown: two copies of the buffers so that a frame doesn't wait for
+	// the previous one to be drawn
+	var v debugVertex
+	debugLines = gl.NewDynamicMesh(gl.VertexLayout{
+		Strides: []int32{int32(unsafe.Sizeof(v))},
+		Attributes: []gl.VertexAttribute{
+			{Location: 0, Components: 3, Type: gl.FLOAT, Offset: int(unsafe.Offsetof(v.Position))},
+			{Location: 1, Components: 3, Type: gl.FLOAT, Offset: int(unsafe.Offsetof(v.Color))},
+		},
+	}, gl.Material{}, gl.DynamicMeshOptions{Usage: gl.STREAM_DRAW, Mode: gl.LINES, Buffers: 2})
+
 	// load texture
 	// ------------
-	woodTexture := loadTexture("../resources/textures/wood.png")
+	woodTexture = loadTexture("../resources/textures/wood.png")
 
 	// configure depth map FBO
 	// -----------------------
-	const (
-		SHADOW_WIDTH  int32 = 1024
-		SHADOW_HEIGHT int32 = 1024
-	)
-	var depthMapFbo uint32
 	gl.GenFramebuffers(1, &depthMapFbo)
 	// create depth texture
-	var depthMap uint32
 	gl.GenTextures(1, &depthMap)
 	gl.BindTexture(gl.TEXTURE_2D, depthMap)
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT, SHADOW_WIDTH, SHADOW_HEIGHT, 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
@@ -135,89 +161,122 @@ func main() {
 
 	// lighting info
 	// -------------
-	lightPos := mgl32.Vec3{-2.0, 4.0, -1.0}
-
-	// render loop
-	// -----------
-	for !window.ShouldClose() {
-		// per-frame time logic
-		// --------------------
-		currentFrame := glfw.GetTime()
-		deltaTime = currentFrame - lastFrame
-		lastFrame = currentFrame
-
-		// input
-		// -----
-		processInput(window)
-
-		// render
-		// ------
-		gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// 1. render depth of scene to texture (from light's perspective)
-		// --------------------------------------------------------------
-		var (
-			lightProjection, lightView, lightSpaceMatrix mgl32.Mat4
-			near_plane, far_plane                        float32 = 1.0, 7.5
-		)
-		lightProjection = mgl32.Ortho(-10.0, 10.0, -10.0, 10.0, near_plane, far_plane)
-		lightView = mgl32.LookAtV(lightPos, mgl32.Vec3{0.0}, mgl32.Vec3{0.0, 1.0, 0.0})
-		lightSpaceMatrix = lightProjection.Mul4(lightView)
-		// render scene from light's point of view
-		simpleDepthShader.Use()
-		simpleDepthShader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
-
-		gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
-		gl.BindFramebuffer(gl.FRAMEBUFFER, depthMapFbo)
-		gl.Clear(gl.DEPTH_BUFFER_BIT)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, woodTexture)
-		renderScene(&simpleDepthShader)
-		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-
-		// reset viewport
-		gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		// 2. render scene as normal using the generated depth/shadow map
-		// --------------------------------------------------------------
-		shader.Use()
-		projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
-		view := camera.GetViewMatrix()
-		shader.SetMat4("projection\x00", &projection)
-		shader.SetMat4("view\x00", &view)
-		// set light uniforms
-		viewPos := camera.Position()
-		shader.SetVec3("viewPos\x00", &viewPos)
-		shader.SetVec3("lightPos\x00", &lightPos)
-		shader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, woodTexture)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, depthMap)
-		renderScene(&shader)
-
-		// render Depth map to quad for visual debugging
-		// ---------------------------------------------
-		debugDepthQuad.Use()
-		debugDepthQuad.SetFloat32("near_plane\x00", near_plane)
-		debugDepthQuad.SetFloat32("far_plane\x00", far_plane)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, depthMap)
-
-		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
-		// -------------------------------------------------------------------------------
-		window.SwapBuffers()
-		glfw.WaitEventsTimeout(0.01)
+	lightPos = mgl32.Vec3{-2.0, 4.0, -1.0}
+	return nil
+}
+
+func render(a *app.App) {
+	// render
+	// ------
+	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// 1. render depth of scene to texture (from light's perspective)
+	// --------------------------------------------------------------
+	var (
+		lightProjection, lightView, lightSpaceMatrix mgl32.Mat4
+		near_plane, far_plane                        float32 = 1.0, 7.5
+	)
+	lightProjection = mgl32.Ortho(-10.0, 10.0, -10.0, 10.0, near_plane, far_plane)
+	lightView = mgl32.LookAtV(lightPos, mgl32.Vec3{0.0}, mgl32.Vec3{0.0, 1.0, 0.0})
+	lightSpaceMatrix = lightProjection.Mul4(lightView)
+	// render scene from light's point of view
+	simpleDepthShader.Use()
+	simpleDepthShader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
+
+	gl.Viewport(0, 0, SHADOW_WIDTH, SHADOW_HEIGHT)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, depthMapFbo)
+	gl.Clear(gl.DEPTH_BUFFER_BIT)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, woodTexture)
+	renderScene(&simpleDepthShader)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	// reset viewport
+	gl.Viewport(0, 0, SRC_WIDTH, SRC_HEIGHT)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// 2. render scene as normal using the generated depth/shadow map
+	// --------------------------------------------------------------
+	shader.Use()
+	projection := mgl32.Perspective(common.Degree2Radian(float32(camera.Zoom())), SRC_WIDTH/SRC_HEIGHT, 0.1, 100.0)
+	view := camera.GetViewMatrix()
+	shader.SetMat4("projection\x00", &projection)
+	shader.SetMat4("view\x00", &view)
+	// set light uniforms
+	viewPos := camera.Position()
+	shader.SetVec3("viewPos\x00", &viewPos)
+	shader.SetVec3("lightPos\x00", &lightPos)
+	shader.SetMat4("lightSpaceMatrix\x00", &lightSpaceMatrix)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, woodTexture)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, depthMap)
+	renderScene(&shader)
+
+	// render the light frustum
+	// ------------------------
+	if showFrustum {
+		debugLines.Next()
+		if err := debugLines.SetVertices([][]byte{gl.VertexBytes(frustumLines(lightSpaceMatrix, lightPos))}); err != nil {
+			log.Printf("debug lines: %v", err)
+		}
+		debugLinesShader.Use()
+		debugLinesShader.SetMat4("projection\x00", &projection)
+		debugLinesShader.SetMat4("view\x00", &view)
+		debugLines.Draw(&debugLinesShader)
 	}
 
+	// render Depth map to quad for visual debugging
+	// ---------------------------------------------
+	debugDepthQuad.Use()
+	debugDepthQuad.SetFloat32("near_plane\x00", near_plane)
+	debugDepthQuad.SetFloat32("far_plane\x00", far_plane)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, depthMap)
+}
+
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
 	// optional: de-allcate all resources once they've outlived their purpose:
 	// -----------------------------------------------------------------------
 	gl.DeleteVertexArrays(1, &planeVao)
 	gl.DeleteBuffers(1, &planeVbo)
+	debugLines.Delete()
+}
 
-	glfw.Terminate()
+// process all input: query whether relevant keys are pressed/released this frame and react accordingly
+// ---------------------------------------------------------------------------------------------------------
+func processInput(a *app.App) {
+	if a.KeyPressed(app.KEY_SPACE) && !frustumKeyPressed {
+		showFrustum = !showFrustum
+		frustumKeyPressed = true
+	}
+	if !a.KeyPressed(app.KEY_SPACE) {
+		frustumKeyPressed = false
+	}
+}
+
+// frustumLines returns the edges of the volume the shadow map covers, the clip space cube of the light transformed
+// back to world space, and a line from the light to the point it looks at.
+func frustumLines(lightSpaceMatrix mgl32.Mat4, lightPos mgl32.Vec3) []debugVertex {
+	inverse := lightSpaceMatrix.Inv()
+	var corners [8]mgl32.Vec3
+	for i := range corners {
+		p := inverse.Mul4x1(mgl32.Vec4{float32(i&1*2 - 1), float32(i>>1&1*2 - 1), float32(i>>2&1*2 - 1), 1})
+		corners[i] = p.Vec3().Mul(1 / p.W())
+	}
+	yellow, white := mgl32.Vec3{1.0, 1.0, 0.0}, mgl32.Vec3{1.0, 1.0, 1.0}
+	var lines []debugVertex
+	for i := range corners {
+		for _, axis := range []int{1, 2, 4} {
+			if i&axis == 0 {
+				lines = append(lines, debugVertex{corners[i], yellow}, debugVertex{corners[i|axis], yellow})
+			}
+		}
+	}
+	return append(lines, debugVertex{lightPos, white}, debugVertex{mgl32.Vec3{}, white})
 }
 
 // render the 3D scene
@@ -322,60 +381,6 @@ func renderCube() {
 	gl.BindVertexArray(0)
 }
 
-func processInput(window *glfw.Window) {
-	if window.GetKey(glfw.KeyEscape) == glfw.Press {
-		window.SetShouldClose(true)
-	}
-
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		camera.ProcessKeyboard(common.Forward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		camera.ProcessKeyboard(common.Backward, deltaTime)
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		camera.ProcessKeyboard(common.Left, deltaTime)
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		camera.ProcessKeyboard(common.Right, deltaTime)
-	}
-
-	if window.GetKey(glfw.KeyB) == glfw.Press && !gammaKeyPressed {
-		gammaEnabled = !gammaEnabled
-		gammaKeyPressed = true
-	}
-	if window.GetKey(glfw.KeyB) == glfw.Release {
-		gammaKeyPressed = false
-	}
-}
-
-// glfw: whenever the mouse moves, this callback is called
-// -------------------------------------------------------
-func mouseCallback(_ *glfw.Window, xposIn, yposIn float64) {
-	xpos := xposIn
-	ypos := yposIn
-
-	if firstMouse {
-		lastX = xpos
-		lastY = ypos
-		firstMouse = false
-	}
-
-	xoffset := xpos - lastX
-	yoffset := lastY - ypos // reversed since y-coordinates go from bottom to top
-
-	lastX = xpos
-	lastY = ypos
-
-	camera.ProcessMouseMovement(xoffset, yoffset, true)
-}
-
-// glfw: whenever the mouse scroll wheel scrolls, this callback is called
-// ----------------------------------------------------------------------
-func scrollCallback(_ *glfw.Window, _, yoffset float64) {
-	camera.ProcessMouseScroll(yoffset)
-}
-
 // utility function for loading a 2D texture from file
 func loadTexture(path string) uint32 {
 	var textureId uint32
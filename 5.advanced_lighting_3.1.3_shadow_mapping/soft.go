@@ -0,0 +1,108 @@
+package main
+
+import (
+	"learn_opengl/gl/soft"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Go version of the shaders for the software backend (LEARN_OPENGL_SOFTWARE=1)
+func init() {
+	soft.Register("3.1.3.shadow_mapping.vs", "3.1.3.shadow_mapping.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aNormal": 1, "aTexCoords": 2},
+		Varyings:   12,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			model := s.Mat4("model")
+			fragPos := model.Mul4x1(in[0].Vec3().Vec4(1)).Vec3()
+			normal := model.Mat3().Inv().Transpose().Mul3x1(in[1].Vec3())
+			fragPosLightSpace := s.Mat4("lightSpaceMatrix").Mul4x1(fragPos.Vec4(1))
+			copy(out[0:3], fragPos[:])
+			copy(out[3:6], normal[:])
+			out[6], out[7] = in[2].X(), in[2].Y()
+			copy(out[8:12], fragPosLightSpace[:])
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(model).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			fragPos, normal, texCoords := mgl32.Vec3{in[0], in[1], in[2]}, mgl32.Vec3{in[3], in[4], in[5]}, mgl32.Vec2{in[6], in[7]}
+			fragPosLightSpace := mgl32.Vec4{in[8], in[9], in[10], in[11]}
+			color := s.Texture("diffuseTexture", texCoords).Vec3()
+			normal = normal.Normalize()
+			lightColor := mgl32.Vec3{0.3, 0.3, 0.3}
+			// ambient
+			ambient := lightColor.Mul(0.3)
+			// diffuse
+			lightDir := s.Vec3("lightPos").Sub(fragPos).Normalize()
+			diff := soft.Max(lightDir.Dot(normal), 0)
+			diffuse := lightColor.Mul(diff)
+			// specular
+			viewDir := s.Vec3("viewPos").Sub(fragPos).Normalize()
+			halfwayDir := lightDir.Add(viewDir).Normalize()
+			spec := soft.Pow(soft.Max(normal.Dot(halfwayDir), 0), 64)
+			specular := lightColor.Mul(spec)
+			// calculate shadow
+			shadow := shadowCalculation(s, fragPosLightSpace, fragPos, normal)
+			lighting := soft.Mul3(ambient.Add(diffuse.Add(specular).Mul(1-shadow)), color)
+			return lighting.Vec4(1), true
+		},
+	})
+	soft.Register("3.1.3.shadow_mapping_depth.vs", "3.1.3.shadow_mapping_depth.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return s.Mat4("lightSpaceMatrix").Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{}, true
+		},
+	})
+	soft.Register("3.1.3.debug_quad.vs", "3.1.3.debug_quad_depth.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoords": 1},
+		Varyings:   2,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[1].X(), in[1].Y()
+			return in[0].Vec3().Vec4(1)
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			depthValue := s.Texture("depthMap", mgl32.Vec2{in[0], in[1]}).X()
+			return mgl32.Vec4{depthValue, depthValue, depthValue, 1}, true // orthographic
+		},
+	})
+	soft.Register("3.1.3.debug_lines.vs", "3.1.3.debug_lines.fs", &soft.Program{
+		Attributes: map[string]int{"aPos": 0, "aColor": 1},
+		Varyings:   3,
+		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			copy(out, in[1][:3])
+			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4x1(in[0].Vec3().Vec4(1))
+		},
+		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{in[0], in[1], in[2], 1}, true
+		},
+	})
+}
+
+func shadowCalculation(s *soft.Stage, fragPosLightSpace mgl32.Vec4, fragPos, normal mgl32.Vec3) float32 {
+	// perform perspective divide, and transform to [0, 1] range
+	projCoords := fragPosLightSpace.Vec3().Mul(1 / fragPosLightSpace.W()).Mul(0.5).Add(mgl32.Vec3{0.5, 0.5, 0.5})
+	// get depth of current fragment from light's perspective
+	currentDepth := projCoords.Z()
+	// calculate bias (based on depth map resolution and slope)
+	lightDir := s.Vec3("lightPos").Sub(fragPos).Normalize()
+	bias := soft.Max(0.05*(1-normal.Dot(lightDir)), 0.005)
+	// PCF
+	var shadow float32
+	width, height := s.TextureSize("shadowMap", 0)
+	texelSize := mgl32.Vec2{1 / float32(width), 1 / float32(height)}
+	for x := -1; x <= 1; x++ {
+		for y := -1; y <= 1; y++ {
+			pcfDepth := s.Texture("shadowMap", projCoords.Vec2().Add(mgl32.Vec2{float32(x) * texelSize.X(), float32(y) * texelSize.Y()})).X()
+			if currentDepth-bias > pcfDepth {
+				shadow++
+			}
+		}
+	}
+	shadow /= 9
+	// keep the shadow at 0.0 when outside the far_plane region of the light's frustum.
+	if projCoords.Z() > 1 {
+		shadow = 0
+	}
+	return shadow
+}
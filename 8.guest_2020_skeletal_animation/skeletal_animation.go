@@ -0,0 +1,107 @@
+package main
+
+import (
+	"learn_opengl/app"
+	"learn_opengl/assimp"
+	"learn_opengl/common"
+	"learn_opengl/gl"
+	"log"
+	"strconv"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/huoshan017/go-stbi"
+)
+
+const (
+	SRC_WIDTH  = 800
+	SRC_HEIGHT = 600
+)
+
+var (
+	ourShader gl.Shader
+	ourModel  *assimp.Model
+	animator  *assimp.Animator
+)
+
+func main() {
+	// the app owns the window, the render loop and the fly camera input
+	a := app.New(app.Config{
+		Width:     SRC_WIDTH,
+		Height:    SRC_HEIGHT,
+		Camera:    common.NewCameraDefaultExceptPosition(mgl32.Vec3{0.0, 0.0, 3.0}),
+		FlyCamera: true,
+	})
+	a.Init = setup
+	a.Update = update
+	a.Render = render
+	a.Close = cleanup
+	if err := a.Run(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+func setup(a *app.App) error {
+	// glTF's texture coordinates start at the top left, the images are loaded as they are stored
+	stbi.SetFlipVerticallyOnLoad(false)
+
+	// configure global opengl state
+	// -----------------------------
+	gl.Enable(gl.DEPTH_TEST)
+
+	// build and compile shaders
+	// -------------------------
+	var err error
+	ourShader, err = gl.LoadShader("anim_model.vs", "anim_model.fs")
+	if err != nil {
+		return err
+	}
+
+	// load models
+	// -----------
+	// assimp reads the skin and the swing animation of the glTF file like any other format
+	ourModel, err = assimp.LoadModel("../resources/objects/tentacle/tentacle.gltf", assimp.LoadOptions{MissingTexture: true})
+	if err != nil {
+		return err
+	}
+	swingAnimation, err := assimp.LoadAnimation("../resources/objects/tentacle/tentacle.gltf", ourModel)
+	if err != nil {
+		return err
+	}
+	animator = assimp.NewAnimator(swingAnimation)
+	return nil
+}
+
+func update(a *app.App, dt float64) {
+	animator.UpdateAnimation(dt)
+}
+
+func render(a *app.App) {
+	gl.ClearColor(0.05, 0.05, 0.05, 1.0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	// don't forget to enable shader before setting uniforms
+	ourShader.Use()
+
+	// view/projection transformations
+	projection := a.Projection(0.1, 100.0)
+	view := a.View()
+	ourShader.SetMat4("projection", &projection)
+	ourShader.SetMat4("view", &view)
+
+	transforms := animator.FinalBoneMatrices()
+	for i := range transforms {
+		ourShader.SetMat4("finalBonesMatrices["+strconv.Itoa(i)+"]", &transforms[i])
+	}
+
+	// render the loaded model
+	model := mgl32.Translate3D(0.0, -0.8, 0.0) // translate it down so it's at the center of the scene
+	model = model.Mul4(mgl32.Scale3D(0.8, 0.8, 0.8))
+	ourModel.Draw(&ourShader, model)
+}
+
+// optional: de-allocate all resources once they've outlived their purpose:
+// ------------------------------------------------------------------------
+func cleanup(a *app.App) {
+	ourModel.Release()
+	ourShader.Delete()
+}
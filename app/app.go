@@ -0,0 +1,197 @@
+package app
+
+import (
+	"learn_opengl/async"
+	"learn_opengl/common"
+	"learn_opengl/gl"
+	"runtime"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	DEFAULT_WIDTH  = 800
+	DEFAULT_HEIGHT = 600
+	DEFAULT_TITLE  = "LearnOpenGL"
+	// at most this many fixed updates run per frame, so that a slow frame doesn't snowball
+	MAX_FIXED_STEPS_PER_FRAME = 8
+)
+
+func init() {
+	// GLFW and the GL context must stay on the main thread, the goroutines of the loader run beside the loop
+	runtime.LockOSThread()
+}
+
+// Config holds the window and loop settings of an App.
+type Config struct {
+	// Width and Height of the window, DEFAULT_WIDTH and DEFAULT_HEIGHT if zero
+	Width, Height int
+	// Title of the window, DEFAULT_TITLE if empty
+	Title string
+	// Samples is the number of MSAA samples of the default framebuffer, 0 disables multisampling
+	Samples int
+	// VSync synchronizes buffer swaps with the display refresh
+	VSync bool
+	// FixedTimestep in seconds makes Update run at a fixed rate, 0 passes the variable frame time instead
+	FixedTimestep float64
+	// Camera is driven by the keyboard (WASD), the mouse and the scroll wheel when FlyCamera is set
+	Camera    *common.Camera
+	FlyCamera bool
+	// Headless renders into an invisible window and writes the frames as PNG files instead of running interactively,
+	// it is read from the environment by Run when nil, see HeadlessFromEnv
+	Headless *HeadlessConfig
+	// Loader uploads what its workers loaded for up to UploadBudget (async.DEFAULT_UPLOAD_BUDGET if zero) at the
+	// start of every frame, before Update. A headless App flushes it instead, so that loads finish at the same frame
+	// on every run. Run closes it after the last frame, or after Init failed, before Close
+	Loader       *async.Loader
+	UploadBudget time.Duration
+}
+
+// App owns the window and the main loop of a demo, the demo only provides the hooks. Every hook is optional.
+type App struct {
+	Config
+	// Init is called once the GL context is current, before the first frame
+	Init func(a *App) error
+	// Input is called every frame before Update, KeyPressed tells the state of the keyboard
+	Input func(a *App)
+	// Mouse is called with the offset of the captured cursor since the last move, y goes up
+	Mouse func(a *App, xoffset, yoffset float64)
+	// Scroll is called with the offset of the scroll wheel
+	Scroll func(a *App, yoffset float64)
+	// Update advances the scene by dt seconds
+	Update func(a *App, dt float64)
+	// Render draws a frame
+	Render func(a *App)
+	// Resize is called after the viewport was set to the new framebuffer size
+	Resize func(a *App, width, height int)
+	// Close is called after the last frame, while the GL context is still current. It is called as well when Init
+	// fails, with whatever Init created until then
+	Close func(a *App)
+
+	platform
+	width      int
+	height     int
+	time       float64
+	deltaTime  float64
+	lastX      float64
+	lastY      float64
+	firstMouse bool
+	captured   []string
+}
+
+func New(config Config) *App {
+	if config.Width == 0 {
+		config.Width = DEFAULT_WIDTH
+	}
+	if config.Height == 0 {
+		config.Height = DEFAULT_HEIGHT
+	}
+	if config.Title == "" {
+		config.Title = DEFAULT_TITLE
+	}
+	return &App{Config: config, firstMouse: true}
+}
+
+// Size returns the framebuffer size.
+func (a *App) Size() (int, int) {
+	return a.width, a.height
+}
+
+func (a *App) Aspect() float32 {
+	if a.height == 0 {
+		return 1
+	}
+	return float32(a.width) / float32(a.height)
+}
+
+// Time returns the scene time in seconds, it advances by the timestep of every Update.
+func (a *App) Time() float64 {
+	return a.time
+}
+
+// DeltaTime returns the duration of the last frame.
+func (a *App) DeltaTime() float64 {
+	return a.deltaTime
+}
+
+// Projection returns the perspective projection for the camera zoom and the framebuffer aspect.
+func (a *App) Projection(near, far float32) mgl32.Mat4 {
+	fov := float32(common.ZOOM)
+	if a.Camera != nil {
+		fov = float32(a.Camera.Zoom())
+	}
+	return mgl32.Perspective(common.Degree2Radian(fov), a.Aspect(), near, far)
+}
+
+// View returns the view matrix of the camera, the identity without one.
+func (a *App) View() mgl32.Mat4 {
+	if a.Camera == nil {
+		return mgl32.Ident4()
+	}
+	return a.Camera.GetViewMatrix()
+}
+
+// Run creates the window, calls Init and runs the loop until the window is closed, or until the frames of a headless
+// App are written.
+func (a *App) Run() error {
+	if a.Headless == nil {
+		headless, err := HeadlessFromEnv()
+		if err != nil {
+			return err
+		}
+		a.Headless = headless
+	}
+	if a.Headless != nil {
+		a.Headless.setDefaults()
+	}
+
+	var release func()
+	var err error
+	if a.Headless != nil && (a.Headless.Software || !windowed) {
+		release, err = a.openSoftware()
+	} else {
+		release, err = a.openWindow()
+	}
+	if err != nil {
+		return err
+	}
+	defer release()
+	// the workers of a loader created by a failing Init must stop too
+	defer func() {
+		if a.Loader != nil {
+			a.Loader.Close()
+		}
+		if a.Close != nil {
+			a.Close(a)
+		}
+		gl.ReportLeaks()
+	}()
+
+	if a.Init != nil {
+		if err = a.Init(a); err != nil {
+			return err
+		}
+	}
+
+	if a.Headless != nil {
+		return a.runHeadless()
+	}
+	a.loop()
+	return nil
+}
+
+func (a *App) update(dt float64) {
+	a.time += dt
+	if a.Update != nil {
+		a.Update(a, dt)
+	}
+}
+
+func (a *App) resize(width, height int) {
+	a.width, a.height = width, height
+	gl.Viewport(0, 0, int32(width), int32(height))
+	if a.Resize != nil {
+		a.Resize(a, width, height)
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"errors"
+	"learn_opengl/async"
+	"testing"
+)
+
+func TestRunInitError(t *testing.T) {
+	initErr := errors.New("missing shader")
+	a := New(Config{Width: 8, Height: 8, Headless: &HeadlessConfig{OutputDir: t.TempDir(), Software: true}})
+	uploaded, closed := false, false
+	a.Init = func(a *App) error {
+		a.Loader = async.NewLoader(2)
+		a.Loader.Go(func() {
+			a.Loader.Main(func() {
+				uploaded = true
+			})
+		})
+		return initErr
+	}
+	a.Render = func(a *App) {
+		t.Errorf("Render called after Init failed")
+	}
+	a.Close = func(a *App) {
+		closed = true
+	}
+
+	if err := a.Run(); err != initErr {
+		t.Errorf("Run() = %v, want %v", err, initErr)
+	}
+	// closing the loader flushes it before stopping its workers
+	if !uploaded {
+		t.Errorf("the loader wasn't closed")
+	}
+	if !closed {
+		t.Errorf("Close wasn't called")
+	}
+}
+
+func TestRunHeadlessClose(t *testing.T) {
+	a := New(Config{Width: 8, Height: 8, Headless: &HeadlessConfig{OutputDir: t.TempDir(), Frames: 2, Software: true}})
+	frames, closes := 0, 0
+	a.Render = func(a *App) {
+		frames++
+	}
+	a.Close = func(a *App) {
+		closes++
+	}
+	if err := a.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if frames != 2 || closes != 1 {
+		t.Errorf("%v frames, %v closes, want 2 frames and 1 close", frames, closes)
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"image"
+	"image/png"
+	"learn_opengl/gl"
+	"os"
+	"unsafe"
+)
+
+// ReadFramebuffer reads the color buffer of fbo, 0 for the back buffer of the default framebuffer, into an image with
+// the origin at the top left. A multisampled fbo has to be resolved into a regular one before it can be read. The
+// alpha channel is forced to opaque, blending leaves it at arbitrary values that don't mean anything on screen.
+func ReadFramebuffer(fbo uint32, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width == 0 || height == 0 {
+		return img
+	}
+
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, fbo)
+	if fbo == 0 {
+		gl.ReadBuffer(gl.BACK)
+	} else {
+		gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+	}
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&img.Pix[0]))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+
+	FlipVertical(img)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	return img
+}
+
+// FlipVertical mirrors the rows of img in place, GL returns the bottom row first.
+func FlipVertical(img *image.RGBA) {
+	height := img.Rect.Dy()
+	row := make([]byte, img.Rect.Dx()*4)
+	for y := 0; y < height/2; y++ {
+		top := img.Pix[y*img.Stride : y*img.Stride+len(row)]
+		bottom := img.Pix[(height-1-y)*img.Stride : (height-1-y)*img.Stride+len(row)]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+}
+
+// SavePNG writes img to path as a PNG file.
+func SavePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
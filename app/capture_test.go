@@ -0,0 +1,46 @@
+package app
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFlipVertical(t *testing.T) {
+	for _, height := range []int{1, 2, 3, 4} {
+		img := image.NewRGBA(image.Rect(0, 0, 2, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < 2; x++ {
+				img.Pix[img.PixOffset(x, y)] = byte(y*2 + x)
+			}
+		}
+		FlipVertical(img)
+		for y := 0; y < height; y++ {
+			for x := 0; x < 2; x++ {
+				if got, want := img.Pix[img.PixOffset(x, y)], byte((height-1-y)*2+x); got != want {
+					t.Errorf("height %v: pixel %v,%v is %v, want %v", height, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestFlipVerticalSubImage(t *testing.T) {
+	// a sub image has a stride larger than its width, the pixels outside of it must not move
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+	sub := img.SubImage(image.Rect(0, 0, 2, 2)).(*image.RGBA)
+	FlipVertical(sub)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			wantY := y
+			if x < 2 {
+				wantY = 1 - y
+			}
+			if got, want := img.Pix[img.PixOffset(x, y)], byte(img.PixOffset(x, wantY)); got != want {
+				t.Errorf("pixel %v,%v is %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
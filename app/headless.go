@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	DEFAULT_HEADLESS_FRAMES   = 1
+	DEFAULT_HEADLESS_TIMESTEP = 1.0 / 60
+	DEFAULT_HEADLESS_PATTERN  = "frame_%04d.png"
+
+	// environment variables that turn an App into a headless one without changing the sample, see HeadlessFromEnv
+	ENV_HEADLESS = "LEARN_OPENGL_HEADLESS"
+	ENV_FRAMES   = "LEARN_OPENGL_FRAMES"
+	ENV_CAPTURE  = "LEARN_OPENGL_CAPTURE"
+	ENV_OSMESA   = "LEARN_OPENGL_OSMESA"
+	ENV_SOFTWARE = "LEARN_OPENGL_SOFTWARE"
+)
+
+// HeadlessConfig renders a fixed number of frames into an invisible window and writes them as PNG files.
+//
+// Frame i is rendered at scene time i*Timestep and Update is called with Timestep between two frames, the camera
+// ignores all input. Rendering and timing therefore only depend on the sample, which makes the frames reproducible.
+//
+// On a Linux box without GPU the frames can be rendered by Mesa's llvmpipe, either under a virtual X server
+// (xvfb-run with LIBGL_ALWAYS_SOFTWARE=1) or with OSMesa, which renders the context into memory. Software skips GL
+// altogether and renders with the Go rasterizer of gl/soft, the sample has to register Go versions of its shaders.
+type HeadlessConfig struct {
+	// Frames to render, DEFAULT_HEADLESS_FRAMES if zero
+	Frames int
+	// Timestep in seconds between two frames, DEFAULT_HEADLESS_TIMESTEP if zero
+	Timestep float64
+	// CaptureFrames lists the frames to write, negative numbers count from the end (-1 is the last frame). Every
+	// frame is written if empty
+	CaptureFrames []int
+	// OutputDir receives the PNG files, it is created if needed. "" is the working directory
+	OutputDir string
+	// Pattern is the fmt pattern of the file names, given the frame number. DEFAULT_HEADLESS_PATTERN if empty
+	Pattern string
+	// Framebuffer is the framebuffer object to read back, 0 reads the default framebuffer
+	Framebuffer uint32
+	// OSMesa creates the context with OSMesa instead of GLX/EGL
+	OSMesa bool
+	// Software renders with the gl/soft backend, without GLFW and without a GL driver
+	Software bool
+}
+
+// HeadlessFromEnv returns the headless configuration given by the environment, nil if ENV_HEADLESS isn't set.
+//
+//	LEARN_OPENGL_HEADLESS=out/  output directory, enables the headless mode
+//	LEARN_OPENGL_FRAMES=60      number of frames
+//	LEARN_OPENGL_CAPTURE=0,-1   frames to write, every frame by default
+//	LEARN_OPENGL_OSMESA=1       create the context with OSMesa
+//	LEARN_OPENGL_SOFTWARE=1     render with the gl/soft backend
+func HeadlessFromEnv() (*HeadlessConfig, error) {
+	dir, ok := os.LookupEnv(ENV_HEADLESS)
+	if !ok {
+		return nil, nil
+	}
+	h := &HeadlessConfig{OutputDir: dir}
+	if s := os.Getenv(ENV_FRAMES); s != "" {
+		frames, err := strconv.Atoi(s)
+		if err != nil || frames <= 0 {
+			return nil, fmt.Errorf("app: invalid %v %q", ENV_FRAMES, s)
+		}
+		h.Frames = frames
+	}
+	if s := os.Getenv(ENV_CAPTURE); s != "" {
+		for _, f := range strings.Split(s, ",") {
+			frame, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				return nil, fmt.Errorf("app: invalid %v %q", ENV_CAPTURE, s)
+			}
+			h.CaptureFrames = append(h.CaptureFrames, frame)
+		}
+	}
+	if s := os.Getenv(ENV_OSMESA); s != "" {
+		osmesa, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("app: invalid %v %q", ENV_OSMESA, s)
+		}
+		h.OSMesa = osmesa
+	}
+	if s := os.Getenv(ENV_SOFTWARE); s != "" {
+		software, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("app: invalid %v %q", ENV_SOFTWARE, s)
+		}
+		h.Software = software
+	}
+	return h, nil
+}
+
+func (h *HeadlessConfig) setDefaults() {
+	if h.Frames == 0 {
+		h.Frames = DEFAULT_HEADLESS_FRAMES
+	}
+	if h.Timestep == 0 {
+		h.Timestep = DEFAULT_HEADLESS_TIMESTEP
+	}
+	if h.Pattern == "" {
+		h.Pattern = DEFAULT_HEADLESS_PATTERN
+	}
+}
+
+func (h *HeadlessConfig) captures(frame int) bool {
+	if len(h.CaptureFrames) == 0 {
+		return true
+	}
+	for _, f := range h.CaptureFrames {
+		if f == frame || f < 0 && h.Frames+f == frame {
+			return true
+		}
+	}
+	return false
+}
+
+// Captured returns the paths of the PNG files written by a headless Run.
+func (a *App) Captured() []string {
+	return a.captured
+}
+
+func (a *App) runHeadless() error {
+	h := a.Headless
+	if h.OutputDir != "" {
+		if err := os.MkdirAll(h.OutputDir, 0755); err != nil {
+			return fmt.Errorf("app: %w", err)
+		}
+	}
+
+	for frame := 0; frame < h.Frames; frame++ {
+		a.deltaTime = h.Timestep
+		if a.Loader != nil {
+			a.Loader.Flush()
+		}
+		if a.Input != nil {
+			a.Input(a)
+		}
+		if frame > 0 {
+			a.update(h.Timestep)
+		}
+
+		if a.Render != nil {
+			a.Render(a)
+		}
+
+		if h.captures(frame) {
+			img := ReadFramebuffer(h.Framebuffer, a.width, a.height)
+			path := filepath.Join(h.OutputDir, fmt.Sprintf(h.Pattern, frame))
+			if err := SavePNG(path, img); err != nil {
+				return fmt.Errorf("app: failed to write frame %v: %w", frame, err)
+			}
+			a.captured = append(a.captured, path)
+		}
+
+		a.swapBuffers()
+	}
+	return nil
+}
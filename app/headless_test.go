@@ -0,0 +1,101 @@
+package app
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestHeadlessFromEnv(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		env  map[string]string
+		want *HeadlessConfig
+		err  bool
+	}{
+		{"unset", map[string]string{}, nil, false},
+		{"output dir", map[string]string{ENV_HEADLESS: "out"}, &HeadlessConfig{OutputDir: "out"}, false},
+		{"empty output dir", map[string]string{ENV_HEADLESS: ""}, &HeadlessConfig{}, false},
+		{"all", map[string]string{ENV_HEADLESS: "out", ENV_FRAMES: "60", ENV_CAPTURE: "0, -1", ENV_OSMESA: "1",
+			ENV_SOFTWARE: "true"},
+			&HeadlessConfig{OutputDir: "out", Frames: 60, CaptureFrames: []int{0, -1}, OSMesa: true, Software: true}, false},
+		{"zero frames", map[string]string{ENV_HEADLESS: "out", ENV_FRAMES: "0"}, nil, true},
+		{"invalid frames", map[string]string{ENV_HEADLESS: "out", ENV_FRAMES: "ten"}, nil, true},
+		{"invalid capture", map[string]string{ENV_HEADLESS: "out", ENV_CAPTURE: "0,last"}, nil, true},
+		{"invalid osmesa", map[string]string{ENV_HEADLESS: "out", ENV_OSMESA: "yes"}, nil, true},
+		{"invalid software", map[string]string{ENV_HEADLESS: "out", ENV_SOFTWARE: "maybe"}, nil, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			for _, name := range []string{ENV_HEADLESS, ENV_FRAMES, ENV_CAPTURE, ENV_OSMESA, ENV_SOFTWARE} {
+				if v, ok := c.env[name]; ok {
+					t.Setenv(name, v)
+				} else {
+					unsetenv(t, name)
+				}
+			}
+			h, err := HeadlessFromEnv()
+			if c.err {
+				if err == nil {
+					t.Fatalf("HeadlessFromEnv() = %+v, want an error", h)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(h, c.want) {
+				t.Errorf("HeadlessFromEnv() = %+v, want %+v", h, c.want)
+			}
+		})
+	}
+}
+
+func TestHeadlessDefaults(t *testing.T) {
+	h := &HeadlessConfig{}
+	h.setDefaults()
+	if h.Frames != DEFAULT_HEADLESS_FRAMES || h.Timestep != DEFAULT_HEADLESS_TIMESTEP || h.Pattern != DEFAULT_HEADLESS_PATTERN {
+		t.Errorf("setDefaults() = %+v", h)
+	}
+	h = &HeadlessConfig{Frames: 3, Timestep: 0.5, Pattern: "%d.png"}
+	h.setDefaults()
+	if h.Frames != 3 || h.Timestep != 0.5 || h.Pattern != "%d.png" {
+		t.Errorf("setDefaults() overwrote %+v", h)
+	}
+}
+
+func TestHeadlessCaptures(t *testing.T) {
+	for _, c := range []struct {
+		capture []int
+		want    []int
+	}{
+		// every frame by default
+		{nil, []int{0, 1, 2, 3, 4}},
+		{[]int{0}, []int{0}},
+		// negative frames count from the end
+		{[]int{-1}, []int{4}},
+		{[]int{1, -2}, []int{1, 3}},
+		// the same frame given twice is written once
+		{[]int{4, -1}, []int{4}},
+		// frames out of range are never written
+		{[]int{5, -6}, nil},
+	} {
+		h := &HeadlessConfig{Frames: 5, CaptureFrames: c.capture}
+		var got []int
+		for frame := 0; frame < h.Frames; frame++ {
+			if h.captures(frame) {
+				got = append(got, frame)
+			}
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("CaptureFrames %v writes %v, want %v", c.capture, got, c.want)
+		}
+	}
+}
+
+// unsetenv removes the environment variable for the duration of the test
+func unsetenv(t *testing.T, name string) {
+	t.Helper()
+	// t.Setenv restores the previous value after the test
+	t.Setenv(name, "")
+	os.Unsetenv(name)
+}
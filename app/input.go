@@ -0,0 +1,29 @@
+package app
+
+// Key is a key of the keyboard, see KeyPressed.
+type Key int
+
+const (
+	KEY_ESCAPE Key = iota
+	KEY_SPACE
+	KEY_ENTER
+	KEY_UP
+	KEY_DOWN
+	KEY_LEFT
+	KEY_RIGHT
+	KEY_A
+	KEY_B
+	KEY_D
+	KEY_E
+	KEY_Q
+	KEY_S
+	KEY_W
+)
+
+// KeyPressed tells whether key is held down. Headless Apps have no keyboard, every key reads as released.
+func (a *App) KeyPressed(key Key) bool {
+	if a.Headless != nil {
+		return false
+	}
+	return a.keyPressed(key)
+}
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"fmt"
+	"learn_opengl/gl"
+	"learn_opengl/gl/soft"
+)
+
+// openSoftware renders with the pure-Go rasterizer of gl/soft instead of a window, release restores the previous GL
+// backend. The App has no window then, Window returns nil.
+func (a *App) openSoftware() (func(), error) {
+	previous := gl.SetBackend(soft.New(a.Width, a.Height))
+	release := func() {
+		gl.SetBackend(previous)
+	}
+	if err := gl.Init(); err != nil {
+		release()
+		return nil, fmt.Errorf("app: failed to initialize GL: %w", err)
+	}
+	a.width, a.height = a.Width, a.Height
+	return release, nil
+}
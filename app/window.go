@@ -0,0 +1,221 @@
+//go:build !soft
+
+package app
+
+import (
+	"fmt"
+	"learn_opengl/common"
+	"learn_opengl/gl"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// windowed tells whether the build opens windows, builds with the soft tag only render headlessly with gl/soft.
+const windowed = true
+
+// platform holds the GLFW window of an App.
+type platform struct {
+	window *glfw.Window
+}
+
+var glfwKeys = map[Key]glfw.Key{
+	KEY_ESCAPE: glfw.KeyEscape,
+	KEY_SPACE:  glfw.KeySpace,
+	KEY_ENTER:  glfw.KeyEnter,
+	KEY_UP:     glfw.KeyUp,
+	KEY_DOWN:   glfw.KeyDown,
+	KEY_LEFT:   glfw.KeyLeft,
+	KEY_RIGHT:  glfw.KeyRight,
+	KEY_A:      glfw.KeyA,
+	KEY_B:      glfw.KeyB,
+	KEY_D:      glfw.KeyD,
+	KEY_E:      glfw.KeyE,
+	KEY_Q:      glfw.KeyQ,
+	KEY_S:      glfw.KeyS,
+	KEY_W:      glfw.KeyW,
+}
+
+// Window returns the GLFW window, nil with the software backend.
+func (a *App) Window() *glfw.Window {
+	return a.window
+}
+
+// openWindow creates the window and makes its GL context current, release destroys it.
+func (a *App) openWindow() (func(), error) {
+	// glfw: initialize and configure
+	// ------------------------------
+	if err := glfw.Init(); err != nil {
+		return nil, fmt.Errorf("app: failed to initialize GLFW: %w", err)
+	}
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	if a.Samples > 0 {
+		glfw.WindowHint(glfw.Samples, a.Samples)
+	}
+	if a.Headless != nil {
+		a.Headless.windowHints()
+	}
+
+	// glfw: window creation
+	// ---------------------
+	window, err := glfw.CreateWindow(a.Width, a.Height, a.Title, nil, nil)
+	if err != nil {
+		glfw.Terminate()
+		return nil, fmt.Errorf("app: failed to create GLFW window: %w", err)
+	}
+	release := func() {
+		window.Destroy()
+		glfw.Terminate()
+	}
+	a.window = window
+	window.MakeContextCurrent()
+	if err = gl.Init(); err != nil {
+		release()
+		return nil, fmt.Errorf("app: failed to initialize GL: %w", err)
+	}
+	if a.VSync && a.Headless == nil {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
+	}
+	if a.Samples > 0 {
+		gl.Enable(gl.MULTISAMPLE)
+	}
+
+	a.width, a.height = window.GetFramebufferSize()
+	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
+		a.resize(width, height)
+	})
+	if a.Headless == nil && (a.Camera != nil && a.FlyCamera || a.Mouse != nil || a.Scroll != nil) {
+		window.SetCursorPosCallback(a.mouseCallback)
+		window.SetScrollCallback(a.scrollCallback)
+		window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	}
+	return release, nil
+}
+
+func (a *App) loop() {
+	// render loop
+	// -----------
+	lastFrame := glfw.GetTime()
+	var accumulator float64
+	for !a.window.ShouldClose() {
+		// per-frame time logic
+		// --------------------
+		currentFrame := glfw.GetTime()
+		a.deltaTime = currentFrame - lastFrame
+		lastFrame = currentFrame
+
+		// input
+		// -----
+		a.processInput()
+		if a.Input != nil {
+			a.Input(a)
+		}
+
+		if a.Loader != nil {
+			a.Loader.Update(a.UploadBudget)
+		}
+
+		if a.FixedTimestep > 0 {
+			accumulator += a.deltaTime
+			for steps := 0; accumulator >= a.FixedTimestep; steps++ {
+				if steps == MAX_FIXED_STEPS_PER_FRAME {
+					accumulator = 0
+					break
+				}
+				a.update(a.FixedTimestep)
+				accumulator -= a.FixedTimestep
+			}
+		} else {
+			a.update(a.deltaTime)
+		}
+
+		// render
+		// ------
+		if a.Render != nil {
+			a.Render(a)
+		}
+
+		// glfw: swap buffers and poll IO events (keys pressed/released, mouse moved etc.)
+		// -------------------------------------------------------------------------------
+		a.window.SwapBuffers()
+		glfw.PollEvents()
+	}
+}
+
+func (a *App) processInput() {
+	if a.window.GetKey(glfw.KeyEscape) == glfw.Press {
+		a.window.SetShouldClose(true)
+	}
+	if a.Camera == nil || !a.FlyCamera {
+		return
+	}
+	if a.window.GetKey(glfw.KeyW) == glfw.Press {
+		a.Camera.ProcessKeyboard(common.Forward, a.deltaTime)
+	}
+	if a.window.GetKey(glfw.KeyS) == glfw.Press {
+		a.Camera.ProcessKeyboard(common.Backward, a.deltaTime)
+	}
+	if a.window.GetKey(glfw.KeyA) == glfw.Press {
+		a.Camera.ProcessKeyboard(common.Left, a.deltaTime)
+	}
+	if a.window.GetKey(glfw.KeyD) == glfw.Press {
+		a.Camera.ProcessKeyboard(common.Right, a.deltaTime)
+	}
+}
+
+// glfw: whenever the mouse moves, this callback is called
+// -------------------------------------------------------
+func (a *App) mouseCallback(_ *glfw.Window, xpos, ypos float64) {
+	if a.firstMouse {
+		a.lastX = xpos
+		a.lastY = ypos
+		a.firstMouse = false
+	}
+
+	xoffset := xpos - a.lastX
+	yoffset := a.lastY - ypos // reversed since y-coordinates go from bottom to top
+
+	a.lastX = xpos
+	a.lastY = ypos
+
+	if a.Camera != nil && a.FlyCamera {
+		a.Camera.ProcessMouseMovement(xoffset, yoffset, true)
+	}
+	if a.Mouse != nil {
+		a.Mouse(a, xoffset, yoffset)
+	}
+}
+
+// glfw: whenever the mouse scroll wheel scrolls, this callback is called
+// ----------------------------------------------------------------------
+func (a *App) scrollCallback(_ *glfw.Window, _, yoffset float64) {
+	if a.Camera != nil && a.FlyCamera {
+		a.Camera.ProcessMouseScroll(yoffset)
+	}
+	if a.Scroll != nil {
+		a.Scroll(a, yoffset)
+	}
+}
+
+func (a *App) keyPressed(key Key) bool {
+	k, ok := glfwKeys[key]
+	return ok && a.window != nil && a.window.GetKey(k) == glfw.Press
+}
+
+// swapBuffers presents the frame of a headless App rendered into an invisible window.
+func (a *App) swapBuffers() {
+	if a.window != nil {
+		a.window.SwapBuffers()
+		glfw.PollEvents()
+	}
+}
+
+func (h *HeadlessConfig) windowHints() {
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	if h.OSMesa {
+		glfw.WindowHint(glfw.ContextCreationAPI, glfw.OSMesaContextAPI)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build soft
+
+package app
+
+import "fmt"
+
+// windowed tells whether the build opens windows, builds with the soft tag only render headlessly with gl/soft.
+const windowed = false
+
+// platform is empty without GLFW.
+type platform struct{}
+
+func (a *App) openWindow() (func(), error) {
+	return nil, fmt.Errorf("app: built with the soft tag, set %v to render headlessly", ENV_HEADLESS)
+}
+
+func (a *App) loop() {
+}
+
+func (a *App) keyPressed(key Key) bool {
+	return false
+}
+
+func (a *App) swapBuffers() {
+}
@@ -0,0 +1,118 @@
+package assimp
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/huoshan017/assimp"
+)
+
+const (
+	// assimp leaves the ticks per second at 0 when the file doesn't tell
+	DEFAULT_TICKS_PER_SECOND = 25.0
+)
+
+// NodeData is a copy of the node hierarchy of the animated scene.
+type NodeData struct {
+	Transformation mgl32.Mat4
+	Name           string
+	Children       []NodeData
+}
+
+// Animation is a skeletal animation of a model, it holds a Bone for every animated node.
+type Animation struct {
+	duration       float64
+	ticksPerSecond float64
+	bones          map[string]*Bone
+	rootNode       NodeData
+	boneInfoMap    map[string]BoneInfo
+}
+
+// NewAnimation reads the first animation of the file at path, usually the file model was loaded from. Bones animated
+// by the file but unknown to the model are added to the bone info map of model.
+func NewAnimation(path string, model *Model) *Animation {
+	a, err := LoadAnimation(path, model)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return a
+}
+
+// LoadAnimation is NewAnimation returning an error instead of exiting.
+func LoadAnimation(path string, model *Model) (*Animation, error) {
+	scene := assimp.ImportFile(path, uint(assimp.Process_Triangulate))
+	if scene == nil || scene.RootNode() == nil {
+		if scene != nil {
+			scene.ReleaseImport()
+		}
+		return nil, fmt.Errorf("assimp: failed to import %v: %v", path, assimp.GetErrorString())
+	}
+	defer scene.ReleaseImport()
+	if scene.NumAnimations() == 0 {
+		return nil, fmt.Errorf("assimp: no animation in %v", path)
+	}
+
+	animation := scene.Animations()[0]
+	a := &Animation{
+		duration:       animation.Duration(),
+		ticksPerSecond: animation.TicksPerSecond(),
+		bones:          make(map[string]*Bone),
+	}
+	if a.ticksPerSecond == 0 {
+		a.ticksPerSecond = DEFAULT_TICKS_PER_SECOND
+	}
+	a.rootNode = readHierarchyData(scene.RootNode())
+	a.readMissingBones(animation, model)
+	return a, nil
+}
+
+// FindBone returns the bone animating the node name, nil if the node isn't animated.
+func (a *Animation) FindBone(name string) *Bone {
+	return a.bones[name]
+}
+
+// Duration returns the length of the animation in ticks.
+func (a *Animation) Duration() float64 {
+	return a.duration
+}
+
+func (a *Animation) TicksPerSecond() float64 {
+	return a.ticksPerSecond
+}
+
+func (a *Animation) RootNode() *NodeData {
+	return &a.rootNode
+}
+
+func (a *Animation) BoneInfoMap() map[string]BoneInfo {
+	return a.boneInfoMap
+}
+
+// readMissingBones creates the bones of the channels, and adds those the meshes don't reference to the model.
+func (a *Animation) readMissingBones(animation *assimp.Animation, model *Model) {
+	boneInfoMap := model.BoneInfoMap()
+	// reading channels (bones engaged in an animation and their keyframes)
+	for _, channel := range animation.Channels() {
+		boneName := channel.Name()
+		if _, ok := boneInfoMap[boneName]; !ok {
+			boneInfoMap[boneName] = BoneInfo{Id: model.boneCounter, Offset: mgl32.Ident4()}
+			model.boneCounter++
+		}
+		a.bones[boneName] = NewBone(boneName, boneInfoMap[boneName].Id, channel)
+	}
+	a.boneInfoMap = boneInfoMap
+}
+
+func readHierarchyData(src *assimp.Node) NodeData {
+	transformation := src.Transformation()
+	dest := NodeData{
+		Name:           src.Name(),
+		Transformation: convertMatrix(&transformation),
+	}
+	children := src.Children()
+	for i := 0; i < src.NumChildren(); i++ {
+		dest.Children = append(dest.Children, readHierarchyData(children[i]))
+	}
+	return dest
+}
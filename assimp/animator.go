@@ -0,0 +1,90 @@
+package assimp
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// size of the finalBonesMatrices uniform array
+	MAX_BONES = 100
+)
+
+// Animator plays an Animation and computes the matrices that move the vertices from the bind pose to the current
+// pose, the vertex shader blends them with the bone weights of each vertex.
+type Animator struct {
+	finalBoneMatrices []mgl32.Mat4
+	currentAnimation  *Animation
+	currentTime       float64
+}
+
+func NewAnimator(animation *Animation) *Animator {
+	a := &Animator{
+		finalBoneMatrices: make([]mgl32.Mat4, MAX_BONES),
+	}
+	for i := range a.finalBoneMatrices {
+		a.finalBoneMatrices[i] = mgl32.Ident4()
+	}
+	a.PlayAnimation(animation)
+	return a
+}
+
+// UpdateAnimation advances the animation by dt seconds, it loops.
+func (a *Animator) UpdateAnimation(dt float64) {
+	if a.currentAnimation == nil {
+		return
+	}
+	a.SetTime(a.currentTime/a.currentAnimation.TicksPerSecond() + dt)
+}
+
+// SetTime poses the animation at t seconds from its start.
+func (a *Animator) SetTime(t float64) {
+	if a.currentAnimation == nil {
+		return
+	}
+	a.currentTime = t * a.currentAnimation.TicksPerSecond()
+	if duration := a.currentAnimation.Duration(); duration > 0 {
+		a.currentTime = math.Mod(a.currentTime, duration)
+		if a.currentTime < 0 {
+			a.currentTime += duration
+		}
+	}
+	a.calculateBoneTransform(a.currentAnimation.RootNode(), mgl32.Ident4())
+}
+
+// PlayAnimation starts animation from its beginning.
+func (a *Animator) PlayAnimation(animation *Animation) {
+	a.currentAnimation = animation
+	a.currentTime = 0
+	if animation != nil {
+		a.calculateBoneTransform(animation.RootNode(), mgl32.Ident4())
+	}
+}
+
+func (a *Animator) calculateBoneTransform(node *NodeData, parentTransform mgl32.Mat4) {
+	nodeTransform := node.Transformation
+	if bone := a.currentAnimation.FindBone(node.Name); bone != nil {
+		bone.Update(a.currentTime)
+		nodeTransform = bone.LocalTransform()
+	}
+	globalTransformation := parentTransform.Mul4(nodeTransform)
+
+	if info, ok := a.currentAnimation.BoneInfoMap()[node.Name]; ok && info.Id < MAX_BONES {
+		a.finalBoneMatrices[info.Id] = globalTransformation.Mul4(info.Offset)
+	}
+
+	for i := range node.Children {
+		a.calculateBoneTransform(&node.Children[i], globalTransformation)
+	}
+}
+
+// FinalBoneMatrices returns the MAX_BONES matrices of the current pose, indexed by BoneInfo.Id.
+func (a *Animator) FinalBoneMatrices() []mgl32.Mat4 {
+	return a.finalBoneMatrices
+}
+
+// CurrentTime returns the position in the animation in ticks.
+func (a *Animator) CurrentTime() float64 {
+	return a.currentTime
+}
@@ -0,0 +1,95 @@
+package assimp
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testAnimation moves the root bone from x=0 to x=10 over 10 ticks at 2 ticks per second, its child is not
+// animated and sits 1 above it
+func testAnimation() *Animation {
+	root := &Bone{
+		name: "root",
+		positions: []KeyPosition{
+			{Position: mgl32.Vec3{0, 0, 0}, TimeStamp: 0},
+			{Position: mgl32.Vec3{10, 0, 0}, TimeStamp: 10},
+		},
+		localTransform: mgl32.Ident4(),
+	}
+	return &Animation{
+		duration:       10,
+		ticksPerSecond: 2,
+		bones:          map[string]*Bone{"root": root},
+		rootNode: NodeData{
+			Name:           "scene",
+			Transformation: mgl32.Ident4(),
+			Children: []NodeData{{
+				Name:           "root",
+				Transformation: mgl32.Ident4(),
+				Children: []NodeData{{
+					Name:           "child",
+					Transformation: mgl32.Translate3D(0, 1, 0),
+				}},
+			}},
+		},
+		boneInfoMap: map[string]BoneInfo{
+			"root": {Id: 0, Offset: mgl32.Ident4()},
+			// the offset moves the bind pose of the child back to the origin
+			"child": {Id: 1, Offset: mgl32.Translate3D(0, -1, 0)},
+		},
+	}
+}
+
+func TestAnimator(t *testing.T) {
+	a := NewAnimator(testAnimation())
+	if len(a.FinalBoneMatrices()) != MAX_BONES {
+		t.Fatalf("%v matrices, want %v", len(a.FinalBoneMatrices()), MAX_BONES)
+	}
+	for _, c := range []struct {
+		dt   float64
+		tick float64
+		x    float32
+	}{
+		{0, 0, 0},
+		{1, 2, 2},
+		{1.5, 5, 5},
+		// the animation loops after 5 seconds
+		{2, 9, 9},
+		{0.5, 0, 0},
+		{0.25, 0.5, 0.5},
+	} {
+		a.UpdateAnimation(c.dt)
+		if a.CurrentTime() < c.tick-1e-9 || a.CurrentTime() > c.tick+1e-9 {
+			t.Errorf("CurrentTime = %v, want %v", a.CurrentTime(), c.tick)
+		}
+		want := mgl32.Translate3D(c.x, 0, 0)
+		for id, m := range a.FinalBoneMatrices()[:2] {
+			if !matApprox(m, want) {
+				t.Errorf("tick %v: bone %v = %v, want %v", c.tick, id, m, want)
+			}
+		}
+		if m := a.FinalBoneMatrices()[2]; m != mgl32.Ident4() {
+			t.Errorf("unused bone matrix = %v, want identity", m)
+		}
+	}
+}
+
+func TestAnimatorSetTime(t *testing.T) {
+	a := NewAnimator(testAnimation())
+	// negative times wrap around to the end
+	a.SetTime(-1)
+	if a.CurrentTime() != 8 {
+		t.Errorf("CurrentTime = %v, want 8", a.CurrentTime())
+	}
+	if want := mgl32.Translate3D(8, 0, 0); !matApprox(a.FinalBoneMatrices()[0], want) {
+		t.Errorf("root = %v, want %v", a.FinalBoneMatrices()[0], want)
+	}
+
+	// without an animation the pose stays as it is
+	a.PlayAnimation(nil)
+	a.UpdateAnimation(1)
+	if a.CurrentTime() != 0 {
+		t.Errorf("CurrentTime without animation = %v, want 0", a.CurrentTime())
+	}
+}
@@ -0,0 +1,157 @@
+package assimp
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/huoshan017/assimp"
+)
+
+// BoneInfo is what the vertex shader needs of a bone.
+type BoneInfo struct {
+	// Id is the index in finalBonesMatrices
+	Id int
+	// Offset transforms a vertex from model space to bone space
+	Offset mgl32.Mat4
+}
+
+type KeyPosition struct {
+	Position  mgl32.Vec3
+	TimeStamp float64
+}
+
+type KeyRotation struct {
+	Orientation mgl32.Quat
+	TimeStamp   float64
+}
+
+type KeyScale struct {
+	Scale     mgl32.Vec3
+	TimeStamp float64
+}
+
+// Bone holds the keyframes of one animation channel and interpolates them into a local transform.
+type Bone struct {
+	positions      []KeyPosition
+	rotations      []KeyRotation
+	scales         []KeyScale
+	localTransform mgl32.Mat4
+	name           string
+	id             int
+}
+
+// NewBone reads the keyframes of a channel.
+func NewBone(name string, id int, channel *assimp.NodeAnim) *Bone {
+	b := &Bone{
+		name:           name,
+		id:             id,
+		localTransform: mgl32.Ident4(),
+	}
+	for _, key := range channel.PositionKeys() {
+		value := key.Value()
+		b.positions = append(b.positions, KeyPosition{Position: convertVec3(&value), TimeStamp: key.Time()})
+	}
+	for _, key := range channel.RotationKeys() {
+		value := key.Value()
+		b.rotations = append(b.rotations, KeyRotation{Orientation: convertQuat(&value), TimeStamp: key.Time()})
+	}
+	for _, key := range channel.ScalingKeys() {
+		value := key.Value()
+		b.scales = append(b.scales, KeyScale{Scale: convertVec3(&value), TimeStamp: key.Time()})
+	}
+	return b
+}
+
+// Update interpolates the position, rotation and scale at animationTime, in ticks, and builds the local transform.
+func (b *Bone) Update(animationTime float64) {
+	translation := b.interpolatePosition(animationTime)
+	rotation := b.interpolateRotation(animationTime)
+	scale := b.interpolateScaling(animationTime)
+	b.localTransform = translation.Mul4(rotation).Mul4(scale)
+}
+
+func (b *Bone) LocalTransform() mgl32.Mat4 {
+	return b.localTransform
+}
+
+func (b *Bone) Name() string {
+	return b.name
+}
+
+func (b *Bone) Id() int {
+	return b.id
+}
+
+// keyIndex returns the index of the key before animationTime, the next one follows it. count must be at least 2.
+func keyIndex(count int, timeStamp func(i int) float64, animationTime float64) int {
+	i := sort.Search(count, func(i int) bool { return timeStamp(i) > animationTime }) - 1
+	if i < 0 {
+		return 0
+	}
+	if i > count-2 {
+		return count - 2
+	}
+	return i
+}
+
+// scaleFactor returns how far animationTime is between two keys, clamped to [0, 1].
+func scaleFactor(lastTimeStamp, nextTimeStamp, animationTime float64) float32 {
+	framesDiff := nextTimeStamp - lastTimeStamp
+	if framesDiff <= 0 {
+		return 0
+	}
+	factor := (animationTime - lastTimeStamp) / framesDiff
+	if factor < 0 {
+		return 0
+	}
+	if factor > 1 {
+		return 1
+	}
+	return float32(factor)
+}
+
+func (b *Bone) interpolatePosition(animationTime float64) mgl32.Mat4 {
+	if len(b.positions) == 0 {
+		return mgl32.Ident4()
+	}
+	if len(b.positions) == 1 {
+		p := b.positions[0].Position
+		return mgl32.Translate3D(p.X(), p.Y(), p.Z())
+	}
+	i := keyIndex(len(b.positions), func(i int) float64 { return b.positions[i].TimeStamp }, animationTime)
+	factor := scaleFactor(b.positions[i].TimeStamp, b.positions[i+1].TimeStamp, animationTime)
+	p := b.positions[i].Position.Add(b.positions[i+1].Position.Sub(b.positions[i].Position).Mul(factor))
+	return mgl32.Translate3D(p.X(), p.Y(), p.Z())
+}
+
+func (b *Bone) interpolateRotation(animationTime float64) mgl32.Mat4 {
+	if len(b.rotations) == 0 {
+		return mgl32.Ident4()
+	}
+	if len(b.rotations) == 1 {
+		return b.rotations[0].Orientation.Normalize().Mat4()
+	}
+	i := keyIndex(len(b.rotations), func(i int) float64 { return b.rotations[i].TimeStamp }, animationTime)
+	factor := scaleFactor(b.rotations[i].TimeStamp, b.rotations[i+1].TimeStamp, animationTime)
+	last, next := b.rotations[i].Orientation, b.rotations[i+1].Orientation
+	// take the shortest path like glm::slerp, mgl32 doesn't
+	if last.Dot(next) < 0 {
+		next = next.Scale(-1)
+	}
+	q := mgl32.QuatSlerp(last, next, factor)
+	return q.Normalize().Mat4()
+}
+
+func (b *Bone) interpolateScaling(animationTime float64) mgl32.Mat4 {
+	if len(b.scales) == 0 {
+		return mgl32.Ident4()
+	}
+	if len(b.scales) == 1 {
+		s := b.scales[0].Scale
+		return mgl32.Scale3D(s.X(), s.Y(), s.Z())
+	}
+	i := keyIndex(len(b.scales), func(i int) float64 { return b.scales[i].TimeStamp }, animationTime)
+	factor := scaleFactor(b.scales[i].TimeStamp, b.scales[i+1].TimeStamp, animationTime)
+	s := b.scales[i].Scale.Add(b.scales[i+1].Scale.Sub(b.scales[i].Scale).Mul(factor))
+	return mgl32.Scale3D(s.X(), s.Y(), s.Z())
+}
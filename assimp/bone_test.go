@@ -0,0 +1,140 @@
+package assimp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// matApprox compares with an absolute tolerance, mgl32's relative one fails for elements close to 0
+func matApprox(a, b mgl32.Mat4) bool {
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > 1e-5 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestKeyIndex(t *testing.T) {
+	times := []float64{0, 10, 20, 30}
+	timeStamp := func(i int) float64 { return times[i] }
+	for _, c := range []struct {
+		time float64
+		want int
+	}{
+		{-5, 0},
+		{0, 0},
+		{5, 0},
+		{10, 1},
+		{29, 2},
+		// the last key is the next one of the last pair
+		{30, 2},
+		{100, 2},
+	} {
+		if got := keyIndex(len(times), timeStamp, c.time); got != c.want {
+			t.Errorf("keyIndex at %v = %v, want %v", c.time, got, c.want)
+		}
+	}
+}
+
+func TestScaleFactor(t *testing.T) {
+	for _, c := range []struct {
+		last, next, time float64
+		want             float32
+	}{
+		{0, 10, 0, 0},
+		{0, 10, 2.5, 0.25},
+		{0, 10, 10, 1},
+		// clamped outside of the keys
+		{0, 10, -1, 0},
+		{0, 10, 11, 1},
+		// keys at the same time don't divide by zero
+		{5, 5, 5, 0},
+	} {
+		if got := scaleFactor(c.last, c.next, c.time); got != c.want {
+			t.Errorf("scaleFactor(%v, %v, %v) = %v, want %v", c.last, c.next, c.time, got, c.want)
+		}
+	}
+}
+
+func TestBoneInterpolation(t *testing.T) {
+	b := &Bone{
+		positions: []KeyPosition{
+			{Position: mgl32.Vec3{0, 0, 0}, TimeStamp: 0},
+			{Position: mgl32.Vec3{2, 4, 0}, TimeStamp: 10},
+			{Position: mgl32.Vec3{2, 4, 8}, TimeStamp: 20},
+		},
+		rotations: []KeyRotation{
+			{Orientation: mgl32.QuatIdent(), TimeStamp: 0},
+			{Orientation: mgl32.QuatRotate(math.Pi/2, mgl32.Vec3{0, 0, 1}), TimeStamp: 20},
+		},
+		scales: []KeyScale{
+			{Scale: mgl32.Vec3{1, 1, 1}, TimeStamp: 0},
+			{Scale: mgl32.Vec3{3, 1, 1}, TimeStamp: 20},
+		},
+		localTransform: mgl32.Ident4(),
+	}
+	for _, c := range []struct {
+		time        float64
+		translation mgl32.Vec3
+		angle       float32
+		scale       mgl32.Vec3
+	}{
+		{0, mgl32.Vec3{0, 0, 0}, 0, mgl32.Vec3{1, 1, 1}},
+		{5, mgl32.Vec3{1, 2, 0}, math.Pi / 8, mgl32.Vec3{1.5, 1, 1}},
+		{15, mgl32.Vec3{2, 4, 4}, 3 * math.Pi / 8, mgl32.Vec3{2.5, 1, 1}},
+		{20, mgl32.Vec3{2, 4, 8}, math.Pi / 2, mgl32.Vec3{3, 1, 1}},
+		// past the last key the bone holds the last pose
+		{30, mgl32.Vec3{2, 4, 8}, math.Pi / 2, mgl32.Vec3{3, 1, 1}},
+	} {
+		b.Update(c.time)
+		rotation := mgl32.QuatRotate(c.angle, mgl32.Vec3{0, 0, 1}).Mat4()
+		want := mgl32.Translate3D(c.translation.X(), c.translation.Y(), c.translation.Z()).Mul4(rotation).
+			Mul4(mgl32.Scale3D(c.scale.X(), c.scale.Y(), c.scale.Z()))
+		if got := b.LocalTransform(); !matApprox(got, want) {
+			t.Errorf("LocalTransform at %v = %v, want %v", c.time, got, want)
+		}
+	}
+}
+
+func TestBoneSingleKey(t *testing.T) {
+	b := &Bone{
+		positions: []KeyPosition{{Position: mgl32.Vec3{1, 2, 3}, TimeStamp: 5}},
+		// a single key is normalized
+		rotations: []KeyRotation{{Orientation: mgl32.Quat{W: 2}, TimeStamp: 5}},
+	}
+	b.Update(100)
+	if want := mgl32.Translate3D(1, 2, 3); !matApprox(b.LocalTransform(), want) {
+		t.Errorf("LocalTransform = %v, want %v", b.LocalTransform(), want)
+	}
+}
+
+func TestBoneRotationShortestPath(t *testing.T) {
+	// q and -q are the same rotation, slerping from q to -q must not turn all the way around
+	q := mgl32.QuatRotate(math.Pi/4, mgl32.Vec3{0, 1, 0})
+	b := &Bone{
+		rotations: []KeyRotation{
+			{Orientation: q, TimeStamp: 0},
+			{Orientation: q.Scale(-1), TimeStamp: 10},
+		},
+	}
+	for _, time := range []float64{0, 2.5, 5, 7.5, 10} {
+		if got, want := b.interpolateRotation(time), q.Mat4(); !matApprox(got, want) {
+			t.Errorf("rotation at %v = %v, want %v", time, got, want)
+		}
+	}
+
+	// 170 degrees to -170 degrees around y passes through 180, not through 0
+	from := mgl32.QuatRotate(mgl32.DegToRad(170), mgl32.Vec3{0, 1, 0})
+	to := mgl32.QuatRotate(mgl32.DegToRad(-170), mgl32.Vec3{0, 1, 0})
+	if from.Dot(to) >= 0 {
+		t.Fatalf("the keys must be in opposite hemispheres")
+	}
+	b.rotations = []KeyRotation{{Orientation: from, TimeStamp: 0}, {Orientation: to, TimeStamp: 10}}
+	want := mgl32.QuatRotate(math.Pi, mgl32.Vec3{0, 1, 0}).Mat4()
+	if got := b.interpolateRotation(5); !matApprox(got, want) {
+		t.Errorf("rotation halfway = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,485 @@
+package assimp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"learn_opengl/gl"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+const (
+	// header of the cooked mesh files, the version changes with their layout
+	CACHE_MAGIC   = "LOGLMESH"
+	CACHE_VERSION = 4
+	CACHE_EXT     = ".mesh"
+)
+
+var (
+	// errStaleCache tells a cache file written for another source, other options or another vertex layout
+	errStaleCache   = errors.New("stale cache")
+	errCorruptCache = errors.New("corrupt cache")
+)
+
+// The cache file holds, little-endian, with strings as their uint32 length followed by their bytes:
+//
+//	magic, version, key               the sha256 of the source file, the files it refers to, PostProcess and
+//	                                  version
+//	vertex layout                     stride, then name, components, GL type and offset of every attribute
+//	bones                             bone counter, then name, id and offset matrix of every bone, by id
+//	textures                          type and name of the pending textures
+//	meshes                            skinned flag, material, textures, bounding box and sphere, vertex and index
+//	                                  blobs
+//	nodes                             name, transform, mesh indices and children count, in pre-order
+//
+// The vertex blob is the memory of []gl.Vertex, it is reused as long as the vertex layout matches.
+
+// cacheAttribute describes an attribute of gl.Vertex.
+type cacheAttribute struct {
+	name       string
+	components uint32
+	typ        uint32
+	offset     uint32
+}
+
+// vertexLayout returns the layout of gl.Vertex the vertex blobs are written with.
+func vertexLayout() []cacheAttribute {
+	var v gl.Vertex
+	return []cacheAttribute{
+		{"position", 3, gl.FLOAT, uint32(unsafe.Offsetof(v.Position))},
+		{"normal", 3, gl.FLOAT, uint32(unsafe.Offsetof(v.Normal))},
+		{"texCoords", 2, gl.FLOAT, uint32(unsafe.Offsetof(v.TexCoords))},
+		{"tangent", 3, gl.FLOAT, uint32(unsafe.Offsetof(v.Tangent))},
+		{"bitangent", 3, gl.FLOAT, uint32(unsafe.Offsetof(v.Bitangent))},
+		{"boneIds", gl.MAX_BONE_INFLUENCE, gl.INT, uint32(unsafe.Offsetof(v.BoneIds))},
+		{"weights", gl.MAX_BONE_INFLUENCE, gl.FLOAT, uint32(unsafe.Offsetof(v.Weights))},
+	}
+}
+
+// littleEndian tells whether the memory of the vertex and index blobs can be copied as is.
+func littleEndian() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}
+
+// cacheFile returns the cache file of the model at path and the key its content must match.
+func cacheFile(path string, opts LoadOptions) (string, [sha256.Size]byte, error) {
+	var key [sha256.Size]byte
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return "", key, fmt.Errorf("assimp: %w", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", key, fmt.Errorf("assimp: %w", err)
+	}
+	h := sha256.New()
+	h.Write(source)
+	// the materials of an .obj come from its libraries, the vertices of a .gltf from its buffers
+	for _, dep := range dependencies(path, source) {
+		var n [8]byte
+		binary.LittleEndian.PutUint64(n[:], uint64(len(dep)))
+		h.Write(n[:])
+		h.Write([]byte(dep))
+		// a missing file hashes differently from an empty one, the key changes when it appears
+		if data, err := os.ReadFile(filepath.Join(filepath.Dir(path), dep)); err == nil {
+			h.Write([]byte{1})
+			binary.LittleEndian.PutUint64(n[:], uint64(len(data)))
+			h.Write(n[:])
+			h.Write(data)
+		} else {
+			h.Write([]byte{0})
+		}
+	}
+	var buf [12]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(opts.PostProcess))
+	binary.LittleEndian.PutUint32(buf[8:], CACHE_VERSION)
+	h.Write(buf[:])
+	copy(key[:], h.Sum(nil))
+
+	// models of different directories may share a name
+	name := sha256.Sum256([]byte(abs))
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return filepath.Join(opts.CacheDir, base+"-"+hex.EncodeToString(name[:4])+CACHE_EXT), key, nil
+}
+
+// dependencies returns the files besides the source that assimp reads to import it, relative to its directory: the
+// material libraries of an .obj and the external buffers of a .gltf. Textures are decoded at every load, they aren't
+// part of the cache.
+func dependencies(path string, source []byte) []string {
+	var deps []string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".obj":
+		// like assimp, the name is the rest of the line
+		scanner := bufio.NewScanner(bytes.NewReader(source))
+		scanner.Buffer(nil, len(source)+1)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) > 1 && fields[0] == "mtllib" {
+				line := strings.TrimSpace(scanner.Text())
+				deps = append(deps, strings.TrimSpace(line[len("mtllib"):]))
+			}
+		}
+	case ".gltf":
+		var doc struct {
+			Buffers []struct {
+				Uri string `json:"uri"`
+			} `json:"buffers"`
+		}
+		// an invalid document fails to import anyway
+		if json.Unmarshal(source, &doc) != nil {
+			return nil
+		}
+		for _, b := range doc.Buffers {
+			if b.Uri == "" || strings.HasPrefix(b.Uri, "data:") {
+				continue
+			}
+			if name, err := url.PathUnescape(b.Uri); err == nil {
+				deps = append(deps, filepath.FromSlash(name))
+			}
+		}
+	}
+	return deps
+}
+
+// writeCache writes the parsed scene to path, replacing the previous file at once.
+func (m *Model) writeCache(path string, key [sha256.Size]byte) error {
+	if !littleEndian() {
+		return fmt.Errorf("cooked meshes need a little-endian host")
+	}
+	var w cacheWriter
+	w.buf.WriteString(CACHE_MAGIC)
+	w.u32(CACHE_VERSION)
+	w.buf.Write(key[:])
+
+	layout := vertexLayout()
+	w.u32(uint32(unsafe.Sizeof(gl.Vertex{})))
+	w.u32(uint32(len(layout)))
+	for _, a := range layout {
+		w.str(a.name)
+		w.u32(a.components)
+		w.u32(a.typ)
+		w.u32(a.offset)
+	}
+
+	w.u32(uint32(m.boneCounter))
+	// by id, the order of the map would change the bytes of the file from one run to the next
+	names := make([]string, 0, len(m.boneInfoMap))
+	for name := range m.boneInfoMap {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := m.boneInfoMap[names[i]], m.boneInfoMap[names[j]]
+		return a.Id < b.Id || a.Id == b.Id && names[i] < names[j]
+	})
+	w.u32(uint32(len(names)))
+	for _, name := range names {
+		info := m.boneInfoMap[name]
+		w.str(name)
+		w.u32(uint32(info.Id))
+		w.floats(info.Offset[:])
+	}
+
+	w.textures(m.pendingTextures)
+
+	w.u32(uint32(len(m.pending)))
+	for i := range m.pending {
+		data := &m.pending[i]
+		w.bool(m.skinned[i])
+		w.material(&data.material)
+		w.textures(data.textures)
+		w.floats(data.bounds.Min[:])
+		w.floats(data.bounds.Max[:])
+		w.floats(data.sphere.Center[:])
+		w.floats([]float32{data.sphere.Radius})
+		w.u32(uint32(len(data.vertices)))
+		if len(data.vertices) > 0 {
+			w.buf.Write(unsafe.Slice((*byte)(unsafe.Pointer(&data.vertices[0])), len(data.vertices)*int(unsafe.Sizeof(data.vertices[0]))))
+		}
+		w.u32(uint32(len(data.indices)))
+		if len(data.indices) > 0 {
+			w.buf.Write(unsafe.Slice((*byte)(unsafe.Pointer(&data.indices[0])), len(data.indices)*4))
+		}
+	}
+
+	w.node(m.root)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// write a temporary file first, readers never see a partial cache
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(w.buf.Bytes())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// readCache restores the parsed scene from the cache file at path. The model is left untouched on error,
+// errStaleCache if the file doesn't match key or the vertex layout.
+func (m *Model) readCache(path string, key [sha256.Size]byte) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	r := cacheReader{data: data}
+	if string(r.bytes(len(CACHE_MAGIC))) != CACHE_MAGIC {
+		return errCorruptCache
+	}
+	if r.u32() != CACHE_VERSION || !bytes.Equal(r.bytes(len(key)), key[:]) || !littleEndian() {
+		return r.result(errStaleCache)
+	}
+
+	layout := vertexLayout()
+	stride := r.u32()
+	n := r.count(16)
+	if stride != uint32(unsafe.Sizeof(gl.Vertex{})) || n != len(layout) {
+		return r.result(errStaleCache)
+	}
+	for _, a := range layout {
+		if r.str() != a.name || r.u32() != a.components || r.u32() != a.typ || r.u32() != a.offset {
+			return r.result(errStaleCache)
+		}
+	}
+
+	boneCounter := int(r.u32())
+	n = r.count(72)
+	boneInfoMap := make(map[string]BoneInfo, n)
+	for i := 0; i < n; i++ {
+		name := r.str()
+		info := BoneInfo{Id: int(r.u32())}
+		r.floats(info.Offset[:])
+		boneInfoMap[name] = info
+	}
+
+	pendingTextures := r.textures()
+
+	n = r.count(80)
+	pending := make([]meshData, n)
+	skinned := make([]bool, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		data := &pending[i]
+		skinned[i] = r.bool()
+		data.material = r.material()
+		data.textures = r.textures()
+		r.floats(data.bounds.Min[:])
+		r.floats(data.bounds.Max[:])
+		r.floats(data.sphere.Center[:])
+		var radius [1]float32
+		r.floats(radius[:])
+		data.sphere.Radius = radius[0]
+		vertexSize := int(unsafe.Sizeof(gl.Vertex{}))
+		if nVertices := r.count(vertexSize); nVertices > 0 {
+			data.vertices = make([]gl.Vertex, nVertices)
+			copy(unsafe.Slice((*byte)(unsafe.Pointer(&data.vertices[0])), nVertices*vertexSize), r.bytes(nVertices*vertexSize))
+		}
+		if nIndices := r.count(4); nIndices > 0 {
+			data.indices = make([]uint32, nIndices)
+			copy(unsafe.Slice((*byte)(unsafe.Pointer(&data.indices[0])), nIndices*4), r.bytes(nIndices*4))
+		}
+	}
+
+	nodes := make(map[string]*Node)
+	root := r.node(nil, nodes, len(pending))
+	if r.err == nil && len(r.data) > 0 {
+		r.err = errCorruptCache
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	m.boneCounter = boneCounter
+	m.boneInfoMap = boneInfoMap
+	m.pendingTextures = pendingTextures
+	m.pending = pending
+	m.skinned = skinned
+	m.nodes = nodes
+	m.root = root
+	return nil
+}
+
+type cacheWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *cacheWriter) u32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	w.buf.Write(b[:])
+}
+
+func (w *cacheWriter) bool(v bool) {
+	if v {
+		w.buf.WriteByte(1)
+	} else {
+		w.buf.WriteByte(0)
+	}
+}
+
+func (w *cacheWriter) str(s string) {
+	w.u32(uint32(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *cacheWriter) floats(v []float32) {
+	for _, f := range v {
+		w.u32(math.Float32bits(f))
+	}
+}
+
+func (w *cacheWriter) textures(textures []textureRef) {
+	w.u32(uint32(len(textures)))
+	for _, t := range textures {
+		w.str(t.typeName)
+		w.str(t.name)
+	}
+}
+
+func (w *cacheWriter) material(mat *gl.Material) {
+	w.str(mat.Name)
+	w.floats(mat.Ambient[:])
+	w.floats(mat.Diffuse[:])
+	w.floats(mat.Specular[:])
+	w.floats(mat.Emissive[:])
+	w.floats([]float32{mat.Shininess, mat.Opacity, mat.Metallic, mat.Roughness})
+	w.bool(mat.TwoSided)
+}
+
+func (w *cacheWriter) node(n *Node) {
+	w.str(n.name)
+	w.floats(n.transform[:])
+	w.u32(uint32(len(n.meshes)))
+	for _, i := range n.meshes {
+		w.u32(uint32(i))
+	}
+	w.u32(uint32(len(n.children)))
+	for _, child := range n.children {
+		w.node(child)
+	}
+}
+
+// cacheReader consumes data, the first error sticks and turns the following reads into zeros.
+type cacheReader struct {
+	data []byte
+	err  error
+}
+
+func (r *cacheReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.data) {
+		r.err = errCorruptCache
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *cacheReader) u32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+// count reads a number of elements of at least size bytes each, 0 if they can't fit in the rest of the data.
+func (r *cacheReader) count(size int) int {
+	n := int(r.u32())
+	if n > len(r.data)/size {
+		r.err = errCorruptCache
+		return 0
+	}
+	return n
+}
+
+func (r *cacheReader) bool() bool {
+	b := r.bytes(1)
+	return b != nil && b[0] != 0
+}
+
+func (r *cacheReader) str() string {
+	return string(r.bytes(r.count(1)))
+}
+
+func (r *cacheReader) floats(v []float32) {
+	for i := range v {
+		v[i] = math.Float32frombits(r.u32())
+	}
+}
+
+func (r *cacheReader) textures() []textureRef {
+	n := r.count(8)
+	if n == 0 {
+		return nil
+	}
+	textures := make([]textureRef, n)
+	for i := range textures {
+		textures[i] = textureRef{typeName: r.str(), name: r.str()}
+	}
+	return textures
+}
+
+func (r *cacheReader) material() gl.Material {
+	mat := gl.Material{Name: r.str()}
+	r.floats(mat.Ambient[:])
+	r.floats(mat.Diffuse[:])
+	r.floats(mat.Specular[:])
+	r.floats(mat.Emissive[:])
+	var factors [4]float32
+	r.floats(factors[:])
+	mat.Shininess, mat.Opacity, mat.Metallic, mat.Roughness = factors[0], factors[1], factors[2], factors[3]
+	mat.TwoSided = r.bool()
+	return mat
+}
+
+// node reads a node and its children, the mesh indices must be below meshes.
+func (r *cacheReader) node(parent *Node, nodes map[string]*Node, meshes int) *Node {
+	n := &Node{name: r.str(), parent: parent}
+	r.floats(n.transform[:])
+	if _, ok := nodes[n.name]; !ok && r.err == nil {
+		nodes[n.name] = n
+	}
+	count := r.count(4)
+	for i := 0; i < count; i++ {
+		index := int(r.u32())
+		if index >= meshes {
+			r.err = errCorruptCache
+			return nil
+		}
+		n.meshes = append(n.meshes, index)
+	}
+	count = r.count(1)
+	for i := 0; i < count && r.err == nil; i++ {
+		n.children = append(n.children, r.node(n, nodes, meshes))
+	}
+	return n
+}
+
+// result returns the error of the reader if any, err otherwise.
+func (r *cacheReader) result(err error) error {
+	if r.err != nil {
+		return r.err
+	}
+	return err
+}
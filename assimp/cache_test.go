@@ -0,0 +1,301 @@
+package assimp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"learn_opengl/gl"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// cachedModel returns a parsed model as the cache stores it, with enough bones for the order of the map to vary.
+func cachedModel() *Model {
+	m := &Model{boneInfoMap: make(map[string]BoneInfo), boneCounter: 20}
+	for i := 0; i < m.boneCounter; i++ {
+		m.boneInfoMap[fmt.Sprintf("bone%v", i)] = BoneInfo{Id: i, Offset: mgl32.Translate3D(float32(i), 0, 1)}
+	}
+	m.pendingTextures = []textureRef{{"texture_diffuse", "body.png"}, {"texture_normal", "body_n.png"}}
+	vertices := []gl.Vertex{
+		{Position: mgl32.Vec3{0, 0, 0}, Normal: mgl32.Vec3{0, 0, 1}, TexCoords: mgl32.Vec2{0, 0}},
+		{Position: mgl32.Vec3{1, 0, 0}, Normal: mgl32.Vec3{0, 0, 1}, TexCoords: mgl32.Vec2{1, 0}},
+		{Position: mgl32.Vec3{0, 1, 0}, Normal: mgl32.Vec3{0, 0, 1}, TexCoords: mgl32.Vec2{0, 1}},
+	}
+	vertices[1].BoneIds = [gl.MAX_BONE_INFLUENCE]int32{3, 7, -1, -1}
+	vertices[1].Weights = [gl.MAX_BONE_INFLUENCE]float32{0.25, 0.75, 0, 0}
+	material := gl.DefaultMaterial()
+	material.Name = "skin"
+	material.TwoSided = true
+	m.pending = []meshData{
+		{
+			vertices: vertices,
+			indices:  []uint32{0, 1, 2},
+			material: material,
+			textures: m.pendingTextures,
+			bounds:   gl.AABB{Min: mgl32.Vec3{0, 0, 0}, Max: mgl32.Vec3{1, 1, 0}},
+			sphere:   gl.BoundingSphere{Center: mgl32.Vec3{0.5, 0.5, 0}, Radius: 0.75},
+		},
+		{material: gl.DefaultMaterial()},
+	}
+	m.skinned = []bool{true, false}
+	m.root = &Node{name: "root", transform: mgl32.Ident4()}
+	m.root.children = []*Node{
+		{name: "body", transform: mgl32.Translate3D(0, 1, 0), meshes: []int{0}, parent: m.root},
+		{name: "empty", transform: mgl32.Ident4(), meshes: []int{1, 0}, parent: m.root},
+	}
+	m.nodes = map[string]*Node{"root": m.root, "body": m.root.children[0], "empty": m.root.children[1]}
+	return m
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	m := cachedModel()
+	path := filepath.Join(t.TempDir(), "cache", "model.mesh")
+	key := sha256.Sum256([]byte("model"))
+	if err := m.writeCache(path, key); err != nil {
+		t.Fatal(err)
+	}
+	var got Model
+	if err := got.readCache(path, key); err != nil {
+		t.Fatal(err)
+	}
+	if got.boneCounter != m.boneCounter || !reflect.DeepEqual(got.boneInfoMap, m.boneInfoMap) {
+		t.Errorf("bones %v %v, want %v %v", got.boneCounter, got.boneInfoMap, m.boneCounter, m.boneInfoMap)
+	}
+	if !reflect.DeepEqual(got.pendingTextures, m.pendingTextures) {
+		t.Errorf("textures %v, want %v", got.pendingTextures, m.pendingTextures)
+	}
+	if !reflect.DeepEqual(got.pending, m.pending) {
+		t.Errorf("meshes %+v, want %+v", got.pending, m.pending)
+	}
+	if !reflect.DeepEqual(got.skinned, m.skinned) {
+		t.Errorf("skinned %v, want %v", got.skinned, m.skinned)
+	}
+	if !reflect.DeepEqual(got.root, m.root) {
+		t.Errorf("nodes differ")
+	}
+	if len(got.nodes) != 3 || got.nodes["body"] != got.root.children[0] || got.nodes["body"].parent != got.root {
+		t.Errorf("node map %v", got.nodes)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("cache directory holds %v files, want only the cache", len(entries))
+	}
+}
+
+func TestCacheDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	key := sha256.Sum256([]byte("model"))
+	var first []byte
+	for i := 0; i < 5; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("model%v.mesh", i))
+		if err := cachedModel().writeCache(path, key); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = data
+		} else if !bytes.Equal(data, first) {
+			t.Fatalf("cache file %v differs from the first one", i)
+		}
+	}
+}
+
+// writeCachedModel writes the cache of cachedModel and returns its path and bytes.
+func writeCachedModel(t *testing.T, key [sha256.Size]byte) (string, []byte) {
+	path := filepath.Join(t.TempDir(), "model.mesh")
+	if err := cachedModel().writeCache(path, key); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, data
+}
+
+func TestCacheStale(t *testing.T) {
+	key := sha256.Sum256([]byte("model"))
+	path, data := writeCachedModel(t, key)
+	versionAt := len(CACHE_MAGIC)
+	strideAt := versionAt + 4 + sha256.Size
+
+	for _, c := range []struct {
+		name  string
+		key   [sha256.Size]byte
+		patch func(data []byte)
+	}{
+		{"other key", sha256.Sum256([]byte("edited model")), nil},
+		{"other version", key, func(data []byte) {
+			binary.LittleEndian.PutUint32(data[versionAt:], CACHE_VERSION+1)
+		}},
+		{"other vertex size", key, func(data []byte) {
+			binary.LittleEndian.PutUint32(data[strideAt:], binary.LittleEndian.Uint32(data[strideAt:])+4)
+		}},
+		{"other attribute", key, func(data []byte) {
+			// the first letter of the name of the first attribute
+			data[strideAt+12]++
+		}},
+	} {
+		file := append([]byte(nil), data...)
+		if c.patch != nil {
+			c.patch(file)
+		}
+		if err := os.WriteFile(path, file, 0644); err != nil {
+			t.Fatal(err)
+		}
+		m := Model{boneCounter: -1}
+		if err := m.readCache(path, c.key); err != errStaleCache {
+			t.Errorf("%v: readCache = %v, want %v", c.name, err, errStaleCache)
+		}
+		if m.boneCounter != -1 || m.root != nil || m.pending != nil {
+			t.Errorf("%v: the stale cache changed the model", c.name)
+		}
+	}
+}
+
+func TestCacheCorrupt(t *testing.T) {
+	key := sha256.Sum256([]byte("model"))
+	path, data := writeCachedModel(t, key)
+
+	check := func(name string, file []byte) {
+		t.Helper()
+		if err := os.WriteFile(path, file, 0644); err != nil {
+			t.Fatal(err)
+		}
+		m := Model{boneCounter: -1}
+		if err := m.readCache(path, key); err != errCorruptCache {
+			t.Errorf("%v: readCache = %v, want %v", name, err, errCorruptCache)
+		}
+		if m.boneCounter != -1 || m.root != nil || m.pending != nil {
+			t.Errorf("%v: the corrupt cache changed the model", name)
+		}
+	}
+	// every truncation, within the header as well as within the blobs and the nodes
+	for n := 0; n < len(data); n++ {
+		check(fmt.Sprintf("truncated to %v bytes", n), data[:n])
+	}
+	check("trailing bytes", append(append([]byte(nil), data...), 0))
+	bad := append([]byte(nil), data...)
+	copy(bad, "NOTAMESH")
+	check("bad magic", bad)
+	// the last node refers to mesh 0, make it 2 of 2
+	bad = append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(bad[len(bad)-8:], 2)
+	check("mesh index out of range", bad)
+	// a huge count must not allocate
+	bad = append([]byte(nil), data...)
+	bonesAt := len(CACHE_MAGIC) + 4 + sha256.Size + 8
+	for _, a := range vertexLayout() {
+		bonesAt += 4 + len(a.name) + 12
+	}
+	binary.LittleEndian.PutUint32(bad[bonesAt+4:], 0xffffffff)
+	check("huge bone count", bad)
+
+	var m Model
+	if err := m.readCache(filepath.Join(t.TempDir(), "missing.mesh"), key); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("readCache of a missing file = %v, want a not exist error", err)
+	}
+}
+
+func TestCacheFile(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "rock.obj")
+	if err := os.WriteFile(model, []byte("v 0 0 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := LoadOptions{CacheDir: filepath.Join(dir, "cache"), PostProcess: DEFAULT_POST_PROCESS}
+	path, key, err := cacheFile(model, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != opts.CacheDir || filepath.Ext(path) != CACHE_EXT {
+		t.Errorf("cache file %v isn't a %v file in %v", path, CACHE_EXT, opts.CacheDir)
+	}
+
+	other := opts
+	other.PostProcess |= 1 << 30
+	if _, otherKey, _ := cacheFile(model, other); otherKey == key {
+		t.Errorf("the key doesn't change with PostProcess")
+	}
+	if err = os.WriteFile(model, []byte("v 1 0 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if samePath, editedKey, _ := cacheFile(model, opts); editedKey == key || samePath != path {
+		t.Errorf("editing the model gives %v, key changed %v", samePath, editedKey != key)
+	}
+	if _, _, err = cacheFile(filepath.Join(dir, "missing.obj"), opts); err == nil {
+		t.Errorf("cacheFile of a missing model succeeded")
+	}
+}
+
+func TestCacheFileDependencies(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := LoadOptions{CacheDir: filepath.Join(dir, "cache")}
+	key := func(name string) [sha256.Size]byte {
+		t.Helper()
+		_, key, err := cacheFile(filepath.Join(dir, name), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	write("rock.obj", "mtllib rock.mtl\nv 0 0 0\n")
+	missing := key("rock.obj")
+	// the library appearing, then changing, invalidates the cache
+	write("rock.mtl", "newmtl rock\nKd 1 1 1\n")
+	first := key("rock.obj")
+	if first == missing {
+		t.Errorf("the key doesn't change when the material library appears")
+	}
+	if key("rock.obj") != first {
+		t.Errorf("the key isn't stable")
+	}
+	write("rock.mtl", "newmtl rock\nKd 1 0 0\n")
+	if key("rock.obj") == first {
+		t.Errorf("the key doesn't change with the material library")
+	}
+
+	write("box.gltf", `{"buffers": [{"uri": "box%20data.bin"}, {"uri": "data:application/octet-stream;base64,AAAA"}]}`)
+	write("box data.bin", "\x00\x01")
+	first = key("box.gltf")
+	write("box data.bin", "\x00\x02")
+	if key("box.gltf") == first {
+		t.Errorf("the key doesn't change with the buffer of the glTF")
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	for _, c := range []struct {
+		path   string
+		source string
+		want   []string
+	}{
+		{"rock.obj", "# mtllib commented.mtl\nmtllib rock.mtl\r\nv 0 0 0\n", []string{"rock.mtl"}},
+		{"suit.OBJ", "mtllib  nano suit.mtl \nusemtl glass\nmtllib other.mtl", []string{"nano suit.mtl", "other.mtl"}},
+		{"plain.obj", "v 0 0 0\nmtllib\n", nil},
+		{"box.gltf", `{"buffers": [{"uri": "bin/box%20data.bin"}, {"uri": "data:,AA"}, {}]}`,
+			[]string{filepath.Join("bin", "box data.bin")}},
+		{"broken.gltf", `{"buffers": [`, nil},
+		{"tentacle.fbx", "mtllib rock.mtl", nil},
+	} {
+		if got := dependencies(c.path, []byte(c.source)); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("dependencies(%v) = %q, want %q", c.path, got, c.want)
+		}
+	}
+}
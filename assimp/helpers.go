@@ -0,0 +1,33 @@
+package assimp
+
+// #cgo pkg-config: assimp
+// #include <assimp/mesh.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/huoshan017/assimp"
+)
+
+// convertMatrix converts a row major assimp matrix to a column major mgl32 one.
+func convertMatrix(m *assimp.Matrix4x4) mgl32.Mat4 {
+	v := m.Values()
+	return mgl32.Mat4FromRows(mgl32.Vec4(v[0]), mgl32.Vec4(v[1]), mgl32.Vec4(v[2]), mgl32.Vec4(v[3]))
+}
+
+func convertVec3(v *assimp.Vector3) mgl32.Vec3 {
+	return mgl32.Vec3{v.X(), v.Y(), v.Z()}
+}
+
+func convertQuat(q *assimp.Quaternion) mgl32.Quat {
+	return mgl32.Quat{W: q.W(), V: mgl32.Vec3{q.X(), q.Y(), q.Z()}}
+}
+
+// boneOffsetMatrix returns the matrix that transforms from mesh space to the bone space of the bind pose, the
+// assimp binding doesn't expose it.
+func boneOffsetMatrix(bone *assimp.Bone) mgl32.Mat4 {
+	b := (*C.struct_aiBone)(unsafe.Pointer(bone))
+	return convertMatrix((*assimp.Matrix4x4)(unsafe.Pointer(&b.mOffsetMatrix)))
+}
@@ -0,0 +1,151 @@
+package assimp
+
+import (
+	"context"
+	"learn_opengl/async"
+	"learn_opengl/gl"
+	"log"
+	"sync/atomic"
+
+	"github.com/huoshan017/assimp"
+)
+
+const (
+	DEFAULT_POST_PROCESS = assimp.Process_Triangulate | assimp.Process_GenSmoothNormals | assimp.Process_FlipUVs | assimp.Process_CalcTangentSpace
+)
+
+// LoadOptions configures LoadModel, the zero value loads like NewModelDefault without logging.
+type LoadOptions struct {
+	// PostProcess are the assimp post processing steps, DEFAULT_POST_PROCESS if zero
+	PostProcess assimp.PostProcessSteps
+	// Gamma marks the textures as gamma corrected
+	Gamma bool
+	// TexturePaths are searched in order for the textures that aren't found relative to the model file
+	TexturePaths []string
+	// MissingTexture replaces the textures that fail to load with a magenta checker instead of failing
+	MissingTexture bool
+	// Logger receives what is loaded, nil discards it
+	Logger *log.Logger
+	// CacheDir receives the cooked meshes of the imported models. Loading reads them instead of importing with
+	// assimp while the model file, the material libraries of an .obj, the buffers of a .gltf and PostProcess are
+	// unchanged. Empty disables the cache
+	CacheDir string
+}
+
+// LoadModel loads a model with supported ASSIMP extensions. Unlike NewModel it returns an error instead of exiting.
+func LoadModel(path string, opts LoadOptions) (*Model, error) {
+	return LoadModelContext(context.Background(), path, opts)
+}
+
+// LoadModelContext is LoadModel stopping with ctx.Err() once ctx is done. The assimp import itself can't be
+// interrupted, the context is checked before every mesh and texture. Whatever was loaded is released on error.
+func LoadModelContext(ctx context.Context, path string, opts LoadOptions) (*Model, error) {
+	model := newModel(ctx, opts)
+	err := model.load(path)
+	model.ctx = nil
+	if err != nil {
+		model.Release()
+		return nil, err
+	}
+	return model, nil
+}
+
+// LoadModelAsync is LoadModelContext without blocking the GL thread. The scene is parsed and the textures decoded
+// on the workers of l, then l.Update uploads one texture or mesh per task on the GL thread. The future counts a step
+// for the parse, two per texture and one per mesh. Whatever was uploaded is released on error.
+func LoadModelAsync(ctx context.Context, l *async.Loader, path string, opts LoadOptions) *async.Future[*Model] {
+	model := newModel(ctx, opts)
+	f := async.NewFuture[*Model]()
+	f.AddSteps(1)
+	l.Go(func() {
+		if err := model.parse(path); err != nil {
+			f.Resolve(nil, err)
+			return
+		}
+		f.AddSteps(2*len(model.pendingTextures) + len(model.pending))
+		f.Step()
+
+		// every texture is decoded by its own task, the last one to finish schedules the uploads
+		textures := model.pendingTextures
+		images := make([]*gl.TextureImage, len(textures))
+		errs := make([]error, len(textures))
+		remaining := int32(len(textures))
+		if remaining == 0 {
+			model.scheduleUploads(l, f, images, errs)
+			return
+		}
+		for i := range textures {
+			i := i
+			l.Go(func() {
+				if errs[i] = ctx.Err(); errs[i] == nil {
+					images[i], errs[i] = model.decodeTexture(textures[i].name)
+				}
+				f.Step()
+				if atomic.AddInt32(&remaining, -1) == 0 {
+					model.scheduleUploads(l, f, images, errs)
+				}
+			})
+		}
+	})
+	return f
+}
+
+// scheduleUploads posts the uploads of the decoded textures and of the pending meshes to the GL thread, followed by
+// the resolution of f.
+func (m *Model) scheduleUploads(l *async.Loader, f *async.Future[*Model], images []*gl.TextureImage, errs []error) {
+	// only touched by the tasks of the GL thread, which run in order
+	var failed error
+	upload := func(task func() error) {
+		l.Main(func() {
+			if failed == nil {
+				failed = m.ctx.Err()
+			}
+			if failed == nil {
+				failed = task()
+			}
+			f.Step()
+		})
+	}
+	for i := range m.pendingTextures {
+		i := i
+		upload(func() error {
+			return m.uploadTexture(m.pendingTextures[i], images[i], errs[i])
+		})
+	}
+	for i := range m.pending {
+		i := i
+		upload(func() error {
+			m.uploadMesh(&m.pending[i])
+			return nil
+		})
+	}
+	l.Main(func() {
+		m.ctx = nil
+		if failed != nil {
+			m.Release()
+			f.Resolve(nil, failed)
+			return
+		}
+		m.finishUpload()
+		f.Resolve(m, nil)
+	})
+}
+
+func newModel(ctx context.Context, opts LoadOptions) *Model {
+	if opts.PostProcess == 0 {
+		opts.PostProcess = DEFAULT_POST_PROCESS
+	}
+	return &Model{
+		gammaCorrection: opts.Gamma,
+		boneInfoMap:     make(map[string]BoneInfo),
+		nodes:           make(map[string]*Node),
+		opts:            opts,
+		ctx:             ctx,
+	}
+}
+
+func (m *Model) logf(format string, v ...interface{}) {
+	if m.opts.Logger != nil {
+		m.opts.Logger.Printf(format, v...)
+	}
+}
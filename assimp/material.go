@@ -0,0 +1,37 @@
+package assimp
+
+import (
+	"learn_opengl/gl"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/huoshan017/assimp"
+)
+
+// loadMaterial reads the colors, shininess, opacity and two-sidedness of an assimp material, the properties a file
+// doesn't set keep the values of gl.DefaultMaterial.
+func loadMaterial(mat *assimp.Material) gl.Material {
+	material := gl.DefaultMaterial()
+	if name, ret := mat.GetMaterialString(assimp.MatKey_Name, assimp.TextureType_None, 0); ret == assimp.Return_Success {
+		material.Name = name
+	}
+	materialColor(mat, assimp.MatKey_ColorAmbient, &material.Ambient)
+	materialColor(mat, assimp.MatKey_ColorDiffuse, &material.Diffuse)
+	materialColor(mat, assimp.MatKey_ColorSpecular, &material.Specular)
+	materialColor(mat, assimp.MatKey_ColorEmissive, &material.Emissive)
+	if shininess, ret := mat.GetMaterialFloat(assimp.MatKey_Shininess, assimp.TextureType_None, 0); ret == assimp.Return_Success && shininess > 0 {
+		material.Shininess = shininess
+	}
+	if opacity, ret := mat.GetMaterialFloat(assimp.MatKey_Opacity, assimp.TextureType_None, 0); ret == assimp.Return_Success {
+		material.Opacity = opacity
+	}
+	if twoSided, ret := mat.GetMaterialInteger(assimp.MatKey_TwoSidid, assimp.TextureType_None, 0); ret == assimp.Return_Success {
+		material.TwoSided = twoSided != 0
+	}
+	return material
+}
+
+func materialColor(mat *assimp.Material, key assimp.MatKey, color *mgl32.Vec3) {
+	if c, ret := mat.GetMaterialColor(key, assimp.TextureType_None, 0); ret == assimp.Return_Success {
+		*color = mgl32.Vec3{c.R(), c.G(), c.B()}
+	}
+}
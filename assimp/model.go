@@ -1,8 +1,13 @@
 package assimp
 
 import (
+	"context"
+	"fmt"
+	"learn_opengl/geometry"
 	"learn_opengl/gl"
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/huoshan017/assimp"
@@ -13,13 +18,47 @@ type Model struct {
 	meshes          []gl.Mesh
 	directory       string
 	gammaCorrection bool
+	boneInfoMap     map[string]BoneInfo
+	boneCounter     int
+	root            *Node
+	nodes           map[string]*Node
+	// skinned tells which meshes are placed by their bones rather than by their node
+	skinned []bool
+	// missingTexture is the checker standing in for the textures that failed to load, 0 until needed
+	missingTexture uint32
+	opts           LoadOptions
+	ctx            context.Context
+	// pending holds the meshes parsed but not uploaded yet, pendingTextures the textures they use, each name once
+	pending         []meshData
+	pendingTextures []textureRef
+	// textureIds maps the names of the uploaded textures to their ids while the meshes are uploaded
+	textureIds map[string]uint32
 }
 
+// meshData is a mesh read from the scene, kept in memory until it is uploaded on the GL thread.
+type meshData struct {
+	vertices []gl.Vertex
+	indices  []uint32
+	material gl.Material
+	textures []textureRef
+	// bounds and sphere contain the positions of the vertices, stored in the cache with them
+	bounds gl.AABB
+	sphere gl.BoundingSphere
+}
+
+// textureRef is a texture named by a material.
+type textureRef struct {
+	typeName string
+	name     string
+}
+
+var _ gl.Model = (*Model)(nil)
+
 func NewModel(path string, gamma bool) *Model {
-	model := &Model{
-		gammaCorrection: gamma,
+	model, err := LoadModel(path, LoadOptions{Gamma: gamma, Logger: log.Default()})
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
-	model.load(path)
 	return model
 }
 
@@ -35,67 +74,254 @@ func (m *Model) Meshes() []gl.Mesh {
 	return m.meshes
 }
 
-func (m *Model) Draw(shader *gl.Shader) {
-	for i := 0; i < len(m.meshes); i++ {
+// BoneInfoMap returns the bones referenced by the meshes by name.
+func (m *Model) BoneInfoMap() map[string]BoneInfo {
+	return m.boneInfoMap
+}
+
+// BoneCount returns the number of bones, the ids range from 0 to BoneCount()-1.
+func (m *Model) BoneCount() int {
+	return m.boneCounter
+}
+
+// Root returns the root of the node hierarchy.
+func (m *Model) Root() *Node {
+	return m.root
+}
+
+// MeshBounds returns the bounding box of mesh i, in the space of its node.
+func (m *Model) MeshBounds(i int) gl.AABB {
+	return m.meshes[i].Bounds()
+}
+
+// Bounds returns the bounding box of the meshes placed by their nodes, skinned meshes in their bind pose.
+func (m *Model) Bounds() gl.AABB {
+	b, _ := m.NodeBounds(m.root)
+	return b
+}
+
+// BoundingSphere returns a sphere containing the meshes placed by their nodes, see Bounds.
+func (m *Model) BoundingSphere() gl.BoundingSphere {
+	_, s := m.NodeBounds(m.root)
+	return s
+}
+
+// NodeBounds returns the bounding box and a bounding sphere of the meshes of node and its descendants, in the space
+// of the model. They are empty for a nil node.
+func (m *Model) NodeBounds(node *Node) (gl.AABB, gl.BoundingSphere) {
+	if node == nil {
+		return gl.EmptyAABB(), gl.EmptyAABB().Sphere()
+	}
+	parent := mgl32.Ident4()
+	if node.parent != nil {
+		parent = node.parent.WorldTransform()
+	}
+	return m.nodeBounds(node, parent)
+}
+
+// nodeBounds places the meshes like drawNode with the identity model matrix.
+func (m *Model) nodeBounds(node *Node, parent mgl32.Mat4) (gl.AABB, gl.BoundingSphere) {
+	world := parent.Mul4(node.transform)
+	b, s := gl.EmptyAABB(), gl.EmptyAABB().Sphere()
+	for _, i := range node.meshes {
+		transform := world
+		if m.skinned[i] {
+			transform = mgl32.Ident4()
+		}
+		b = b.Union(m.meshes[i].Bounds().Transform(transform))
+		s = s.Union(m.meshes[i].BoundingSphere().Transform(transform))
+	}
+	for _, child := range node.children {
+		childBounds, childSphere := m.nodeBounds(child, world)
+		b, s = b.Union(childBounds), s.Union(childSphere)
+	}
+	return b, s
+}
+
+// FindNode returns the node called name, nil if there is none. The first node wins if several share a name.
+func (m *Model) FindNode(name string) *Node {
+	return m.nodes[name]
+}
+
+// Draw draws the meshes of every node with the model matrix model times the world transform of the node, uploaded to
+// MODEL_UNIFORM. Skinned meshes are drawn with model alone, their bone matrices already place them.
+func (m *Model) Draw(shader *gl.Shader, model mgl32.Mat4) {
+	if m.root != nil {
+		m.drawNode(m.root, shader, model, model)
+	}
+}
+
+func (m *Model) drawNode(node *Node, shader *gl.Shader, model, parent mgl32.Mat4) {
+	world := parent.Mul4(node.transform)
+	for _, i := range node.meshes {
+		if m.skinned[i] {
+			shader.SetMat4(MODEL_UNIFORM, &model)
+		} else {
+			shader.SetMat4(MODEL_UNIFORM, &world)
+		}
 		m.meshes[i].Draw(shader)
 	}
+	for _, child := range node.children {
+		m.drawNode(child, shader, model, world)
+	}
+}
+
+// Release frees the buffers of every mesh and each loaded texture exactly once, the model must not be drawn
+// afterwards. A nil model is ignored.
+func (m *Model) Release() {
+	if m == nil {
+		return
+	}
+	for i := 0; i < len(m.meshes); i++ {
+		m.meshes[i].Delete()
+	}
+	// meshes share the textures of textureLoaded, which holds every path only once
+	for i := 0; i < len(m.textureLoaded); i++ {
+		m.textureLoaded[i].Delete()
+	}
+	if m.missingTexture != 0 {
+		gl.NewTexture(m.missingTexture, "", "").Delete()
+		m.missingTexture = 0
+	}
+	m.meshes = nil
+	m.textureLoaded = nil
+	m.pending = nil
+	m.pendingTextures = nil
+	m.textureIds = nil
+	m.skinned = nil
+	m.root = nil
+	m.nodes = make(map[string]*Node)
 }
 
 // loads a model with supported ASSIMP extensions from file and stores the resulting meshes in the meshes vector.
-func (m *Model) load(path string) {
+func (m *Model) load(path string) error {
+	if err := m.parse(path); err != nil {
+		return err
+	}
+	for _, ref := range m.pendingTextures {
+		if err := m.ctx.Err(); err != nil {
+			return err
+		}
+		image, err := m.decodeTexture(ref.name)
+		if err = m.uploadTexture(ref, image, err); err != nil {
+			return err
+		}
+	}
+	for i := range m.pending {
+		if err := m.ctx.Err(); err != nil {
+			return err
+		}
+		m.uploadMesh(&m.pending[i])
+	}
+	m.finishUpload()
+	return nil
+}
+
+// parse reads the scene into the node hierarchy and the pending meshes, from the cache when it is enabled and up to
+// date. It makes no GL call.
+func (m *Model) parse(path string) error {
+	// retrieve the directory path of the filepath
+	m.directory = filepath.Dir(path)
+	if m.opts.CacheDir == "" {
+		return m.importScene(path)
+	}
+
+	cachePath, key, err := cacheFile(path, m.opts)
+	if err != nil {
+		return err
+	}
+	if err = m.readCache(cachePath, key); err == nil {
+		m.logf("read cooked meshes of %v from %v", path, cachePath)
+		return nil
+	} else if !os.IsNotExist(err) {
+		m.logf("assimp: ignoring cache %v: %v", cachePath, err)
+	}
+	if err = m.importScene(path); err != nil {
+		return err
+	}
+	// the model is fine without cache, it is imported again next time
+	if err = m.writeCache(cachePath, key); err != nil {
+		m.logf("assimp: failed to write cache %v: %v", cachePath, err)
+	}
+	return nil
+}
+
+// importScene reads the scene with assimp.
+func (m *Model) importScene(path string) error {
 	// read file via assimp
-	scene := assimp.ImportFile(path, uint(assimp.Process_Triangulate|assimp.Process_GenSmoothNormals|assimp.Process_FlipUVs|assimp.Process_CalcTangentSpace))
+	scene := assimp.ImportFile(path, uint(m.opts.PostProcess))
 
 	// check for errors
 	if scene == nil || scene.Flags()&assimp.SceneFlags_Incomplete > 0 || scene.RootNode() == nil {
-		log.Fatalf("ERROR::ASSIMP:: %v", assimp.GetErrorString())
-	}
-
-	// retrieve the directory path of the filepath
-	var found bool
-	pathBytes := []byte(path)
-	for i := len(pathBytes) - 1; i >= 0; i-- {
-		if pathBytes[i] == '/' {
-			m.directory = string(pathBytes[:i])
-			found = true
-			break
+		if scene != nil {
+			scene.ReleaseImport()
 		}
+		return fmt.Errorf("assimp: failed to import %v: %v", path, assimp.GetErrorString())
 	}
-	if !found {
-		log.Fatalf("ERROR::STRINGS:: not found sperate")
-	}
+	defer scene.ReleaseImport()
 
 	// process ASSIMP's root node recursively
-	m.processNode(scene.RootNode(), scene)
+	var err error
+	m.root, err = m.processNode(scene.RootNode(), nil, scene, make(map[int32]int))
+	return err
 }
 
 // processes a node in a recursive fashion. Processes each individual mesh located at the node and repeats this process on its children nodes (if any).
-func (m *Model) processNode(node *assimp.Node, scene *assimp.Scene) {
+// processed maps the scene's mesh indices to those of m.pending, a mesh shared by several nodes is only loaded once.
+func (m *Model) processNode(node *assimp.Node, parent *Node, scene *assimp.Scene, processed map[int32]int) (*Node, error) {
+	transformation := node.Transformation()
+	n := &Node{
+		name:      node.Name(),
+		transform: convertMatrix(&transformation),
+		parent:    parent,
+	}
+	if _, ok := m.nodes[n.name]; !ok {
+		m.nodes[n.name] = n
+	}
+
 	meshes := scene.Meshes()
 	// process each mesh located at the current node
+	nMeshes := node.Meshes()
 	for i := 0; i < node.NumMeshes(); i++ {
 		// the node object only contains indices to index the actual objects in the scene.
 		// the scene contains all the data, node is just to keep stuff organized (like relations between nodes).
-		nMeshes := node.Meshes()
-		mesh := meshes[nMeshes[i]]
-		glmesh := m.processMesh(mesh, scene)
-		m.meshes = append(m.meshes, glmesh)
+		index, ok := processed[nMeshes[i]]
+		if !ok {
+			if err := m.ctx.Err(); err != nil {
+				return nil, err
+			}
+			mesh := meshes[nMeshes[i]]
+			data, err := m.processMesh(mesh, scene)
+			if err != nil {
+				return nil, err
+			}
+			m.pending = append(m.pending, data)
+			m.skinned = append(m.skinned, mesh.NumBones() > 0)
+			index = len(m.pending) - 1
+			processed[nMeshes[i]] = index
+		}
+		n.meshes = append(n.meshes, index)
 	}
 	// after we've processed all of the meshes (if any) we then recursively process each of the children nodes
 	nodeChildren := node.Children()
 	for i := 0; i < node.NumChildren(); i++ {
-		m.processNode(nodeChildren[i], scene)
+		child, err := m.processNode(nodeChildren[i], n, scene, processed)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, child)
 	}
 
-	log.Printf("num of meshes %v, num of nodeChildren %v", node.NumMeshes(), node.NumChildren())
+	m.logf("num of meshes %v, num of nodeChildren %v", node.NumMeshes(), node.NumChildren())
+	return n, nil
 }
 
-func (m *Model) processMesh(mesh *assimp.Mesh, scene *assimp.Scene) gl.Mesh {
+func (m *Model) processMesh(mesh *assimp.Mesh, scene *assimp.Scene) (meshData, error) {
 	// data to fill
 	var (
 		vertices []gl.Vertex
 		indices  []uint32
-		textures []gl.Texture
+		textures []textureRef
 	)
 
 	meshVertices := mesh.Vertices()
@@ -105,6 +331,7 @@ func (m *Model) processMesh(mesh *assimp.Mesh, scene *assimp.Scene) gl.Mesh {
 	// walk through each of the mesh's vertices
 	for i := 0; i < mesh.NumVertices(); i++ {
 		var vertex gl.Vertex
+		setVertexBoneDataToDefault(&vertex)
 		// positions
 		aiVertex := &meshVertices[i]
 		var vector = mgl32.Vec3{aiVertex.X(), aiVertex.Y(), aiVertex.Z()}
@@ -121,20 +348,25 @@ func (m *Model) processMesh(mesh *assimp.Mesh, scene *assimp.Scene) gl.Mesh {
 			// use models where a vertex can have multiple texture coordinates so we always take the first set (0).
 			var vec = mgl32.Vec2{c[i].X(), c[i].Y()}
 			vertex.TexCoords = vec
-			// tangent
-			tangent := &meshTangents[i]
-			vector = mgl32.Vec3{tangent.X(), tangent.Y(), tangent.Z()}
-			vertex.Tangent = vector
-			// bitangent
-			bitangent := &meshBitangents[i]
-			vector = mgl32.Vec3{bitangent.X(), bitangent.Y(), bitangent.Z()}
-			vertex.Bitangent = vector
+			// tangent, missing when the tangent space wasn't computed or failed
+			if meshTangents != nil && meshBitangents != nil {
+				tangent := &meshTangents[i]
+				vector = mgl32.Vec3{tangent.X(), tangent.Y(), tangent.Z()}
+				vertex.Tangent = vector
+				// bitangent
+				bitangent := &meshBitangents[i]
+				vector = mgl32.Vec3{bitangent.X(), bitangent.Y(), bitangent.Z()}
+				vertex.Bitangent = vector
+			}
 		} else {
 			vertex.TexCoords = mgl32.Vec2{0.0, 0.0}
 		}
 
 		vertices = append(vertices, vertex)
 	}
+	if err := m.extractBoneWeightForVertices(vertices, mesh); err != nil {
+		return meshData{}, err
+	}
 
 	meshNumFaces := mesh.NumFaces()
 	meshFaces := mesh.Faces()
@@ -147,6 +379,11 @@ func (m *Model) processMesh(mesh *assimp.Mesh, scene *assimp.Scene) gl.Mesh {
 			indices = append(indices, face.Index(j))
 		}
 	}
+	// compute the tangent space assimp didn't provide
+	if (meshTangents == nil || meshBitangents == nil) && len(mesh.TextureCoords(0)) > 0 &&
+		m.opts.PostProcess&assimp.Process_CalcTangentSpace != 0 && len(indices)%3 == 0 {
+		vertices, indices = geometry.GenerateTangents(vertices, indices)
+	}
 
 	// process materials
 	material := scene.Materials()[mesh.MaterialIndex()]
@@ -158,49 +395,175 @@ func (m *Model) processMesh(mesh *assimp.Mesh, scene *assimp.Scene) gl.Mesh {
 	// normal: texture_normalN
 
 	// 1. diffuse maps
-	diffuseMaps := m.loadMaterialTextures(material, assimp.TextureType_Diffuse, "texture_diffuse")
-	textures = append(textures, diffuseMaps...)
 	// 2. specular maps
-	specularMaps := m.loadMaterialTextures(material, assimp.TextureType_Specular, "texture_specular")
-	textures = append(textures, specularMaps...)
 	// 3. normal maps
-	normalMaps := m.loadMaterialTextures(material, assimp.TextureType_Height, "texture_normal")
-	textures = append(textures, normalMaps...)
 	// 4. height maps
-	heightMaps := m.loadMaterialTextures(material, assimp.TextureType_Ambient, "texture_height")
-	textures = append(textures, heightMaps...)
+	for _, slot := range []struct {
+		typ      assimp.TextureType
+		typeName string
+	}{
+		{assimp.TextureType_Diffuse, "texture_diffuse"},
+		{assimp.TextureType_Specular, "texture_specular"},
+		{assimp.TextureType_Height, "texture_normal"},
+		{assimp.TextureType_Ambient, "texture_height"},
+	} {
+		textures = append(textures, m.loadMaterialTextures(material, slot.typ, slot.typeName)...)
+	}
+
+	m.logf("len(vertices)=%v len(indices)=%v len(textures)=%v", len(vertices), len(indices), len(textures))
+	// 5. colors, shininess, opacity and the other properties
+	data := meshData{vertices: vertices, indices: indices, material: loadMaterial(material), textures: textures}
+	data.bounds, data.sphere = gl.ComputeAABB(vertices), gl.ComputeBoundingSphere(vertices)
+	return data, nil
+}
+
+func setVertexBoneDataToDefault(vertex *gl.Vertex) {
+	for i := 0; i < gl.MAX_BONE_INFLUENCE; i++ {
+		vertex.BoneIds[i] = -1
+		vertex.Weights[i] = 0.0
+	}
+}
 
-	log.Printf("len(vertices)=%v len(indices)=%v len(textures)=%v", len(vertices), len(indices), len(textures))
-	return gl.NewMesh(vertices, indices, textures)
+// setVertexBoneData keeps the MAX_BONE_INFLUENCE largest weights of a vertex.
+func setVertexBoneData(vertex *gl.Vertex, boneId int32, weight float32) {
+	smallest := 0
+	for i := 0; i < gl.MAX_BONE_INFLUENCE; i++ {
+		if vertex.BoneIds[i] < 0 {
+			vertex.BoneIds[i] = boneId
+			vertex.Weights[i] = weight
+			return
+		}
+		if vertex.Weights[i] < vertex.Weights[smallest] {
+			smallest = i
+		}
+	}
+	if weight > vertex.Weights[smallest] {
+		vertex.BoneIds[smallest] = boneId
+		vertex.Weights[smallest] = weight
+	}
 }
 
-func (m *Model) loadMaterialTextures(mat *assimp.Material, typ assimp.TextureType, typeName string) []gl.Texture {
-	var textures []gl.Texture
+// extractBoneWeightForVertices assigns the bones of the mesh to the model and their weights to the vertices, the
+// weights of every vertex are normalized to sum up to 1.
+func (m *Model) extractBoneWeightForVertices(vertices []gl.Vertex, mesh *assimp.Mesh) error {
+	for _, bone := range mesh.Bones() {
+		boneName := bone.Name()
+		info, ok := m.boneInfoMap[boneName]
+		if !ok {
+			info = BoneInfo{Id: m.boneCounter, Offset: boneOffsetMatrix(bone)}
+			m.boneInfoMap[boneName] = info
+			m.boneCounter++
+		}
+		for _, w := range bone.Weights() {
+			vertexId := int(w.VertexId())
+			if vertexId >= len(vertices) {
+				return fmt.Errorf("assimp: bone %v weights vertex %v of %v", boneName, vertexId, len(vertices))
+			}
+			setVertexBoneData(&vertices[vertexId], int32(info.Id), w.Weight())
+		}
+	}
+
+	for i := range vertices {
+		var sum float32
+		for _, w := range vertices[i].Weights {
+			sum += w
+		}
+		if sum > 0 {
+			for j := range vertices[i].Weights {
+				vertices[i].Weights[j] /= sum
+			}
+		}
+	}
+	return nil
+}
+
+// loadMaterialTextures names the textures of a type, the textures not seen yet are added to the pending ones.
+func (m *Model) loadMaterialTextures(mat *assimp.Material, typ assimp.TextureType, typeName string) []textureRef {
+	var textures []textureRef
 	matTextureCount := mat.GetMaterialTextureCount(typ)
 	for i := 0; i < matTextureCount; i++ {
 		var str string
 		str, _, _, _, _, _, _, _ = mat.GetMaterialTexture(typ, i)
-		if str == "" {
-			log.Fatalf("material %p get empty string with type %v and index %v", mat, typ, i)
-		}
+		ref := textureRef{typeName: typeName, name: str}
+		textures = append(textures, ref)
 		var skip bool
-		for j := 0; j < len(m.textureLoaded); j++ {
-			if str == m.textureLoaded[j].Path() {
-				textures = append(textures, m.textureLoaded[j])
+		for j := 0; j < len(m.pendingTextures); j++ {
+			if str == m.pendingTextures[j].name {
 				skip = true
 				break
 			}
 		}
+		// if texture hasn't been seen already, load it. Each texture is only loaded once for the entire model.
 		if !skip {
-			// if texture hasn't been loaded already, load it
-			log.Printf("before new texture, str %v", str)
-			textureId := gl.TextureFromFile(str, m.directory, false)
-			texture := gl.NewTexture(textureId, typeName, str)
-			textures = append(textures, texture)
-			m.textureLoaded = append(m.textureLoaded, texture) // store it as texture loaded for entire model, to ensure we won't unnecessary load duplicate textures.
-			log.Printf("new texture %v, str %v", texture.Id(), str)
+			m.pendingTextures = append(m.pendingTextures, ref)
 		}
 	}
-	log.Printf("m.textureLoaded len %v,  textures len %v", len(m.textureLoaded), len(textures))
 	return textures
 }
+
+// decodeTexture decodes a texture named by a material, looked up relative to the directory of the model and then in
+// the texture search paths. It makes no GL call.
+func (m *Model) decodeTexture(name string) (*gl.TextureImage, error) {
+	path, directory, err := gl.FindTexture(name, append([]string{m.directory}, m.opts.TexturePaths...))
+	if err != nil {
+		return nil, fmt.Errorf("assimp: %w", err)
+	}
+	return gl.DecodeTextureImage(path, directory)
+}
+
+// uploadTexture creates the texture of ref from its decoded image. When decoding failed with decodeErr, the texture
+// is replaced by the missing texture if the options allow it.
+func (m *Model) uploadTexture(ref textureRef, image *gl.TextureImage, decodeErr error) error {
+	if m.textureIds == nil {
+		m.textureIds = make(map[string]uint32)
+	}
+	if decodeErr != nil {
+		if !m.opts.MissingTexture {
+			return decodeErr
+		}
+		m.logf("%v, using the missing texture", decodeErr)
+		// the checker isn't stored with the loaded textures, it is shared and released once
+		textureId, err := m.missingTextureId()
+		if err != nil {
+			return err
+		}
+		m.textureIds[ref.name] = textureId
+		return nil
+	}
+	textureId, err := gl.UploadTextureImage(image)
+	if err != nil {
+		return err
+	}
+	texture := gl.NewTexture(textureId, ref.typeName, ref.name)
+	m.textureLoaded = append(m.textureLoaded, texture)
+	m.textureIds[ref.name] = textureId
+	m.logf("new texture %v, str %v", texture.Id(), ref.name)
+	return nil
+}
+
+// uploadMesh creates the buffers of a pending mesh, its textures must be uploaded.
+func (m *Model) uploadMesh(data *meshData) {
+	material := data.material
+	for _, ref := range data.textures {
+		material.Textures = append(material.Textures, gl.NewTexture(m.textureIds[ref.name], ref.typeName, ref.name))
+	}
+	m.meshes = append(m.meshes, gl.NewMeshWithBounds(data.vertices, data.indices, material, data.bounds, data.sphere))
+}
+
+// finishUpload drops what was only needed while uploading.
+func (m *Model) finishUpload() {
+	m.pending = nil
+	m.pendingTextures = nil
+	m.textureIds = nil
+}
+
+func (m *Model) missingTextureId() (uint32, error) {
+	if m.missingTexture == 0 {
+		textureId, err := gl.MissingTexture()
+		if err != nil {
+			return 0, err
+		}
+		m.missingTexture = textureId
+	}
+	return m.missingTexture, nil
+}
@@ -0,0 +1,59 @@
+package assimp
+
+import (
+	"learn_opengl/gl"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// uniform the per node model matrix is uploaded to by Model.Draw
+	MODEL_UNIFORM = gl.MODEL_UNIFORM
+)
+
+// Node is a node of the scene hierarchy of a model. Its transform is relative to its parent and places the meshes
+// of the node and its children.
+type Node struct {
+	name      string
+	transform mgl32.Mat4
+	meshes    []int
+	parent    *Node
+	children  []*Node
+}
+
+func (n *Node) Name() string {
+	return n.name
+}
+
+// Transform returns the local transform, relative to the parent node.
+func (n *Node) Transform() mgl32.Mat4 {
+	return n.transform
+}
+
+// SetTransform overrides the local transform, e.g. to move one part of a model.
+func (n *Node) SetTransform(transform mgl32.Mat4) {
+	n.transform = transform
+}
+
+// WorldTransform returns the transform of the node relative to the root of the model.
+func (n *Node) WorldTransform() mgl32.Mat4 {
+	transform := n.transform
+	for p := n.parent; p != nil; p = p.parent {
+		transform = p.transform.Mul4(transform)
+	}
+	return transform
+}
+
+// Meshes returns the indices of the meshes of the node in Model.Meshes.
+func (n *Node) Meshes() []int {
+	return n.meshes
+}
+
+// Parent returns the parent node, nil for the root.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+func (n *Node) Children() []*Node {
+	return n.children
+}
@@ -0,0 +1,98 @@
+package async
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrPending is returned by Future.Result before the future is resolved
+	ErrPending = errors.New("async: result pending")
+)
+
+// Future is the handle of a result computed in the background. It counts the steps of the work as well, so that a
+// loading screen can show the progress.
+type Future[T any] struct {
+	done  chan struct{}
+	once  sync.Once
+	value T
+	err   error
+	// total and completed steps, updated atomically
+	total     int64
+	completed int64
+}
+
+func NewFuture[T any]() *Future[T] {
+	return &Future[T]{done: make(chan struct{})}
+}
+
+// Resolve sets the result and wakes up the waiters, only the first call counts.
+func (f *Future[T]) Resolve(value T, err error) {
+	f.once.Do(func() {
+		f.value = value
+		f.err = err
+		close(f.done)
+	})
+}
+
+// Done is closed once the future is resolved.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *Future[T]) Ready() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Result returns the result once resolved, the zero value and ErrPending before.
+func (f *Future[T]) Result() (T, error) {
+	if !f.Ready() {
+		var zero T
+		return zero, ErrPending
+	}
+	return f.value, f.err
+}
+
+// Wait blocks until the future is resolved. Work finishing on the GL thread can't progress while that thread waits,
+// it has to call Loader.Flush instead.
+func (f *Future[T]) Wait() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// AddSteps adds n steps to the work, as soon as they are known.
+func (f *Future[T]) AddSteps(n int) {
+	atomic.AddInt64(&f.total, int64(n))
+}
+
+// Step marks one step as completed.
+func (f *Future[T]) Step() {
+	atomic.AddInt64(&f.completed, 1)
+}
+
+// Steps returns the completed and the total steps known so far.
+func (f *Future[T]) Steps() (completed, total int) {
+	return int(atomic.LoadInt64(&f.completed)), int(atomic.LoadInt64(&f.total))
+}
+
+// Progress returns the completed part of the work, from 0 to 1. It is 1 once resolved, even after a failure.
+func (f *Future[T]) Progress() float32 {
+	if f.Ready() {
+		return 1
+	}
+	completed, total := f.Steps()
+	if total == 0 {
+		return 0
+	}
+	if completed >= total {
+		// the last steps may not be known yet
+		return 0.99
+	}
+	return float32(completed) / float32(total)
+}
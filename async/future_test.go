@@ -0,0 +1,77 @@
+package async
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFutureResolve(t *testing.T) {
+	f := NewFuture[int]()
+	if f.Ready() {
+		t.Errorf("new future is ready")
+	}
+	if v, err := f.Result(); v != 0 || err != ErrPending {
+		t.Errorf("Result = %v, %v, want 0, ErrPending", v, err)
+	}
+
+	waited := make(chan int)
+	go func() {
+		v, _ := f.Wait()
+		waited <- v
+	}()
+	f.Resolve(42, nil)
+	if v := <-waited; v != 42 {
+		t.Errorf("Wait = %v, want 42", v)
+	}
+	select {
+	case <-f.Done():
+	default:
+		t.Errorf("Done isn't closed after Resolve")
+	}
+	// only the first resolution counts
+	f.Resolve(7, errors.New("late"))
+	if v, err := f.Result(); v != 42 || err != nil {
+		t.Errorf("Result = %v, %v, want 42, nil", v, err)
+	}
+}
+
+func TestFutureError(t *testing.T) {
+	f := NewFuture[*int]()
+	want := errors.New("decode failed")
+	go f.Resolve(nil, want)
+	if v, err := f.Wait(); v != nil || err != want {
+		t.Errorf("Wait = %v, %v, want nil, %v", v, err, want)
+	}
+	if _, err := f.Result(); err != want {
+		t.Errorf("Result error = %v, want %v", err, want)
+	}
+	if p := f.Progress(); p != 1 {
+		t.Errorf("Progress of a failed future = %v, want 1", p)
+	}
+}
+
+func TestFutureProgress(t *testing.T) {
+	f := NewFuture[struct{}]()
+	if p := f.Progress(); p != 0 {
+		t.Errorf("Progress without steps = %v, want 0", p)
+	}
+	f.AddSteps(4)
+	f.Step()
+	if p := f.Progress(); p != 0.25 {
+		t.Errorf("Progress = %v, want 0.25", p)
+	}
+	for i := 0; i < 3; i++ {
+		f.Step()
+	}
+	if completed, total := f.Steps(); completed != 4 || total != 4 {
+		t.Errorf("Steps = %v, %v, want 4, 4", completed, total)
+	}
+	// steps still unknown may follow, the future isn't done before it resolves
+	if p := f.Progress(); p != 0.99 {
+		t.Errorf("Progress of completed steps = %v, want 0.99", p)
+	}
+	f.Resolve(struct{}{}, nil)
+	if p := f.Progress(); p != 1 {
+		t.Errorf("Progress once resolved = %v, want 1", p)
+	}
+}
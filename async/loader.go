@@ -0,0 +1,66 @@
+// Package async loads resources in the background: files are read and decoded by a pool of workers, then the GL
+// objects are created on the render thread a few at a time, so that the window keeps drawing meanwhile.
+package async
+
+import (
+	"time"
+)
+
+const (
+	// time spent uploading per frame by Loader.Update when no budget is given
+	DEFAULT_UPLOAD_BUDGET = 4 * time.Millisecond
+)
+
+// Loader pairs a Pool of workers with the Queue of the GL thread. Loading functions such as gl.LoadTextureAsync and
+// assimp.LoadModelAsync decode with Go and post the GL calls with Main, the render loop calls Update once per frame.
+type Loader struct {
+	pool  *Pool
+	queue Queue
+}
+
+// NewLoader starts a loader with workers goroutines, one per CPU if workers isn't positive.
+func NewLoader(workers int) *Loader {
+	return &Loader{pool: NewPool(workers)}
+}
+
+// Go runs task on a worker, it must not make GL calls.
+func (l *Loader) Go(task func()) {
+	l.pool.Go(task)
+}
+
+// Main posts task to the GL thread.
+func (l *Loader) Main(task func()) {
+	l.queue.Post(task)
+}
+
+// Update runs the tasks posted to the GL thread for up to budget, DEFAULT_UPLOAD_BUDGET if zero, and returns the
+// number of tasks run. It must be called from the GL thread.
+func (l *Loader) Update(budget time.Duration) int {
+	if budget == 0 {
+		budget = DEFAULT_UPLOAD_BUDGET
+	}
+	return l.queue.Run(0, budget)
+}
+
+// Busy tells whether tasks are still queued or running, on the workers or for the GL thread.
+func (l *Loader) Busy() bool {
+	return !l.pool.Idle() || l.queue.Len() > 0
+}
+
+// Flush runs everything to completion: it waits for the workers and runs the tasks they post until both are idle.
+// It must be called from the GL thread. Loads then finish at the same frame whatever the timing of the workers.
+func (l *Loader) Flush() {
+	for {
+		l.pool.Wait()
+		if l.queue.Run(0, 0) == 0 && l.pool.Idle() {
+			return
+		}
+	}
+}
+
+// Close flushes the loader and stops the workers. Cancel the contexts of the unfinished loads first to skip their
+// work, they still resolve their futures.
+func (l *Loader) Close() {
+	l.Flush()
+	l.pool.Close()
+}
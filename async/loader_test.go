@@ -0,0 +1,104 @@
+package async
+
+import (
+	"context"
+	"testing"
+)
+
+// load mimics the loading functions: a decode step on a worker, then an upload on the GL thread. Both skip their work
+// once ctx is cancelled but still resolve the future.
+func load(ctx context.Context, l *Loader, value int, mainThread *int) *Future[int] {
+	f := NewFuture[int]()
+	f.AddSteps(2)
+	l.Go(func() {
+		if err := ctx.Err(); err != nil {
+			f.Resolve(0, err)
+			return
+		}
+		decoded := value * 2
+		f.Step()
+		l.Main(func() {
+			*mainThread++
+			f.Step()
+			if err := ctx.Err(); err != nil {
+				f.Resolve(0, err)
+				return
+			}
+			f.Resolve(decoded, nil)
+		})
+	})
+	return f
+}
+
+func TestLoaderFlush(t *testing.T) {
+	l := NewLoader(4)
+	defer l.Close()
+	uploads := 0
+	var futures []*Future[int]
+	for i := 0; i < 20; i++ {
+		futures = append(futures, load(context.Background(), l, i, &uploads))
+	}
+	l.Flush()
+	if l.Busy() {
+		t.Errorf("loader is busy after Flush")
+	}
+	if uploads != 20 {
+		t.Errorf("%v uploads ran, want 20", uploads)
+	}
+	for i, f := range futures {
+		if v, err := f.Result(); v != 2*i || err != nil {
+			t.Errorf("future %v = %v, %v, want %v, nil", i, v, err, 2*i)
+		}
+	}
+}
+
+func TestLoaderUpdate(t *testing.T) {
+	// the uploads only run when the GL thread calls Update
+	l := NewLoader(2)
+	defer l.Close()
+	uploads := 0
+	f := load(context.Background(), l, 1, &uploads)
+	l.pool.Wait()
+	if f.Ready() || uploads != 0 {
+		t.Fatalf("upload ran before Update")
+	}
+	if !l.Busy() {
+		t.Errorf("loader with a queued upload isn't busy")
+	}
+	if n := l.Update(0); n != 1 {
+		t.Errorf("Update = %v, want 1", n)
+	}
+	if v, err := f.Result(); v != 2 || err != nil {
+		t.Errorf("Result = %v, %v, want 2, nil", v, err)
+	}
+}
+
+func TestLoaderCancel(t *testing.T) {
+	l := NewLoader(2)
+	uploads := 0
+
+	// cancelled before the worker ran
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	early := load(ctx, l, 1, &uploads)
+
+	// cancelled between the decode and the upload
+	ctx, cancel = context.WithCancel(context.Background())
+	late := load(ctx, l, 2, &uploads)
+	l.pool.Wait()
+	cancel()
+
+	l.Close()
+	for name, f := range map[string]*Future[int]{"early": early, "late": late} {
+		if !f.Ready() {
+			t.Errorf("%v future isn't resolved after Close", name)
+			continue
+		}
+		if v, err := f.Result(); v != 0 || err != context.Canceled {
+			t.Errorf("%v future = %v, %v, want 0, %v", name, v, err, context.Canceled)
+		}
+	}
+	if uploads != 1 {
+		t.Errorf("%v uploads ran, want 1", uploads)
+	}
+}
@@ -0,0 +1,95 @@
+package async
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Pool runs tasks on a fixed number of worker goroutines. Tasks are queued without bound, so a task may submit more
+// tasks without ever blocking.
+type Pool struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	tasks []func()
+	// pending counts the queued and the running tasks
+	pending int
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// NewPool starts workers goroutines, one per CPU if workers isn't positive.
+func NewPool(workers int) *Pool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	p := &Pool{}
+	p.cond = sync.NewCond(&p.mu)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Go queues task to run on a worker.
+func (p *Pool) Go(task func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		panic("async: Go on a closed pool")
+	}
+	p.tasks = append(p.tasks, task)
+	p.pending++
+	p.cond.Broadcast()
+}
+
+// Wait blocks until the queued tasks, and the tasks they queued, have run.
+func (p *Pool) Wait() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.pending > 0 {
+		p.cond.Wait()
+	}
+}
+
+// Idle tells whether no task is queued or running.
+func (p *Pool) Idle() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pending == 0
+}
+
+// Close runs the queued tasks and stops the workers.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		for len(p.tasks) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if len(p.tasks) == 0 {
+			return
+		}
+		task := p.tasks[0]
+		p.tasks[0] = nil
+		p.tasks = p.tasks[1:]
+
+		p.mu.Unlock()
+		task()
+		p.mu.Lock()
+
+		p.pending--
+		if p.pending == 0 {
+			p.cond.Broadcast()
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package async
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPoolOrder(t *testing.T) {
+	// a single worker runs the tasks in the order they were queued
+	p := NewPool(1)
+	var got []int
+	for i := 0; i < 100; i++ {
+		i := i
+		p.Go(func() {
+			got = append(got, i)
+		})
+	}
+	p.Wait()
+	if len(got) != 100 {
+		t.Fatalf("%v tasks ran, want 100", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("task %v ran at position %v", v, i)
+		}
+	}
+	if !p.Idle() {
+		t.Errorf("pool isn't idle after Wait")
+	}
+	p.Close()
+}
+
+func TestPoolNested(t *testing.T) {
+	// Wait also waits for the tasks queued by tasks
+	p := NewPool(4)
+	var n int32
+	var spawn func(depth int)
+	spawn = func(depth int) {
+		atomic.AddInt32(&n, 1)
+		if depth == 0 {
+			return
+		}
+		for i := 0; i < 2; i++ {
+			p.Go(func() {
+				spawn(depth - 1)
+			})
+		}
+	}
+	p.Go(func() {
+		spawn(5)
+	})
+	p.Wait()
+	if n != 63 {
+		t.Errorf("%v tasks ran, want 63", n)
+	}
+	p.Close()
+}
+
+func TestPoolClose(t *testing.T) {
+	// Close runs the queued tasks before the workers stop
+	p := NewPool(2)
+	block := make(chan struct{})
+	var mu sync.Mutex
+	ran := 0
+	for i := 0; i < 10; i++ {
+		p.Go(func() {
+			<-block
+			mu.Lock()
+			ran++
+			mu.Unlock()
+		})
+	}
+	if p.Idle() {
+		t.Errorf("pool is idle with blocked tasks")
+	}
+	close(block)
+	p.Close()
+	if ran != 10 {
+		t.Errorf("%v tasks ran before Close returned, want 10", ran)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Go on a closed pool didn't panic")
+		}
+	}()
+	p.Go(func() {})
+}
@@ -0,0 +1,50 @@
+package async
+
+import (
+	"sync"
+	"time"
+)
+
+// Queue holds tasks posted from any goroutine until the thread owning the GL context runs them. GL calls are only
+// valid on that thread, so workers hand their uploads over through a Queue.
+type Queue struct {
+	mu    sync.Mutex
+	tasks []func()
+}
+
+// Post queues task, it runs during a later call to Run.
+func (q *Queue) Post(task func()) {
+	q.mu.Lock()
+	q.tasks = append(q.tasks, task)
+	q.mu.Unlock()
+}
+
+// Run runs the queued tasks, oldest first, until max tasks have run or budget has elapsed. A zero max or budget
+// doesn't limit, and at least one task runs whatever the budget. Tasks posted meanwhile wait for the next call, so a
+// frame never runs more than what was queued when it started. Run returns the number of tasks run.
+func (q *Queue) Run(max int, budget time.Duration) int {
+	start := time.Now()
+	n := q.Len()
+	if max > 0 && n > max {
+		n = max
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 && budget > 0 && time.Since(start) >= budget {
+			return i
+		}
+		q.mu.Lock()
+		task := q.tasks[0]
+		q.tasks[0] = nil
+		q.tasks = q.tasks[1:]
+		q.mu.Unlock()
+		task()
+	}
+	return n
+}
+
+// Len returns the number of queued tasks.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.tasks)
+}
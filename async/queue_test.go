@@ -0,0 +1,94 @@
+package async
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueueDrain(t *testing.T) {
+	// tasks posted by several goroutines all run on the goroutine calling Run, in order per poster
+	var q Queue
+	var got [4][]int
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		p := p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				i := i
+				q.Post(func() {
+					got[p] = append(got[p], i)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if q.Len() != 200 {
+		t.Fatalf("Len = %v, want 200", q.Len())
+	}
+	if n := q.Run(0, 0); n != 200 {
+		t.Errorf("Run = %v, want 200", n)
+	}
+	for p := range got {
+		for i, v := range got[p] {
+			if v != i {
+				t.Fatalf("task %v of poster %v ran at position %v", v, p, i)
+			}
+		}
+	}
+	if q.Len() != 0 || q.Run(0, 0) != 0 {
+		t.Errorf("queue isn't empty after Run")
+	}
+}
+
+func TestQueueMax(t *testing.T) {
+	var q Queue
+	ran := 0
+	for i := 0; i < 5; i++ {
+		q.Post(func() {
+			ran++
+		})
+	}
+	for _, want := range []int{2, 2, 1, 0} {
+		if n := q.Run(2, 0); n != want {
+			t.Errorf("Run(2, 0) = %v, want %v", n, want)
+		}
+	}
+	if ran != 5 {
+		t.Errorf("%v tasks ran, want 5", ran)
+	}
+}
+
+func TestQueuePostedDuringRun(t *testing.T) {
+	// a task posting another one doesn't make Run loop forever, the new task waits for the next frame
+	var q Queue
+	var post func()
+	post = func() {
+		q.Post(post)
+	}
+	q.Post(post)
+	if n := q.Run(0, 0); n != 1 {
+		t.Errorf("Run = %v, want 1", n)
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len = %v, want 1", q.Len())
+	}
+}
+
+func TestQueueBudget(t *testing.T) {
+	// at least one task runs whatever the budget, then Run stops once it's spent
+	var q Queue
+	for i := 0; i < 3; i++ {
+		q.Post(func() {
+			time.Sleep(2 * time.Millisecond)
+		})
+	}
+	if n := q.Run(0, time.Nanosecond); n != 1 {
+		t.Errorf("Run with a spent budget = %v, want 1", n)
+	}
+	if n := q.Run(0, time.Hour); n != 2 {
+		t.Errorf("Run = %v, want 2", n)
+	}
+}
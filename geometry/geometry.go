@@ -0,0 +1,74 @@
+// Package geometry generates the vertices of primitive shapes, cubes, planes, spheres, cylinders, cones, tori,
+// capsules and screen quads, as gl.Vertex data with normals, tangents and bitangents. Generation makes no GL call,
+// Mesh.Upload creates the gl.Mesh.
+//
+// The triangles are counter-clockwise seen from outside, the texture coordinates start at the bottom left like the
+// images loaded with stbi.SetFlipVerticallyOnLoad(true), the tangents follow u and the bitangents v.
+package geometry
+
+import (
+	"learn_opengl/gl"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Mesh is generated indexed geometry.
+type Mesh struct {
+	Vertices []gl.Vertex
+	Indices  []uint32
+}
+
+// Upload creates a gl.Mesh with material from the mesh.
+func (m Mesh) Upload(material gl.Material) gl.Mesh {
+	return gl.NewMeshWithMaterial(m.Vertices, m.Indices, material)
+}
+
+// Append adds the vertices and triangles of other to the mesh.
+func (m *Mesh) Append(other Mesh) {
+	base := uint32(len(m.Vertices))
+	m.Vertices = append(m.Vertices, other.Vertices...)
+	for _, index := range other.Indices {
+		m.Indices = append(m.Indices, base+index)
+	}
+}
+
+// vertex returns a vertex without bones.
+func vertex(position, normal mgl32.Vec3, texCoords mgl32.Vec2, tangent, bitangent mgl32.Vec3) gl.Vertex {
+	v := gl.Vertex{Position: position, Normal: normal, TexCoords: texCoords, Tangent: tangent, Bitangent: bitangent}
+	for i := 0; i < gl.MAX_BONE_INFLUENCE; i++ {
+		v.BoneIds[i] = -1
+	}
+	return v
+}
+
+// grid generates a surface of (columns+1)*(rows+1) vertices, vertexAt returns the vertex of column i and row j.
+// The triangles are counter-clockwise around the normal when the columns run along the tangent and the rows along
+// the bitangent. Triangles collapsed to a line or a point, e.g. at the poles of a sphere, are left out.
+func grid(columns, rows int, vertexAt func(i, j int) gl.Vertex) Mesh {
+	var m Mesh
+	for j := 0; j <= rows; j++ {
+		for i := 0; i <= columns; i++ {
+			m.Vertices = append(m.Vertices, vertexAt(i, j))
+		}
+	}
+	index := func(i, j int) uint32 {
+		return uint32(j*(columns+1) + i)
+	}
+	for j := 0; j < rows; j++ {
+		for i := 0; i < columns; i++ {
+			a, b, c, d := index(i, j), index(i+1, j), index(i+1, j+1), index(i, j+1)
+			m.triangle(a, b, c)
+			m.triangle(a, c, d)
+		}
+	}
+	return m
+}
+
+// triangle adds the triangle a, b, c unless two of its corners share a position.
+func (m *Mesh) triangle(a, b, c uint32) {
+	pa, pb, pc := m.Vertices[a].Position, m.Vertices[b].Position, m.Vertices[c].Position
+	if pa == pb || pb == pc || pc == pa {
+		return
+	}
+	m.Indices = append(m.Indices, a, b, c)
+}
@@ -0,0 +1,153 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Icosphere returns a sphere of radius centered on the origin made by splitting every triangle of an icosahedron
+// in four subdivisions times, its triangles are evenly sized unlike those of UVSphere. The texture is mapped like
+// UVSphere, the vertices on the seam are duplicated.
+func Icosphere(radius float32, subdivisions int) Mesh {
+	positions, triangles := icosahedron()
+	for i := 0; i < subdivisions; i++ {
+		positions, triangles = subdivide(positions, triangles)
+	}
+
+	var m Mesh
+	// the vertices by position and u, the seam and the poles need copies with other u
+	type key struct {
+		position int
+		u        float32
+	}
+	vertices := make(map[key]uint32)
+	for _, triangle := range triangles {
+		// a triangle crossing the seam gets u beyond 1 on its side starting over at 0, the poles don't count
+		var u [3]float32
+		lowest, highest := float32(1), float32(0)
+		for c, p := range triangle {
+			u[c] = longitude(positions[p]) / (2 * math.Pi)
+			if !pole(positions[p]) {
+				lowest, highest = minFloat(lowest, u[c]), maxFloat(highest, u[c])
+			}
+		}
+		if highest-lowest > 0.5 {
+			for c := range u {
+				if u[c] < 0.5 {
+					u[c]++
+				}
+			}
+		}
+		// a pole takes the u of the middle of the opposite edge
+		for c, p := range triangle {
+			if pole(positions[p]) {
+				u[c] = (u[(c+1)%3] + u[(c+2)%3]) / 2
+			}
+		}
+		var corners [3]uint32
+		for c, p := range triangle {
+			k := key{p, u[c]}
+			index, ok := vertices[k]
+			if !ok {
+				normal := positions[p]
+				_, tangent := spherical(2*math.Pi*u[c], 0)
+				v := float32(math.Acos(float64(-normal[1]))) / math.Pi
+				m.Vertices = append(m.Vertices, vertex(normal.Mul(radius), normal, mgl32.Vec2{u[c], v}, tangent, normal.Cross(tangent)))
+				index = uint32(len(m.Vertices) - 1)
+				vertices[k] = index
+			}
+			corners[c] = index
+		}
+		m.Indices = append(m.Indices, corners[0], corners[1], corners[2])
+	}
+	return m
+}
+
+// icosahedron returns the unit vectors of the vertices of an icosahedron with two of them at the poles, and its
+// counter-clockwise triangles.
+func icosahedron() ([]mgl32.Vec3, [][3]int) {
+	positions := []mgl32.Vec3{{0, -1, 0}}
+	// two rings of five vertices at the latitudes where the edges are equal
+	latitude := float32(math.Atan(0.5))
+	for i := 0; i < 10; i++ {
+		ring := -latitude
+		if i >= 5 {
+			ring = latitude
+		}
+		angle := float32(i) * 2 * math.Pi / 5
+		if i >= 5 {
+			angle += math.Pi / 5
+		}
+		positions = append(positions, mgl32.Vec3{cos(ring) * sin(angle), sin(ring), cos(ring) * cos(angle)})
+	}
+	positions = append(positions, mgl32.Vec3{0, 1, 0})
+
+	var triangles [][3]int
+	for i := 0; i < 5; i++ {
+		lower, nextLower := 1+i, 1+(i+1)%5
+		upper, nextUpper := 6+i, 6+(i+1)%5
+		triangles = append(triangles,
+			[3]int{0, nextLower, lower},
+			[3]int{lower, nextLower, upper},
+			[3]int{upper, nextLower, nextUpper},
+			[3]int{upper, nextUpper, 11},
+		)
+	}
+	return positions, triangles
+}
+
+// subdivide splits every triangle in four at the middle of its edges, pushed onto the unit sphere.
+func subdivide(positions []mgl32.Vec3, triangles [][3]int) ([]mgl32.Vec3, [][3]int) {
+	middles := make(map[[2]int]int)
+	middle := func(a, b int) int {
+		if a > b {
+			a, b = b, a
+		}
+		index, ok := middles[[2]int{a, b}]
+		if !ok {
+			positions = append(positions, positions[a].Add(positions[b]).Normalize())
+			index = len(positions) - 1
+			middles[[2]int{a, b}] = index
+		}
+		return index
+	}
+	split := make([][3]int, 0, 4*len(triangles))
+	for _, t := range triangles {
+		ab, bc, ca := middle(t[0], t[1]), middle(t[1], t[2]), middle(t[2], t[0])
+		split = append(split,
+			[3]int{t[0], ab, ca},
+			[3]int{ab, t[1], bc},
+			[3]int{ca, bc, t[2]},
+			[3]int{ab, bc, ca},
+		)
+	}
+	return positions, split
+}
+
+// longitude returns the angle of p around the y axis in [0, 2π), 0 towards +z and increasing towards +x.
+func longitude(p mgl32.Vec3) float32 {
+	a := float32(math.Atan2(float64(p[0]), float64(p[2])))
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	return a
+}
+
+func pole(p mgl32.Vec3) bool {
+	return p[0] == 0 && p[2] == 0
+}
+
+func minFloat(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
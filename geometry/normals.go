@@ -0,0 +1,115 @@
+package geometry
+
+import (
+	"learn_opengl/gl"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// GenerateNormals replaces the normals of the triangles of an indexed mesh. The faces sharing a position are smoothed
+// together when their normals are at most smoothingAngle radians apart, each weighted by its angle at the position;
+// sharper edges stay hard. Vertices whose faces end up with different normals are split, so the function returns
+// new vertices and indices. A smoothingAngle of 0 gives flat normals, π smooths everything.
+func GenerateNormals(vertices []gl.Vertex, indices []uint32, smoothingAngle float32) ([]gl.Vertex, []uint32) {
+	triangles := len(indices) / 3
+	faceNormals := make([]mgl32.Vec3, triangles)
+	// the triangles around every position, vertices with different indices may share a position
+	around := make(map[mgl32.Vec3][]int)
+	for t := 0; t < triangles; t++ {
+		a, b, c := vertices[indices[3*t]].Position, vertices[indices[3*t+1]].Position, vertices[indices[3*t+2]].Position
+		faceNormals[t] = normalize(b.Sub(a).Cross(c.Sub(a)))
+		for _, p := range [3]mgl32.Vec3{a, b, c} {
+			if list := around[p]; len(list) == 0 || list[len(list)-1] != t {
+				around[p] = append(list, t)
+			}
+		}
+	}
+	// slightly below the cosine so that coplanar faces merge despite rounding
+	threshold := float32(math.Cos(float64(smoothingAngle))) - 1e-5
+
+	var out mesher
+	out.init(vertices, len(indices))
+	for t := 0; t < triangles; t++ {
+		for c := 0; c < 3; c++ {
+			index := indices[3*t+c]
+			position := vertices[index].Position
+			var normal mgl32.Vec3
+			for _, other := range around[position] {
+				if faceNormals[other].Dot(faceNormals[t]) >= threshold {
+					normal = normal.Add(faceNormals[other].Mul(cornerAngle(vertices, indices, other, position)))
+				}
+			}
+			v := vertices[index]
+			v.Normal = normalize(normal)
+			if v.Normal == (mgl32.Vec3{}) {
+				v.Normal = faceNormals[t]
+			}
+			out.add(index, v)
+		}
+	}
+	return out.vertices, out.indices
+}
+
+// FlatNormals gives every triangle the normal of its plane, splitting the vertices shared by triangles that aren't
+// coplanar.
+func FlatNormals(vertices []gl.Vertex, indices []uint32) ([]gl.Vertex, []uint32) {
+	return GenerateNormals(vertices, indices, 0)
+}
+
+// cornerAngle returns the angle of triangle t at the corner at position.
+func cornerAngle(vertices []gl.Vertex, indices []uint32, t int, position mgl32.Vec3) float32 {
+	for c := 0; c < 3; c++ {
+		if vertices[indices[3*t+c]].Position == position {
+			a := vertices[indices[3*t+(c+1)%3]].Position.Sub(position)
+			b := vertices[indices[3*t+(c+2)%3]].Position.Sub(position)
+			return angleBetween(a, b)
+		}
+	}
+	return 0
+}
+
+// angleBetween returns the angle between a and b, 0 if one of them is zero.
+func angleBetween(a, b mgl32.Vec3) float32 {
+	la, lb := a.Len(), b.Len()
+	if la == 0 || lb == 0 {
+		return 0
+	}
+	return float32(math.Acos(float64(mgl32.Clamp(a.Dot(b)/(la*lb), -1, 1))))
+}
+
+// normalize returns v of unit length, zero if v is too short to have a direction.
+func normalize(v mgl32.Vec3) mgl32.Vec3 {
+	if l := v.Len(); l > 1e-12 {
+		return v.Mul(1 / l)
+	}
+	return mgl32.Vec3{}
+}
+
+// mesher rebuilds indexed vertices, the corners of an input vertex that end up identical share an output vertex.
+type mesher struct {
+	vertices []gl.Vertex
+	indices  []uint32
+	// copies holds the output vertices made from every input vertex
+	copies map[uint32][]uint32
+}
+
+func (m *mesher) init(vertices []gl.Vertex, corners int) {
+	m.vertices = make([]gl.Vertex, 0, len(vertices))
+	m.indices = make([]uint32, 0, corners)
+	m.copies = make(map[uint32][]uint32)
+}
+
+// add adds a corner made from input vertex index, which became v.
+func (m *mesher) add(index uint32, v gl.Vertex) {
+	for _, copy := range m.copies[index] {
+		if m.vertices[copy] == v {
+			m.indices = append(m.indices, copy)
+			return
+		}
+	}
+	m.vertices = append(m.vertices, v)
+	copy := uint32(len(m.vertices) - 1)
+	m.copies[index] = append(m.copies[index], copy)
+	m.indices = append(m.indices, copy)
+}
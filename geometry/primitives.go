@@ -0,0 +1,186 @@
+package geometry
+
+import (
+	"learn_opengl/gl"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Plane returns a plane of width along x and depth along z centered on the origin, facing +y, split into
+// subdivisions quads along each side. The texture repeats tiling times along u and v.
+func Plane(width, depth float32, subdivisions int, tiling mgl32.Vec2) Mesh {
+	subdivisions = atLeast(subdivisions, 1)
+	return grid(subdivisions, subdivisions, func(i, j int) gl.Vertex {
+		s, t := float32(i)/float32(subdivisions), float32(j)/float32(subdivisions)
+		position := mgl32.Vec3{(s - 0.5) * width, 0, (0.5 - t) * depth}
+		return vertex(position, mgl32.Vec3{0, 1, 0}, mgl32.Vec2{s * tiling[0], t * tiling[1]}, mgl32.Vec3{1, 0, 0}, mgl32.Vec3{0, 0, -1})
+	})
+}
+
+// Cube returns a cube of side size centered on the origin, every face split into subdivisions quads along each
+// side and mapped to the whole texture.
+func Cube(size float32, subdivisions int) Mesh {
+	subdivisions = atLeast(subdivisions, 1)
+	// normal and tangent of every face, the bitangent is their cross product
+	faces := [6][2]mgl32.Vec3{
+		{{1, 0, 0}, {0, 0, -1}},
+		{{-1, 0, 0}, {0, 0, 1}},
+		{{0, 1, 0}, {1, 0, 0}},
+		{{0, -1, 0}, {1, 0, 0}},
+		{{0, 0, 1}, {1, 0, 0}},
+		{{0, 0, -1}, {-1, 0, 0}},
+	}
+	var m Mesh
+	for _, face := range faces {
+		normal, tangent := face[0], face[1]
+		bitangent := normal.Cross(tangent)
+		center := normal.Mul(size / 2)
+		m.Append(grid(subdivisions, subdivisions, func(i, j int) gl.Vertex {
+			s, t := float32(i)/float32(subdivisions), float32(j)/float32(subdivisions)
+			position := center.Add(tangent.Mul((s - 0.5) * size)).Add(bitangent.Mul((t - 0.5) * size))
+			return vertex(position, normal, mgl32.Vec2{s, t}, tangent, bitangent)
+		}))
+	}
+	return m
+}
+
+// ScreenQuad returns the quad covering the screen in normalized device coordinates, from (-1, -1) to (1, 1) at
+// z = 0, with texture coordinates from (0, 0) to (1, 1).
+func ScreenQuad() Mesh {
+	return grid(1, 1, func(i, j int) gl.Vertex {
+		s, t := float32(i), float32(j)
+		return vertex(mgl32.Vec3{2*s - 1, 2*t - 1, 0}, mgl32.Vec3{0, 0, 1}, mgl32.Vec2{s, t}, mgl32.Vec3{1, 0, 0}, mgl32.Vec3{0, 1, 0})
+	})
+}
+
+// UVSphere returns a sphere of radius centered on the origin made of segments around the y axis and rings from
+// the south pole to the north pole. The texture wraps around once, u following the longitude and v the latitude.
+func UVSphere(radius float32, segments, rings int) Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 2)
+	return grid(segments, rings, func(i, j int) gl.Vertex {
+		s, t := float32(i)/float32(segments), float32(j)/float32(rings)
+		normal, tangent := spherical(2*math.Pi*s, math.Pi*t)
+		return vertex(normal.Mul(radius), normal, mgl32.Vec2{s, t}, tangent, normal.Cross(tangent))
+	})
+}
+
+// Cylinder returns a cylinder of radius around the y axis, from -height/2 to height/2, made of segments around the
+// axis and rings along it, closed by caps.
+func Cylinder(radius, height float32, segments, rings int) Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 1)
+	m := grid(segments, rings, func(i, j int) gl.Vertex {
+		s, t := float32(i)/float32(segments), float32(j)/float32(rings)
+		normal, tangent := spherical(2*math.Pi*s, math.Pi/2)
+		position := normal.Mul(radius).Add(mgl32.Vec3{0, (t - 0.5) * height, 0})
+		return vertex(position, normal, mgl32.Vec2{s, t}, tangent, mgl32.Vec3{0, 1, 0})
+	})
+	m.Append(disk(radius, height/2, segments, true))
+	m.Append(disk(radius, -height/2, segments, false))
+	return m
+}
+
+// Cone returns a cone of radius around the y axis with its base at -height/2 and its apex at height/2, made of
+// segments around the axis and rings along it, closed by a cap.
+func Cone(radius, height float32, segments, rings int) Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 1)
+	// the normals lean up by the angle of the slope
+	slope := float32(math.Atan2(float64(radius), float64(height)))
+	m := grid(segments, rings, func(i, j int) gl.Vertex {
+		s, t := float32(i)/float32(segments), float32(j)/float32(rings)
+		out, tangent := spherical(2*math.Pi*s, math.Pi/2)
+		position := out.Mul((1 - t) * radius).Add(mgl32.Vec3{0, (t - 0.5) * height, 0})
+		normal := out.Mul(cos(slope)).Add(mgl32.Vec3{0, sin(slope), 0})
+		return vertex(position, normal, mgl32.Vec2{s, t}, tangent, normal.Cross(tangent))
+	})
+	m.Append(disk(radius, -height/2, segments, false))
+	return m
+}
+
+// Torus returns a torus around the y axis, the center of its tube runs at majorRadius from the origin and the tube
+// has minorRadius. It is made of segments around the y axis and sides around the tube.
+func Torus(majorRadius, minorRadius float32, segments, sides int) Mesh {
+	segments, sides = atLeast(segments, 3), atLeast(sides, 3)
+	return grid(segments, sides, func(i, j int) gl.Vertex {
+		s, t := float32(i)/float32(segments), float32(j)/float32(sides)
+		out, tangent := spherical(2*math.Pi*s, math.Pi/2)
+		// the tube starts on the outside, then goes up
+		angle := 2 * math.Pi * t
+		normal := out.Mul(cos(angle)).Add(mgl32.Vec3{0, sin(angle), 0})
+		position := out.Mul(majorRadius).Add(normal.Mul(minorRadius))
+		return vertex(position, normal, mgl32.Vec2{s, t}, tangent, normal.Cross(tangent))
+	})
+}
+
+// Capsule returns a cylinder of radius around the y axis from -height/2 to height/2 closed by hemispheres, made of
+// segments around the axis and rings along each hemisphere. v follows the length of the profile.
+func Capsule(radius, height float32, segments, rings int) Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 1)
+	// the rows run over the bottom hemisphere then the top one, the equator appears twice and the quads between
+	// the two copies make the cylinder
+	quarter := float32(math.Pi) / 2 * radius
+	length := 2*quarter + height
+	return grid(segments, 2*rings+1, func(i, j int) gl.Vertex {
+		s := float32(i) / float32(segments)
+		var latitude, offset, v float32
+		if j <= rings {
+			latitude = float32(j) / float32(rings) * math.Pi / 2
+			offset = -height / 2
+			v = latitude * radius / length
+		} else {
+			latitude = math.Pi/2 + float32(j-rings-1)/float32(rings)*math.Pi/2
+			offset = height / 2
+			v = (latitude*radius + height) / length
+		}
+		normal, tangent := spherical(2*math.Pi*s, latitude)
+		position := normal.Mul(radius).Add(mgl32.Vec3{0, offset, 0})
+		return vertex(position, normal, mgl32.Vec2{s, v}, tangent, normal.Cross(tangent))
+	})
+}
+
+// disk returns a disk of radius at y facing up or down, with planar texture coordinates.
+func disk(radius, y float32, segments int, up bool) Mesh {
+	normal, bitangent := mgl32.Vec3{0, -1, 0}, mgl32.Vec3{0, 0, 1}
+	if up {
+		normal, bitangent = mgl32.Vec3{0, 1, 0}, mgl32.Vec3{0, 0, -1}
+	}
+	// the rows go from the rim to the center facing up and the other way facing down, for the winding
+	return grid(segments, 1, func(i, j int) gl.Vertex {
+		out, _ := spherical(2*math.Pi*float32(i)/float32(segments), math.Pi/2)
+		if up == (j == 1) {
+			out = mgl32.Vec3{}
+		}
+		position := out.Mul(radius)
+		texCoords := mgl32.Vec2{0.5 + position.Dot(mgl32.Vec3{1, 0, 0})/(2*radius), 0.5 + position.Dot(bitangent)/(2*radius)}
+		position[1] = y
+		return vertex(position, normal, texCoords, mgl32.Vec3{1, 0, 0}, bitangent)
+	})
+}
+
+// spherical returns the direction at longitude around the y axis, 0 towards +z, and latitude from the south pole,
+// and the direction of increasing longitude.
+func spherical(longitude, latitude float32) (direction, tangent mgl32.Vec3) {
+	r := sin(latitude)
+	if r < 1e-6 {
+		// exact poles, the triangles around them collapse
+		r = 0
+	}
+	direction = mgl32.Vec3{r * sin(longitude), -cos(latitude), r * cos(longitude)}
+	tangent = mgl32.Vec3{cos(longitude), 0, -sin(longitude)}
+	return
+}
+
+func sin(a float32) float32 {
+	return float32(math.Sin(float64(a)))
+}
+
+func cos(a float32) float32 {
+	return float32(math.Cos(float64(a)))
+}
+
+func atLeast(n, min int) int {
+	if n < min {
+		return min
+	}
+	return n
+}
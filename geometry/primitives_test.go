@@ -0,0 +1,153 @@
+package geometry
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var primitives = []struct {
+	name string
+	mesh Mesh
+	// convex meshes enclose the origin
+	convex bool
+}{
+	{"plane", Plane(2, 3, 4, mgl32.Vec2{2, 3}), false},
+	{"cube", Cube(2, 3), true},
+	{"screen quad", ScreenQuad(), false},
+	{"uv sphere", UVSphere(1.5, 16, 8), true},
+	{"icosphere", Icosphere(1.5, 2), true},
+	{"cylinder", Cylinder(1, 2, 12, 3), true},
+	{"cone", Cone(1, 2, 12, 3), true},
+	{"torus", Torus(2, 0.5, 16, 8), false},
+	{"capsule", Capsule(0.5, 1, 12, 4), true},
+}
+
+func near(a, b mgl32.Vec3) bool {
+	return a.Sub(b).Len() < 1e-4
+}
+
+func TestPrimitiveWinding(t *testing.T) {
+	for _, p := range primitives {
+		m := p.mesh
+		if len(m.Indices) == 0 || len(m.Indices)%3 != 0 {
+			t.Errorf("%v: %v indices", p.name, len(m.Indices))
+			continue
+		}
+		for i := 0; i < len(m.Indices); i += 3 {
+			a, b, c := m.Vertices[m.Indices[i]], m.Vertices[m.Indices[i+1]], m.Vertices[m.Indices[i+2]]
+			face := b.Position.Sub(a.Position).Cross(c.Position.Sub(a.Position))
+			if face.Len() == 0 {
+				t.Errorf("%v: triangle %v is degenerate", p.name, i/3)
+				continue
+			}
+			// counter-clockwise seen from the side the normals point to
+			for _, v := range [3]mgl32.Vec3{a.Normal, b.Normal, c.Normal} {
+				if face.Dot(v) <= 0 {
+					t.Errorf("%v: triangle %v faces %v, its vertices %v", p.name, i/3, face, v)
+					break
+				}
+			}
+			// and from outside
+			if p.convex && face.Dot(a.Position) <= 0 {
+				t.Errorf("%v: triangle %v faces %v, inwards", p.name, i/3, face)
+			}
+		}
+	}
+}
+
+func TestPrimitiveTangentSpace(t *testing.T) {
+	for _, p := range primitives {
+		m := p.mesh
+		for i, v := range m.Vertices {
+			if l := v.Normal.Len(); l < 0.9999 || l > 1.0001 {
+				t.Errorf("%v: vertex %v has normal %v", p.name, i, v.Normal)
+			}
+			if l := v.Tangent.Len(); l < 0.9999 || l > 1.0001 {
+				t.Errorf("%v: vertex %v has tangent %v", p.name, i, v.Tangent)
+			}
+			if d := v.Tangent.Dot(v.Normal); d > 1e-4 || d < -1e-4 {
+				t.Errorf("%v: vertex %v has a tangent %v not orthogonal to its normal %v", p.name, i, v.Tangent, v.Normal)
+			}
+			// right-handed
+			if !near(v.Bitangent, v.Normal.Cross(v.Tangent)) {
+				t.Errorf("%v: vertex %v has bitangent %v, want %v", p.name, i, v.Bitangent, v.Normal.Cross(v.Tangent))
+			}
+			if v.BoneIds != [4]int32{-1, -1, -1, -1} {
+				t.Errorf("%v: vertex %v has bones %v", p.name, i, v.BoneIds)
+			}
+		}
+		// the tangents follow u and the bitangents v
+		for i := 0; i < len(m.Indices); i += 3 {
+			tangent, bitangent, ok := uvDirections(m, i)
+			if !ok {
+				continue
+			}
+			for c := 0; c < 3; c++ {
+				v := m.Vertices[m.Indices[i+c]]
+				if v.Tangent.Dot(tangent) <= 0 || v.Bitangent.Dot(bitangent) <= 0 {
+					t.Errorf("%v: triangle %v has u along %v and v along %v, corner %v has tangent %v and bitangent %v",
+						p.name, i/3, tangent, bitangent, c, v.Tangent, v.Bitangent)
+					break
+				}
+			}
+		}
+	}
+}
+
+// uvDirections returns the directions of increasing u and v on the triangle starting at index i, ok is false if
+// its texture coordinates are on a line.
+func uvDirections(m Mesh, i int) (tangent, bitangent mgl32.Vec3, ok bool) {
+	v0, v1, v2 := m.Vertices[m.Indices[i]], m.Vertices[m.Indices[i+1]], m.Vertices[m.Indices[i+2]]
+	e1, e2 := v1.Position.Sub(v0.Position), v2.Position.Sub(v0.Position)
+	d1, d2 := v1.TexCoords.Sub(v0.TexCoords), v2.TexCoords.Sub(v0.TexCoords)
+	det := d1.X()*d2.Y() - d2.X()*d1.Y()
+	if det == 0 {
+		return mgl32.Vec3{}, mgl32.Vec3{}, false
+	}
+	tangent = e1.Mul(d2.Y()).Sub(e2.Mul(d1.Y())).Mul(1 / det)
+	bitangent = e2.Mul(d1.X()).Sub(e1.Mul(d2.X())).Mul(1 / det)
+	return tangent, bitangent, true
+}
+
+func TestPrimitiveShapes(t *testing.T) {
+	for _, p := range primitives[3:5] {
+		// the sphere normals point from the center
+		for _, v := range p.mesh.Vertices {
+			if !near(v.Position, v.Normal.Mul(1.5)) {
+				t.Errorf("%v: vertex at %v has normal %v", p.name, v.Position, v.Normal)
+			}
+		}
+	}
+	for _, v := range Cube(2, 1).Vertices {
+		if !near(v.Position.Mul(1), mgl32.Vec3{clamp1(v.Position[0]), clamp1(v.Position[1]), clamp1(v.Position[2])}) {
+			t.Errorf("cube vertex at %v", v.Position)
+		}
+		if v.Position.Dot(v.Normal) != 1 {
+			t.Errorf("cube vertex at %v has normal %v", v.Position, v.Normal)
+		}
+	}
+	plane := Plane(2, 4, 1, mgl32.Vec2{3, 2})
+	if b := plane.Vertices[len(plane.Vertices)-1]; b.Position != (mgl32.Vec3{1, 0, -2}) || b.TexCoords != (mgl32.Vec2{3, 2}) {
+		t.Errorf("plane corner at %v, %v", b.Position, b.TexCoords)
+	}
+	// the torus tube is at minorRadius from its center line
+	for _, v := range Torus(2, 0.5, 8, 6).Vertices {
+		center := mgl32.Vec3{v.Position[0], 0, v.Position[2]}.Normalize().Mul(2)
+		if !near(v.Position.Sub(center), v.Normal.Mul(0.5)) {
+			t.Errorf("torus vertex at %v has normal %v", v.Position, v.Normal)
+		}
+	}
+
+	var m Mesh
+	m.Append(ScreenQuad())
+	m.Append(ScreenQuad())
+	if len(m.Vertices) != 8 || len(m.Indices) != 12 || m.Indices[6] != m.Indices[0]+4 {
+		t.Errorf("appended %v vertices, indices %v", len(m.Vertices), m.Indices)
+	}
+}
+
+// clamp1 returns the coordinate of a point of the surface of the cube of side 2 centered on the origin.
+func clamp1(x float32) float32 {
+	return mgl32.Clamp(x, -1, 1)
+}
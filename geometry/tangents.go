@@ -0,0 +1,116 @@
+package geometry
+
+import (
+	"learn_opengl/gl"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// GenerateTangents computes the tangents and bitangents of the triangles of an indexed mesh from their texture
+// coordinates and the vertex normals, following the conventions of MikkTSpace, the tangent space of most baking
+// tools and of glTF: the tangent of every triangle is made orthogonal to the normal of each corner and averaged
+// over the triangles sharing the vertex, weighted by their angle, and the bitangent is the cross product of the
+// normal and the tangent, negated where the texture is mirrored.
+//
+// A vertex shared by mirrored and unmirrored triangles, on the seam of a mirrored texture, is split in two, so the
+// function returns new vertices and indices. Triangles without texture area, whose texture coordinates are on a
+// line, take the tangent of their vertices from the other triangles, or any direction orthogonal to the normal.
+func GenerateTangents(vertices []gl.Vertex, indices []uint32) ([]gl.Vertex, []uint32) {
+	// the tangent sums of every vertex, by handedness
+	type key struct {
+		index  uint32
+		mirror bool
+	}
+	sums := make(map[key]mgl32.Vec3)
+	triangles := len(indices) / 3
+	mirrored := make([]bool, triangles)
+	degenerate := make([]bool, triangles)
+	for t := 0; t < triangles; t++ {
+		v0, v1, v2 := &vertices[indices[3*t]], &vertices[indices[3*t+1]], &vertices[indices[3*t+2]]
+		edge1 := v1.Position.Sub(v0.Position)
+		edge2 := v2.Position.Sub(v0.Position)
+		deltaUV1 := v1.TexCoords.Sub(v0.TexCoords)
+		deltaUV2 := v2.TexCoords.Sub(v0.TexCoords)
+		det := deltaUV1.X()*deltaUV2.Y() - deltaUV2.X()*deltaUV1.Y()
+		tangent := edge1.Mul(deltaUV2.Y()).Sub(edge2.Mul(deltaUV1.Y()))
+		if det == 0 || tangent.Len() == 0 {
+			degenerate[t] = true
+			continue
+		}
+		// the texture is mirrored when its area turns clockwise, the direction of the tangent follows the sign
+		mirrored[t] = det < 0
+		tangent = tangent.Mul(1 / det)
+		for c := 0; c < 3; c++ {
+			index := indices[3*t+c]
+			// normalized so that the triangles weigh by their angle only, not by their texture density
+			projected := normalize(orthogonal(tangent, vertexNormal(vertices, indices, t, c)))
+			k := key{index, mirrored[t]}
+			sums[k] = sums[k].Add(projected.Mul(cornerAngle(vertices, indices, t, vertices[index].Position)))
+		}
+	}
+
+	var out mesher
+	out.init(vertices, len(indices))
+	for t := 0; t < triangles; t++ {
+		for c := 0; c < 3; c++ {
+			index := indices[3*t+c]
+			n := vertexNormal(vertices, indices, t, c)
+			k := key{index, mirrored[t]}
+			sum, ok := sums[k]
+			if degenerate[t] {
+				// borrow the tangent of the vertex, unmirrored first
+				if sum, ok = sums[key{index, false}]; !ok {
+					sum, ok = sums[key{index, true}]
+					k.mirror = ok
+				}
+			}
+			tangent := normalize(orthogonal(sum, n))
+			if !ok || tangent == (mgl32.Vec3{}) {
+				tangent = perpendicular(n)
+			}
+			bitangent := n.Cross(tangent)
+			if k.mirror {
+				bitangent = bitangent.Mul(-1)
+			}
+			v := vertices[index]
+			v.Tangent, v.Bitangent = tangent, bitangent
+			out.add(index, v)
+		}
+	}
+	return out.vertices, out.indices
+}
+
+// vertexNormal returns the unit normal of corner c of triangle t, the normal of the triangle if the vertex has none.
+func vertexNormal(vertices []gl.Vertex, indices []uint32, t, c int) mgl32.Vec3 {
+	if n := normalize(vertices[indices[3*t+c]].Normal); n != (mgl32.Vec3{}) {
+		return n
+	}
+	a, b, d := vertices[indices[3*t]].Position, vertices[indices[3*t+1]].Position, vertices[indices[3*t+2]].Position
+	if n := normalize(b.Sub(a).Cross(d.Sub(a))); n != (mgl32.Vec3{}) {
+		return n
+	}
+	return mgl32.Vec3{0, 0, 1}
+}
+
+// orthogonal removes the part of v along the unit vector n.
+func orthogonal(v, n mgl32.Vec3) mgl32.Vec3 {
+	return v.Sub(n.Mul(n.Dot(v)))
+}
+
+// perpendicular returns a unit vector orthogonal to the unit vector n, from the axis furthest from it.
+func perpendicular(n mgl32.Vec3) mgl32.Vec3 {
+	axis := mgl32.Vec3{1, 0, 0}
+	if abs(n[1]) < abs(n[0]) && abs(n[1]) <= abs(n[2]) {
+		axis = mgl32.Vec3{0, 1, 0}
+	} else if abs(n[2]) < abs(n[0]) {
+		axis = mgl32.Vec3{0, 0, 1}
+	}
+	return normalize(orthogonal(axis, n))
+}
+
+func abs(a float32) float32 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,101 @@
+package geometry
+
+import (
+	"learn_opengl/gl"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// flat returns vertices facing +z at positions (x, y, 0) with the texture coordinates uvs.
+func flat(positions []mgl32.Vec2, uvs []mgl32.Vec2) []gl.Vertex {
+	vertices := make([]gl.Vertex, len(positions))
+	for i, p := range positions {
+		vertices[i] = gl.Vertex{Position: p.Vec3(0), Normal: mgl32.Vec3{0, 0, 1}, TexCoords: uvs[i]}
+	}
+	return vertices
+}
+
+func checkOrthonormal(t *testing.T, vertices []gl.Vertex) {
+	t.Helper()
+	for i, v := range vertices {
+		if l := v.Tangent.Len(); l < 0.9999 || l > 1.0001 {
+			t.Errorf("vertex %v has tangent %v", i, v.Tangent)
+		}
+		if d := v.Tangent.Dot(v.Normal); d > 1e-5 || d < -1e-5 {
+			t.Errorf("vertex %v has tangent %v along its normal %v", i, v.Tangent, v.Normal)
+		}
+		if b := v.Normal.Cross(v.Tangent); !near(v.Bitangent, b) && !near(v.Bitangent, b.Mul(-1)) {
+			t.Errorf("vertex %v has bitangent %v, want ±%v", i, v.Bitangent, b)
+		}
+	}
+}
+
+func TestTangentsWeighByAngle(t *testing.T) {
+	// two right angles at the origin, the texture of the second is a hundred times denser along its tangent +y
+	vertices := flat(
+		[]mgl32.Vec2{{0, 0}, {1, 0}, {0, 1}, {-1, 0}, {0, -1}},
+		[]mgl32.Vec2{{0, 0}, {1, 0}, {0, 1}, {0, 1}, {-100, 0}})
+	indices := []uint32{0, 1, 2, 0, 3, 4}
+	vertices, indices = GenerateTangents(vertices, indices)
+	checkOrthonormal(t, vertices)
+	if len(vertices) != 5 {
+		t.Fatalf("%v vertices, want 5", len(vertices))
+	}
+	want := mgl32.Vec3{1, 1, 0}.Normalize()
+	if v := vertices[indices[0]]; !near(v.Tangent, want) || !near(v.Bitangent, mgl32.Vec3{-1, 1, 0}.Normalize()) {
+		t.Errorf("shared vertex has tangent %v and bitangent %v, want %v", v.Tangent, v.Bitangent, want)
+	}
+}
+
+func TestTangentsDegenerateUVs(t *testing.T) {
+	// a quad whose second triangle has all its texture coordinates equal, then a triangle without any
+	vertices := flat(
+		[]mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {2, 0}, {3, 0}, {2, 1}},
+		[]mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {1, 1}, {0.5, 0.5}, {0.5, 0.5}, {0.5, 0.5}})
+	indices := []uint32{0, 1, 2, 2, 3, 0, 4, 5, 6}
+	out, outIndices := GenerateTangents(vertices, indices)
+	checkOrthonormal(t, out)
+	if len(outIndices) != len(indices) {
+		t.Fatalf("%v indices, want %v", len(outIndices), len(indices))
+	}
+	for c := 0; c < 6; c++ {
+		if v := out[outIndices[c]]; !near(v.Tangent, mgl32.Vec3{1, 0, 0}) || !near(v.Bitangent, mgl32.Vec3{0, 1, 0}) {
+			t.Errorf("corner %v has tangent %v and bitangent %v, want those of the first triangle", c, v.Tangent,
+				v.Bitangent)
+		}
+	}
+	// the triangle without texture area gets any tangent orthogonal to its normal, the same for its three corners
+	if a, b := out[outIndices[6]], out[outIndices[8]]; a.Tangent != b.Tangent || a.Bitangent != b.Bitangent {
+		t.Errorf("corners of the degenerate triangle have tangents %v and %v", a.Tangent, b.Tangent)
+	}
+}
+
+func TestTangentsMirroredSeam(t *testing.T) {
+	// a strip across x = 0 where u = |x|, the left half mirrors the right one
+	vertices := flat(
+		[]mgl32.Vec2{{-1, 0}, {0, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}},
+		[]mgl32.Vec2{{1, 0}, {0, 0}, {1, 0}, {1, 1}, {0, 1}, {1, 1}})
+	indices := []uint32{0, 1, 4, 4, 3, 0, 1, 2, 5, 5, 4, 1}
+	out, outIndices := GenerateTangents(vertices, indices)
+	checkOrthonormal(t, out)
+	// both vertices on the seam are split
+	if len(out) != 8 {
+		t.Errorf("%v vertices, want 8", len(out))
+	}
+	m := Mesh{Vertices: out, Indices: outIndices}
+	for i := 0; i < len(outIndices); i += 3 {
+		tangent, bitangent, _ := uvDirections(m, i)
+		for c := 0; c < 3; c++ {
+			v := out[outIndices[i+c]]
+			if !near(v.Tangent, tangent.Normalize()) || !near(v.Bitangent, bitangent.Normalize()) {
+				t.Errorf("triangle %v corner %v has tangent %v and bitangent %v, want %v and %v", i/3, c, v.Tangent,
+					v.Bitangent, tangent.Normalize(), bitangent.Normalize())
+			}
+		}
+	}
+	// the seam vertices follow the triangles on their side
+	if out[outIndices[1]].Tangent != (mgl32.Vec3{-1, 0, 0}) || out[outIndices[6]].Tangent != (mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("seam tangents %v and %v", out[outIndices[1]].Tangent, out[outIndices[6]].Tangent)
+	}
+}
@@ -0,0 +1,144 @@
+package gl
+
+import "unsafe"
+
+// Backend executes the GL calls of this package. The wrapper functions of gl.go forward to the current backend, which
+// is the go-gl implementation unless replaced with SetBackend, e.g. by a RecordingBackend in tests. Builds with the
+// soft tag leave out go-gl and its cgo bindings, they have no backend until one is set, like the gl/soft one.
+type Backend interface {
+	Init() error
+	GetError() uint32
+	Viewport(x, y, width, height int32)
+	ClearColor(r, g, b, a float32)
+	Clear(flag uint32)
+	Disable(flag uint32)
+	Enable(flag uint32)
+	Scissor(x, y, w, h int32)
+	DepthMask(flag bool)
+	ColorMask(r, g, b, a bool)
+	BlendFunc(src, dst uint32)
+	DepthFunc(fn uint32)
+	StencilMask(mask uint32)
+	StencilFunc(xfunc uint32, ref int32, mask uint32)
+	StencilOp(fail, zfail, zpass uint32)
+
+	// vao
+	GenVertexArrays(n int32, array *uint32)
+	BindVertexArray(array uint32)
+	DeleteVertexArrays(n int32, array *uint32)
+
+	// program & shader
+	CreateProgram() uint32
+	DeleteProgram(program uint32)
+	AttachShader(program, shader uint32)
+	LinkProgram(program uint32)
+	UseProgram(program uint32)
+	GetProgramiv(program, pname uint32, params *int32)
+	GetProgramInfoLog(program uint32) string
+	CreateShader(xtype uint32) uint32
+	ShaderSource(shader uint32, src string)
+	CompileShader(shader uint32)
+	GetShaderiv(shader uint32, pname uint32, params *int32)
+	GetShaderInfoLog(shader uint32) string
+	DeleteShader(shader uint32)
+
+	// buffer & draw
+	GenBuffers(n int32, buffers *uint32)
+	BufferData(target uint32, size int, data unsafe.Pointer, usage uint32)
+	BufferSubData(target uint32, offset, size int, data unsafe.Pointer)
+	BindBuffer(target, buffer uint32)
+	DeleteBuffers(n int32, buffers *uint32)
+	BindBufferRange(target, index, buffer uint32, offset, size int)
+	BindBufferBase(target, index, buffer uint32)
+	GenFramebuffers(n int32, buffers *uint32)
+	BindFramebuffer(target, buffer uint32)
+	FramebufferTexture1D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32)
+	FramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32)
+	FramebufferTexture3D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32, zoffset int32)
+	FramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer uint32)
+	GenRenderbuffers(n int32, buffers *uint32)
+	BindRenderbuffer(target, buffer uint32)
+	RenderbufferStorage(target, internalformat uint32, width, height int32)
+	RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32)
+	CheckFramebufferStatus(target uint32) uint32
+	TexImage2DMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32, fixedsamplelocations bool)
+	DrawElements(mode uint32, count int32, typ uint32, offset int)
+	DrawArrays(mode uint32, first, count int32)
+	DrawArraysInstanced(mode uint32, first, count, instancecount int32)
+	DrawElementsInstanced(mode uint32, count int32, xtype uint32, indices unsafe.Pointer, instancecount int32)
+	DrawBuffer(buf uint32)
+	DrawBuffers(n int32, bufs *uint32)
+	ReadBuffer(src uint32)
+	ReadPixels(x, y, width, height int32, format, xtype uint32, pixels unsafe.Pointer)
+	PixelStorei(pname uint32, param int32)
+	Finish()
+
+	// uniform
+	GetUniformLocation(program uint32, name string) int32
+	Uniform1i(loc, v int32)
+	Uniform1iv(loc, num int32, v *int32)
+	Uniform1f(location int32, v0 float32)
+	Uniform2f(location int32, v0, v1 float32)
+	Uniform3f(location int32, v0, v1, v2 float32)
+	Uniform4f(location int32, v0, v1, v2, v3 float32)
+	Uniform1fv(loc, num int32, v *float32)
+	Uniform2fv(loc, num int32, v *float32)
+	Uniform3fv(loc, num int32, v *float32)
+	Uniform4fv(loc, num int32, v *float32)
+	UniformMatrix2fv(loc, num int32, t bool, v *float32)
+	UniformMatrix3fv(loc, num int32, t bool, v *float32)
+	UniformMatrix4fv(loc, num int32, t bool, v *float32)
+	GetUniformBlockIndex(program uint32, uniformBlockName *uint8) uint32
+	UniformBlockBinding(program uint32, uniformBlockIndex uint32, uniformBlockBinding uint32)
+	// returns name, size and type of the active uniform at index
+	GetActiveUniform(program, index uint32) (string, int32, uint32)
+	GetActiveUniformsiv(program uint32, uniformCount int32, uniformIndices *uint32, pname uint32, params *int32)
+	GetActiveUniformBlockiv(program, uniformBlockIndex, pname uint32, params *int32)
+	GetActiveUniformBlockName(program, uniformBlockIndex uint32) string
+
+	// attribute
+	EnableVertexAttribArray(index uint32)
+	VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset int)
+	VertexAttribIPointer(index uint32, size int32, xtype uint32, stride int32, offset int)
+	VertexAttribDivisor(index, divisor uint32)
+	DisableVertexAttribArray(index uint32)
+	GetAttribLocation(program uint32, name string) int32
+	// returns name, size and type of the active attribute at index
+	GetActiveAttrib(program, index uint32) (string, int32, uint32)
+	BindFragDataLocation(program uint32, color uint32, name string)
+
+	// texture
+	ActiveTexture(texture uint32)
+	BindTexture(target uint32, texture uint32)
+	TexSubImage2D(target uint32, level int32, xOffset, yOffset, width, height int32, format, xtype uint32, pixels unsafe.Pointer)
+	TexImage2D(target uint32, level int32, internalFormat int32, width, height, border int32, format, xtype uint32, pixels unsafe.Pointer)
+	GenTextures(n int32, textures *uint32)
+	DeleteTextures(n int32, textures *uint32)
+	TexParameteri(texture, pname uint32, param int32)
+	TexParameterfv(target uint32, pname uint32, params *float32)
+	GenerateMipmap(target uint32)
+	BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32)
+}
+
+// ShaderFileBackend is implemented by backends that don't run GLSL but shaders of their own, they are told the file
+// every shader object is compiled from to pick the matching implementation.
+type ShaderFileBackend interface {
+	ShaderFile(shader uint32, path string)
+}
+
+var (
+	backend Backend = defaultBackend()
+)
+
+// SetBackend replaces the backend used by every GL call of this package and returns the previous one. It must be
+// called before any GL object is created, objects don't move from one backend to another.
+func SetBackend(b Backend) Backend {
+	prev := backend
+	backend = b
+	return prev
+}
+
+// CurrentBackend returns the backend in use.
+func CurrentBackend() Backend {
+	return backend
+}
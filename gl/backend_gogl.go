@@ -0,0 +1,452 @@
+//go:build !soft
+
+package gl
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// goglBackend is the default Backend, it calls the OpenGL 3.3 core profile through github.com/go-gl/gl.
+type goglBackend struct{}
+
+var _ Backend = goglBackend{}
+
+func defaultBackend() Backend {
+	return goglBackend{}
+}
+
+func (bk goglBackend) Init() error {
+	return gl.Init()
+}
+
+func (bk goglBackend) GetError() uint32 {
+	return gl.GetError()
+}
+
+func (bk goglBackend) Viewport(x, y, width, height int32) {
+	gl.Viewport(x, y, width, height)
+}
+
+func (bk goglBackend) ClearColor(r, g, b, a float32) {
+	gl.ClearColor(r, g, b, a)
+}
+
+func (bk goglBackend) Clear(flag uint32) {
+	gl.Clear(flag)
+}
+
+func (bk goglBackend) Disable(flag uint32) {
+	gl.Disable(flag)
+}
+
+func (bk goglBackend) Enable(flag uint32) {
+	gl.Enable(flag)
+}
+
+func (bk goglBackend) Scissor(x, y, w, h int32) {
+	gl.Scissor(x, y, w, h)
+}
+
+func (bk goglBackend) DepthMask(flag bool) {
+	gl.DepthMask(flag)
+}
+
+func (bk goglBackend) ColorMask(r, g, b, a bool) {
+	gl.ColorMask(r, g, b, a)
+}
+
+func (bk goglBackend) BlendFunc(src, dst uint32) {
+	gl.BlendFunc(src, dst)
+}
+
+func (bk goglBackend) DepthFunc(fn uint32) {
+	gl.DepthFunc(fn)
+}
+
+func (bk goglBackend) StencilMask(mask uint32) {
+	gl.StencilMask(mask)
+}
+
+func (bk goglBackend) StencilFunc(xfunc uint32, ref int32, mask uint32) {
+	gl.StencilFunc(xfunc, ref, mask)
+}
+
+func (bk goglBackend) StencilOp(fail, zfail, zpass uint32) {
+	gl.StencilOp(fail, zfail, zpass)
+}
+
+func (bk goglBackend) GenVertexArrays(n int32, array *uint32) {
+	gl.GenVertexArrays(n, array)
+}
+
+func (bk goglBackend) BindVertexArray(array uint32) {
+	gl.BindVertexArray(array)
+}
+
+func (bk goglBackend) DeleteVertexArrays(n int32, array *uint32) {
+	gl.DeleteVertexArrays(n, array)
+}
+
+func (bk goglBackend) CreateProgram() uint32 {
+	return gl.CreateProgram()
+}
+
+func (bk goglBackend) DeleteProgram(program uint32) {
+	gl.DeleteProgram(program)
+}
+
+func (bk goglBackend) AttachShader(program, shader uint32) {
+	gl.AttachShader(program, shader)
+}
+
+func (bk goglBackend) LinkProgram(program uint32) {
+	gl.LinkProgram(program)
+}
+
+func (bk goglBackend) UseProgram(program uint32) {
+	gl.UseProgram(program)
+}
+
+func (bk goglBackend) GetProgramiv(program, pname uint32, params *int32) {
+	gl.GetProgramiv(program, pname, params)
+}
+
+// TODO 原来的视线中 buf = logLength + 1，需要测试这种情况
+func (bk goglBackend) GetProgramInfoLog(program uint32) string {
+	var logLength int32
+	bk.GetProgramiv(program, INFO_LOG_LENGTH, &logLength)
+
+	if logLength == 0 {
+		return ""
+	}
+
+	buf := make([]uint8, logLength)
+	gl.GetProgramInfoLog(program, logLength, nil, &buf[0])
+	return string(buf)
+}
+
+func (bk goglBackend) CreateShader(xtype uint32) uint32 {
+	return gl.CreateShader(xtype)
+}
+
+func (bk goglBackend) ShaderSource(shader uint32, src string) {
+	cstr, free := gl.Strs(src)
+	gl.ShaderSource(shader, 1, cstr, nil)
+	free()
+}
+
+func (bk goglBackend) CompileShader(shader uint32) {
+	gl.CompileShader(shader)
+}
+
+func (bk goglBackend) GetShaderiv(shader uint32, pname uint32, params *int32) {
+	gl.GetShaderiv(shader, pname, params)
+}
+
+func (bk goglBackend) GetShaderInfoLog(shader uint32) string {
+	var logLength int32
+	gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+	if logLength == 0 {
+		logLength = 128
+	}
+	buf := make([]uint8, logLength)
+	gl.GetShaderInfoLog(shader, logLength, nil, &buf[0])
+	return string(buf)
+}
+
+func (bk goglBackend) DeleteShader(shader uint32) {
+	gl.DeleteShader(shader)
+}
+
+func (bk goglBackend) GenBuffers(n int32, buffers *uint32) {
+	gl.GenBuffers(n, buffers)
+}
+
+func (bk goglBackend) BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
+	gl.BufferData(target, size, data, usage)
+}
+
+func (bk goglBackend) BufferSubData(target uint32, offset, size int, data unsafe.Pointer) {
+	gl.BufferSubData(target, offset, size, data)
+}
+
+func (bk goglBackend) BindBuffer(target, buffer uint32) {
+	gl.BindBuffer(target, buffer)
+}
+
+func (bk goglBackend) DeleteBuffers(n int32, buffers *uint32) {
+	gl.DeleteBuffers(n, buffers)
+}
+
+func (bk goglBackend) BindBufferRange(target, index, buffer uint32, offset, size int) {
+	gl.BindBufferRange(target, index, buffer, offset, size)
+}
+
+func (bk goglBackend) BindBufferBase(target, index, buffer uint32) {
+	gl.BindBufferBase(target, index, buffer)
+}
+
+func (bk goglBackend) GenFramebuffers(n int32, buffers *uint32) {
+	gl.GenFramebuffers(n, buffers)
+}
+
+func (bk goglBackend) BindFramebuffer(target, buffer uint32) {
+	gl.BindFramebuffer(target, buffer)
+}
+
+func (bk goglBackend) FramebufferTexture1D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32) {
+	gl.FramebufferTexture1D(target, attachment, textarget, texture, level)
+}
+
+func (bk goglBackend) FramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32) {
+	gl.FramebufferTexture2D(target, attachment, textarget, texture, level)
+}
+
+func (bk goglBackend) FramebufferTexture3D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32, zoffset int32) {
+	gl.FramebufferTexture3D(target, attachment, textarget, texture, level, zoffset)
+}
+
+func (bk goglBackend) FramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer uint32) {
+	gl.FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer)
+}
+
+func (bk goglBackend) GenRenderbuffers(n int32, buffers *uint32) {
+	gl.GenRenderbuffers(n, buffers)
+}
+
+func (bk goglBackend) BindRenderbuffer(target, buffer uint32) {
+	gl.BindRenderbuffer(target, buffer)
+}
+
+func (bk goglBackend) RenderbufferStorage(target, internalformat uint32, width, height int32) {
+	gl.RenderbufferStorage(target, internalformat, width, height)
+}
+
+func (bk goglBackend) RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32) {
+	gl.RenderbufferStorageMultisample(target, samples, internalformat, width, height)
+}
+
+func (bk goglBackend) CheckFramebufferStatus(target uint32) uint32 {
+	return gl.CheckFramebufferStatus(target)
+}
+
+func (bk goglBackend) TexImage2DMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32, fixedsamplelocations bool) {
+	gl.TexImage2DMultisample(target, samples, internalformat, width, height, fixedsamplelocations)
+}
+
+func (bk goglBackend) DrawElements(mode uint32, count int32, typ uint32, offset int) {
+	gl.DrawElements(mode, count, typ, gl.PtrOffset(offset))
+}
+
+func (bk goglBackend) DrawArrays(mode uint32, first, count int32) {
+	gl.DrawArrays(mode, first, count)
+}
+
+func (bk goglBackend) DrawArraysInstanced(mode uint32, first, count, instancecount int32) {
+	gl.DrawArraysInstanced(mode, first, count, instancecount)
+}
+
+func (bk goglBackend) DrawElementsInstanced(mode uint32, count int32, xtype uint32, indices unsafe.Pointer, instancecount int32) {
+	gl.DrawElementsInstanced(mode, count, xtype, indices, instancecount)
+}
+
+func (bk goglBackend) DrawBuffer(buf uint32) {
+	gl.DrawBuffer(buf)
+}
+
+func (bk goglBackend) DrawBuffers(n int32, bufs *uint32) {
+	gl.DrawBuffers(n, bufs)
+}
+
+func (bk goglBackend) ReadBuffer(src uint32) {
+	gl.ReadBuffer(src)
+}
+
+func (bk goglBackend) ReadPixels(x, y, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	gl.ReadPixels(x, y, width, height, format, xtype, pixels)
+}
+
+func (bk goglBackend) PixelStorei(pname uint32, param int32) {
+	gl.PixelStorei(pname, param)
+}
+
+func (bk goglBackend) Finish() {
+	gl.Finish()
+}
+
+func (bk goglBackend) GetUniformLocation(program uint32, name string) int32 {
+	return gl.GetUniformLocation(program, gl.Str(name))
+}
+
+func (bk goglBackend) Uniform1i(loc, v int32) {
+	gl.Uniform1i(loc, v)
+}
+
+func (bk goglBackend) Uniform1iv(loc, num int32, v *int32) {
+	gl.Uniform1iv(loc, num, v)
+}
+
+func (bk goglBackend) Uniform1f(location int32, v0 float32) {
+	gl.Uniform1f(location, v0)
+}
+
+func (bk goglBackend) Uniform2f(location int32, v0, v1 float32) {
+	gl.Uniform2f(location, v0, v1)
+}
+
+func (bk goglBackend) Uniform3f(location int32, v0, v1, v2 float32) {
+	gl.Uniform3f(location, v0, v1, v2)
+}
+
+func (bk goglBackend) Uniform4f(location int32, v0, v1, v2, v3 float32) {
+	gl.Uniform4f(location, v0, v1, v2, v3)
+}
+
+func (bk goglBackend) Uniform1fv(loc, num int32, v *float32) {
+	gl.Uniform1fv(loc, num, v)
+}
+
+func (bk goglBackend) Uniform2fv(loc, num int32, v *float32) {
+	gl.Uniform2fv(loc, num, v)
+}
+
+func (bk goglBackend) Uniform3fv(loc, num int32, v *float32) {
+	gl.Uniform3fv(loc, num, v)
+}
+
+func (bk goglBackend) Uniform4fv(loc, num int32, v *float32) {
+	gl.Uniform4fv(loc, num, v)
+}
+
+func (bk goglBackend) UniformMatrix2fv(loc, num int32, t bool, v *float32) {
+	gl.UniformMatrix2fv(loc, num, t, v)
+}
+
+func (bk goglBackend) UniformMatrix3fv(loc, num int32, t bool, v *float32) {
+	gl.UniformMatrix3fv(loc, num, t, v)
+}
+
+func (bk goglBackend) UniformMatrix4fv(loc, num int32, t bool, v *float32) {
+	gl.UniformMatrix4fv(loc, num, t, v)
+}
+
+func (bk goglBackend) GetUniformBlockIndex(program uint32, uniformBlockName *uint8) uint32 {
+	return gl.GetUniformBlockIndex(program, uniformBlockName)
+}
+
+func (bk goglBackend) UniformBlockBinding(program uint32, uniformBlockIndex uint32, uniformBlockBinding uint32) {
+	gl.UniformBlockBinding(program, uniformBlockIndex, uniformBlockBinding)
+}
+
+func (bk goglBackend) GetActiveUniform(program, index uint32) (string, int32, uint32) {
+	var maxLength, length, size int32
+	var xtype uint32
+	bk.GetProgramiv(program, ACTIVE_UNIFORM_MAX_LENGTH, &maxLength)
+	if maxLength == 0 {
+		return "", 0, 0
+	}
+	buf := make([]uint8, maxLength)
+	gl.GetActiveUniform(program, index, maxLength, &length, &size, &xtype, &buf[0])
+	return string(buf[:length]), size, xtype
+}
+
+func (bk goglBackend) GetActiveUniformsiv(program uint32, uniformCount int32, uniformIndices *uint32, pname uint32, params *int32) {
+	gl.GetActiveUniformsiv(program, uniformCount, uniformIndices, pname, params)
+}
+
+func (bk goglBackend) GetActiveUniformBlockiv(program, uniformBlockIndex, pname uint32, params *int32) {
+	gl.GetActiveUniformBlockiv(program, uniformBlockIndex, pname, params)
+}
+
+func (bk goglBackend) GetActiveUniformBlockName(program, uniformBlockIndex uint32) string {
+	var maxLength, length int32
+	bk.GetActiveUniformBlockiv(program, uniformBlockIndex, UNIFORM_BLOCK_NAME_LENGTH, &maxLength)
+	if maxLength == 0 {
+		return ""
+	}
+	buf := make([]uint8, maxLength)
+	gl.GetActiveUniformBlockName(program, uniformBlockIndex, maxLength, &length, &buf[0])
+	return string(buf[:length])
+}
+
+func (bk goglBackend) EnableVertexAttribArray(index uint32) {
+	gl.EnableVertexAttribArray(index)
+}
+
+func (bk goglBackend) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset int) {
+	gl.VertexAttribPointer(index, size, xtype, normalized, stride, gl.PtrOffset(offset))
+}
+
+func (bk goglBackend) VertexAttribIPointer(index uint32, size int32, xtype uint32, stride int32, offset int) {
+	gl.VertexAttribIPointer(index, size, xtype, stride, gl.PtrOffset(offset))
+}
+
+func (bk goglBackend) VertexAttribDivisor(index, divisor uint32) {
+	gl.VertexAttribDivisor(index, divisor)
+}
+
+func (bk goglBackend) DisableVertexAttribArray(index uint32) {
+	gl.DisableVertexAttribArray(index)
+}
+
+func (bk goglBackend) GetAttribLocation(program uint32, name string) int32 {
+	return gl.GetAttribLocation(program, gl.Str(name))
+}
+
+func (bk goglBackend) GetActiveAttrib(program, index uint32) (string, int32, uint32) {
+	var maxLength, length, size int32
+	var xtype uint32
+	bk.GetProgramiv(program, ACTIVE_ATTRIBUTE_MAX_LENGTH, &maxLength)
+	if maxLength == 0 {
+		return "", 0, 0
+	}
+	buf := make([]uint8, maxLength)
+	gl.GetActiveAttrib(program, index, maxLength, &length, &size, &xtype, &buf[0])
+	return string(buf[:length]), size, xtype
+}
+
+func (bk goglBackend) BindFragDataLocation(program uint32, color uint32, name string) {
+	gl.BindFragDataLocation(program, color, gl.Str(name))
+}
+
+func (bk goglBackend) ActiveTexture(texture uint32) {
+	gl.ActiveTexture(texture)
+}
+
+func (bk goglBackend) BindTexture(target uint32, texture uint32) {
+	gl.BindTexture(target, texture)
+}
+
+func (bk goglBackend) TexSubImage2D(target uint32, level int32, xOffset, yOffset, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	gl.TexSubImage2D(target, level, xOffset, yOffset, width, height, format, xtype, pixels)
+}
+
+func (bk goglBackend) TexImage2D(target uint32, level int32, internalFormat int32, width, height, border int32, format, xtype uint32, pixels unsafe.Pointer) {
+	gl.TexImage2D(target, level, internalFormat, width, height, border, format, xtype, pixels)
+}
+
+func (bk goglBackend) GenTextures(n int32, textures *uint32) {
+	gl.GenTextures(n, textures)
+}
+
+func (bk goglBackend) DeleteTextures(n int32, textures *uint32) {
+	gl.DeleteTextures(n, textures)
+}
+
+func (bk goglBackend) TexParameteri(texture, pname uint32, param int32) {
+	gl.TexParameteri(texture, pname, param)
+}
+
+func (bk goglBackend) TexParameterfv(target uint32, pname uint32, params *float32) {
+	gl.TexParameterfv(target, pname, params)
+}
+
+func (bk goglBackend) GenerateMipmap(target uint32) {
+	gl.GenerateMipmap(target)
+}
+
+func (bk goglBackend) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32) {
+	gl.BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
@@ -0,0 +1,544 @@
+package gl
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// Call is a GL call recorded by a RecordingBackend. Pointer arguments are recorded by value: object names as
+// []uint32, uniform values as []float32 or []int32; buffer and pixel data pointers are not kept.
+type Call struct {
+	Name string
+	Args []interface{}
+}
+
+// RecordingBackend is a Backend that needs no GL context. It records every call, hands out increasing object names
+// from 1, reports shaders as compiled and programs as linked, and tracks the texture bound to each texture unit.
+type RecordingBackend struct {
+	calls  []Call
+	nextId uint32
+	// CompileLog is returned as info log and makes every shader fail to compile if not empty
+	CompileLog string
+	// LinkLog is returned as info log and makes every program fail to link if not empty
+	LinkLog       string
+	locations     map[uint32]map[string]int32
+	activeTexture uint32
+	textures      map[uint32]uint32
+}
+
+var _ Backend = (*RecordingBackend)(nil)
+
+func NewRecordingBackend() *RecordingBackend {
+	return &RecordingBackend{
+		locations:     make(map[uint32]map[string]int32),
+		activeTexture: TEXTURE0,
+		textures:      make(map[uint32]uint32),
+	}
+}
+
+func (rb *RecordingBackend) record(name string, args ...interface{}) {
+	rb.calls = append(rb.calls, Call{Name: name, Args: args})
+}
+
+// Calls returns every recorded call in order.
+func (rb *RecordingBackend) Calls() []Call {
+	return rb.calls
+}
+
+// CallsTo returns the recorded calls of one function.
+func (rb *RecordingBackend) CallsTo(name string) []Call {
+	var calls []Call
+	for _, c := range rb.calls {
+		if c.Name == name {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the recorded calls, the object names and the bound textures are kept.
+func (rb *RecordingBackend) Reset() {
+	rb.calls = nil
+}
+
+// BoundTexture returns the texture bound to a unit, TEXTURE0 + i.
+func (rb *RecordingBackend) BoundTexture(unit uint32) uint32 {
+	return rb.textures[unit]
+}
+
+func (rb *RecordingBackend) gen(name string, n int32, ids *uint32) {
+	s := unsafe.Slice(ids, n)
+	for i := range s {
+		rb.nextId++
+		s[i] = rb.nextId
+	}
+	rb.record(name, n, append([]uint32(nil), s...))
+}
+
+func (rb *RecordingBackend) del(name string, n int32, ids *uint32) {
+	rb.record(name, n, append([]uint32(nil), unsafe.Slice(ids, n)...))
+}
+
+func floats(v *float32, n int32) []float32 {
+	return append([]float32(nil), unsafe.Slice(v, n)...)
+}
+
+func (rb *RecordingBackend) Init() error {
+	rb.record("Init")
+	return nil
+}
+
+func (rb *RecordingBackend) GetError() uint32 {
+	rb.record("GetError")
+	return NO_ERROR
+}
+
+func (rb *RecordingBackend) GenVertexArrays(n int32, array *uint32) {
+	rb.gen("GenVertexArrays", n, array)
+}
+
+func (rb *RecordingBackend) DeleteVertexArrays(n int32, array *uint32) {
+	rb.del("DeleteVertexArrays", n, array)
+}
+
+func (rb *RecordingBackend) GenBuffers(n int32, buffers *uint32) {
+	rb.gen("GenBuffers", n, buffers)
+}
+
+func (rb *RecordingBackend) DeleteBuffers(n int32, buffers *uint32) {
+	rb.del("DeleteBuffers", n, buffers)
+}
+
+func (rb *RecordingBackend) GenFramebuffers(n int32, buffers *uint32) {
+	rb.gen("GenFramebuffers", n, buffers)
+}
+
+func (rb *RecordingBackend) GenRenderbuffers(n int32, buffers *uint32) {
+	rb.gen("GenRenderbuffers", n, buffers)
+}
+
+func (rb *RecordingBackend) GenTextures(n int32, textures *uint32) {
+	rb.gen("GenTextures", n, textures)
+}
+
+func (rb *RecordingBackend) DeleteTextures(n int32, textures *uint32) {
+	rb.del("DeleteTextures", n, textures)
+}
+
+func (rb *RecordingBackend) CreateProgram() uint32 {
+	rb.nextId++
+	rb.record("CreateProgram", rb.nextId)
+	return rb.nextId
+}
+
+func (rb *RecordingBackend) CreateShader(xtype uint32) uint32 {
+	rb.nextId++
+	rb.record("CreateShader", xtype, rb.nextId)
+	return rb.nextId
+}
+
+func (rb *RecordingBackend) ShaderSource(shader uint32, src string) {
+	rb.record("ShaderSource", shader, strings.TrimSuffix(src, "\x00"))
+}
+
+func (rb *RecordingBackend) GetShaderiv(shader uint32, pname uint32, params *int32) {
+	rb.record("GetShaderiv", shader, pname)
+	*params = 0
+	switch pname {
+	case COMPILE_STATUS:
+		*params = TRUE
+		if rb.CompileLog != "" {
+			*params = FALSE
+		}
+	case INFO_LOG_LENGTH:
+		*params = int32(len(rb.CompileLog))
+	}
+}
+
+func (rb *RecordingBackend) GetShaderInfoLog(shader uint32) string {
+	rb.record("GetShaderInfoLog", shader)
+	return rb.CompileLog
+}
+
+func (rb *RecordingBackend) GetProgramiv(program, pname uint32, params *int32) {
+	rb.record("GetProgramiv", program, pname)
+	*params = 0
+	switch pname {
+	case LINK_STATUS:
+		*params = TRUE
+		if rb.LinkLog != "" {
+			*params = FALSE
+		}
+	case INFO_LOG_LENGTH:
+		*params = int32(len(rb.LinkLog))
+	}
+}
+
+func (rb *RecordingBackend) GetProgramInfoLog(program uint32) string {
+	rb.record("GetProgramInfoLog", program)
+	return rb.LinkLog
+}
+
+// GetUniformLocation hands out a stable location per program and name, starting from 0.
+func (rb *RecordingBackend) GetUniformLocation(program uint32, name string) int32 {
+	name = strings.TrimSuffix(name, "\x00")
+	rb.record("GetUniformLocation", program, name)
+	locations := rb.locations[program]
+	if locations == nil {
+		locations = make(map[string]int32)
+		rb.locations[program] = locations
+	}
+	loc, ok := locations[name]
+	if !ok {
+		loc = int32(len(locations))
+		locations[name] = loc
+	}
+	return loc
+}
+
+// UniformName returns the name a location of GetUniformLocation was handed out for.
+func (rb *RecordingBackend) UniformName(program uint32, loc int32) string {
+	for name, l := range rb.locations[program] {
+		if l == loc {
+			return name
+		}
+	}
+	return ""
+}
+
+func (rb *RecordingBackend) GetAttribLocation(program uint32, name string) int32 {
+	rb.record("GetAttribLocation", program, strings.TrimSuffix(name, "\x00"))
+	return -1
+}
+
+func (rb *RecordingBackend) GetUniformBlockIndex(program uint32, uniformBlockName *uint8) uint32 {
+	rb.record("GetUniformBlockIndex", program)
+	return INVALID_INDEX
+}
+
+func (rb *RecordingBackend) GetActiveUniform(program, index uint32) (string, int32, uint32) {
+	rb.record("GetActiveUniform", program, index)
+	return "", 0, 0
+}
+
+func (rb *RecordingBackend) GetActiveUniformsiv(program uint32, uniformCount int32, uniformIndices *uint32, pname uint32, params *int32) {
+	rb.record("GetActiveUniformsiv", program, uniformCount, pname)
+	s := unsafe.Slice(params, uniformCount)
+	for i := range s {
+		s[i] = -1
+	}
+}
+
+func (rb *RecordingBackend) GetActiveUniformBlockiv(program, uniformBlockIndex, pname uint32, params *int32) {
+	rb.record("GetActiveUniformBlockiv", program, uniformBlockIndex, pname)
+	*params = 0
+}
+
+func (rb *RecordingBackend) GetActiveUniformBlockName(program, uniformBlockIndex uint32) string {
+	rb.record("GetActiveUniformBlockName", program, uniformBlockIndex)
+	return ""
+}
+
+func (rb *RecordingBackend) GetActiveAttrib(program, index uint32) (string, int32, uint32) {
+	rb.record("GetActiveAttrib", program, index)
+	return "", 0, 0
+}
+
+func (rb *RecordingBackend) CheckFramebufferStatus(target uint32) uint32 {
+	rb.record("CheckFramebufferStatus", target)
+	return FRAMEBUFFER_COMPLETE
+}
+
+func (rb *RecordingBackend) BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
+	rb.record("BufferData", target, size, nil, usage)
+}
+
+func (rb *RecordingBackend) BufferSubData(target uint32, offset, size int, data unsafe.Pointer) {
+	rb.record("BufferSubData", target, offset, size, nil)
+}
+
+func (rb *RecordingBackend) DrawElementsInstanced(mode uint32, count int32, xtype uint32, indices unsafe.Pointer, instancecount int32) {
+	rb.record("DrawElementsInstanced", mode, count, xtype, uintptr(indices), instancecount)
+}
+
+func (rb *RecordingBackend) DrawBuffers(n int32, bufs *uint32) {
+	rb.record("DrawBuffers", n, append([]uint32(nil), unsafe.Slice(bufs, n)...))
+}
+
+func (rb *RecordingBackend) Uniform1iv(loc, num int32, v *int32) {
+	rb.record("Uniform1iv", loc, num, append([]int32(nil), unsafe.Slice(v, num)...))
+}
+
+func (rb *RecordingBackend) Uniform1fv(loc, num int32, v *float32) {
+	rb.record("Uniform1fv", loc, num, floats(v, num))
+}
+
+func (rb *RecordingBackend) Uniform2fv(loc, num int32, v *float32) {
+	rb.record("Uniform2fv", loc, num, floats(v, 2*num))
+}
+
+func (rb *RecordingBackend) Uniform3fv(loc, num int32, v *float32) {
+	rb.record("Uniform3fv", loc, num, floats(v, 3*num))
+}
+
+func (rb *RecordingBackend) Uniform4fv(loc, num int32, v *float32) {
+	rb.record("Uniform4fv", loc, num, floats(v, 4*num))
+}
+
+func (rb *RecordingBackend) UniformMatrix2fv(loc, num int32, t bool, v *float32) {
+	rb.record("UniformMatrix2fv", loc, num, t, floats(v, 4*num))
+}
+
+func (rb *RecordingBackend) UniformMatrix3fv(loc, num int32, t bool, v *float32) {
+	rb.record("UniformMatrix3fv", loc, num, t, floats(v, 9*num))
+}
+
+func (rb *RecordingBackend) UniformMatrix4fv(loc, num int32, t bool, v *float32) {
+	rb.record("UniformMatrix4fv", loc, num, t, floats(v, 16*num))
+}
+
+func (rb *RecordingBackend) ActiveTexture(texture uint32) {
+	rb.record("ActiveTexture", texture)
+	rb.activeTexture = texture
+}
+
+func (rb *RecordingBackend) BindTexture(target uint32, texture uint32) {
+	rb.record("BindTexture", target, texture)
+	rb.textures[rb.activeTexture] = texture
+}
+
+func (rb *RecordingBackend) TexImage2D(target uint32, level int32, internalFormat int32, width, height, border int32, format, xtype uint32, pixels unsafe.Pointer) {
+	rb.record("TexImage2D", target, level, internalFormat, width, height, border, format, xtype, nil)
+}
+
+func (rb *RecordingBackend) TexSubImage2D(target uint32, level int32, xOffset, yOffset, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	rb.record("TexSubImage2D", target, level, xOffset, yOffset, width, height, format, xtype, nil)
+}
+
+func (rb *RecordingBackend) TexParameterfv(target uint32, pname uint32, params *float32) {
+	n := int32(1)
+	if pname == TEXTURE_BORDER_COLOR {
+		n = 4
+	}
+	rb.record("TexParameterfv", target, pname, floats(params, n))
+}
+
+func (rb *RecordingBackend) Viewport(x, y, width, height int32) {
+	rb.record("Viewport", x, width, height)
+}
+
+func (rb *RecordingBackend) ClearColor(r, g, b, a float32) {
+	rb.record("ClearColor", r, b, a)
+}
+
+func (rb *RecordingBackend) Clear(flag uint32) {
+	rb.record("Clear", flag)
+}
+
+func (rb *RecordingBackend) Disable(flag uint32) {
+	rb.record("Disable", flag)
+}
+
+func (rb *RecordingBackend) Enable(flag uint32) {
+	rb.record("Enable", flag)
+}
+
+func (rb *RecordingBackend) Scissor(x, y, w, h int32) {
+	rb.record("Scissor", x, w, h)
+}
+
+func (rb *RecordingBackend) DepthMask(flag bool) {
+	rb.record("DepthMask", flag)
+}
+
+func (rb *RecordingBackend) ColorMask(r, g, b, a bool) {
+	rb.record("ColorMask", r, b, a)
+}
+
+func (rb *RecordingBackend) BlendFunc(src, dst uint32) {
+	rb.record("BlendFunc", src, dst)
+}
+
+func (rb *RecordingBackend) DepthFunc(fn uint32) {
+	rb.record("DepthFunc", fn)
+}
+
+func (rb *RecordingBackend) StencilMask(mask uint32) {
+	rb.record("StencilMask", mask)
+}
+
+func (rb *RecordingBackend) StencilFunc(xfunc uint32, ref int32, mask uint32) {
+	rb.record("StencilFunc", xfunc, ref, mask)
+}
+
+func (rb *RecordingBackend) StencilOp(fail, zfail, zpass uint32) {
+	rb.record("StencilOp", fail, zpass)
+}
+
+func (rb *RecordingBackend) BindVertexArray(array uint32) {
+	rb.record("BindVertexArray", array)
+}
+
+func (rb *RecordingBackend) DeleteProgram(program uint32) {
+	rb.record("DeleteProgram", program)
+}
+
+func (rb *RecordingBackend) AttachShader(program, shader uint32) {
+	rb.record("AttachShader", program, shader)
+}
+
+func (rb *RecordingBackend) LinkProgram(program uint32) {
+	rb.record("LinkProgram", program)
+}
+
+func (rb *RecordingBackend) UseProgram(program uint32) {
+	rb.record("UseProgram", program)
+}
+
+func (rb *RecordingBackend) CompileShader(shader uint32) {
+	rb.record("CompileShader", shader)
+}
+
+func (rb *RecordingBackend) DeleteShader(shader uint32) {
+	rb.record("DeleteShader", shader)
+}
+
+func (rb *RecordingBackend) BindBuffer(target, buffer uint32) {
+	rb.record("BindBuffer", target, buffer)
+}
+
+func (rb *RecordingBackend) BindBufferRange(target, index, buffer uint32, offset, size int) {
+	rb.record("BindBufferRange", target, buffer, offset, size)
+}
+
+func (rb *RecordingBackend) BindBufferBase(target, index, buffer uint32) {
+	rb.record("BindBufferBase", target, buffer)
+}
+
+func (rb *RecordingBackend) BindFramebuffer(target, buffer uint32) {
+	rb.record("BindFramebuffer", target, buffer)
+}
+
+func (rb *RecordingBackend) FramebufferTexture1D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32) {
+	rb.record("FramebufferTexture1D", target, attachment, textarget, texture, level)
+}
+
+func (rb *RecordingBackend) FramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32) {
+	rb.record("FramebufferTexture2D", target, attachment, textarget, texture, level)
+}
+
+func (rb *RecordingBackend) FramebufferTexture3D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32, zoffset int32) {
+	rb.record("FramebufferTexture3D", target, attachment, textarget, texture, level, zoffset)
+}
+
+func (rb *RecordingBackend) FramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer uint32) {
+	rb.record("FramebufferRenderbuffer", target, attachment, renderbuffertarget, renderbuffer)
+}
+
+func (rb *RecordingBackend) BindRenderbuffer(target, buffer uint32) {
+	rb.record("BindRenderbuffer", target, buffer)
+}
+
+func (rb *RecordingBackend) RenderbufferStorage(target, internalformat uint32, width, height int32) {
+	rb.record("RenderbufferStorage", target, internalformat, width, height)
+}
+
+func (rb *RecordingBackend) RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32) {
+	rb.record("RenderbufferStorageMultisample", target, samples, internalformat, width, height)
+}
+
+func (rb *RecordingBackend) TexImage2DMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32, fixedsamplelocations bool) {
+	rb.record("TexImage2DMultisample", target, samples, internalformat, width, height, fixedsamplelocations)
+}
+
+func (rb *RecordingBackend) DrawElements(mode uint32, count int32, typ uint32, offset int) {
+	rb.record("DrawElements", mode, count, typ, offset)
+}
+
+func (rb *RecordingBackend) DrawArrays(mode uint32, first, count int32) {
+	rb.record("DrawArrays", mode, first, count)
+}
+
+func (rb *RecordingBackend) DrawArraysInstanced(mode uint32, first, count, instancecount int32) {
+	rb.record("DrawArraysInstanced", mode, first, instancecount)
+}
+
+func (rb *RecordingBackend) DrawBuffer(buf uint32) {
+	rb.record("DrawBuffer", buf)
+}
+
+func (rb *RecordingBackend) ReadBuffer(src uint32) {
+	rb.record("ReadBuffer", src)
+}
+
+func (rb *RecordingBackend) ReadPixels(x, y, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	rb.record("ReadPixels", x, y, width, height, format, xtype, nil)
+}
+
+func (rb *RecordingBackend) PixelStorei(pname uint32, param int32) {
+	rb.record("PixelStorei", pname, param)
+}
+
+func (rb *RecordingBackend) Finish() {
+	rb.record("Finish")
+}
+
+func (rb *RecordingBackend) Uniform1i(loc, v int32) {
+	rb.record("Uniform1i", loc, v)
+}
+
+func (rb *RecordingBackend) Uniform1f(location int32, v0 float32) {
+	rb.record("Uniform1f", location, v0)
+}
+
+func (rb *RecordingBackend) Uniform2f(location int32, v0, v1 float32) {
+	rb.record("Uniform2f", location, v0, v1)
+}
+
+func (rb *RecordingBackend) Uniform3f(location int32, v0, v1, v2 float32) {
+	rb.record("Uniform3f", location, v0, v2)
+}
+
+func (rb *RecordingBackend) Uniform4f(location int32, v0, v1, v2, v3 float32) {
+	rb.record("Uniform4f", location, v0, v2, v3)
+}
+
+func (rb *RecordingBackend) UniformBlockBinding(program uint32, uniformBlockIndex uint32, uniformBlockBinding uint32) {
+	rb.record("UniformBlockBinding", program, uniformBlockIndex, uniformBlockBinding)
+}
+
+func (rb *RecordingBackend) EnableVertexAttribArray(index uint32) {
+	rb.record("EnableVertexAttribArray", index)
+}
+
+func (rb *RecordingBackend) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset int) {
+	rb.record("VertexAttribPointer", index, size, xtype, normalized, stride, offset)
+}
+
+func (rb *RecordingBackend) VertexAttribIPointer(index uint32, size int32, xtype uint32, stride int32, offset int) {
+	rb.record("VertexAttribIPointer", index, size, xtype, stride, offset)
+}
+
+func (rb *RecordingBackend) VertexAttribDivisor(index, divisor uint32) {
+	rb.record("VertexAttribDivisor", index, divisor)
+}
+
+func (rb *RecordingBackend) DisableVertexAttribArray(index uint32) {
+	rb.record("DisableVertexAttribArray", index)
+}
+
+func (rb *RecordingBackend) BindFragDataLocation(program uint32, color uint32, name string) {
+	rb.record("BindFragDataLocation", program, color, name)
+}
+
+func (rb *RecordingBackend) TexParameteri(texture, pname uint32, param int32) {
+	rb.record("TexParameteri", texture, pname, param)
+}
+
+func (rb *RecordingBackend) GenerateMipmap(target uint32) {
+	rb.record("GenerateMipmap", target)
+}
+
+func (rb *RecordingBackend) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32) {
+	rb.record("BlitFramebuffer", srcX0, srcX1, dstX0, dstX1, dstY1, mask, filter)
+}
@@ -0,0 +1,9 @@
+//go:build soft
+
+package gl
+
+// defaultBackend is nil in builds with the soft tag, which leave out the go-gl backend, SetBackend must be called
+// before any GL call.
+func defaultBackend() Backend {
+	return nil
+}
@@ -0,0 +1,284 @@
+package gl
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// AABB is an axis-aligned bounding box. It is empty when Min exceeds Max on an axis, like the one of EmptyAABB
+// which any union replaces.
+type AABB struct {
+	Min, Max mgl32.Vec3
+}
+
+// EmptyAABB returns a box containing nothing, the start of a union.
+func EmptyAABB() AABB {
+	inf := float32(math.Inf(1))
+	return AABB{Min: mgl32.Vec3{inf, inf, inf}, Max: mgl32.Vec3{-inf, -inf, -inf}}
+}
+
+// ComputeAABB returns the bounding box of the positions of vertices, empty if there are none.
+func ComputeAABB(vertices []Vertex) AABB {
+	b := EmptyAABB()
+	for i := range vertices {
+		b = b.Extend(vertices[i].Position)
+	}
+	return b
+}
+
+func (b AABB) Empty() bool {
+	return b.Min[0] > b.Max[0] || b.Min[1] > b.Max[1] || b.Min[2] > b.Max[2]
+}
+
+func (b AABB) Center() mgl32.Vec3 {
+	return b.Min.Add(b.Max).Mul(0.5)
+}
+
+// Size returns the extent of the box along every axis.
+func (b AABB) Size() mgl32.Vec3 {
+	return b.Max.Sub(b.Min)
+}
+
+// Extend returns the box grown to contain p.
+func (b AABB) Extend(p mgl32.Vec3) AABB {
+	for i := 0; i < 3; i++ {
+		b.Min[i] = float32(math.Min(float64(b.Min[i]), float64(p[i])))
+		b.Max[i] = float32(math.Max(float64(b.Max[i]), float64(p[i])))
+	}
+	return b
+}
+
+// Union returns the box containing b and other.
+func (b AABB) Union(other AABB) AABB {
+	if other.Empty() {
+		return b
+	}
+	return b.Extend(other.Min).Extend(other.Max)
+}
+
+// Contains tells whether p is inside the box or on its faces.
+func (b AABB) Contains(p mgl32.Vec3) bool {
+	for i := 0; i < 3; i++ {
+		if p[i] < b.Min[i] || p[i] > b.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAABB tells whether other is entirely inside the box, an empty box is inside any box.
+func (b AABB) ContainsAABB(other AABB) bool {
+	return other.Empty() || b.Contains(other.Min) && b.Contains(other.Max)
+}
+
+// Intersects tells whether the boxes overlap.
+func (b AABB) Intersects(other AABB) bool {
+	for i := 0; i < 3; i++ {
+		if b.Min[i] > other.Max[i] || other.Min[i] > b.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Transform returns the axis-aligned box containing the box transformed by the affine matrix m, e.g. a model
+// matrix. It is larger than the box unless m only scales and translates, Oriented keeps the exact volume.
+func (b AABB) Transform(m mgl32.Mat4) AABB {
+	if b.Empty() {
+		return b
+	}
+	return b.Oriented(m).Bounds()
+}
+
+// Oriented returns the box transformed by the affine matrix m.
+func (b AABB) Oriented(m mgl32.Mat4) OBB {
+	half := b.Size().Mul(0.5)
+	var o OBB
+	o.Center = m.Mul4x1(b.Center().Vec4(1)).Vec3()
+	for i := 0; i < 3; i++ {
+		o.Axes[i] = m.Col(i).Vec3().Mul(half[i])
+	}
+	return o
+}
+
+// Sphere returns the sphere through the corners of the box, looser than ComputeBoundingSphere.
+func (b AABB) Sphere() BoundingSphere {
+	if b.Empty() {
+		return BoundingSphere{Radius: -1}
+	}
+	return BoundingSphere{Center: b.Center(), Radius: b.Size().Len() / 2}
+}
+
+// OBB is an oriented bounding box, Axes holds the vectors from the center to the middle of three faces, so the
+// box spans Center ± Axes[0] ± Axes[1] ± Axes[2].
+type OBB struct {
+	Center mgl32.Vec3
+	Axes   [3]mgl32.Vec3
+}
+
+// Corners returns the 8 corners of the box.
+func (o OBB) Corners() [8]mgl32.Vec3 {
+	var corners [8]mgl32.Vec3
+	for i := range corners {
+		c := o.Center
+		for axis := 0; axis < 3; axis++ {
+			if i&(1<<axis) != 0 {
+				c = c.Add(o.Axes[axis])
+			} else {
+				c = c.Sub(o.Axes[axis])
+			}
+		}
+		corners[i] = c
+	}
+	return corners
+}
+
+// Bounds returns the axis-aligned box containing the box.
+func (o OBB) Bounds() AABB {
+	var half mgl32.Vec3
+	for axis := 0; axis < 3; axis++ {
+		for i := 0; i < 3; i++ {
+			half[i] += float32(math.Abs(float64(o.Axes[axis][i])))
+		}
+	}
+	return AABB{Min: o.Center.Sub(half), Max: o.Center.Add(half)}
+}
+
+// Contains tells whether p is inside the box, the axes must be orthogonal, which they are unless the box was
+// transformed by a shear. The box is flat along its zero axes, p must be in the span of the others.
+func (o OBB) Contains(p mgl32.Vec3) bool {
+	d := p.Sub(o.Center)
+	// what is left of d outside the span of the axes
+	rest := d
+	var size float32
+	for _, axis := range o.Axes {
+		l := axis.Dot(axis)
+		if l == 0 {
+			continue
+		}
+		// the projection on the axis, in multiples of its half length
+		projection := d.Dot(axis) / l
+		if math.Abs(float64(projection)) > 1+1e-6 {
+			return false
+		}
+		rest = rest.Sub(axis.Mul(projection))
+		size += axis.Len()
+	}
+	return rest.Len() <= 1e-5*(size+d.Len())
+}
+
+// BoundingSphere is a sphere containing a volume, empty when Radius is negative.
+type BoundingSphere struct {
+	Center mgl32.Vec3
+	Radius float32
+}
+
+// ComputeBoundingSphere returns a sphere containing the positions of vertices, centered on their bounding box,
+// empty if there are none.
+func ComputeBoundingSphere(vertices []Vertex) BoundingSphere {
+	s := ComputeAABB(vertices).Sphere()
+	if s.Radius > 0 {
+		var radius float32
+		for i := range vertices {
+			radius = float32(math.Max(float64(radius), float64(vertices[i].Position.Sub(s.Center).Len())))
+		}
+		s.Radius = radius
+	}
+	return s
+}
+
+func (s BoundingSphere) Empty() bool {
+	return s.Radius < 0
+}
+
+// Union returns a sphere containing s and other.
+func (s BoundingSphere) Union(other BoundingSphere) BoundingSphere {
+	switch {
+	case other.Empty():
+		return s
+	case s.Empty():
+		return other
+	}
+	d := other.Center.Sub(s.Center)
+	distance := d.Len()
+	if distance+other.Radius <= s.Radius {
+		return s
+	}
+	if distance+s.Radius <= other.Radius {
+		return other
+	}
+	radius := (distance + s.Radius + other.Radius) / 2
+	// the center moves from s towards other so that both touch the new sphere from the inside
+	center := s.Center.Add(d.Mul((radius - s.Radius) / distance))
+	return BoundingSphere{Center: center, Radius: radius}
+}
+
+// Contains tells whether p is inside the sphere or on its surface.
+func (s BoundingSphere) Contains(p mgl32.Vec3) bool {
+	return p.Sub(s.Center).Len() <= s.Radius
+}
+
+// ContainsSphere tells whether other is entirely inside the sphere, an empty sphere is inside any sphere.
+func (s BoundingSphere) ContainsSphere(other BoundingSphere) bool {
+	return other.Empty() || other.Center.Sub(s.Center).Len()+other.Radius <= s.Radius
+}
+
+// Transform returns a sphere containing the sphere transformed by the affine matrix m, its radius is scaled by the
+// largest scale of m.
+func (s BoundingSphere) Transform(m mgl32.Mat4) BoundingSphere {
+	if s.Empty() {
+		return s
+	}
+	var scale float32
+	for i := 0; i < 3; i++ {
+		scale = float32(math.Max(float64(scale), float64(m.Col(i).Vec3().Len())))
+	}
+	return BoundingSphere{Center: m.Mul4x1(s.Center.Vec4(1)).Vec3(), Radius: s.Radius * scale}
+}
+
+// FramingDistance returns the distance from the center at which a perspective camera with the vertical field of
+// view fovy, in radians, and the aspect ratio width/height sees the whole sphere, 0 for an empty sphere.
+func (s BoundingSphere) FramingDistance(fovy, aspect float32) float32 {
+	if s.Empty() {
+		return 0
+	}
+	half := float64(fovy) / 2
+	// the narrower of the vertical and horizontal fields of view limits
+	half = math.Min(half, math.Atan(math.Tan(half)*float64(aspect)))
+	return s.Radius / float32(math.Sin(half))
+}
+
+// layoutBounds returns the bounding box and sphere of the positions of vertices in layout, the FLOAT attribute at
+// location 0, empty if there is no such attribute.
+func layoutBounds(layout *VertexLayout, streams [][]byte) (AABB, BoundingSphere) {
+	var position *VertexAttribute
+	for i := range layout.Attributes {
+		a := &layout.Attributes[i]
+		if a.Location == 0 && a.Type == FLOAT && a.Components >= 3 {
+			position = a
+		}
+	}
+	b := EmptyAABB()
+	if position == nil || len(streams) <= position.Stream {
+		return b, b.Sphere()
+	}
+	stream := streams[position.Stream]
+	stride := int(layout.Strides[position.Stream])
+	at := func(offset int) mgl32.Vec3 {
+		return *(*mgl32.Vec3)(unsafe.Pointer(&stream[offset]))
+	}
+	for offset := position.Offset; offset+12 <= len(stream); offset += stride {
+		b = b.Extend(at(offset))
+	}
+	s := b.Sphere()
+	if s.Radius > 0 {
+		var radius float32
+		for offset := position.Offset; offset+12 <= len(stream); offset += stride {
+			radius = float32(math.Max(float64(radius), float64(at(offset).Sub(s.Center).Len())))
+		}
+		s.Radius = radius
+	}
+	return b, s
+}
@@ -0,0 +1,160 @@
+package gl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func nearVec(a, b mgl32.Vec3) bool {
+	return a.Sub(b).Len() < 1e-5
+}
+
+func TestAABB(t *testing.T) {
+	b := ComputeAABB([]Vertex{{Position: mgl32.Vec3{1, -2, 3}}, {Position: mgl32.Vec3{-1, 2, 0}}})
+	if b != (AABB{Min: mgl32.Vec3{-1, -2, 0}, Max: mgl32.Vec3{1, 2, 3}}) {
+		t.Fatalf("ComputeAABB = %v", b)
+	}
+	if !ComputeAABB(nil).Empty() || !EmptyAABB().Sphere().Empty() {
+		t.Errorf("no vertices give a non-empty box")
+	}
+
+	if u := EmptyAABB().Union(b).Union(EmptyAABB()); u != b {
+		t.Errorf("union with empty boxes = %v", u)
+	}
+	other := AABB{Min: mgl32.Vec3{0, 0, 2}, Max: mgl32.Vec3{4, 1, 5}}
+	u := b.Union(other)
+	if u != (AABB{Min: mgl32.Vec3{-1, -2, 0}, Max: mgl32.Vec3{4, 2, 5}}) {
+		t.Errorf("Union = %v", u)
+	}
+	if !u.ContainsAABB(b) || !u.ContainsAABB(other) || b.ContainsAABB(other) || !b.ContainsAABB(EmptyAABB()) {
+		t.Errorf("ContainsAABB is wrong")
+	}
+	if !b.Contains(mgl32.Vec3{1, 2, 3}) || b.Contains(mgl32.Vec3{1, 2, 3.001}) {
+		t.Errorf("Contains is wrong on the faces")
+	}
+	if !b.Intersects(other) || b.Intersects(AABB{Min: mgl32.Vec3{2, 0, 0}, Max: mgl32.Vec3{3, 1, 1}}) {
+		t.Errorf("Intersects is wrong")
+	}
+}
+
+func TestAABBTransform(t *testing.T) {
+	b := AABB{Min: mgl32.Vec3{-1, -1, -1}, Max: mgl32.Vec3{1, 1, 1}}
+	m := mgl32.Translate3D(10, 0, 0).Mul4(mgl32.Scale3D(2, 3, 4))
+	if got := b.Transform(m); !nearVec(got.Min, mgl32.Vec3{8, -3, -4}) || !nearVec(got.Max, mgl32.Vec3{12, 3, 4}) {
+		t.Errorf("scaled and translated box = %v", got)
+	}
+	// turned by 45° the box grows by √2 on x and z
+	rotY := mgl32.HomogRotate3DY(math.Pi / 4)
+	got := b.Transform(rotY)
+	if r := float32(math.Sqrt2); !nearVec(got.Min, mgl32.Vec3{-r, -1, -r}) || !nearVec(got.Max, mgl32.Vec3{r, 1, r}) {
+		t.Errorf("rotated box = %v", got)
+	}
+	if e := EmptyAABB(); !e.Transform(m).Empty() {
+		t.Errorf("transformed empty box isn't empty")
+	}
+
+	o := b.Oriented(rotY)
+	for _, c := range o.Corners() {
+		if !o.Contains(c) || !got.Contains(c) {
+			t.Errorf("corner %v outside", c)
+		}
+	}
+	if !o.Contains(mgl32.Vec3{1.3, 0, 0}) || o.Contains(mgl32.Vec3{1.5, 0, 0}) || o.Contains(mgl32.Vec3{0, 1.1, 0}) {
+		t.Errorf("OBB.Contains is wrong")
+	}
+}
+
+func TestOBBFlat(t *testing.T) {
+	// a square in the plane y = 0, turned around y
+	square := AABB{Min: mgl32.Vec3{-1, 0, -1}, Max: mgl32.Vec3{1, 0, 1}}
+	o := square.Oriented(mgl32.HomogRotate3DY(math.Pi / 3))
+	if o.Contains(mgl32.Vec3{0, 100, 0}) || o.Contains(mgl32.Vec3{0, 0.001, 0}) {
+		t.Errorf("points off the plane are inside the flat box")
+	}
+	if !o.Contains(mgl32.Vec3{0, 0, 0}) || !o.Contains(o.Corners()[3]) {
+		t.Errorf("points of the square are outside")
+	}
+	// a segment along x
+	segment := AABB{Min: mgl32.Vec3{-1, 2, 3}, Max: mgl32.Vec3{1, 2, 3}}.Oriented(mgl32.Ident4())
+	if !segment.Contains(mgl32.Vec3{0.5, 2, 3}) || segment.Contains(mgl32.Vec3{0.5, 2, 3.5}) ||
+		segment.Contains(mgl32.Vec3{0.5, 2.5, 3}) {
+		t.Errorf("Contains is wrong on a segment")
+	}
+	point := AABB{Min: mgl32.Vec3{1, 1, 1}, Max: mgl32.Vec3{1, 1, 1}}.Oriented(mgl32.Ident4())
+	if !point.Contains(mgl32.Vec3{1, 1, 1}) || point.Contains(mgl32.Vec3{1, 1, 1.01}) {
+		t.Errorf("Contains is wrong on a point")
+	}
+}
+
+func TestBoundingSphere(t *testing.T) {
+	vertices := []Vertex{{Position: mgl32.Vec3{-1, 0, 0}}, {Position: mgl32.Vec3{1, 0, 0}}, {Position: mgl32.Vec3{0, 1, 0}}}
+	s := ComputeBoundingSphere(vertices)
+	if !nearVec(s.Center, mgl32.Vec3{0, 0.5, 0}) || math.Abs(float64(s.Radius)-math.Sqrt(1.25)) > 1e-5 {
+		t.Fatalf("ComputeBoundingSphere = %v", s)
+	}
+	for _, v := range vertices {
+		if !s.Contains(v.Position) {
+			t.Errorf("%v outside %v", v.Position, s)
+		}
+	}
+	empty := ComputeBoundingSphere(nil)
+	if !empty.Empty() || empty.Union(s) != s || s.Union(empty) != s || !s.ContainsSphere(empty) {
+		t.Errorf("union with the empty sphere is wrong")
+	}
+
+	a := BoundingSphere{Center: mgl32.Vec3{0, 0, 0}, Radius: 1}
+	b := BoundingSphere{Center: mgl32.Vec3{4, 0, 0}, Radius: 2}
+	u := a.Union(b)
+	if !nearVec(u.Center, mgl32.Vec3{2.5, 0, 0}) || u.Radius != 3.5 || !u.ContainsSphere(a) || !u.ContainsSphere(b) {
+		t.Errorf("Union = %v", u)
+	}
+	inner := BoundingSphere{Center: mgl32.Vec3{4.5, 0, 0}, Radius: 1}
+	if b.Union(inner) != b || inner.Union(b) != b || inner.ContainsSphere(b) {
+		t.Errorf("union with a contained sphere is wrong")
+	}
+
+	m := mgl32.Translate3D(0, 5, 0).Mul4(mgl32.Scale3D(1, 3, 2))
+	if got := a.Transform(m); got.Center != (mgl32.Vec3{0, 5, 0}) || got.Radius != 3 {
+		t.Errorf("Transform = %v", got)
+	}
+	if !empty.Transform(m).Empty() {
+		t.Errorf("transformed empty sphere isn't empty")
+	}
+}
+
+func TestFramingDistance(t *testing.T) {
+	s := BoundingSphere{Radius: 1}
+	// a 90° field of view sees the sphere tangent at √2
+	if d := s.FramingDistance(math.Pi/2, 2); math.Abs(float64(d)-math.Sqrt2) > 1e-5 {
+		t.Errorf("FramingDistance = %v, want √2", d)
+	}
+	// a portrait view is limited by its width
+	narrow := s.FramingDistance(math.Pi/2, 0.5)
+	half := math.Atan(0.5)
+	if math.Abs(float64(narrow)-1/math.Sin(half)) > 1e-5 {
+		t.Errorf("FramingDistance = %v, want %v", narrow, 1/math.Sin(half))
+	}
+	if d := ComputeBoundingSphere(nil).FramingDistance(math.Pi/4, 1); d != 0 {
+		t.Errorf("empty sphere framed at %v", d)
+	}
+}
+
+func TestMeshBounds(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+
+	vertices := []Vertex{{Position: mgl32.Vec3{-1, 0, 0}}, {Position: mgl32.Vec3{2, 1, 0}}, {Position: mgl32.Vec3{0, 0, 3}}}
+	mesh := NewMeshWithMaterial(vertices, []uint32{0, 1, 2}, DefaultMaterial())
+	if mesh.Bounds() != ComputeAABB(vertices) || mesh.BoundingSphere() != ComputeBoundingSphere(vertices) {
+		t.Errorf("mesh bounds %v %v", mesh.Bounds(), mesh.BoundingSphere())
+	}
+	// given bounds are kept as they are
+	bounds := AABB{Max: mgl32.Vec3{1, 1, 1}}
+	sphere := BoundingSphere{Radius: 7}
+	mesh = NewMeshWithBounds(vertices, []uint32{0, 1, 2}, DefaultMaterial(), bounds, sphere)
+	if mesh.Bounds() != bounds || mesh.BoundingSphere() != sphere {
+		t.Errorf("mesh bounds %v %v, want %v %v", mesh.Bounds(), mesh.BoundingSphere(), bounds, sphere)
+	}
+}
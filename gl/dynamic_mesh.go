@@ -0,0 +1,350 @@
+package gl
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// DynamicMeshOptions configures NewDynamicMesh.
+type DynamicMeshOptions struct {
+	// Usage is DYNAMIC_DRAW if zero, STREAM_DRAW suits data replaced every frame
+	Usage uint32
+	// Mode is the primitive drawn, TRIANGLES if zero, e.g. LINES for debug lines
+	Mode uint32
+	// Buffers is the number of copies of the buffers cycled by Next, 1 if zero. The GPU may still draw from the
+	// previous copies while the current one is updated
+	Buffers int
+}
+
+// DynamicMesh is a mesh whose vertices and indices change after creation, e.g. particles, cloth or debug lines.
+// It keeps a copy of its data to grow its buffers and to bring the copies cycled by Next up to date.
+type DynamicMesh struct {
+	layout   VertexLayout
+	material Material
+	usage    uint32
+	mode     uint32
+	// streams holds the vertices of every stream of the layout, indices the indices
+	streams     [][]byte
+	indices     []uint32
+	vertexCount int
+	// vertexCapacity and indexCapacity are the vertices and indices the buffers have room for
+	vertexCapacity int
+	indexCapacity  int
+	copies         []dynamicBuffers
+	current        int
+}
+
+// dynamicBuffers is a copy of the buffers of a dynamic mesh with the ranges it missed while other copies were
+// current.
+type dynamicBuffers struct {
+	vao  uint32
+	vbos []uint32
+	ebo  uint32
+	// dirtyVertices and dirtyIndices are the first and the end of the outdated ranges, empty if end <= first
+	dirtyVertices [2]int
+	dirtyIndices  [2]int
+}
+
+// NewDynamicMesh creates an empty mesh with vertices in layout, filled by SetVertices and SetIndices.
+func NewDynamicMesh(layout VertexLayout, material Material, opts DynamicMeshOptions) *DynamicMesh {
+	if opts.Usage == 0 {
+		opts.Usage = DYNAMIC_DRAW
+	}
+	if opts.Mode == 0 {
+		opts.Mode = TRIANGLES
+	}
+	if opts.Buffers < 1 {
+		opts.Buffers = 1
+	}
+	m := &DynamicMesh{
+		layout:   layout,
+		material: material,
+		usage:    opts.Usage,
+		mode:     opts.Mode,
+		streams:  make([][]byte, len(layout.Strides)),
+		copies:   make([]dynamicBuffers, opts.Buffers),
+	}
+	for i := range m.copies {
+		b := &m.copies[i]
+		GenVertexArrays(1, &b.vao)
+		trackObject("vertex array", b.vao)
+		BindVertexArray(b.vao)
+		b.vbos = make([]uint32, len(layout.Strides))
+		for j := range b.vbos {
+			GenBuffers(1, &b.vbos[j])
+			trackObject("buffer", b.vbos[j])
+		}
+		GenBuffers(1, &b.ebo)
+		trackObject("buffer", b.ebo)
+		BindBuffer(ELEMENT_ARRAY_BUFFER, b.ebo)
+		// the attribute pointers stay valid when the storage of the buffers is replaced
+		m.layout.Apply(b.vbos)
+		BindVertexArray(0)
+	}
+	return m
+}
+
+// Material returns the material of the mesh, changes to it show from the next Draw.
+func (m *DynamicMesh) Material() *Material {
+	return &m.material
+}
+
+// VertexCount returns the number of vertices of the mesh.
+func (m *DynamicMesh) VertexCount() int {
+	return m.vertexCount
+}
+
+// Bounds returns the bounding box and a bounding sphere of the current vertices like Mesh.Bounds, they are computed
+// by every call.
+func (m *DynamicMesh) Bounds() (AABB, BoundingSphere) {
+	return layoutBounds(&m.layout, m.streams)
+}
+
+// Indices returns the indices of the mesh, they must not be modified.
+func (m *DynamicMesh) Indices() []uint32 {
+	return m.indices
+}
+
+// SetVertices replaces the vertices, streams holds the bytes of every stream of the layout. The storage of the
+// buffers is orphaned rather than waited for.
+func (m *DynamicMesh) SetVertices(streams [][]byte) error {
+	count, err := m.layout.Validate(streams)
+	if err != nil {
+		return err
+	}
+	for i, stream := range streams {
+		m.streams[i] = append(m.streams[i][:0], stream...)
+	}
+	m.vertexCount = count
+	if count > m.vertexCapacity {
+		m.growVertices()
+		return nil
+	}
+	b := &m.copies[m.current]
+	for i := range m.streams {
+		stride := int(m.layout.Strides[i])
+		BindBuffer(ARRAY_BUFFER, b.vbos[i])
+		BufferData(ARRAY_BUFFER, m.vertexCapacity*stride, nil, m.usage)
+	}
+	m.uploadVertices(b, 0, count)
+	m.markVertices(0, count)
+	return nil
+}
+
+// UpdateVertices replaces the vertices from first on with streams, updating only that range of the buffers. The
+// vertices past the end are appended, first must not exceed VertexCount.
+func (m *DynamicMesh) UpdateVertices(first int, streams [][]byte) error {
+	count, err := m.layout.Validate(streams)
+	if err != nil {
+		return err
+	}
+	if first < 0 || first > m.vertexCount {
+		return fmt.Errorf("gl: update from vertex %v of %v", first, m.vertexCount)
+	}
+	end := first + count
+	for i, stream := range streams {
+		stride := int(m.layout.Strides[i])
+		if end*stride > len(m.streams[i]) {
+			m.streams[i] = append(m.streams[i], make([]byte, end*stride-len(m.streams[i]))...)
+		}
+		copy(m.streams[i][first*stride:], stream)
+	}
+	if end > m.vertexCount {
+		m.vertexCount = end
+	}
+	if m.vertexCount > m.vertexCapacity {
+		m.growVertices()
+		return nil
+	}
+	m.uploadVertices(&m.copies[m.current], first, end)
+	m.markVertices(first, end)
+	return nil
+}
+
+// SetIndices replaces the indices, without indices the vertices are drawn in order. The storage of the buffer is
+// orphaned rather than waited for.
+func (m *DynamicMesh) SetIndices(indices []uint32) {
+	m.indices = append(m.indices[:0], indices...)
+	if len(indices) > m.indexCapacity {
+		m.growIndices()
+		return
+	}
+	b := &m.copies[m.current]
+	BindVertexArray(b.vao)
+	BufferData(ELEMENT_ARRAY_BUFFER, m.indexCapacity*4, nil, m.usage)
+	BindVertexArray(0)
+	m.uploadIndices(b, 0, len(indices))
+	m.markIndices(0, len(indices))
+}
+
+// UpdateIndices replaces the indices from first on, updating only that range of the buffer. The indices past the
+// end are appended, first must not exceed len(Indices()).
+func (m *DynamicMesh) UpdateIndices(first int, indices []uint32) error {
+	if first < 0 || first > len(m.indices) {
+		return fmt.Errorf("gl: update from index %v of %v", first, len(m.indices))
+	}
+	end := first + len(indices)
+	if end > len(m.indices) {
+		m.indices = append(m.indices, make([]uint32, end-len(m.indices))...)
+	}
+	copy(m.indices[first:], indices)
+	if len(m.indices) > m.indexCapacity {
+		m.growIndices()
+		return nil
+	}
+	m.uploadIndices(&m.copies[m.current], first, end)
+	m.markIndices(first, end)
+	return nil
+}
+
+// Next makes the next copy of the buffers current and brings it up to date, call it once per frame before the
+// updates. It does nothing with a single copy.
+func (m *DynamicMesh) Next() {
+	if len(m.copies) == 1 {
+		return
+	}
+	m.current = (m.current + 1) % len(m.copies)
+	b := &m.copies[m.current]
+	if first, end := b.dirtyVertices[0], b.dirtyVertices[1]; end > first {
+		m.uploadVertices(b, first, end)
+	}
+	if first, end := b.dirtyIndices[0], b.dirtyIndices[1]; end > first {
+		m.uploadIndices(b, first, end)
+	}
+	b.dirtyVertices, b.dirtyIndices = [2]int{}, [2]int{}
+}
+
+// Draw draws the current copy, the indexed primitives if the mesh has indices.
+func (m *DynamicMesh) Draw(shader *Shader) {
+	if m.vertexCount == 0 {
+		return
+	}
+	bindMaterial(shader, &m.material)
+
+	BindVertexArray(m.copies[m.current].vao)
+	if len(m.indices) > 0 {
+		DrawElements(m.mode, int32(len(m.indices)), UNSIGNED_INT, 0)
+	} else {
+		DrawArrays(m.mode, 0, int32(m.vertexCount))
+	}
+	BindVertexArray(0)
+
+	ActiveTexture(TEXTURE0)
+}
+
+// Delete frees the vertex arrays and buffers of every copy, the textures are left alone. A nil mesh is ignored.
+func (m *DynamicMesh) Delete() {
+	if m == nil {
+		return
+	}
+	for i := range m.copies {
+		b := &m.copies[i]
+		DeleteVertexArrays(1, &b.vao)
+		untrackObject("vertex array", b.vao)
+		for j := range b.vbos {
+			DeleteBuffers(1, &b.vbos[j])
+			untrackObject("buffer", b.vbos[j])
+		}
+		DeleteBuffers(1, &b.ebo)
+		untrackObject("buffer", b.ebo)
+	}
+	m.copies = nil
+	m.streams, m.indices = nil, nil
+	m.vertexCount, m.vertexCapacity, m.indexCapacity = 0, 0, 0
+}
+
+// growVertices at least doubles the capacity of the vertex buffers to hold the vertices, the current copy receives
+// them all and the other copies when they become current.
+func (m *DynamicMesh) growVertices() {
+	m.vertexCapacity = grow(m.vertexCapacity, m.vertexCount)
+	for c := range m.copies {
+		b := &m.copies[c]
+		for i := range m.streams {
+			stride := int(m.layout.Strides[i])
+			BindBuffer(ARRAY_BUFFER, b.vbos[i])
+			BufferData(ARRAY_BUFFER, m.vertexCapacity*stride, nil, m.usage)
+		}
+	}
+	m.uploadVertices(&m.copies[m.current], 0, m.vertexCount)
+	m.markVertices(0, m.vertexCount)
+}
+
+// growIndices at least doubles the capacity of the index buffers like growVertices.
+func (m *DynamicMesh) growIndices() {
+	m.indexCapacity = grow(m.indexCapacity, len(m.indices))
+	for c := range m.copies {
+		BindVertexArray(m.copies[c].vao)
+		BufferData(ELEMENT_ARRAY_BUFFER, m.indexCapacity*4, nil, m.usage)
+	}
+	BindVertexArray(0)
+	m.uploadIndices(&m.copies[m.current], 0, len(m.indices))
+	m.markIndices(0, len(m.indices))
+}
+
+func grow(capacity, needed int) int {
+	if capacity *= 2; capacity < needed {
+		capacity = needed
+	}
+	return capacity
+}
+
+func (m *DynamicMesh) uploadVertices(b *dynamicBuffers, first, end int) {
+	if end > m.vertexCount {
+		end = m.vertexCount
+	}
+	if end <= first {
+		return
+	}
+	for i, stream := range m.streams {
+		stride := int(m.layout.Strides[i])
+		BindBuffer(ARRAY_BUFFER, b.vbos[i])
+		BufferSubData(ARRAY_BUFFER, first*stride, (end-first)*stride, unsafe.Pointer(&stream[first*stride]))
+	}
+}
+
+func (m *DynamicMesh) uploadIndices(b *dynamicBuffers, first, end int) {
+	if end > len(m.indices) {
+		end = len(m.indices)
+	}
+	if end <= first {
+		return
+	}
+	// the element array buffer binding belongs to the vertex array
+	BindVertexArray(b.vao)
+	BufferSubData(ELEMENT_ARRAY_BUFFER, first*4, (end-first)*4, unsafe.Pointer(&m.indices[first]))
+	BindVertexArray(0)
+}
+
+// markVertices adds the range to the outdated vertices of the copies that aren't current.
+func (m *DynamicMesh) markVertices(first, end int) {
+	for i := range m.copies {
+		if i != m.current {
+			m.copies[i].dirtyVertices = union(m.copies[i].dirtyVertices, first, end)
+		}
+	}
+}
+
+func (m *DynamicMesh) markIndices(first, end int) {
+	for i := range m.copies {
+		if i != m.current {
+			m.copies[i].dirtyIndices = union(m.copies[i].dirtyIndices, first, end)
+		}
+	}
+}
+
+// union returns the smallest range holding r and [first, end).
+func union(r [2]int, first, end int) [2]int {
+	if end <= first {
+		return r
+	}
+	if r[1] <= r[0] {
+		return [2]int{first, end}
+	}
+	if first < r[0] {
+		r[0] = first
+	}
+	if end > r[1] {
+		r[1] = end
+	}
+	return r
+}
@@ -0,0 +1,260 @@
+package gl
+
+import (
+	"testing"
+)
+
+// bufferUpload is a BufferData or BufferSubData call with the buffer it went to.
+type bufferUpload struct {
+	buffer uint32
+	// sub is set for BufferSubData, whose range is offset and size, BufferData allocates size bytes
+	sub    bool
+	offset int
+	size   int
+}
+
+// bufferUploads replays the buffer bindings of calls to find the buffer of every upload from the call at index from
+// on, the element array buffer binding belonging to the bound vertex array.
+func bufferUploads(calls []Call, from int) []bufferUpload {
+	var array, vao uint32
+	elements := make(map[uint32]uint32)
+	var uploads []bufferUpload
+	for i, c := range calls {
+		switch c.Name {
+		case "BindVertexArray":
+			vao = c.Args[0].(uint32)
+		case "BindBuffer":
+			switch c.Args[0].(uint32) {
+			case ARRAY_BUFFER:
+				array = c.Args[1].(uint32)
+			case ELEMENT_ARRAY_BUFFER:
+				elements[vao] = c.Args[1].(uint32)
+			}
+		case "BufferData", "BufferSubData":
+			u := bufferUpload{buffer: array, sub: c.Name == "BufferSubData"}
+			if c.Args[0].(uint32) == ELEMENT_ARRAY_BUFFER {
+				u.buffer = elements[vao]
+			}
+			if u.sub {
+				u.offset, u.size = c.Args[1].(int), c.Args[2].(int)
+			} else {
+				u.size = c.Args[1].(int)
+			}
+			if i >= from {
+				uploads = append(uploads, u)
+			}
+		}
+	}
+	return uploads
+}
+
+// uploadChecker checks the uploads recorded since its last check.
+type uploadChecker struct {
+	rb   *RecordingBackend
+	mark int
+}
+
+// skip ignores the uploads recorded so far.
+func (c *uploadChecker) skip() {
+	c.mark = len(c.rb.Calls())
+}
+
+func (c *uploadChecker) check(t *testing.T, want ...bufferUpload) {
+	t.Helper()
+	got := bufferUploads(c.rb.Calls(), c.mark)
+	if len(got) != len(want) {
+		t.Errorf("uploads %+v, want %+v", got, want)
+	} else {
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("uploads %+v, want %+v", got, want)
+				break
+			}
+		}
+	}
+	c.skip()
+}
+
+// positionLayout is a single stream of 12 byte positions.
+var positionLayout = VertexLayout{
+	Strides:    []int32{12},
+	Attributes: []VertexAttribute{{Location: 0, Components: 3, Type: FLOAT}},
+}
+
+func positions(n int) [][]byte {
+	return [][]byte{make([]byte, 12*n)}
+}
+
+func TestDynamicMeshGrowth(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	uc := &uploadChecker{rb: rb}
+	m := NewDynamicMesh(positionLayout, Material{}, DynamicMeshOptions{})
+	defer m.Delete()
+	vbo, ebo := m.copies[0].vbos[0], m.copies[0].ebo
+	uc.skip()
+
+	if err := m.SetVertices(positions(3)); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t, bufferUpload{vbo, false, 0, 36}, bufferUpload{vbo, true, 0, 36})
+	// the capacity at least doubles
+	if err := m.SetVertices(positions(5)); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t, bufferUpload{vbo, false, 0, 72}, bufferUpload{vbo, true, 0, 60})
+	if err := m.UpdateVertices(5, positions(8)); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t, bufferUpload{vbo, false, 0, 13 * 12}, bufferUpload{vbo, true, 0, 13 * 12})
+	if m.VertexCount() != 13 || m.vertexCapacity != 13 {
+		t.Errorf("%v vertices, capacity %v, want 13, 13", m.VertexCount(), m.vertexCapacity)
+	}
+
+	m.SetIndices([]uint32{0, 1, 2})
+	uc.check(t, bufferUpload{ebo, false, 0, 12}, bufferUpload{ebo, true, 0, 12})
+	if err := m.UpdateIndices(3, []uint32{3, 4, 5, 6}); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t, bufferUpload{ebo, false, 0, 28}, bufferUpload{ebo, true, 0, 28})
+}
+
+func TestDynamicMeshOrphaning(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	uc := &uploadChecker{rb: rb}
+	m := NewDynamicMesh(positionLayout, Material{}, DynamicMeshOptions{Usage: STREAM_DRAW})
+	defer m.Delete()
+	vbo, ebo := m.copies[0].vbos[0], m.copies[0].ebo
+	if err := m.SetVertices(positions(8)); err != nil {
+		t.Fatal(err)
+	}
+	m.SetIndices(make([]uint32, 6))
+	uc.skip()
+
+	// replacing the contents orphans the storage at its full capacity before writing, even when they shrink
+	if err := m.SetVertices(positions(2)); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t, bufferUpload{vbo, false, 0, 96}, bufferUpload{vbo, true, 0, 24})
+	m.SetIndices(make([]uint32, 6))
+	uc.check(t, bufferUpload{ebo, false, 0, 24}, bufferUpload{ebo, true, 0, 24})
+	for _, c := range rb.CallsTo("BufferData") {
+		if c.Args[2] != nil || c.Args[3].(uint32) != STREAM_DRAW {
+			t.Errorf("orphaning call %v", c)
+		}
+	}
+	if m.vertexCapacity != 8 || m.indexCapacity != 6 {
+		t.Errorf("capacities %v, %v, want 8, 6", m.vertexCapacity, m.indexCapacity)
+	}
+}
+
+func TestDynamicMeshUpdateRange(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	uc := &uploadChecker{rb: rb}
+	m := NewDynamicMesh(positionLayout, Material{}, DynamicMeshOptions{})
+	defer m.Delete()
+	vbo, ebo := m.copies[0].vbos[0], m.copies[0].ebo
+	if err := m.SetVertices(positions(6)); err != nil {
+		t.Fatal(err)
+	}
+	m.SetIndices([]uint32{0, 1, 2, 3, 4, 5})
+	uc.skip()
+
+	// only the updated ranges are written, appending within the capacity doesn't allocate
+	if err := m.UpdateVertices(1, positions(2)); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t, bufferUpload{vbo, true, 12, 24})
+	if err := m.UpdateIndices(2, []uint32{5, 4}); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t, bufferUpload{ebo, true, 8, 8})
+	if got := m.Indices(); got[1] != 1 || got[2] != 5 || got[3] != 4 || got[4] != 4 {
+		t.Errorf("indices %v", got)
+	}
+	if err := m.UpdateVertices(6, positions(0)); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t)
+
+	if err := m.UpdateVertices(7, positions(1)); err == nil {
+		t.Errorf("update past the end succeeded")
+	}
+	if err := m.UpdateVertices(-1, positions(1)); err == nil {
+		t.Errorf("update before the start succeeded")
+	}
+	if err := m.UpdateVertices(0, [][]byte{make([]byte, 10)}); err == nil {
+		t.Errorf("update with a partial vertex succeeded")
+	}
+	if err := m.UpdateIndices(7, []uint32{0}); err == nil {
+		t.Errorf("index update past the end succeeded")
+	}
+	uc.check(t)
+	if m.VertexCount() != 6 || len(m.Indices()) != 6 {
+		t.Errorf("failed updates changed the mesh: %v vertices, %v indices", m.VertexCount(), len(m.Indices()))
+	}
+}
+
+func TestDynamicMeshNext(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	uc := &uploadChecker{rb: rb}
+	m := NewDynamicMesh(positionLayout, Material{}, DynamicMeshOptions{Mode: LINES, Buffers: 3})
+	defer m.Delete()
+	vbo := func(i int) uint32 { return m.copies[i].vbos[0] }
+	ebo := func(i int) uint32 { return m.copies[i].ebo }
+
+	if err := m.SetVertices(positions(4)); err != nil {
+		t.Fatal(err)
+	}
+	m.SetIndices([]uint32{0, 1, 2, 3})
+	uc.skip()
+
+	// the copies that weren't current receive everything they missed
+	m.Next()
+	uc.check(t, bufferUpload{vbo(1), true, 0, 48}, bufferUpload{ebo(1), true, 0, 16})
+	if err := m.UpdateVertices(2, positions(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.UpdateIndices(0, []uint32{3}); err != nil {
+		t.Fatal(err)
+	}
+	uc.check(t, bufferUpload{vbo(1), true, 24, 12}, bufferUpload{ebo(1), true, 0, 4})
+	m.Next()
+	uc.check(t, bufferUpload{vbo(2), true, 0, 48}, bufferUpload{ebo(2), true, 0, 16})
+	// the missed ranges of copy 0 merged into one
+	if err := m.UpdateVertices(0, positions(1)); err != nil {
+		t.Fatal(err)
+	}
+	uc.skip()
+	m.Next()
+	uc.check(t, bufferUpload{vbo(0), true, 0, 36}, bufferUpload{ebo(0), true, 0, 4})
+	// copy 1 only missed the update of vertex 0, copy 2 was current then
+	m.Next()
+	uc.check(t, bufferUpload{vbo(1), true, 0, 12})
+	m.Next()
+	uc.check(t)
+	m.Next()
+	uc.check(t)
+
+	// a copy whose data was dropped by the growth is uploaded whole
+	if err := m.UpdateVertices(4, positions(4)); err != nil {
+		t.Fatal(err)
+	}
+	uc.skip()
+	m.Next()
+	uc.check(t, bufferUpload{vbo(1), true, 0, 96})
+
+	shader := Shader{id: CreateProgram()}
+	rb.Reset()
+	m.Draw(&shader)
+	if calls := rb.CallsTo("BindVertexArray"); len(calls) == 0 || calls[0].Args[0].(uint32) != m.copies[1].vao {
+		t.Errorf("draw binds %v, want the vertex array %v of the current copy", calls, m.copies[1].vao)
+	}
+	if draws := rb.CallsTo("DrawElements"); len(draws) != 1 || draws[0].Args[0].(uint32) != LINES ||
+		draws[0].Args[1].(int32) != 4 {
+		t.Errorf("draw calls %v", draws)
+	}
+}
@@ -0,0 +1,92 @@
+package gl
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+type glObject struct {
+	kind string
+	id   uint32
+}
+
+var (
+	leakTracking bool
+	liveObjects  = make(map[glObject]string)
+)
+
+// LeakedObject is a GL object created by this package that was never deleted.
+type LeakedObject struct {
+	// Kind is one of "program", "vertex array", "buffer" or "texture"
+	Kind string
+	Id   uint32
+	// Origin is the file:line that created the object
+	Origin string
+}
+
+func (o LeakedObject) String() string {
+	return fmt.Sprintf("%v %v created at %v", o.Kind, o.Id, o.Origin)
+}
+
+// EnableLeakTracking starts or stops recording the programs, vertex arrays, buffers and textures created by Shader,
+// Mesh, UniformBuffer and TextureFromFile until they are deleted. It is meant for debugging and should be enabled
+// before any object is created.
+func EnableLeakTracking(enable bool) {
+	leakTracking = enable
+	if !enable {
+		liveObjects = make(map[glObject]string)
+	}
+}
+
+func trackObject(kind string, id uint32) {
+	if !leakTracking || id == 0 {
+		return
+	}
+	// the origin is the first caller outside of this package
+	origin := "unknown"
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "learn_opengl/gl.") {
+			origin = fmt.Sprintf("%v:%v", frame.File, frame.Line)
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	liveObjects[glObject{kind: kind, id: id}] = origin
+}
+
+func untrackObject(kind string, id uint32) {
+	delete(liveObjects, glObject{kind: kind, id: id})
+}
+
+// Leaks returns the tracked objects that are still alive, sorted by kind and id.
+func Leaks() []LeakedObject {
+	leaks := make([]LeakedObject, 0, len(liveObjects))
+	for o, origin := range liveObjects {
+		leaks = append(leaks, LeakedObject{Kind: o.kind, Id: o.id, Origin: origin})
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		if leaks[i].Kind != leaks[j].Kind {
+			return leaks[i].Kind < leaks[j].Kind
+		}
+		return leaks[i].Id < leaks[j].Id
+	})
+	return leaks
+}
+
+// ReportLeaks logs the objects that are still alive and returns their number, call it before the context is
+// destroyed.
+func ReportLeaks() int {
+	leaks := Leaks()
+	for _, o := range leaks {
+		log.Printf("leaked %v", o)
+	}
+	return len(leaks)
+}
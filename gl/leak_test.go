@@ -0,0 +1,58 @@
+package gl
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestLeaks(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	EnableLeakTracking(true)
+	defer EnableLeakTracking(false)
+
+	vertices := []Vertex{{Position: mgl32.Vec3{0, 0, 0}}, {Position: mgl32.Vec3{1, 0, 0}}, {Position: mgl32.Vec3{0, 1, 0}}}
+	deleted := NewMesh(vertices, []uint32{0, 1, 2}, nil)
+	leaked := NewMesh(vertices, []uint32{0, 1, 2}, nil)
+	deleted.Delete()
+	ubo, err := NewUniformBuffer("Matrices", 0, &struct {
+		View mgl32.Mat4 `glsl:"view"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ubo.Delete()
+
+	want := map[LeakedObject]bool{{Kind: "vertex array", Id: leaked.vao}: true, {Kind: "buffer", Id: leaked.ebo}: true}
+	for _, vbo := range leaked.vbos {
+		want[LeakedObject{Kind: "buffer", Id: vbo}] = true
+	}
+	leaks := Leaks()
+	if len(leaks) != len(want) {
+		t.Errorf("leaks %v, want the %v objects of the mesh that wasn't deleted", leaks, len(want))
+	}
+	for i, o := range leaks {
+		if !want[LeakedObject{Kind: o.Kind, Id: o.Id}] {
+			t.Errorf("leaked %v", o)
+		}
+		if i > 0 && (leaks[i-1].Kind > o.Kind || leaks[i-1].Kind == o.Kind && leaks[i-1].Id >= o.Id) {
+			t.Errorf("leaks %v aren't sorted by kind and id", leaks)
+		}
+	}
+
+	leaked.Delete()
+	if leaks = Leaks(); len(leaks) != 0 {
+		t.Errorf("leaks %v after deleting every object", leaks)
+	}
+}
+
+func TestLeakTrackingDisabled(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+
+	NewMesh([]Vertex{{}, {}, {}}, []uint32{0, 1, 2}, nil)
+	if leaks := Leaks(); len(leaks) != 0 {
+		t.Errorf("leaks %v without tracking", leaks)
+	}
+}
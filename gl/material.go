@@ -0,0 +1,96 @@
+package gl
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// uniform struct Mesh.Draw uploads the material of the mesh to
+	MATERIAL_UNIFORM = "material"
+	// shininess of a material that doesn't specify one
+	DEFAULT_SHININESS = 32.0
+)
+
+// Material holds the surface properties of a mesh. Mesh.Draw uploads the tagged fields to the uniform struct
+// MATERIAL_UNIFORM, together with flags telling which texture maps are bound:
+//
+//	struct Material {
+//	    vec3 ambient;
+//	    vec3 diffuse;
+//	    vec3 specular;
+//	    vec3 emissive;
+//	    float shininess;
+//	    float opacity;
+//	    float metallic;
+//	    float roughness;
+//	    bool twoSided;
+//	    bool hasDiffuseMap;
+//	    bool hasSpecularMap;
+//	    bool hasNormalMap;
+//	    bool hasHeightMap;
+//	    bool hasMetallicRoughnessMap;
+//	    bool hasOcclusionMap;
+//	    bool hasEmissiveMap;
+//	};
+//	uniform Material material;
+//
+// Shaders may declare only the members they use.
+type Material struct {
+	Name      string
+	Ambient   mgl32.Vec3 `glsl:"ambient"`
+	Diffuse   mgl32.Vec3 `glsl:"diffuse"`
+	Specular  mgl32.Vec3 `glsl:"specular"`
+	Emissive  mgl32.Vec3 `glsl:"emissive"`
+	Shininess float32    `glsl:"shininess"`
+	Opacity   float32    `glsl:"opacity"`
+	// Metallic and Roughness are the factors of the metallic-roughness PBR model, they scale the blue and green
+	// channels of the texture_metallicRoughness map
+	Metallic  float32 `glsl:"metallic"`
+	Roughness float32 `glsl:"roughness"`
+	// TwoSided faces must not be culled, shaders may flip the normal of back faces
+	TwoSided bool `glsl:"twoSided"`
+	// Textures are bound to the samplers texture_diffuseN, texture_specularN, texture_normalN and texture_heightN
+	// following their type, the PBR maps to texture_metallicRoughness, texture_occlusion and texture_emissive
+	Textures []Texture
+}
+
+// DefaultMaterial returns a white, opaque, dielectric and fully rough material without textures.
+func DefaultMaterial() Material {
+	return Material{
+		Diffuse:   mgl32.Vec3{1.0, 1.0, 1.0},
+		Shininess: DEFAULT_SHININESS,
+		Opacity:   1.0,
+		Roughness: 1.0,
+	}
+}
+
+// HasMap tells whether the material has a texture of the type, e.g. "texture_diffuse".
+func (m *Material) HasMap(typ string) bool {
+	for i := range m.Textures {
+		if m.Textures[i].typ == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// upload sets the material uniforms of shader, the textures are bound by bindMaterial. It runs on every draw, so
+// it sets the members directly rather than through SetStruct.
+func (m *Material) upload(shader *Shader) {
+	shader.SetVec3(MATERIAL_UNIFORM+".ambient", &m.Ambient)
+	shader.SetVec3(MATERIAL_UNIFORM+".diffuse", &m.Diffuse)
+	shader.SetVec3(MATERIAL_UNIFORM+".specular", &m.Specular)
+	shader.SetVec3(MATERIAL_UNIFORM+".emissive", &m.Emissive)
+	shader.SetFloat32(MATERIAL_UNIFORM+".shininess", m.Shininess)
+	shader.SetFloat32(MATERIAL_UNIFORM+".opacity", m.Opacity)
+	shader.SetFloat32(MATERIAL_UNIFORM+".metallic", m.Metallic)
+	shader.SetFloat32(MATERIAL_UNIFORM+".roughness", m.Roughness)
+	shader.SetBool(MATERIAL_UNIFORM+".twoSided", m.TwoSided)
+	shader.SetBool(MATERIAL_UNIFORM+".hasDiffuseMap", m.HasMap("texture_diffuse"))
+	shader.SetBool(MATERIAL_UNIFORM+".hasSpecularMap", m.HasMap("texture_specular"))
+	shader.SetBool(MATERIAL_UNIFORM+".hasNormalMap", m.HasMap("texture_normal"))
+	shader.SetBool(MATERIAL_UNIFORM+".hasHeightMap", m.HasMap("texture_height"))
+	shader.SetBool(MATERIAL_UNIFORM+".hasMetallicRoughnessMap", m.HasMap("texture_metallicRoughness"))
+	shader.SetBool(MATERIAL_UNIFORM+".hasOcclusionMap", m.HasMap("texture_occlusion"))
+	shader.SetBool(MATERIAL_UNIFORM+".hasEmissiveMap", m.HasMap("texture_emissive"))
+}
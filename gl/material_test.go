@@ -0,0 +1,58 @@
+package gl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// uniformValues returns the value set to every uniform name by the recorded calls.
+func uniformValues(rb *RecordingBackend, program uint32) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, c := range rb.Calls() {
+		switch c.Name {
+		case "Uniform1i", "Uniform1f", "Uniform3fv":
+			values[rb.UniformName(program, c.Args[0].(int32))] = c.Args[len(c.Args)-1]
+		}
+	}
+	return values
+}
+
+func TestMaterialUploadMatchesTags(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	shader := Shader{id: CreateProgram()}
+
+	m := Material{
+		Ambient:   mgl32.Vec3{0.1, 0.2, 0.3},
+		Diffuse:   mgl32.Vec3{0.4, 0.5, 0.6},
+		Specular:  mgl32.Vec3{0.7, 0.8, 0.9},
+		Emissive:  mgl32.Vec3{1, 2, 3},
+		Shininess: 64,
+		Opacity:   0.5,
+		Metallic:  0.25,
+		Roughness: 0.75,
+		TwoSided:  true,
+		Textures:  []Texture{NewTexture(1, "texture_normal", "n.png")},
+	}
+	m.upload(&shader)
+	uploaded := uniformValues(rb, shader.id)
+
+	rb.Reset()
+	if err := shader.SetStruct(MATERIAL_UNIFORM, &m); err != nil {
+		t.Fatal(err)
+	}
+	tagged := uniformValues(rb, shader.id)
+	if len(tagged) != 9 {
+		t.Errorf("SetStruct set %v members: %v", len(tagged), tagged)
+	}
+	for name, want := range tagged {
+		if got := uploaded[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("upload set %v to %v, SetStruct to %v", name, got, want)
+		}
+	}
+	if uploaded[MATERIAL_UNIFORM+".hasNormalMap"] != int32(1) || uploaded[MATERIAL_UNIFORM+".hasDiffuseMap"] != int32(0) {
+		t.Errorf("map flags %v", uploaded)
+	}
+}
@@ -1,6 +1,8 @@
 package gl
 
 import (
+	"fmt"
+	"log"
 	"strconv"
 	"unsafe"
 
@@ -28,10 +30,6 @@ type Vertex struct {
 	Weights [MAX_BONE_INFLUENCE]float32
 }
 
-var (
-	_dummyVertex = Vertex{}
-)
-
 type Texture struct {
 	id   uint32
 	typ  string
@@ -54,22 +52,87 @@ func (t Texture) Path() string {
 	return t.path
 }
 
+// Delete frees the texture. Textures are shared by value between meshes, so it is up to the owner, e.g. the
+// model that loaded them, to delete each of them once.
+func (t Texture) Delete() {
+	if t.id == 0 {
+		return
+	}
+	DeleteTextures(1, &t.id)
+	untrackObject("texture", t.id)
+}
+
 type Mesh struct {
-	vertices []Vertex
-	indices  []uint32
-	textures []Texture
-	vao      uint32
-	vbo      uint32
-	ebo      uint32
+	indices     []uint32
+	vertexCount int
+	material    Material
+	vao         uint32
+	// vbos holds a buffer per stream of the vertex layout
+	vbos []uint32
+	ebo  uint32
+	// bounds and sphere contain the positions of the vertices, in mesh space
+	bounds AABB
+	sphere BoundingSphere
 }
 
+// NewMesh creates a mesh with the default material and textures.
 func NewMesh(vertices []Vertex, indices []uint32, textures []Texture) Mesh {
+	material := DefaultMaterial()
+	material.Textures = textures
+	return NewMeshWithMaterial(vertices, indices, material)
+}
+
+func NewMeshWithMaterial(vertices []Vertex, indices []uint32, material Material) Mesh {
+	return NewMeshWithLayout(DefaultVertexLayout(), [][]byte{VertexBytes(vertices)}, indices, material)
+}
+
+// LoadMeshWithLayout creates a mesh from vertices in any layout, streams holds the bytes of every stream of the
+// layout, see VertexBytes. Without indices the vertices are drawn in order. It fails, before creating any GL object,
+// if the streams don't match the layout or an index is out of range.
+func LoadMeshWithLayout(layout VertexLayout, streams [][]byte, indices []uint32, material Material) (Mesh, error) {
+	return loadMesh(layout, streams, indices, material, nil, nil)
+}
+
+// NewMeshWithBounds is NewMeshWithMaterial with the bounds of the vertices already known, e.g. read from a cache,
+// instead of computed from them.
+func NewMeshWithBounds(vertices []Vertex, indices []uint32, material Material, bounds AABB, sphere BoundingSphere) Mesh {
+	mesh, err := loadMesh(DefaultVertexLayout(), [][]byte{VertexBytes(vertices)}, indices, material, &bounds, &sphere)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return mesh
+}
+
+// loadMesh is LoadMeshWithLayout, the bounds are computed from the vertices if nil.
+func loadMesh(layout VertexLayout, streams [][]byte, indices []uint32, material Material, bounds *AABB, sphere *BoundingSphere) (Mesh, error) {
+	count, err := layout.Validate(streams)
+	if err != nil {
+		return Mesh{}, err
+	}
+	for _, index := range indices {
+		if int(index) >= count {
+			return Mesh{}, fmt.Errorf("gl: index %v of a mesh of %v vertices", index, count)
+		}
+	}
 	mesh := Mesh{
-		vertices: vertices,
-		indices:  indices,
-		textures: textures,
+		indices:     indices,
+		vertexCount: count,
+		material:    material,
+	}
+	if bounds != nil && sphere != nil {
+		mesh.bounds, mesh.sphere = *bounds, *sphere
+	} else {
+		mesh.bounds, mesh.sphere = layoutBounds(&layout, streams)
+	}
+	mesh.setupMesh(&layout, streams)
+	return mesh, nil
+}
+
+func NewMeshWithLayout(layout VertexLayout, streams [][]byte, indices []uint32, material Material) Mesh {
+	mesh, err := LoadMeshWithLayout(layout, streams, indices, material)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
-	mesh.setupMesh()
 	return mesh
 }
 
@@ -81,7 +144,65 @@ func (m Mesh) Indices() []uint32 {
 	return m.indices
 }
 
+// VertexCount returns the number of vertices of the mesh.
+func (m Mesh) VertexCount() int {
+	return m.vertexCount
+}
+
+// Bounds returns the bounding box of the vertices, computed from the FLOAT positions at location 0 when the mesh was
+// created, empty for layouts without them.
+func (m Mesh) Bounds() AABB {
+	return m.bounds
+}
+
+// BoundingSphere returns a sphere containing the vertices, see Bounds.
+func (m Mesh) BoundingSphere() BoundingSphere {
+	return m.sphere
+}
+
+// Material returns the material of the mesh, changes to it show from the next Draw.
+func (m *Mesh) Material() *Material {
+	return &m.material
+}
+
+// Delete frees the vertex array and buffers of the mesh, its textures are left alone.
+func (m *Mesh) Delete() {
+	if m.vao != 0 {
+		DeleteVertexArrays(1, &m.vao)
+		untrackObject("vertex array", m.vao)
+	}
+	for i := range m.vbos {
+		DeleteBuffers(1, &m.vbos[i])
+		untrackObject("buffer", m.vbos[i])
+	}
+	if m.ebo != 0 {
+		DeleteBuffers(1, &m.ebo)
+		untrackObject("buffer", m.ebo)
+	}
+	m.vao, m.vbos, m.ebo = 0, nil, 0
+}
+
 func (m *Mesh) Draw(shader *Shader) {
+	// bind appropriate textures and the material
+	bindMaterial(shader, &m.material)
+
+	// draw mesh
+	BindVertexArray(m.vao)
+	if m.ebo != 0 {
+		DrawElements(TRIANGLES, int32(len(m.indices)), UNSIGNED_INT, 0)
+	} else {
+		DrawArrays(TRIANGLES, 0, int32(m.vertexCount))
+	}
+	BindVertexArray(0)
+
+	//log.Printf("mesh %p drawn, indices len %v", m, len(m.indices))
+
+	// always good practice to set everything back to defaults once configured
+	ActiveTexture(TEXTURE0)
+}
+
+// bindMaterial binds the textures of material to their samplers and uploads its uniforms.
+func bindMaterial(shader *Shader, material *Material) {
 	// bind appropriate textures
 	var (
 		diffuseNr  = 1
@@ -89,11 +210,12 @@ func (m *Mesh) Draw(shader *Shader) {
 		normalNr   = 1
 		heightNr   = 1
 	)
-	for i := int32(0); i < int32(len(m.textures)); i++ {
+	textures := material.Textures
+	for i := int32(0); i < int32(len(textures)); i++ {
 		ActiveTexture(TEXTURE0 + uint32(i)) // active proper texture unit before binding
 		// retrieve texture number (the N in diffuse_textureN)
 		var number string
-		var name = m.textures[i].typ
+		var name = textures[i].typ
 		if name == "texture_diffuse" {
 			number = strconv.Itoa(diffuseNr)
 			diffuseNr++
@@ -109,63 +231,40 @@ func (m *Mesh) Draw(shader *Shader) {
 		}
 
 		// now set the sampler to the correct texture unit
-		Uniform1i(GetUniformLocation(shader.Id(), name+number+"\x00"), i)
+		shader.SetInt32(name+number, i)
 		// and finally bind the texture
-		BindTexture(TEXTURE_2D, m.textures[i].id)
-
-		//log.Printf("mesh %p bind texture %v", m, m.textures[i].id)
+		BindTexture(TEXTURE_2D, textures[i].id)
 	}
-
-	// draw mesh
-	BindVertexArray(m.vao)
-	DrawElements(TRIANGLES, int32(len(m.indices)), UNSIGNED_INT, 0)
-	BindVertexArray(0)
-
-	//log.Printf("mesh %p drawn, indices len %v", m, len(m.indices))
-
-	// always good practice to set everything back to defaults once configured
-	ActiveTexture(TEXTURE0)
+	// and the colors and map flags of the material
+	material.upload(shader)
 }
 
-func (m *Mesh) setupMesh() {
+func (m *Mesh) setupMesh(layout *VertexLayout, streams [][]byte) {
 	// create buffers/arrays
 	GenVertexArrays(1, &m.vao)
-	GenBuffers(1, &m.vbo)
-	GenBuffers(1, &m.ebo)
-
+	trackObject("vertex array", m.vao)
 	BindVertexArray(m.vao)
-	// load data into vertex buffers
-	BindBuffer(ARRAY_BUFFER, m.vbo)
-	// A great thing about structs is that their memory layout is sequential for all its items.
-	// The effect is that we can simply pass a pointer to the struct and it translates perfectly to a mgl32.Vec3/2 array which
-	// again translates to 3/2 floats which translates to a byte array.
-	BufferData(ARRAY_BUFFER, len(m.vertices)*int(unsafe.Sizeof(_dummyVertex)), unsafe.Pointer(&m.vertices[0]), STATIC_DRAW)
 
-	BindBuffer(ELEMENT_ARRAY_BUFFER, m.ebo)
-	BufferData(ELEMENT_ARRAY_BUFFER, len(m.indices)*4, unsafe.Pointer(&m.indices[0]), STATIC_DRAW)
+	// load data into vertex buffers, one per stream
+	m.vbos = make([]uint32, len(streams))
+	for i, stream := range streams {
+		GenBuffers(1, &m.vbos[i])
+		trackObject("buffer", m.vbos[i])
+		BindBuffer(ARRAY_BUFFER, m.vbos[i])
+		if len(stream) > 0 {
+			BufferData(ARRAY_BUFFER, len(stream), unsafe.Pointer(&stream[0]), STATIC_DRAW)
+		}
+	}
+
+	if len(m.indices) > 0 {
+		GenBuffers(1, &m.ebo)
+		trackObject("buffer", m.ebo)
+		BindBuffer(ELEMENT_ARRAY_BUFFER, m.ebo)
+		BufferData(ELEMENT_ARRAY_BUFFER, len(m.indices)*4, unsafe.Pointer(&m.indices[0]), STATIC_DRAW)
+	}
 
 	// set the vertex attribute pointers
-	// vertex Positions
-	EnableVertexAttribArray(0)
-	VertexAttribPointer(0, 3, FLOAT, false, int32(unsafe.Sizeof(_dummyVertex)), 0)
-	// vertex normals
-	EnableVertexAttribArray(1)
-	VertexAttribPointer(1, 3, FLOAT, false, int32(unsafe.Sizeof(_dummyVertex)), int(unsafe.Offsetof(_dummyVertex.Normal)))
-	// vertex texture coords
-	EnableVertexAttribArray(2)
-	VertexAttribPointer(2, 2, FLOAT, false, int32(unsafe.Sizeof(_dummyVertex)), int(unsafe.Offsetof(_dummyVertex.TexCoords)))
-	// vertex tangent
-	EnableVertexAttribArray(3)
-	VertexAttribPointer(3, 3, FLOAT, false, int32(unsafe.Sizeof(_dummyVertex)), int(unsafe.Offsetof(_dummyVertex.Tangent)))
-	// vertex bitangent
-	EnableVertexAttribArray(4)
-	VertexAttribPointer(4, 3, FLOAT, false, int32(unsafe.Sizeof(_dummyVertex)), int(unsafe.Offsetof(_dummyVertex.Bitangent)))
-	// ids
-	EnableVertexAttribArray(5)
-	VertexAttribIPointer(5, 4, INT, int32(unsafe.Sizeof(_dummyVertex)), int(unsafe.Offsetof(_dummyVertex.BoneIds)))
-	// weights
-	EnableVertexAttribArray(6)
-	VertexAttribPointer(6, 4, FLOAT, false, int32(unsafe.Sizeof(_dummyVertex)), int(unsafe.Offsetof(_dummyVertex.Weights)))
+	layout.Apply(m.vbos)
 
 	BindVertexArray(0)
 }
@@ -0,0 +1,99 @@
+package gl
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestMeshDrawBindsTextureUnits(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+
+	textures := []Texture{
+		NewTexture(11, "texture_diffuse", "a.png"),
+		NewTexture(12, "texture_specular", "b.png"),
+		NewTexture(13, "texture_diffuse", "c.png"),
+		NewTexture(14, "texture_normal", "d.png"),
+		NewTexture(15, "texture_height", "e.png"),
+	}
+	vertices := []Vertex{
+		{Position: mgl32.Vec3{0, 0, 0}},
+		{Position: mgl32.Vec3{1, 0, 0}},
+		{Position: mgl32.Vec3{0, 1, 0}},
+	}
+	mesh := NewMesh(vertices, []uint32{0, 1, 2}, textures)
+	shader := Shader{id: CreateProgram()}
+	rb.Reset()
+	mesh.Draw(&shader)
+
+	samplers := make(map[string]int32)
+	for _, c := range rb.CallsTo("Uniform1i") {
+		samplers[rb.UniformName(shader.id, c.Args[0].(int32))] = c.Args[1].(int32)
+	}
+	for name, unit := range map[string]int32{
+		"texture_diffuse1":  0,
+		"texture_specular1": 1,
+		"texture_diffuse2":  2,
+		"texture_normal1":   3,
+		"texture_height1":   4,
+	} {
+		if got, ok := samplers[name]; !ok || got != unit {
+			t.Errorf("%v set to unit %v (%v), want %v", name, got, ok, unit)
+		}
+		if got := rb.BoundTexture(TEXTURE0 + uint32(unit)); got != textures[unit].Id() {
+			t.Errorf("unit %v has texture %v, want %v", unit, got, textures[unit].Id())
+		}
+	}
+	if samplers["material.hasDiffuseMap"] != 1 || samplers["material.hasOcclusionMap"] != 0 {
+		t.Errorf("map flags %v", samplers)
+	}
+
+	draws := rb.CallsTo("DrawElements")
+	if len(draws) != 1 || draws[0].Args[1].(int32) != 3 {
+		t.Errorf("draw calls %v", draws)
+	}
+	// the active unit is reset after the draw
+	calls := rb.CallsTo("ActiveTexture")
+	if last := calls[len(calls)-1]; last.Args[0].(uint32) != TEXTURE0 {
+		t.Errorf("active texture left at %v", last.Args[0])
+	}
+}
+
+func TestLoadMeshWithLayoutErrors(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+
+	vertices := VertexBytes([]Vertex{{}, {}, {}})
+	layout := DefaultVertexLayout()
+	for _, test := range []struct {
+		name    string
+		layout  VertexLayout
+		streams [][]byte
+		indices []uint32
+	}{
+		{"no streams", layout, nil, nil},
+		{"partial vertex", layout, [][]byte{vertices[:len(vertices)-1]}, nil},
+		{"index out of range", layout, [][]byte{vertices}, []uint32{0, 1, 3}},
+		{"attribute past the stride", VertexLayout{
+			Strides:    []int32{8},
+			Attributes: []VertexAttribute{{Location: 0, Components: 3, Type: FLOAT}},
+		}, [][]byte{make([]byte, 24)}, nil},
+	} {
+		rb.Reset()
+		if _, err := LoadMeshWithLayout(test.layout, test.streams, test.indices, DefaultMaterial()); err == nil {
+			t.Errorf("%v: no error", test.name)
+		}
+		if calls := rb.Calls(); len(calls) != 0 {
+			t.Errorf("%v: made GL calls %v", test.name, calls)
+		}
+	}
+
+	mesh, err := LoadMeshWithLayout(layout, [][]byte{vertices}, []uint32{0, 1, 2}, DefaultMaterial())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mesh.VertexCount() != 3 || mesh.Vao() == 0 {
+		t.Errorf("mesh of %v vertices, vao %v", mesh.VertexCount(), mesh.Vao())
+	}
+}
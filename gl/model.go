@@ -0,0 +1,23 @@
+package gl
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// uniform the model matrix is uploaded to by Model.Draw
+	MODEL_UNIFORM = "model"
+)
+
+// Model is a set of meshes loaded from a file, whatever loader read it: assimp.Model and obj.Model both are.
+type Model interface {
+	// Draw draws the meshes placed by the model matrix model, uploaded to MODEL_UNIFORM
+	Draw(shader *Shader, model mgl32.Mat4)
+	Meshes() []Mesh
+	// Bounds and BoundingSphere contain the meshes as drawn with the identity model matrix, Transform them by
+	// another one
+	Bounds() AABB
+	BoundingSphere() BoundingSphere
+	// Release frees the meshes and textures, the model must not be drawn afterwards
+	Release()
+}
@@ -0,0 +1,89 @@
+package gl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ShaderError is returned by LoadShader and friends when a shader file can't be read, a stage fails to compile or
+// the program fails to link.
+type ShaderError struct {
+	// Stage is one of "VERTEX", "FRAGMENT", "GEOMETRY" or "PROGRAM"
+	Stage string
+	// Path of the shader file, for "PROGRAM" the list of linked files
+	Path string
+	// Lines holds the messages of Log that carry a source location
+	Lines []ShaderErrorLine
+	// Log is the raw info log of the driver
+	Log string
+	// Err is the underlying error when the file or one of its includes could not be read
+	Err error
+}
+
+// ShaderErrorLine is one located message of a driver info log.
+type ShaderErrorLine struct {
+	// Source is the source string number, 0 unless the source contains #line directives that change it
+	Source int
+	// File is the file Source refers to when the shader was preprocessed, see PreprocessShader
+	File string
+	// Line is the line number reported by the driver
+	Line    int
+	Message string
+}
+
+func newShaderError(typ, path, infoLog string) *ShaderError {
+	infoLog = strings.TrimRight(infoLog, "\x00")
+	return &ShaderError{
+		Stage: typ,
+		Path:  path,
+		Lines: ParseShaderInfoLog(infoLog),
+		Log:   infoLog,
+	}
+}
+
+func (e *ShaderError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("Failed to load %v shader file %v: %v", strings.ToLower(e.Stage), e.Path, e.Err)
+	}
+	if e.Stage == "PROGRAM" {
+		return fmt.Sprintf("ERROR::PROGRAM_LINKING_ERROR of type: %v (%v)\n%v", e.Stage, e.Path, e.Log)
+	}
+	return fmt.Sprintf("ERROR::SHADER_COMPILATION_ERROR of type: %v (%v)\n%v", e.Stage, e.Path, e.Log)
+}
+
+func (e *ShaderError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// Mesa:            0:12(5): error: ...
+	// AMD/Intel/Apple: ERROR: 0:12: ...
+	// NVIDIA:          0(12) : error C0000: ...
+	infoLogLineRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`^(\d+):(\d+)\(\d+\):\s*(.*)$`),
+		regexp.MustCompile(`^(?:ERROR|WARNING):\s*(\d+):(\d+):\s*(.*)$`),
+		regexp.MustCompile(`^(\d+)\((\d+)\)\s*:\s*(.*)$`),
+	}
+)
+
+// ParseShaderInfoLog extracts the source locations from a compile info log. It understands the message formats of
+// the Mesa, NVIDIA, AMD, Intel and Apple drivers, lines in other formats are skipped.
+func ParseShaderInfoLog(infoLog string) []ShaderErrorLine {
+	var lines []ShaderErrorLine
+	for _, l := range strings.Split(infoLog, "\n") {
+		l = strings.TrimSpace(strings.TrimRight(l, "\x00"))
+		for _, re := range infoLogLineRegexps {
+			m := re.FindStringSubmatch(l)
+			if m == nil {
+				continue
+			}
+			source, _ := strconv.Atoi(m[1])
+			line, _ := strconv.Atoi(m[2])
+			lines = append(lines, ShaderErrorLine{Source: source, Line: line, Message: m[3]})
+			break
+		}
+	}
+	return lines
+}
@@ -0,0 +1,64 @@
+package gl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShaderInfoLog(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		log  string
+		want []ShaderErrorLine
+	}{
+		{"empty", "", nil},
+		{
+			"mesa",
+			"0:12(5): error: `fragColor' undeclared\n" +
+				"0:12(5): error: value of type vec4 cannot be assigned to variable of type error\n" +
+				"2:40(17): warning: `normal' used uninitialized\n\x00",
+			[]ShaderErrorLine{
+				{Source: 0, Line: 12, Message: "error: `fragColor' undeclared"},
+				{Source: 0, Line: 12, Message: "error: value of type vec4 cannot be assigned to variable of type error"},
+				{Source: 2, Line: 40, Message: "warning: `normal' used uninitialized"},
+			},
+		},
+		{
+			"amd",
+			"ERROR: 0:12: 'fragColor' : undeclared identifier \n" +
+				"ERROR: 0:12: 'assign' :  cannot convert from '4-component vector of float' to 'float'\n" +
+				"ERROR: 2 compilation errors.  No code generated.\n\n",
+			[]ShaderErrorLine{
+				{Source: 0, Line: 12, Message: "'fragColor' : undeclared identifier"},
+				{Source: 0, Line: 12, Message: "'assign' :  cannot convert from '4-component vector of float' to 'float'"},
+			},
+		},
+		{
+			"intel windows",
+			"WARNING: 0:3: '' : #version directive missing\r\n" +
+				"ERROR: 0:7: 'vec3' : syntax error: syntax error\r\n",
+			[]ShaderErrorLine{
+				{Source: 0, Line: 3, Message: "'' : #version directive missing"},
+				{Source: 0, Line: 7, Message: "'vec3' : syntax error: syntax error"},
+			},
+		},
+		{
+			"nvidia",
+			"0(12) : error C1008: undefined variable \"fragColor\"\n" +
+				"1(7) : warning C7050: \"color\" might be used before being initialized\n",
+			[]ShaderErrorLine{
+				{Source: 0, Line: 12, Message: "error C1008: undefined variable \"fragColor\""},
+				{Source: 1, Line: 7, Message: "warning C7050: \"color\" might be used before being initialized"},
+			},
+		},
+		{
+			"unknown format",
+			"Vertex info\n-----------\nshader compiled with warnings\n",
+			nil,
+		},
+	} {
+		if got := ParseShaderInfoLog(c.log); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%v: ParseShaderInfoLog = %#v, want %#v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package gl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PreprocessOptions configures PreprocessShader.
+type PreprocessOptions struct {
+	// Defines are injected as "#define KEY VALUE" right after the #version line, sorted by key
+	Defines map[string]string
+	// ReadFile reads a shader file, defaults to ioutil.ReadFile
+	ReadFile func(path string) ([]byte, error)
+}
+
+// PreprocessedShader is the output of PreprocessShader.
+type PreprocessedShader struct {
+	// Source is the expanded GLSL source
+	Source string
+	// Files lists the root file followed by every included file, the index of a file is the source string number
+	// used for it in the generated #line directives
+	Files []string
+}
+
+// File returns the path of the given source string number, or "" if it is out of range.
+func (p *PreprocessedShader) File(source int) string {
+	if source < 0 || source >= len(p.Files) {
+		return ""
+	}
+	return p.Files[source]
+}
+
+type shaderPreprocessor struct {
+	opts  PreprocessOptions
+	files []string
+	done  map[string]bool
+	stack []string
+	out   strings.Builder
+	// lineOffset is subtracted from the numbers of the #line directives, 1 before GLSL 4.20
+	lineOffset int
+}
+
+// PreprocessShader expands the `#include "file"` directives of the shader at path and injects opts.Defines.
+//
+// Included paths are resolved relative to the including file. Every file is included at most once, so shared files
+// need no #ifndef guards ("#pragma once" is accepted and dropped), and an include cycle is reported as an error.
+// #line directives are inserted around every include and after the injected defines so that the line numbers in
+// driver messages refer to the original files, the source string number is the index into Files. The directives
+// follow the #version of the root file: from GLSL 4.20 and GLSL ES 3.00 the line following "#line N" is line N,
+// before it is line N+1.
+//
+// Includes are expanded unconditionally, they are not evaluated against #if/#ifdef blocks.
+func PreprocessShader(path string, opts PreprocessOptions) (*PreprocessedShader, error) {
+	if opts.ReadFile == nil {
+		opts.ReadFile = ioutil.ReadFile
+	}
+	p := &shaderPreprocessor{
+		opts: opts,
+		done: make(map[string]bool),
+	}
+	if err := p.process(filepath.Clean(path)); err != nil {
+		return nil, err
+	}
+	return &PreprocessedShader{Source: p.out.String(), Files: p.files}, nil
+}
+
+func (p *shaderPreprocessor) process(path string) error {
+	for i, f := range p.stack {
+		if f == path {
+			return fmt.Errorf("include cycle: %v", strings.Join(append(p.stack[i:], path), " -> "))
+		}
+	}
+	if p.done[path] {
+		return nil
+	}
+
+	data, err := p.opts.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	index := len(p.files)
+	p.files = append(p.files, path)
+	p.stack = append(p.stack, path)
+
+	root := index == 0
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	hasVersion := false
+	for _, line := range lines {
+		if directive(line) == "version" {
+			hasVersion = true
+			if root {
+				p.lineOffset = lineOffset(line)
+			}
+			break
+		}
+	}
+	if root && !hasVersion {
+		// GLSL 1.10
+		p.lineOffset = 1
+	}
+	if root && !hasVersion && len(p.opts.Defines) > 0 {
+		p.writeDefines()
+		p.writeLine(1, index)
+	} else if !root {
+		p.writeLine(1, index)
+	}
+
+	for i, line := range lines {
+		switch directive(line) {
+		case "version":
+			if !root {
+				// only the root file may declare the version, keep the line count of the include
+				p.out.WriteString("\n")
+				continue
+			}
+			p.out.WriteString(line + "\n")
+			if len(p.opts.Defines) > 0 {
+				p.writeDefines()
+				p.writeLine(i+2, index)
+			}
+		case "pragma":
+			if isPragmaOnce(line) {
+				p.out.WriteString("\n")
+			} else {
+				p.out.WriteString(line + "\n")
+			}
+		case "include":
+			name, err := includeName(line)
+			if err != nil {
+				return fmt.Errorf("%v:%v: %v", path, i+1, err)
+			}
+			includePath := filepath.Clean(filepath.Join(filepath.Dir(path), name))
+			if p.done[includePath] {
+				p.out.WriteString("\n")
+				continue
+			}
+			if err = p.process(includePath); err != nil {
+				return fmt.Errorf("%v:%v: %v", path, i+1, err)
+			}
+			p.writeLine(i+2, index)
+		default:
+			p.out.WriteString(line + "\n")
+		}
+	}
+
+	p.stack = p.stack[:len(p.stack)-1]
+	p.done[path] = true
+	return nil
+}
+
+func (p *shaderPreprocessor) writeDefines() {
+	keys := make([]string, 0, len(p.opts.Defines))
+	for k := range p.opts.Defines {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v := p.opts.Defines[k]; v != "" {
+			p.out.WriteString("#define " + k + " " + v + "\n")
+		} else {
+			p.out.WriteString("#define " + k + "\n")
+		}
+	}
+}
+
+func (p *shaderPreprocessor) writeLine(line, source int) {
+	p.out.WriteString("#line " + strconv.Itoa(line-p.lineOffset) + " " + strconv.Itoa(source) + "\n")
+}
+
+// lineOffset returns the offset of the #line directives for a "#version" line, 0 if the line following "#line N" is
+// line N, which is the case from GLSL 4.20 and GLSL ES 3.00, 1 if it is line N+1.
+func lineOffset(version string) int {
+	fields := strings.Fields(strings.TrimSpace(strings.TrimSpace(version)[1:]))
+	if len(fields) < 2 {
+		return 1
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 1
+	}
+	es := len(fields) >= 3 && fields[2] == "es"
+	if n >= 420 || es && n >= 300 {
+		return 0
+	}
+	return 1
+}
+
+// directive returns the name of the preprocessor directive on the line, or "" if it is not one.
+func directive(line string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "#") {
+		return ""
+	}
+	line = strings.TrimSpace(line[1:])
+	end := strings.IndexAny(line, " \t\"<")
+	if end < 0 {
+		return line
+	}
+	return line[:end]
+}
+
+func isPragmaOnce(line string) bool {
+	fields := strings.Fields(strings.TrimSpace(strings.TrimSpace(line)[1:]))
+	return len(fields) >= 2 && fields[0] == "pragma" && fields[1] == "once"
+}
+
+func includeName(line string) (string, error) {
+	line = strings.TrimSpace(line)
+	start := strings.IndexAny(line, "\"<")
+	if start < 0 {
+		return "", fmt.Errorf("malformed #include: %v", line)
+	}
+	closing := "\""
+	if line[start] == '<' {
+		closing = ">"
+	}
+	end := strings.Index(line[start+1:], closing)
+	if end <= 0 {
+		return "", fmt.Errorf("malformed #include: %v", line)
+	}
+	return line[start+1 : start+1+end], nil
+}
@@ -0,0 +1,169 @@
+package gl
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// memFiles serves shader files from memory, the keys are cleaned paths.
+func memFiles(files map[string]string) func(string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		if s, ok := files[filepath.Clean(path)]; ok {
+			return []byte(s), nil
+		}
+		return nil, os.ErrNotExist
+	}
+}
+
+func TestPreprocessIncludeOnce(t *testing.T) {
+	files := map[string]string{
+		"main.fs":         "#version 330 core\n#include \"lib/a.glsl\"\n#include \"lib/b.glsl\"\nvoid main() {}\n",
+		"lib/a.glsl":      "#pragma once\n#include \"common.glsl\"\nfloat a;\n",
+		"lib/b.glsl":      "#include \"common.glsl\"\nfloat b;\n",
+		"lib/common.glsl": "float common;\n",
+	}
+	p, err := PreprocessShader("main.fs", PreprocessOptions{ReadFile: memFiles(files)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(p.Source, "float common;"); n != 1 {
+		t.Errorf("common.glsl included %v times, want 1:\n%v", n, p.Source)
+	}
+	if strings.Contains(p.Source, "pragma once") {
+		t.Errorf("#pragma once not dropped:\n%v", p.Source)
+	}
+	want := []string{"main.fs", "lib/a.glsl", "lib/common.glsl", "lib/b.glsl"}
+	if strings.Join(p.Files, ",") != strings.Join(want, ",") {
+		t.Errorf("Files = %v, want %v", p.Files, want)
+	}
+	if p.File(2) != "lib/common.glsl" || p.File(4) != "" || p.File(-1) != "" {
+		t.Errorf("File returned %q, %q, %q", p.File(2), p.File(4), p.File(-1))
+	}
+}
+
+func TestPreprocessCycle(t *testing.T) {
+	files := map[string]string{
+		"main.fs": "#version 330 core\n#include \"a.glsl\"\n",
+		"a.glsl":  "#include \"b.glsl\"\n",
+		"b.glsl":  "#include \"a.glsl\"\n",
+	}
+	_, err := PreprocessShader("main.fs", PreprocessOptions{ReadFile: memFiles(files)})
+	if err == nil || !strings.Contains(err.Error(), "include cycle: a.glsl -> b.glsl -> a.glsl") {
+		t.Errorf("err = %v, want an include cycle", err)
+	}
+
+	files["main.fs"] = "#include \"missing.glsl\"\n"
+	if _, err = PreprocessShader("main.fs", PreprocessOptions{ReadFile: memFiles(files)}); err == nil {
+		t.Errorf("missing include gave no error")
+	}
+	files["main.fs"] = "#include missing.glsl\n"
+	if _, err = PreprocessShader("main.fs", PreprocessOptions{ReadFile: memFiles(files)}); err == nil {
+		t.Errorf("malformed include gave no error")
+	}
+}
+
+func TestPreprocessDefines(t *testing.T) {
+	files := map[string]string{
+		"main.fs":    "#version 330 core\nvoid main() {}\n",
+		"noversion":  "void main() {}\n",
+		"include.fs": "#version 330 core\n#include \"noversion\"\n",
+	}
+	opts := PreprocessOptions{ReadFile: memFiles(files), Defines: map[string]string{"B": "2", "A": ""}}
+	p, err := PreprocessShader("main.fs", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "#version 330 core\n#define A\n#define B 2\n#line 1 0\nvoid main() {}\n"
+	if p.Source != want {
+		t.Errorf("Source = %q, want %q", p.Source, want)
+	}
+
+	// without #version the defines come first
+	if p, err = PreprocessShader("noversion", opts); err != nil {
+		t.Fatal(err)
+	}
+	want = "#define A\n#define B 2\n#line 0 0\nvoid main() {}\n"
+	if p.Source != want {
+		t.Errorf("Source = %q, want %q", p.Source, want)
+	}
+
+	// an include doesn't get the defines again
+	if p, err = PreprocessShader("include.fs", opts); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(p.Source, "#define B"); n != 1 {
+		t.Errorf("defines injected %v times:\n%v", n, p.Source)
+	}
+}
+
+func TestPreprocessLineMapping(t *testing.T) {
+	for _, test := range []struct {
+		version string
+		offset  int
+	}{
+		{"#version 330 core", 1},
+		{"#version 410", 1},
+		{"#version 420 core", 0},
+		{"#version 460", 0},
+		{"#version 100", 1},
+		{"#version 300 es", 0},
+		{"# version 450", 0},
+	} {
+		files := map[string]string{
+			"main.fs":   test.version + "\n// line 2\n#include \"inc.glsl\"\n// line 4\n#include \"inc.glsl\"\n// line 6\n",
+			"inc.glsl":  "// inc 1\n#include \"deep.glsl\"\n// inc 3\n",
+			"deep.glsl": "// deep 1\n",
+		}
+		opts := PreprocessOptions{ReadFile: memFiles(files), Defines: map[string]string{"X": "1"}}
+		p, err := PreprocessShader("main.fs", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, mark := range []struct {
+			text   string
+			line   int
+			source int
+		}{
+			{"// line 2", 2, 0},
+			{"// inc 1", 1, 1},
+			{"// deep 1", 1, 2},
+			{"// inc 3", 3, 1},
+			{"// line 4", 4, 0},
+			{"// line 6", 6, 0},
+		} {
+			line, source := resolveLine(t, p.Source, mark.text, test.offset)
+			if line != mark.line || source != mark.source {
+				t.Errorf("%v: %q is at %v:%v, want %v:%v\n%v", test.version, mark.text, source, line, mark.source,
+					mark.line, p.Source)
+			}
+		}
+	}
+}
+
+// resolveLine returns the line and source string number a compiler assigns to the line with text, following the
+// #line directives with the given offset.
+func resolveLine(t *testing.T, source, text string, offset int) (int, int) {
+	line, number := 1, 0
+	for _, l := range strings.Split(source, "\n") {
+		if l == text {
+			return line, number
+		}
+		if fields := strings.Fields(l); len(fields) == 3 && fields[0] == "#line" {
+			var err error
+			if line, err = strconv.Atoi(fields[1]); err != nil {
+				t.Fatal(err)
+			}
+			if number, err = strconv.Atoi(fields[2]); err != nil {
+				t.Fatal(err)
+			}
+			line += offset
+			continue
+		}
+		line++
+	}
+	t.Fatalf("%q not found", text)
+	return 0, 0
+}
@@ -0,0 +1,155 @@
+package gl
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+const (
+	DEFAULT_SHADER_POLL_INTERVAL = 500 * time.Millisecond
+)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
+
+// ReloadableShader is a Shader that is rebuilt when one of its source files, included files among them, changes on
+// disk. Poll must be called from the render thread, typically once per frame.
+type ReloadableShader struct {
+	opts         ShaderOptions
+	vertexPath   string
+	fragmentPath string
+	geometryPath string
+	shader       Shader
+	stamps       map[string]fileStamp
+	interval     time.Duration
+	lastPoll     time.Time
+	err          error
+}
+
+// NewReloadableShader loads the shader like LoadShaderWithOptions and starts watching its files. The geometry shader
+// is optional and skipped if geometryPath is "".
+func NewReloadableShader(opts ShaderOptions, vertexPath, fragmentPath, geometryPath string) (*ReloadableShader, error) {
+	r := &ReloadableShader{
+		opts:         opts,
+		vertexPath:   vertexPath,
+		fragmentPath: fragmentPath,
+		geometryPath: geometryPath,
+		interval:     DEFAULT_SHADER_POLL_INTERVAL,
+	}
+	shader, files, err := r.load()
+	r.watch(files)
+	if err != nil {
+		return nil, err
+	}
+	r.shader = shader
+	return r, nil
+}
+
+func (r *ReloadableShader) load() (Shader, []string, error) {
+	stages := []string{r.vertexPath, r.fragmentPath}
+	if r.geometryPath != "" {
+		stages = append(stages, r.geometryPath)
+	}
+	files := append([]string(nil), stages...)
+	shader, err := loadShader(&r.opts, &files, r.vertexPath, r.fragmentPath, r.geometryPath)
+	if err != nil {
+		// the stages after the failed one weren't preprocessed, watch their includes too, and the missing files
+		// whose creation may fix the build
+		read := func(path string) ([]byte, error) {
+			files = append(files, path)
+			return ioutil.ReadFile(path)
+		}
+		for _, path := range stages {
+			PreprocessShader(path, PreprocessOptions{Defines: r.opts.Defines, ReadFile: read})
+		}
+	}
+	return shader, files, err
+}
+
+func (r *ReloadableShader) watch(files []string) {
+	r.stamps = make(map[string]fileStamp, len(files))
+	for _, f := range files {
+		r.stamps[f] = statFile(f)
+	}
+}
+
+// Shader returns the current program. The pointer stays valid across reloads.
+func (r *ReloadableShader) Shader() *Shader {
+	return &r.shader
+}
+
+// Files returns the watched files.
+func (r *ReloadableShader) Files() []string {
+	files := make([]string, 0, len(r.stamps))
+	for f := range r.stamps {
+		files = append(files, f)
+	}
+	return files
+}
+
+// SetPollInterval sets the minimal time between two checks of the files, DEFAULT_SHADER_POLL_INTERVAL by default.
+func (r *ReloadableShader) SetPollInterval(interval time.Duration) {
+	r.interval = interval
+}
+
+// Err returns the error of the last failed reload, or nil if the last reload succeeded.
+func (r *ReloadableShader) Err() error {
+	return r.err
+}
+
+// Poll reloads the shader if one of its files changed since the last load. It reports whether the program was
+// replaced, in which case the uniforms have to be set again. If the new sources fail to compile the previous program
+// is kept and the *ShaderError is returned; it is not reported again until the files change once more.
+func (r *ReloadableShader) Poll() (bool, error) {
+	now := time.Now()
+	if now.Sub(r.lastPoll) < r.interval {
+		return false, nil
+	}
+	r.lastPoll = now
+
+	changed := false
+	for f, stamp := range r.stamps {
+		if statFile(f) != stamp {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	if err := r.Reload(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Reload rebuilds the program unconditionally, keeping the previous one if the build fails.
+func (r *ReloadableShader) Reload() error {
+	shader, files, err := r.load()
+	r.watch(files)
+	r.err = err
+	if err != nil {
+		return err
+	}
+	r.shader.Delete()
+	shader.warnUnknown = r.shader.warnUnknown
+	r.shader = shader
+	return nil
+}
+
+// Delete frees the current program.
+func (r *ReloadableShader) Delete() {
+	r.shader.Delete()
+}
@@ -0,0 +1,64 @@
+package gl
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestReloadWatchesEveryStageAfterFailure(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+
+	dir := t.TempDir()
+	write := func(name, source string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	vs := write("shader.vs", "#version 330 core\n#include \"vertex.glsl\"\nvoid main() {}\n")
+	fs := write("shader.fs", "#version 330 core\n#include \"fragment.glsl\"\nvoid main() {}\n")
+	gs := write("shader.gs", "#version 330 core\n#include \"missing.glsl\"\nvoid main() {}\n")
+	write("vertex.glsl", "")
+	write("fragment.glsl", "")
+
+	r, err := NewReloadableShader(ShaderOptions{}, vs, fs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{vs, fs, filepath.Join(dir, "vertex.glsl"), filepath.Join(dir, "fragment.glsl")}
+	checkFiles(t, r.Files(), want)
+
+	// the vertex shader fails, the fragment shader isn't compiled but its include is still watched
+	rb.CompileLog = "0(2) : error C0000: syntax error"
+	if err = r.Reload(); err == nil {
+		t.Fatal("Reload succeeded with a failing compile")
+	}
+	checkFiles(t, r.Files(), want)
+
+	// a missing include of a later stage is watched so that its creation triggers a reload
+	r.geometryPath = gs
+	if err = r.Reload(); err == nil {
+		t.Fatal("Reload succeeded with a failing compile")
+	}
+	checkFiles(t, r.Files(), append(want, gs, filepath.Join(dir, "missing.glsl")))
+}
+
+func checkFiles(t *testing.T, got, want []string) {
+	t.Helper()
+	got = append([]string(nil), got...)
+	want = append([]string(nil), want...)
+	sort.Strings(got)
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("watched %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("watched %v, want %v", got, want)
+		}
+	}
+}
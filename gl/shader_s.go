@@ -1,74 +1,147 @@
 package gl
 
 import (
-	"io/ioutil"
 	"log"
 
 	"github.com/go-gl/mathgl/mgl32"
 )
 
 type Shader struct {
-	id uint32
+	id          uint32
+	uniforms    []UniformInfo
+	blocks      []UniformBlockInfo
+	attributes  []AttributeInfo
+	locations   map[string]int32
+	warnUnknown bool
+	// warned holds the inactive uniforms already logged
+	warned map[string]bool
 }
 
-func vsAndFs(vertexPath, fragmentPath string) (uint32, uint32) {
-	vertexShaderSource, err := ioutil.ReadFile(vertexPath)
+// ShaderOptions configures LoadShaderWithOptions.
+type ShaderOptions struct {
+	// Defines are injected after the #version line of every stage, see PreprocessShader
+	Defines map[string]string
+}
+
+func vsAndFs(opts *ShaderOptions, files *[]string, vertexPath, fragmentPath string) (uint32, uint32, error) {
+	// vertex shader
+	vertexShader, err := compileShaderFile(opts, files, VERTEX_SHADER, "VERTEX", vertexPath)
 	if err != nil {
-		log.Fatalf("Failed to read vertex shader file %v", vertexPath)
+		return 0, 0, err
 	}
-	fragmentShaderSource, err := ioutil.ReadFile(fragmentPath)
+	// fragment shader
+	fragmentShader, err := compileShaderFile(opts, files, FRAGMENT_SHADER, "FRAGMENT", fragmentPath)
 	if err != nil {
-		log.Fatalf("Failed to read fragment shader file %v", fragmentPath)
+		DeleteShader(vertexShader)
+		return 0, 0, err
 	}
-	// vertex shader
-	vertexShader := CreateShader(VERTEX_SHADER)
-	ShaderSource(vertexShader, string(vertexShaderSource)+"\x00")
-	CompileShader(vertexShader)
-	checkCompileErrors(vertexShader, "VERTEX")
-	// fragment shader
-	fragmentShader := CreateShader(FRAGMENT_SHADER)
-	ShaderSource(fragmentShader, string(fragmentShaderSource)+"\x00")
-	CompileShader(fragmentShader)
-	checkCompileErrors(fragmentShader, "FRAGMENT")
-	return vertexShader, fragmentShader
+	return vertexShader, fragmentShader, nil
 }
 
-func NewShader(vertexPath, fragmentPath string) Shader {
-	vertexShader, fragmentShader := vsAndFs(vertexPath, fragmentPath)
-	// shader program
+// compileShaderFile preprocesses and compiles one stage, the files it consists of are appended to files.
+func compileShaderFile(opts *ShaderOptions, files *[]string, xtype uint32, typ, path string) (uint32, error) {
+	source, err := PreprocessShader(path, PreprocessOptions{Defines: opts.Defines})
+	if err != nil {
+		*files = append(*files, path)
+		return 0, &ShaderError{Stage: typ, Path: path, Err: err}
+	}
+	*files = append(*files, source.Files...)
+	shader := CreateShader(xtype)
+	ShaderSource(shader, source.Source+"\x00")
+	if b, ok := backend.(ShaderFileBackend); ok {
+		b.ShaderFile(shader, path)
+	}
+	CompileShader(shader)
+	if err = checkCompileErrors(shader, typ, path); err != nil {
+		DeleteShader(shader)
+		// map the source string numbers of the #line directives back to the files
+		serr := err.(*ShaderError)
+		for i := range serr.Lines {
+			serr.Lines[i].File = source.File(serr.Lines[i].Source)
+		}
+		return 0, serr
+	}
+	return shader, nil
+}
+
+func linkProgram(path string, shaders ...uint32) (uint32, error) {
 	id := CreateProgram()
-	AttachShader(id, vertexShader)
-	AttachShader(id, fragmentShader)
+	for _, shader := range shaders {
+		AttachShader(id, shader)
+	}
 	LinkProgram(id)
-	checkCompileErrors(id, "PROGRAM")
+	err := checkCompileErrors(id, "PROGRAM", path)
 	// delete the shaders as they're linked into our program now and no longer necessary
-	DeleteShader(vertexShader)
-	DeleteShader(fragmentShader)
-	return Shader{id: id}
+	for _, shader := range shaders {
+		DeleteShader(shader)
+	}
+	if err != nil {
+		DeleteProgram(id)
+		return 0, err
+	}
+	return id, nil
 }
 
-func NewShader2(vertexPath, fragmentPath, geometryPath string) Shader {
-	vertexShader, fragmentShader := vsAndFs(vertexPath, fragmentPath)
-	geometryShaderSource, err := ioutil.ReadFile(geometryPath)
+// LoadShader builds a program from a vertex and a fragment shader file. Unlike NewShader it returns a *ShaderError
+// instead of exiting when a file is missing or the driver rejects the sources.
+func LoadShader(vertexPath, fragmentPath string) (Shader, error) {
+	return LoadShaderWithOptions(ShaderOptions{}, vertexPath, fragmentPath, "")
+}
+
+// LoadShader2 is LoadShader with an additional geometry shader.
+func LoadShader2(vertexPath, fragmentPath, geometryPath string) (Shader, error) {
+	return LoadShaderWithOptions(ShaderOptions{}, vertexPath, fragmentPath, geometryPath)
+}
+
+// LoadShaderWithOptions is LoadShader2 with defines, the geometry shader is optional and skipped if geometryPath is "".
+func LoadShaderWithOptions(opts ShaderOptions, vertexPath, fragmentPath, geometryPath string) (Shader, error) {
+	var files []string
+	return loadShader(&opts, &files, vertexPath, fragmentPath, geometryPath)
+}
+
+func loadShader(opts *ShaderOptions, files *[]string, vertexPath, fragmentPath, geometryPath string) (Shader, error) {
+	vertexShader, fragmentShader, err := vsAndFs(opts, files, vertexPath, fragmentPath)
 	if err != nil {
-		log.Fatalf("Failed to read geometry shader file %v", geometryPath)
+		return Shader{}, err
+	}
+	shaders := []uint32{vertexShader, fragmentShader}
+	path := vertexPath + ", " + fragmentPath
+	if geometryPath != "" {
+		geometryShader, err := compileShaderFile(opts, files, GEOMETRY_SHADER, "GEOMETRY", geometryPath)
+		if err != nil {
+			DeleteShader(vertexShader)
+			DeleteShader(fragmentShader)
+			return Shader{}, err
+		}
+		shaders = append(shaders, geometryShader)
+		path += ", " + geometryPath
 	}
-	geometryShader := CreateShader(GEOMETRY_SHADER)
-	ShaderSource(geometryShader, string(geometryShaderSource)+"\x00")
-	CompileShader(geometryShader)
-	checkCompileErrors(geometryShader, "GEOMETRY")
 	// shader program
-	id := CreateProgram()
-	AttachShader(id, vertexShader)
-	AttachShader(id, fragmentShader)
-	AttachShader(id, geometryShader)
-	LinkProgram(id)
-	checkCompileErrors(id, "PROGRAM")
-	// delete the shaders as they're linked into our program now and no longer necessary
-	DeleteShader(vertexShader)
-	DeleteShader(fragmentShader)
-	DeleteShader(geometryShader)
-	return Shader{id: id}
+	id, err := linkProgram(path, shaders...)
+	if err != nil {
+		return Shader{}, err
+	}
+	trackObject("program", id)
+	shader := Shader{id: id}
+	shader.reflect()
+	bindUniformBlocks(&shader)
+	return shader, nil
+}
+
+func NewShader(vertexPath, fragmentPath string) Shader {
+	shader, err := LoadShader(vertexPath, fragmentPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return shader
+}
+
+func NewShader2(vertexPath, fragmentPath, geometryPath string) Shader {
+	shader, err := LoadShader2(vertexPath, fragmentPath, geometryPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return shader
 }
 
 func (s *Shader) Id() uint32 {
@@ -79,6 +152,18 @@ func (s *Shader) Use() {
 	UseProgram(s.id)
 }
 
+// Delete frees the program, the Shader must not be used afterwards.
+func (s *Shader) Delete() {
+	if s.id == 0 {
+		return
+	}
+	DeleteProgram(s.id)
+	delete(programBlocks, s.id)
+	untrackObject("program", s.id)
+	s.id = 0
+	s.locations = nil
+}
+
 func (s *Shader) SetBool(name string, value bool) {
 	v := func() int32 {
 		if value {
@@ -87,66 +172,67 @@ func (s *Shader) SetBool(name string, value bool) {
 			return 0
 		}
 	}()
-	Uniform1i(GetUniformLocation(s.id, name), v)
+	Uniform1i(s.UniformLocation(name), v)
 }
 
 func (s *Shader) SetInt32(name string, value int32) {
-	Uniform1i(GetUniformLocation(s.id, name), value)
+	Uniform1i(s.UniformLocation(name), value)
 }
 
 func (s *Shader) SetFloat32(name string, value float32) {
-	Uniform1f(GetUniformLocation(s.id, name), value)
+	Uniform1f(s.UniformLocation(name), value)
 }
 
 func (s *Shader) SetVec2(name string, value *mgl32.Vec2) {
-	Uniform2fv(GetUniformLocation(s.id, name), 1, &((*value)[0]))
+	Uniform2fv(s.UniformLocation(name), 1, &((*value)[0]))
 }
 
 func (s *Shader) SetVec2WithXY(name string, x, y float32) {
-	Uniform2f(GetUniformLocation(s.id, name), x, y)
+	Uniform2f(s.UniformLocation(name), x, y)
 }
 
 func (s *Shader) SetVec3(name string, value *mgl32.Vec3) {
-	Uniform3fv(GetUniformLocation(s.id, name), 1, &((*value)[0]))
+	Uniform3fv(s.UniformLocation(name), 1, &((*value)[0]))
 }
 
 func (s *Shader) SetVec3WithXYZ(name string, x, y, z float32) {
-	Uniform3f(GetUniformLocation(s.id, name), x, y, z)
+	Uniform3f(s.UniformLocation(name), x, y, z)
 }
 
 func (s *Shader) SetVec4(name string, value *mgl32.Vec4) {
-	Uniform4fv(GetUniformLocation(s.id, name), 1, &((*value)[0]))
+	Uniform4fv(s.UniformLocation(name), 1, &((*value)[0]))
 }
 
 func (s *Shader) SetVec4WithXYZW(name string, x, y, z, w float32) {
-	Uniform4f(GetUniformLocation(s.id, name), x, y, z, w)
+	Uniform4f(s.UniformLocation(name), x, y, z, w)
 }
 
 func (s *Shader) SetMat2(name string, value *mgl32.Mat2) {
-	UniformMatrix2fv(GetUniformLocation(s.id, name), 1, false, &((*value)[0]))
+	UniformMatrix2fv(s.UniformLocation(name), 1, false, &((*value)[0]))
 }
 
 func (s *Shader) SetMat3(name string, value *mgl32.Mat3) {
-	UniformMatrix3fv(GetUniformLocation(s.id, name), 1, false, &((*value)[0]))
+	UniformMatrix3fv(s.UniformLocation(name), 1, false, &((*value)[0]))
 }
 
 func (s *Shader) SetMat4(name string, value *mgl32.Mat4) {
-	UniformMatrix4fv(GetUniformLocation(s.id, name), 1, false, &((*value)[0]))
+	UniformMatrix4fv(s.UniformLocation(name), 1, false, &((*value)[0]))
 }
 
-func checkCompileErrors(shader uint32, typ string) {
+func checkCompileErrors(shader uint32, typ, path string) error {
 	var success int32
 	if typ == "VERTEX" || typ == "FRAGMENT" || typ == "GEOMETRY" {
 		GetShaderiv(shader, COMPILE_STATUS, &success)
 		if success == FALSE {
-			log.Fatalf("ERROR::SHADER_COMPILATION_ERROR of type: %v\n%v", typ, GetShaderInfoLog(shader))
+			return newShaderError(typ, path, GetShaderInfoLog(shader))
 		}
 	} else if typ == "PROGRAM" {
 		GetProgramiv(shader, LINK_STATUS, &success)
 		if success == FALSE {
-			log.Fatalf("ERROR::PROGRAM_LINKING_ERROR of type: %v\n%v", typ, GetProgramInfoLog(shader))
+			return newShaderError(typ, path, GetProgramInfoLog(shader))
 		}
 	} else {
-		log.Fatalf("ERROR unknown type: %v", typ)
+		return &ShaderError{Stage: typ, Path: path, Log: "unknown type"}
 	}
+	return nil
 }
@@ -0,0 +1,87 @@
+package gl
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SetStruct uploads a Go value to the uniforms it mirrors. value may be a struct, a pointer to one, or an array or
+// slice of them; name is the GLSL name of the uniform and may be "" for a struct whose fields are top-level uniforms.
+//
+// Struct fields are mapped by their `glsl:"name"` tag, untagged and unexported fields are skipped. Supported field
+// types are float32, int32, bool, mgl32.Vec2/3/4, mgl32.Mat2/3/4, nested structs and arrays or slices of those,
+// element i of an array field "lights" is uploaded to "lights[i]".
+//
+//	type PointLight struct {
+//		Position mgl32.Vec3 `glsl:"position"`
+//		Constant float32    `glsl:"constant"`
+//	}
+//	shader.SetStruct("pointLights", []PointLight{...})
+func (s *Shader) SetStruct(name string, value interface{}) error {
+	return s.setValue(name, reflect.ValueOf(value))
+}
+
+func (s *Shader) setValue(name string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return fmt.Errorf("gl: nil value for uniform %v", name)
+		}
+		v = v.Elem()
+	}
+	// the zero Value of a nil interface{}
+	if !v.IsValid() {
+		return fmt.Errorf("gl: nil value for uniform %v", name)
+	}
+
+	switch x := v.Interface().(type) {
+	case float32:
+		s.SetFloat32(name, x)
+	case int32:
+		s.SetInt32(name, x)
+	case bool:
+		s.SetBool(name, x)
+	case mgl32.Vec2:
+		s.SetVec2(name, &x)
+	case mgl32.Vec3:
+		s.SetVec3(name, &x)
+	case mgl32.Vec4:
+		s.SetVec4(name, &x)
+	case mgl32.Mat2:
+		s.SetMat2(name, &x)
+	case mgl32.Mat3:
+		s.SetMat3(name, &x)
+	case mgl32.Mat4:
+		s.SetMat4(name, &x)
+	default:
+		switch v.Kind() {
+		case reflect.Struct:
+			t := v.Type()
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				tag := f.Tag.Get("glsl")
+				if tag == "" || tag == "-" || f.PkgPath != "" {
+					continue
+				}
+				fieldName := tag
+				if name != "" {
+					fieldName = name + "." + tag
+				}
+				if err := s.setValue(fieldName, v.Field(i)); err != nil {
+					return err
+				}
+			}
+		case reflect.Array, reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				if err := s.setValue(name+"["+strconv.Itoa(i)+"]", v.Index(i)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("gl: unsupported type %v for uniform %v", v.Type(), name)
+		}
+	}
+	return nil
+}
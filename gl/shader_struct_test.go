@@ -0,0 +1,68 @@
+package gl
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type testLight struct {
+	Position mgl32.Vec3  `glsl:"position"`
+	Constant float32     `glsl:"constant"`
+	Shadow   *int32      `glsl:"shadow"`
+	Extra    interface{} `glsl:"extra"`
+	skipped  float32
+}
+
+func TestSetStruct(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	s := Shader{id: 1}
+
+	shadow := int32(3)
+	lights := []testLight{
+		{Position: mgl32.Vec3{1, 2, 3}, Constant: 1, Shadow: &shadow, Extra: float32(4)},
+		{Constant: 0.5, Shadow: &shadow, Extra: true},
+	}
+	if err := s.SetStruct("lights", &lights); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range rb.CallsTo("GetUniformLocation") {
+		names = append(names, c.Args[1].(string))
+	}
+	want := []string{"lights[0].position", "lights[0].constant", "lights[0].shadow", "lights[0].extra",
+		"lights[1].position", "lights[1].constant", "lights[1].shadow", "lights[1].extra"}
+	if len(names) != len(want) {
+		t.Fatalf("set %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("set %v, want %v", names, want)
+		}
+	}
+}
+
+func TestSetStructNil(t *testing.T) {
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	s := Shader{id: 1}
+
+	var light *testLight
+	for _, value := range []interface{}{
+		nil,
+		light,
+		testLight{Shadow: nil},
+		testLight{Shadow: new(int32), Extra: nil},
+		[]interface{}{nil},
+	} {
+		if err := s.SetStruct("light", value); err == nil {
+			t.Errorf("SetStruct(%#v) succeeded", value)
+		}
+	}
+	if err := s.SetStruct("light", struct {
+		X string `glsl:"x"`
+	}{}); err == nil {
+		t.Errorf("SetStruct of a string field succeeded")
+	}
+}
@@ -0,0 +1,121 @@
+package gl
+
+import (
+	"log"
+	"strings"
+)
+
+// UniformInfo describes an active uniform of a linked program.
+type UniformInfo struct {
+	// Name as reported by the driver, arrays of basic types end with "[0]"
+	Name string
+	Type uint32
+	// Size is the number of array elements, 1 for non-arrays
+	Size int32
+	// Location is -1 for the members of uniform blocks
+	Location int32
+	// Block is the index into Shader.UniformBlocks, or -1 for the default uniform block
+	Block int32
+}
+
+// AttributeInfo describes an active vertex attribute of a linked program.
+type AttributeInfo struct {
+	Name     string
+	Type     uint32
+	Size     int32
+	Location int32
+}
+
+// UniformBlockInfo describes an active uniform block of a linked program.
+type UniformBlockInfo struct {
+	Name     string
+	Index    uint32
+	DataSize int32
+}
+
+// reflect enumerates the active uniforms, uniform blocks and attributes of the linked program and fills the
+// location cache.
+func (s *Shader) reflect() {
+	s.locations = make(map[string]int32)
+
+	var count int32
+	GetProgramiv(s.id, ACTIVE_UNIFORM_BLOCKS, &count)
+	s.blocks = make([]UniformBlockInfo, 0, count)
+	for i := uint32(0); i < uint32(count); i++ {
+		var dataSize int32
+		GetActiveUniformBlockiv(s.id, i, UNIFORM_BLOCK_DATA_SIZE, &dataSize)
+		s.blocks = append(s.blocks, UniformBlockInfo{Name: GetActiveUniformBlockName(s.id, i), Index: i, DataSize: dataSize})
+	}
+
+	GetProgramiv(s.id, ACTIVE_UNIFORMS, &count)
+	s.uniforms = make([]UniformInfo, 0, count)
+	for i := uint32(0); i < uint32(count); i++ {
+		name, size, xtype := GetActiveUniform(s.id, i)
+		var block int32
+		GetActiveUniformsiv(s.id, 1, &i, UNIFORM_BLOCK_INDEX, &block)
+		info := UniformInfo{Name: name, Type: xtype, Size: size, Location: -1, Block: block}
+		if block < 0 {
+			info.Location = GetUniformLocation(s.id, name+"\x00")
+			s.locations[name] = info.Location
+			if strings.HasSuffix(name, "[0]") {
+				s.locations[strings.TrimSuffix(name, "[0]")] = info.Location
+			}
+		}
+		s.uniforms = append(s.uniforms, info)
+	}
+
+	GetProgramiv(s.id, ACTIVE_ATTRIBUTES, &count)
+	s.attributes = make([]AttributeInfo, 0, count)
+	for i := uint32(0); i < uint32(count); i++ {
+		name, size, xtype := GetActiveAttrib(s.id, i)
+		s.attributes = append(s.attributes, AttributeInfo{
+			Name:     name,
+			Type:     xtype,
+			Size:     size,
+			Location: GetAttribLocation(s.id, name+"\x00"),
+		})
+	}
+}
+
+// Uniforms returns the active uniforms of the program.
+func (s *Shader) Uniforms() []UniformInfo {
+	return s.uniforms
+}
+
+// UniformBlocks returns the active uniform blocks of the program.
+func (s *Shader) UniformBlocks() []UniformBlockInfo {
+	return s.blocks
+}
+
+// Attributes returns the active vertex attributes of the program.
+func (s *Shader) Attributes() []AttributeInfo {
+	return s.attributes
+}
+
+// SetWarnUnknownUniforms makes the setters log, once per name, the uniforms that are not active in the program.
+func (s *Shader) SetWarnUnknownUniforms(warn bool) {
+	s.warnUnknown = warn
+}
+
+// UniformLocation returns the cached location of the uniform, or -1 if it is not active. The name is a plain Go
+// string, a trailing "\x00" is accepted for compatibility.
+func (s *Shader) UniformLocation(name string) int32 {
+	name = strings.TrimSuffix(name, "\x00")
+	loc, ok := s.locations[name]
+	if !ok {
+		loc = GetUniformLocation(s.id, name+"\x00")
+		if s.locations == nil {
+			s.locations = make(map[string]int32)
+		}
+		s.locations[name] = loc
+	}
+	// the location may have been cached while warnings were off
+	if loc < 0 && s.warnUnknown && !s.warned[name] {
+		if s.warned == nil {
+			s.warned = make(map[string]bool)
+		}
+		s.warned[name] = true
+		log.Printf("shader %v: uniform %v is not active", s.id, name)
+	}
+	return loc
+}
@@ -0,0 +1,30 @@
+package gl
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestUniformLocationWarnsAfterCaching(t *testing.T) {
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	defer log.SetFlags(log.Flags())
+	log.SetOutput(&out)
+	log.SetFlags(0)
+
+	// an inactive uniform, as the driver reports it
+	s := Shader{id: 1, locations: map[string]int32{"missing": -1}}
+	if loc := s.UniformLocation("missing"); loc != -1 || out.Len() != 0 {
+		t.Fatalf("location %v, logged %q with warnings off", loc, out.String())
+	}
+
+	// the cached location still warns once warnings are on, and only once
+	s.SetWarnUnknownUniforms(true)
+	s.UniformLocation("missing")
+	s.UniformLocation("missing\x00")
+	if n := strings.Count(out.String(), "uniform missing is not active"); n != 1 {
+		t.Errorf("warned %v times, want 1: %q", n, out.String())
+	}
+}
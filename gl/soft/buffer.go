@@ -0,0 +1,366 @@
+package soft
+
+import (
+	"encoding/binary"
+	"learn_opengl/gl"
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type buffer struct {
+	data []byte
+}
+
+type bufferRange struct {
+	buffer uint32
+	offset int
+	// size is -1 for the whole buffer
+	size int
+}
+
+type vertexAttrib struct {
+	enabled    bool
+	size       int32
+	xtype      uint32
+	normalized bool
+	integer    bool
+	stride     int32
+	offset     int
+	buffer     uint32
+	divisor    uint32
+}
+
+type vertexArray struct {
+	attribs       [MAX_VERTEX_ATTRIBS]vertexAttrib
+	elementBuffer uint32
+}
+
+func (b *Backend) GenVertexArrays(n int32, arrays *uint32) {
+	for i, s := 0, ids(n, arrays); i < len(s); i++ {
+		s[i] = b.newId()
+		b.vertexArrays[s[i]] = &vertexArray{}
+	}
+}
+
+func (b *Backend) BindVertexArray(array uint32) {
+	vao, ok := b.vertexArrays[array]
+	if !ok {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	b.vertexArray = vao
+}
+
+func (b *Backend) DeleteVertexArrays(n int32, arrays *uint32) {
+	for _, id := range ids(n, arrays) {
+		if vao, ok := b.vertexArrays[id]; ok && id != 0 {
+			if b.vertexArray == vao {
+				b.vertexArray = b.vertexArrays[0]
+			}
+			delete(b.vertexArrays, id)
+		}
+	}
+}
+
+func (b *Backend) GenBuffers(n int32, buffers *uint32) {
+	for i, s := 0, ids(n, buffers); i < len(s); i++ {
+		s[i] = b.newId()
+		b.buffers[s[i]] = &buffer{}
+	}
+}
+
+// boundBuffer returns the name of the buffer bound to target, or false for an unknown target.
+func (b *Backend) boundBuffer(target uint32) (uint32, bool) {
+	switch target {
+	case gl.ARRAY_BUFFER:
+		return b.arrayBuffer, true
+	case gl.ELEMENT_ARRAY_BUFFER:
+		return b.vertexArray.elementBuffer, true
+	case gl.UNIFORM_BUFFER:
+		return b.uniformBuffer, true
+	}
+	b.setError(gl.INVALID_ENUM)
+	return 0, false
+}
+
+func (b *Backend) BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
+	id, ok := b.boundBuffer(target)
+	if !ok {
+		return
+	}
+	buf := b.buffers[id]
+	if buf == nil || size < 0 {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	buf.data = make([]byte, size)
+	if data != nil && size > 0 {
+		copy(buf.data, unsafe.Slice((*byte)(data), size))
+	}
+}
+
+func (b *Backend) BufferSubData(target uint32, offset, size int, data unsafe.Pointer) {
+	id, ok := b.boundBuffer(target)
+	if !ok {
+		return
+	}
+	buf := b.buffers[id]
+	if buf == nil || offset < 0 || size < 0 || offset+size > len(buf.data) {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	if data != nil && size > 0 {
+		copy(buf.data[offset:], unsafe.Slice((*byte)(data), size))
+	}
+}
+
+func (b *Backend) BindBuffer(target, id uint32) {
+	if _, ok := b.buffers[id]; !ok && id != 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	switch target {
+	case gl.ARRAY_BUFFER:
+		b.arrayBuffer = id
+	case gl.ELEMENT_ARRAY_BUFFER:
+		b.vertexArray.elementBuffer = id
+	case gl.UNIFORM_BUFFER:
+		b.uniformBuffer = id
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+}
+
+func (b *Backend) DeleteBuffers(n int32, buffers *uint32) {
+	for _, id := range ids(n, buffers) {
+		if _, ok := b.buffers[id]; !ok || id == 0 {
+			continue
+		}
+		delete(b.buffers, id)
+		if b.arrayBuffer == id {
+			b.arrayBuffer = 0
+		}
+		if b.uniformBuffer == id {
+			b.uniformBuffer = 0
+		}
+		if b.vertexArray.elementBuffer == id {
+			b.vertexArray.elementBuffer = 0
+		}
+	}
+}
+
+func (b *Backend) BindBufferRange(target, index, id uint32, offset, size int) {
+	if target != gl.UNIFORM_BUFFER {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	if index >= MAX_UNIFORM_BUFFER_BINDINGS || offset < 0 || size <= 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.uniformBlocks[index] = bufferRange{buffer: id, offset: offset, size: size}
+	b.uniformBuffer = id
+}
+
+func (b *Backend) BindBufferBase(target, index, id uint32) {
+	if target != gl.UNIFORM_BUFFER {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	if index >= MAX_UNIFORM_BUFFER_BINDINGS {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.uniformBlocks[index] = bufferRange{buffer: id, size: -1}
+	b.uniformBuffer = id
+}
+
+// uniformBlockData returns the bytes of the buffer range bound to a uniform buffer binding point.
+func (b *Backend) uniformBlockData(binding uint32) []byte {
+	if binding >= MAX_UNIFORM_BUFFER_BINDINGS {
+		return nil
+	}
+	r := b.uniformBlocks[binding]
+	buf := b.buffers[r.buffer]
+	if buf == nil || r.offset > len(buf.data) {
+		return nil
+	}
+	data := buf.data[r.offset:]
+	if r.size >= 0 && r.size < len(data) {
+		data = data[:r.size]
+	}
+	return data
+}
+
+func (b *Backend) EnableVertexAttribArray(index uint32) {
+	if index >= MAX_VERTEX_ATTRIBS {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.vertexArray.attribs[index].enabled = true
+}
+
+func (b *Backend) DisableVertexAttribArray(index uint32) {
+	if index >= MAX_VERTEX_ATTRIBS {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.vertexArray.attribs[index].enabled = false
+}
+
+func (b *Backend) vertexAttribPointer(index uint32, size int32, xtype uint32, normalized, integer bool, stride int32, offset int) {
+	if index >= MAX_VERTEX_ATTRIBS || size < 1 || size > 4 || stride < 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	if attribTypeSize(xtype) == 0 || integer && (xtype == gl.FLOAT || xtype == gl.HALF_FLOAT || packedType(xtype)) {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	if packedType(xtype) && size != 4 {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	a := &b.vertexArray.attribs[index]
+	a.size, a.xtype, a.normalized, a.integer = size, xtype, normalized, integer
+	a.stride, a.offset, a.buffer = stride, offset, b.arrayBuffer
+}
+
+func (b *Backend) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset int) {
+	b.vertexAttribPointer(index, size, xtype, normalized, false, stride, offset)
+}
+
+func (b *Backend) VertexAttribIPointer(index uint32, size int32, xtype uint32, stride int32, offset int) {
+	b.vertexAttribPointer(index, size, xtype, false, true, stride, offset)
+}
+
+func (b *Backend) VertexAttribDivisor(index, divisor uint32) {
+	if index >= MAX_VERTEX_ATTRIBS {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.vertexArray.attribs[index].divisor = divisor
+}
+
+func attribTypeSize(xtype uint32) int {
+	switch xtype {
+	case gl.BYTE, gl.UNSIGNED_BYTE:
+		return 1
+	case gl.SHORT, gl.UNSIGNED_SHORT, gl.HALF_FLOAT:
+		return 2
+	case gl.INT, gl.UNSIGNED_INT, gl.FLOAT:
+		return 4
+	case gl.INT_2_10_10_10_REV, gl.UNSIGNED_INT_2_10_10_10_REV:
+		// the four components share the 4 bytes
+		return 1
+	}
+	return 0
+}
+
+// packedType tells the types packing four components in 32 bits.
+func packedType(xtype uint32) bool {
+	return xtype == gl.INT_2_10_10_10_REV || xtype == gl.UNSIGNED_INT_2_10_10_10_REV
+}
+
+// fetch reads the attribute of a vertex, the missing components default to (0, 0, 0, 1). Integer attributes are
+// converted to float32 too, the Go shaders read them from the same []mgl32.Vec4.
+func (b *Backend) fetch(a *vertexAttrib, vertex, instance int) mgl32.Vec4 {
+	v := mgl32.Vec4{0, 0, 0, 1}
+	buf := b.buffers[a.buffer]
+	if buf == nil {
+		return v
+	}
+	typeSize := attribTypeSize(a.xtype)
+	stride := int(a.stride)
+	if stride == 0 {
+		stride = typeSize * int(a.size)
+	}
+	index := vertex
+	if a.divisor > 0 {
+		index = instance / int(a.divisor)
+	}
+	start := a.offset + index*stride
+	if start < 0 || start+typeSize*int(a.size) > len(buf.data) {
+		return v
+	}
+	data := buf.data[start:]
+	if packedType(a.xtype) {
+		return unpack2101010(binary.LittleEndian.Uint32(data), a.xtype == gl.INT_2_10_10_10_REV, a.normalized)
+	}
+	for i := 0; i < int(a.size); i++ {
+		p := data[i*typeSize:]
+		switch a.xtype {
+		case gl.FLOAT:
+			v[i] = math.Float32frombits(binary.LittleEndian.Uint32(p))
+		case gl.HALF_FLOAT:
+			v[i] = halfToFloat(binary.LittleEndian.Uint16(p))
+		case gl.UNSIGNED_BYTE:
+			v[i] = normalize(float32(p[0]), 255, a.normalized && !a.integer)
+		case gl.BYTE:
+			v[i] = normalizeSigned(float32(int8(p[0])), 127, a.normalized && !a.integer)
+		case gl.UNSIGNED_SHORT:
+			v[i] = normalize(float32(binary.LittleEndian.Uint16(p)), 65535, a.normalized && !a.integer)
+		case gl.SHORT:
+			v[i] = normalizeSigned(float32(int16(binary.LittleEndian.Uint16(p))), 32767, a.normalized && !a.integer)
+		case gl.UNSIGNED_INT:
+			v[i] = normalize(float32(binary.LittleEndian.Uint32(p)), 4294967295, a.normalized && !a.integer)
+		case gl.INT:
+			v[i] = normalizeSigned(float32(int32(binary.LittleEndian.Uint32(p))), 2147483647, a.normalized && !a.integer)
+		}
+	}
+	return v
+}
+
+// unpack2101010 reads x, y and z from the low 10 bit fields and w from the top 2 bits.
+func unpack2101010(p uint32, signed, normalized bool) mgl32.Vec4 {
+	var v mgl32.Vec4
+	for i, bits := range [4]uint{10, 10, 10, 2} {
+		shift := uint(10 * i)
+		field := p >> shift & (1<<bits - 1)
+		if signed {
+			// sign extend the field
+			value := float32(int32(field<<(32-bits)) >> (32 - bits))
+			v[i] = normalizeSigned(value, float32(int(1)<<(bits-1)-1), normalized)
+		} else {
+			v[i] = normalize(float32(field), float32(int(1)<<bits-1), normalized)
+		}
+	}
+	return v
+}
+
+func normalize(v, max float32, normalized bool) float32 {
+	if normalized {
+		return v / max
+	}
+	return v
+}
+
+func normalizeSigned(v, max float32, normalized bool) float32 {
+	if normalized {
+		if v /= max; v < -1 {
+			v = -1
+		}
+	}
+	return v
+}
+
+// readIndex reads element i of an index buffer.
+func readIndex(data []byte, xtype uint32, i int) (int, bool) {
+	switch xtype {
+	case gl.UNSIGNED_BYTE:
+		if i < len(data) {
+			return int(data[i]), true
+		}
+	case gl.UNSIGNED_SHORT:
+		if 2*i+2 <= len(data) {
+			return int(binary.LittleEndian.Uint16(data[2*i:])), true
+		}
+	case gl.UNSIGNED_INT:
+		if 4*i+4 <= len(data) {
+			return int(binary.LittleEndian.Uint32(data[4*i:])), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,558 @@
+package soft
+
+import (
+	"encoding/binary"
+	"learn_opengl/gl"
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type renderbuffer struct {
+	surface *surface
+}
+
+// attachment is a texture level or a renderbuffer attached to a framebuffer, it is resolved at every use since the
+// texture level may be respecified.
+type attachment struct {
+	tex   *texture
+	level int
+	rb    *renderbuffer
+}
+
+func (a attachment) attached() bool {
+	return a.tex != nil || a.rb != nil
+}
+
+func (a attachment) surface() *surface {
+	if a.rb != nil {
+		return a.rb.surface
+	}
+	if a.tex != nil && a.level < len(a.tex.levels) {
+		return a.tex.levels[a.level]
+	}
+	return nil
+}
+
+type framebuffer struct {
+	// only the first color attachment is supported
+	color      attachment
+	depth      attachment
+	stencil    attachment
+	drawBuffer uint32
+	readBuffer uint32
+}
+
+func newDefaultFramebuffer(width, height int) *framebuffer {
+	color := &renderbuffer{surface: newSurface(width, height, format{channels: 4})}
+	depthStencil := &renderbuffer{surface: newSurface(width, height, format{channels: 1, depth: true, stencil: true})}
+	return &framebuffer{
+		color:      attachment{rb: color},
+		depth:      attachment{rb: depthStencil},
+		stencil:    attachment{rb: depthStencil},
+		drawBuffer: gl.BACK,
+		readBuffer: gl.BACK,
+	}
+}
+
+// colorTarget returns the surface color writes go to, nil if drawing to no color buffer.
+func (fb *framebuffer) colorTarget() *surface {
+	if fb.drawBuffer == gl.NONE {
+		return nil
+	}
+	return fb.color.surface()
+}
+
+// colorSource returns the surface ReadPixels and BlitFramebuffer read the colors from.
+func (fb *framebuffer) colorSource() *surface {
+	if fb.readBuffer == gl.NONE {
+		return nil
+	}
+	return fb.color.surface()
+}
+
+func (fb *framebuffer) depthTarget() *surface {
+	if s := fb.depth.surface(); s != nil && s.format.depth {
+		return s
+	}
+	return nil
+}
+
+func (fb *framebuffer) stencilTarget() *surface {
+	if s := fb.stencil.surface(); s != nil && s.stencil != nil {
+		return s
+	}
+	return nil
+}
+
+// size returns the size of the drawable area, the intersection of the attachments.
+func (fb *framebuffer) size() (int, int) {
+	width, height := -1, -1
+	for _, a := range []attachment{fb.color, fb.depth, fb.stencil} {
+		if s := a.surface(); s != nil {
+			if width < 0 || s.width < width {
+				width = s.width
+			}
+			if height < 0 || s.height < height {
+				height = s.height
+			}
+		}
+	}
+	if width < 0 {
+		return 0, 0
+	}
+	return width, height
+}
+
+// clear clears the buffers of mask within the scissor box, honouring the write masks.
+func (fb *framebuffer) clear(b *Backend, mask uint32) {
+	x0, y0, x1, y1 := b.clipRect(fb)
+	if color := fb.colorTarget(); mask&gl.COLOR_BUFFER_BIT != 0 && color != nil {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				c := color.at(x, y)
+				for j := 0; j < 4; j++ {
+					if b.colorMask[j] {
+						c[j] = b.clearColor[j]
+					}
+				}
+				color.set(x, y, c)
+			}
+		}
+	}
+	if depth := fb.depthTarget(); mask&gl.DEPTH_BUFFER_BIT != 0 && depth != nil && b.depthMask {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				depth.setDepth(x, y, 1)
+			}
+		}
+	}
+	if stencil := fb.stencilTarget(); mask&gl.STENCIL_BUFFER_BIT != 0 && stencil != nil {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				i := y*stencil.width + x
+				stencil.stencil[i] &= ^uint8(b.stencilWrite)
+			}
+		}
+	}
+}
+
+// clipRect returns the pixels of fb that may be written, limited by the scissor box when the scissor test is enabled.
+func (b *Backend) clipRect(fb *framebuffer) (int, int, int, int) {
+	width, height := fb.size()
+	x0, y0, x1, y1 := 0, 0, width, height
+	if b.scissorTest {
+		x0 = maxInt(x0, int(b.scissor[0]))
+		y0 = maxInt(y0, int(b.scissor[1]))
+		x1 = minInt(x1, int(b.scissor[0]+b.scissor[2]))
+		y1 = minInt(y1, int(b.scissor[1]+b.scissor[3]))
+	}
+	return x0, y0, x1, y1
+}
+
+func (b *Backend) GenFramebuffers(n int32, framebuffers *uint32) {
+	for i, s := 0, ids(n, framebuffers); i < len(s); i++ {
+		s[i] = b.newId()
+		b.framebuffers[s[i]] = &framebuffer{drawBuffer: gl.COLOR_ATTACHMENT0, readBuffer: gl.COLOR_ATTACHMENT0}
+	}
+}
+
+func (b *Backend) BindFramebuffer(target, id uint32) {
+	fb, ok := b.framebuffers[id]
+	if !ok {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	switch target {
+	case gl.FRAMEBUFFER:
+		b.drawFb, b.readFb = fb, fb
+	case gl.DRAW_FRAMEBUFFER:
+		b.drawFb = fb
+	case gl.READ_FRAMEBUFFER:
+		b.readFb = fb
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+}
+
+// boundFramebuffer returns the framebuffer object bound to target, nil and an error for the default framebuffer.
+func (b *Backend) boundFramebuffer(target uint32) *framebuffer {
+	var fb *framebuffer
+	switch target {
+	case gl.FRAMEBUFFER, gl.DRAW_FRAMEBUFFER:
+		fb = b.drawFb
+	case gl.READ_FRAMEBUFFER:
+		fb = b.readFb
+	default:
+		b.setError(gl.INVALID_ENUM)
+		return nil
+	}
+	if fb == b.framebuffers[0] {
+		b.setError(gl.INVALID_OPERATION)
+		return nil
+	}
+	return fb
+}
+
+func (b *Backend) attach(target, point uint32, a attachment) {
+	fb := b.boundFramebuffer(target)
+	if fb == nil {
+		return
+	}
+	switch point {
+	case gl.COLOR_ATTACHMENT0:
+		fb.color = a
+	case gl.DEPTH_ATTACHMENT:
+		fb.depth = a
+	case gl.STENCIL_ATTACHMENT:
+		fb.stencil = a
+	case gl.DEPTH_STENCIL_ATTACHMENT:
+		fb.depth, fb.stencil = a, a
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+}
+
+func (b *Backend) FramebufferTexture1D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32) {
+	b.setError(gl.INVALID_OPERATION)
+}
+
+func (b *Backend) FramebufferTexture2D(target uint32, point uint32, textarget uint32, id uint32, level int32) {
+	if id == 0 {
+		b.attach(target, point, attachment{})
+		return
+	}
+	t, ok := b.textures[id]
+	if !ok || !textureTarget(textarget) || level < 0 {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	b.attach(target, point, attachment{tex: t, level: int(level)})
+}
+
+func (b *Backend) FramebufferTexture3D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32, zoffset int32) {
+	b.setError(gl.INVALID_OPERATION)
+}
+
+func (b *Backend) FramebufferRenderbuffer(target uint32, point uint32, renderbuffertarget uint32, id uint32) {
+	if id == 0 {
+		b.attach(target, point, attachment{})
+		return
+	}
+	rb, ok := b.renderbuffers[id]
+	if !ok || renderbuffertarget != gl.RENDERBUFFER {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	b.attach(target, point, attachment{rb: rb})
+}
+
+func (b *Backend) GenRenderbuffers(n int32, renderbuffers *uint32) {
+	for i, s := 0, ids(n, renderbuffers); i < len(s); i++ {
+		s[i] = b.newId()
+		b.renderbuffers[s[i]] = &renderbuffer{}
+	}
+}
+
+func (b *Backend) BindRenderbuffer(target, id uint32) {
+	if target != gl.RENDERBUFFER {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	if _, ok := b.renderbuffers[id]; !ok && id != 0 {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	b.renderbuffer = id
+}
+
+func (b *Backend) RenderbufferStorage(target, internalformat uint32, width, height int32) {
+	if target != gl.RENDERBUFFER {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	rb := b.renderbuffers[b.renderbuffer]
+	if rb == nil {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	f, ok := parseFormat(internalformat)
+	if !ok {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	if width < 0 || height < 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	rb.surface = newSurface(int(width), int(height), f)
+}
+
+// RenderbufferStorageMultisample allocates a single sample renderbuffer, multisampling isn't supported.
+func (b *Backend) RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32) {
+	b.RenderbufferStorage(target, internalformat, width, height)
+}
+
+// TexImage2DMultisample allocates a single sample texture, multisampling isn't supported.
+func (b *Backend) TexImage2DMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32, fixedsamplelocations bool) {
+	if target != gl.TEXTURE_2D_MULTISAMPLE {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	t := b.boundTexture(target)
+	if t == nil {
+		return
+	}
+	f, ok := parseFormat(internalformat)
+	if !ok {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	t.minFilter, t.magFilter = gl.NEAREST, gl.NEAREST
+	t.setLevel(0, newSurface(int(width), int(height), f))
+}
+
+func (b *Backend) CheckFramebufferStatus(target uint32) uint32 {
+	var fb *framebuffer
+	switch target {
+	case gl.FRAMEBUFFER, gl.DRAW_FRAMEBUFFER:
+		fb = b.drawFb
+	case gl.READ_FRAMEBUFFER:
+		fb = b.readFb
+	default:
+		b.setError(gl.INVALID_ENUM)
+		return 0
+	}
+	if fb == b.framebuffers[0] {
+		return gl.FRAMEBUFFER_COMPLETE
+	}
+	if !fb.color.attached() && !fb.depth.attached() && !fb.stencil.attached() {
+		return gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT
+	}
+	for _, a := range []attachment{fb.color, fb.depth, fb.stencil} {
+		if !a.attached() {
+			continue
+		}
+		if s := a.surface(); s == nil || s.width == 0 || s.height == 0 {
+			return gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT
+		}
+	}
+	if s := fb.color.surface(); s != nil && (s.format.depth || s.format.channels == 0) {
+		return gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT
+	}
+	if s := fb.depth.surface(); s != nil && !s.format.depth {
+		return gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT
+	}
+	if s := fb.stencil.surface(); s != nil && !s.format.stencil {
+		return gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT
+	}
+	return gl.FRAMEBUFFER_COMPLETE
+}
+
+// colorBuffer validates a DrawBuffer or ReadBuffer argument for fb.
+func (b *Backend) colorBuffer(fb *framebuffer, buf uint32) bool {
+	if buf == gl.NONE {
+		return true
+	}
+	if fb == b.framebuffers[0] {
+		return buf == gl.BACK || buf == gl.FRONT
+	}
+	return buf == gl.COLOR_ATTACHMENT0
+}
+
+func (b *Backend) DrawBuffer(buf uint32) {
+	if !b.colorBuffer(b.drawFb, buf) {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	b.drawFb.drawBuffer = buf
+}
+
+// DrawBuffers only honours the first buffer, there is a single color output.
+func (b *Backend) DrawBuffers(n int32, bufs *uint32) {
+	if n < 1 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.DrawBuffer(ids(n, bufs)[0])
+}
+
+func (b *Backend) ReadBuffer(src uint32) {
+	if !b.colorBuffer(b.readFb, src) {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	b.readFb.readBuffer = src
+}
+
+func (b *Backend) ReadPixels(x, y, width, height int32, xformat, xtype uint32, pixels unsafe.Pointer) {
+	if width < 0 || height < 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	n, pixelSize, ok := pixelLayout(xformat, xtype)
+	if !ok || xformat == gl.DEPTH_STENCIL {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	var s *surface
+	if xformat == gl.DEPTH_COMPONENT {
+		s = b.readFb.depthTarget()
+	} else {
+		s = b.readFb.colorSource()
+	}
+	if s == nil {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	if width == 0 || height == 0 || pixels == nil {
+		return
+	}
+	stride := rowStride(int(width)*pixelSize, b.packAlign)
+	data := unsafe.Slice((*byte)(pixels), stride*(int(height)-1)+int(width)*pixelSize)
+	for row := 0; row < int(height); row++ {
+		sy := int(y) + row
+		if sy < 0 || sy >= s.height {
+			continue
+		}
+		for col := 0; col < int(width); col++ {
+			sx := int(x) + col
+			if sx < 0 || sx >= s.width {
+				continue
+			}
+			c := s.at(sx, sy)
+			p := data[row*stride+col*pixelSize:]
+			for j := 0; j < n; j++ {
+				putComponent(p, xtype, j, c[j])
+			}
+		}
+	}
+}
+
+// putComponent writes component j of a pixel, the inverse of component.
+func putComponent(p []byte, xtype uint32, j int, v float32) {
+	switch xtype {
+	case gl.UNSIGNED_BYTE:
+		p[j] = uint8(math.Round(float64(clamp01(v)) * 255))
+	case gl.UNSIGNED_SHORT:
+		binary.LittleEndian.PutUint16(p[2*j:], uint16(math.Round(float64(clamp01(v))*65535)))
+	case gl.UNSIGNED_INT:
+		binary.LittleEndian.PutUint32(p[4*j:], uint32(math.Round(float64(clamp01(v))*4294967295)))
+	case gl.FLOAT:
+		binary.LittleEndian.PutUint32(p[4*j:], math.Float32bits(v))
+	case gl.HALF_FLOAT:
+		binary.LittleEndian.PutUint16(p[2*j:], floatToHalf(v))
+	}
+}
+
+func floatToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+	switch {
+	case bits&0x7fffffff == 0:
+		return sign
+	case exp >= 0x1f:
+		if bits&0x7f800000 == 0x7f800000 && mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp <= 0:
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		return sign | uint16(mant>>uint(14-exp))
+	}
+	return sign | uint16(exp)<<10 | uint16(mant>>13)
+}
+
+func (b *Backend) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32) {
+	if filter != gl.NEAREST && filter != gl.LINEAR {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	if filter == gl.LINEAR && mask&(gl.DEPTH_BUFFER_BIT|gl.STENCIL_BUFFER_BIT) != 0 {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	cx0, cy0, cx1, cy1 := b.clipRect(b.drawFb)
+	dx0, dx1 := minInt(int(dstX0), int(dstX1)), maxInt(int(dstX0), int(dstX1))
+	dy0, dy1 := minInt(int(dstY0), int(dstY1)), maxInt(int(dstY0), int(dstY1))
+	if dx0 == dx1 || dy0 == dy1 {
+		return
+	}
+	scaleX := float32(srcX1-srcX0) / float32(dstX1-dstX0)
+	scaleY := float32(srcY1-srcY0) / float32(dstY1-dstY0)
+
+	type pair struct {
+		src, dst *surface
+		stencil  bool
+	}
+	var pairs []pair
+	if mask&gl.COLOR_BUFFER_BIT != 0 {
+		pairs = append(pairs, pair{b.readFb.colorSource(), b.drawFb.colorTarget(), false})
+	}
+	if mask&gl.DEPTH_BUFFER_BIT != 0 {
+		pairs = append(pairs, pair{b.readFb.depthTarget(), b.drawFb.depthTarget(), false})
+	}
+	if mask&gl.STENCIL_BUFFER_BIT != 0 {
+		pairs = append(pairs, pair{b.readFb.stencilTarget(), b.drawFb.stencilTarget(), true})
+	}
+	for _, p := range pairs {
+		if p.src == nil || p.dst == nil {
+			continue
+		}
+		// copy the source when blitting within a surface
+		src := p.src
+		if src == p.dst {
+			src = &surface{width: p.src.width, height: p.src.height, format: p.src.format,
+				pix: append([]float32(nil), p.src.pix...), stencil: append([]uint8(nil), p.src.stencil...)}
+		}
+		for y := maxInt(dy0, cy0); y < minInt(dy1, cy1) && y < p.dst.height; y++ {
+			for x := maxInt(dx0, cx0); x < minInt(dx1, cx1) && x < p.dst.width; x++ {
+				sx := float32(srcX0) + (float32(x)+0.5-float32(dstX0))*scaleX
+				sy := float32(srcY0) + (float32(y)+0.5-float32(dstY0))*scaleY
+				if p.stencil {
+					ix, iy := int(math.Floor(float64(sx))), int(math.Floor(float64(sy)))
+					if ix >= 0 && iy >= 0 && ix < src.width && iy < src.height {
+						p.dst.stencil[y*p.dst.width+x] = src.stencil[iy*src.width+ix]
+					}
+					continue
+				}
+				c, ok := blitSample(src, sx, sy, filter)
+				if !ok {
+					continue
+				}
+				if p.dst.format.depth {
+					p.dst.setDepth(x, y, c[0])
+				} else {
+					p.dst.set(x, y, c)
+				}
+			}
+		}
+	}
+}
+
+// blitSample reads a surface at pixel coordinates, false outside of it.
+func blitSample(s *surface, x, y float32, filter uint32) (mgl32.Vec4, bool) {
+	ix, iy := int(math.Floor(float64(x))), int(math.Floor(float64(y)))
+	if ix < 0 || iy < 0 || ix >= s.width || iy >= s.height {
+		return mgl32.Vec4{}, false
+	}
+	if filter == gl.NEAREST {
+		return s.at(ix, iy), true
+	}
+	x -= 0.5
+	y -= 0.5
+	x0, y0 := math.Floor(float64(x)), math.Floor(float64(y))
+	a, b := x-float32(x0), y-float32(y0)
+	at := func(i, j int) mgl32.Vec4 {
+		return s.at(minInt(maxInt(i, 0), s.width-1), minInt(maxInt(j, 0), s.height-1))
+	}
+	i, j := int(x0), int(y0)
+	return at(i, j).Mul((1 - a) * (1 - b)).Add(at(i+1, j).Mul(a * (1 - b))).
+		Add(at(i, j+1).Mul((1 - a) * b)).Add(at(i+1, j+1).Mul(a * b)), true
+}
@@ -0,0 +1,845 @@
+package soft
+
+import (
+	"encoding/binary"
+	"fmt"
+	"learn_opengl/gl"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Program is the Go implementation of a GLSL program.
+//
+//	soft.Register("6.3.coordinate_systems.vs", "6.3.coordinate_systems.fs", &soft.Program{
+//		Attributes: map[string]int{"aPos": 0, "aTexCoord": 1},
+//		Varyings:   2,
+//		Vertex: func(s *soft.Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+//			out[0], out[1] = in[1].X(), 1-in[1].Y()
+//			return s.Mat4("projection").Mul4(s.Mat4("view")).Mul4(s.Mat4("model")).Mul4x1(in[0].Vec3().Vec4(1))
+//		},
+//		Fragment: func(s *soft.Stage, in []float32) (mgl32.Vec4, bool) {
+//			uv := mgl32.Vec2{in[0], in[1]}
+//			return soft.Mix(s.Texture("texture1", uv), s.Texture("texture2", uv), 0.2), true
+//		},
+//	})
+type Program struct {
+	// Attributes maps the vertex inputs to their locations, they are reported as the active attributes
+	Attributes map[string]int
+	// Blocks names the uniform blocks the stages read with Stage.Block, they are reported as the active blocks
+	Blocks []string
+	// Varyings is the number of floats the vertex stage passes to the next stage, the fragment stage interpolates
+	// them perspective correct
+	Varyings int
+	// Vertex returns gl_Position of a vertex, in holds its attributes by location and the varyings go to out
+	Vertex func(s *Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4
+	// Geometry is the optional geometry stage of programs registered with RegisterGeometry. It runs once per
+	// GeometryInput primitive (gl.POINTS, gl.LINES or gl.TRIANGLES) with the vertices of the vertex stage, and
+	// outputs GeometryOutput strips (gl.POINTS, gl.LINE_STRIP or gl.TRIANGLE_STRIP) with Stage.EmitVertex and
+	// Stage.EndPrimitive. Their vertices carry GeometryVaryings floats to the fragment stage
+	Geometry         func(s *Stage, in []Vertex)
+	GeometryInput    uint32
+	GeometryOutput   uint32
+	GeometryVaryings int
+	// Fragment returns the color of a fragment given the interpolated varyings, false discards the fragment
+	Fragment func(s *Stage, in []float32) (mgl32.Vec4, bool)
+}
+
+// Vertex is a vertex passed to the geometry stage.
+type Vertex struct {
+	// Position is gl_Position
+	Position mgl32.Vec4
+	Varyings []float32
+}
+
+// stages identifies a program by its vertex, geometry and fragment shaders, geometry is empty without geometry stage.
+type stages struct {
+	vertex, geometry, fragment string
+}
+
+var (
+	// Go programs by the base names of their shader files
+	registered = make(map[stages]*Program)
+	// Go programs by the sources of their shaders, for the shaders that aren't loaded from files
+	registeredSources = make(map[stages]*Program)
+)
+
+// Register sets the Go implementation of the programs linked from the shader files vertexFile and fragmentFile. The
+// files are matched by base name, the directory doesn't matter.
+func Register(vertexFile, fragmentFile string, p *Program) {
+	registered[stages{vertex: filepath.Base(vertexFile), fragment: filepath.Base(fragmentFile)}] = p
+}
+
+// RegisterGeometry is Register for the programs with a geometry shader, p has to set the Geometry stage.
+func RegisterGeometry(vertexFile, geometryFile, fragmentFile string, p *Program) {
+	registered[stages{filepath.Base(vertexFile), filepath.Base(geometryFile), filepath.Base(fragmentFile)}] = p
+}
+
+// RegisterSource sets the Go implementation of the programs linked from shaders given by their source with
+// ShaderSource rather than loaded from a file. Leading and trailing spaces and the terminating NUL don't matter.
+func RegisterSource(vertexSource, fragmentSource string, p *Program) {
+	registeredSources[stages{vertex: trimSource(vertexSource), fragment: trimSource(fragmentSource)}] = p
+}
+
+func trimSource(src string) string {
+	return strings.TrimSpace(strings.TrimSuffix(src, "\x00"))
+}
+
+type shader struct {
+	xtype  uint32
+	file   string
+	source string
+}
+
+type program struct {
+	shaders []*shader
+	linked  bool
+	log     string
+	impl    *Program
+	// uniforms are stored by base name, an array element has the location of its base plus the index
+	uniformIds    map[string]int32
+	uniforms      []*uniformValue
+	blockBindings []uint32
+}
+
+type uniformValue struct {
+	// floats and ints hold the same values, every setter writes both
+	floats []float32
+	ints   []int32
+	// comps is the number of components of an element
+	comps int
+}
+
+const (
+	// an array element is addressed by the low bits of its location
+	uniformElementBits = 10
+	maxUniformElements = 1 << uniformElementBits
+)
+
+func (b *Backend) ShaderFile(id uint32, path string) {
+	if s, ok := b.shaders[id]; ok {
+		s.file = path
+	}
+}
+
+func (b *Backend) CreateShader(xtype uint32) uint32 {
+	if xtype != gl.VERTEX_SHADER && xtype != gl.FRAGMENT_SHADER && xtype != gl.GEOMETRY_SHADER {
+		b.setError(gl.INVALID_ENUM)
+		return 0
+	}
+	id := b.newId()
+	b.shaders[id] = &shader{xtype: xtype}
+	return id
+}
+
+// ShaderSource keeps the source for the shaders registered with RegisterSource, the Go implementation of the others
+// is found by the file name passed to ShaderFile.
+func (b *Backend) ShaderSource(id uint32, src string) {
+	s, ok := b.shaders[id]
+	if !ok {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	s.source = trimSource(src)
+}
+
+func (b *Backend) CompileShader(id uint32) {
+	if _, ok := b.shaders[id]; !ok {
+		b.setError(gl.INVALID_VALUE)
+	}
+}
+
+func (b *Backend) GetShaderiv(id uint32, pname uint32, params *int32) {
+	s, ok := b.shaders[id]
+	if !ok {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	switch pname {
+	case gl.COMPILE_STATUS:
+		*params = gl.TRUE
+	case gl.INFO_LOG_LENGTH:
+		*params = 0
+	case gl.SHADER_TYPE:
+		*params = int32(s.xtype)
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+}
+
+func (b *Backend) GetShaderInfoLog(id uint32) string {
+	return ""
+}
+
+func (b *Backend) DeleteShader(id uint32) {
+	delete(b.shaders, id)
+}
+
+func (b *Backend) CreateProgram() uint32 {
+	id := b.newId()
+	b.programs[id] = &program{}
+	return id
+}
+
+func (b *Backend) DeleteProgram(id uint32) {
+	delete(b.programs, id)
+}
+
+func (b *Backend) AttachShader(id, shaderId uint32) {
+	p, ok := b.programs[id]
+	s, ok2 := b.shaders[shaderId]
+	if !ok || !ok2 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	p.shaders = append(p.shaders, s)
+}
+
+func (b *Backend) LinkProgram(id uint32) {
+	p, ok := b.programs[id]
+	if !ok {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	p.linked, p.impl, p.log = false, nil, ""
+	var files, sources stages
+	fromFiles := false
+	for _, s := range p.shaders {
+		var file, source *string
+		switch s.xtype {
+		case gl.VERTEX_SHADER:
+			file, source = &files.vertex, &sources.vertex
+		case gl.GEOMETRY_SHADER:
+			file, source = &files.geometry, &sources.geometry
+		case gl.FRAGMENT_SHADER:
+			file, source = &files.fragment, &sources.fragment
+		}
+		if s.file != "" {
+			*file = filepath.Base(s.file)
+			fromFiles = true
+		}
+		*source = s.source
+	}
+	impl := registeredSources[sources]
+	if fromFiles {
+		impl = registered[files]
+	}
+	geometry := files.geometry != "" || sources.geometry != ""
+	if impl == nil || impl.Vertex == nil || impl.Fragment == nil || geometry != (impl.Geometry != nil) {
+		if fromFiles {
+			p.log = fmt.Sprintf("soft: no Go implementation registered for %q", strings.Join(files.names(), ", "))
+		} else {
+			p.log = "soft: no Go implementation registered for the shader sources"
+		}
+		return
+	}
+	p.linked, p.impl = true, impl
+	p.uniformIds = make(map[string]int32)
+	p.uniforms = nil
+	p.blockBindings = make([]uint32, len(impl.Blocks))
+}
+
+func (s stages) names() []string {
+	if s.geometry == "" {
+		return []string{s.vertex, s.fragment}
+	}
+	return []string{s.vertex, s.geometry, s.fragment}
+}
+
+func (b *Backend) UseProgram(id uint32) {
+	if id == 0 {
+		b.current = nil
+		return
+	}
+	p, ok := b.programs[id]
+	if !ok || !p.linked {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	b.current = p
+}
+
+func (b *Backend) GetProgramiv(id, pname uint32, params *int32) {
+	p, ok := b.programs[id]
+	if !ok {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	switch pname {
+	case gl.LINK_STATUS:
+		*params = gl.FALSE
+		if p.linked {
+			*params = gl.TRUE
+		}
+	case gl.INFO_LOG_LENGTH:
+		*params = 0
+		if p.log != "" {
+			*params = int32(len(p.log) + 1)
+		}
+	case gl.ATTACHED_SHADERS:
+		*params = int32(len(p.shaders))
+	case gl.ACTIVE_UNIFORMS:
+		// the uniforms of Go programs are only known once they are set
+		*params = 0
+	case gl.ACTIVE_ATTRIBUTES:
+		*params = 0
+		if p.impl != nil {
+			*params = int32(len(p.impl.Attributes))
+		}
+	case gl.ACTIVE_UNIFORM_BLOCKS:
+		*params = 0
+		if p.impl != nil {
+			*params = int32(len(p.impl.Blocks))
+		}
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+}
+
+func (b *Backend) GetProgramInfoLog(id uint32) string {
+	if p, ok := b.programs[id]; ok {
+		return p.log
+	}
+	b.setError(gl.INVALID_VALUE)
+	return ""
+}
+
+// linkedProgram returns a linked program or sets an error.
+func (b *Backend) linkedProgram(id uint32) *program {
+	p, ok := b.programs[id]
+	if !ok {
+		b.setError(gl.INVALID_VALUE)
+		return nil
+	}
+	if !p.linked {
+		b.setError(gl.INVALID_OPERATION)
+		return nil
+	}
+	return p
+}
+
+// splitElement splits "name[i]" into "name" and i.
+func splitElement(name string) (string, int) {
+	if !strings.HasSuffix(name, "]") {
+		return name, 0
+	}
+	i := strings.LastIndexByte(name, '[')
+	if i < 0 {
+		return name, 0
+	}
+	n, err := strconv.Atoi(name[i+1 : len(name)-1])
+	if err != nil || n < 0 {
+		return name, 0
+	}
+	return name[:i], n
+}
+
+func (b *Backend) GetUniformLocation(id uint32, name string) int32 {
+	p := b.linkedProgram(id)
+	if p == nil {
+		return -1
+	}
+	base, element := splitElement(strings.TrimSuffix(name, "\x00"))
+	if element >= maxUniformElements {
+		return -1
+	}
+	uid, ok := p.uniformIds[base]
+	if !ok {
+		uid = int32(len(p.uniforms))
+		p.uniformIds[base] = uid
+		p.uniforms = append(p.uniforms, &uniformValue{})
+	}
+	return uid<<uniformElementBits | int32(element)
+}
+
+// uniformValue returns the storage of a location of the current program, nil for -1 or on error.
+func (b *Backend) uniformValue(loc int32) (*uniformValue, int) {
+	if loc == -1 {
+		return nil, 0
+	}
+	if b.current == nil || loc < 0 || int(loc>>uniformElementBits) >= len(b.current.uniforms) {
+		b.setError(gl.INVALID_OPERATION)
+		return nil, 0
+	}
+	return b.current.uniforms[loc>>uniformElementBits], int(loc & (maxUniformElements - 1))
+}
+
+func (b *Backend) setUniform(loc int32, comps int, floats []float32, ints []int32) {
+	u, element := b.uniformValue(loc)
+	if u == nil {
+		return
+	}
+	n := len(floats)
+	if ints != nil {
+		n = len(ints)
+	}
+	if u.comps != comps {
+		u.comps, u.floats, u.ints = comps, nil, nil
+	}
+	if end := element*comps + n; end > len(u.floats) {
+		u.floats = append(u.floats, make([]float32, end-len(u.floats))...)
+		u.ints = append(u.ints, make([]int32, end-len(u.ints))...)
+	}
+	for i := 0; i < n; i++ {
+		j := element*comps + i
+		if ints != nil {
+			u.ints[j], u.floats[j] = ints[i], float32(ints[i])
+		} else {
+			u.floats[j], u.ints[j] = floats[i], int32(floats[i])
+		}
+	}
+}
+
+func (b *Backend) Uniform1i(loc, v int32) {
+	b.setUniform(loc, 1, nil, []int32{v})
+}
+
+func (b *Backend) Uniform1iv(loc, num int32, v *int32) {
+	b.setUniform(loc, 1, nil, unsafe.Slice(v, num))
+}
+
+func (b *Backend) Uniform1f(loc int32, v0 float32) {
+	b.setUniform(loc, 1, []float32{v0}, nil)
+}
+
+func (b *Backend) Uniform2f(loc int32, v0, v1 float32) {
+	b.setUniform(loc, 2, []float32{v0, v1}, nil)
+}
+
+func (b *Backend) Uniform3f(loc int32, v0, v1, v2 float32) {
+	b.setUniform(loc, 3, []float32{v0, v1, v2}, nil)
+}
+
+func (b *Backend) Uniform4f(loc int32, v0, v1, v2, v3 float32) {
+	b.setUniform(loc, 4, []float32{v0, v1, v2, v3}, nil)
+}
+
+func (b *Backend) Uniform1fv(loc, num int32, v *float32) {
+	b.setUniform(loc, 1, unsafe.Slice(v, num), nil)
+}
+
+func (b *Backend) Uniform2fv(loc, num int32, v *float32) {
+	b.setUniform(loc, 2, unsafe.Slice(v, 2*num), nil)
+}
+
+func (b *Backend) Uniform3fv(loc, num int32, v *float32) {
+	b.setUniform(loc, 3, unsafe.Slice(v, 3*num), nil)
+}
+
+func (b *Backend) Uniform4fv(loc, num int32, v *float32) {
+	b.setUniform(loc, 4, unsafe.Slice(v, 4*num), nil)
+}
+
+// uniformMatrix stores n x n matrices column major.
+func (b *Backend) uniformMatrix(loc, num int32, n int, transpose bool, v *float32) {
+	values := unsafe.Slice(v, int(num)*n*n)
+	if transpose {
+		t := make([]float32, len(values))
+		for m := 0; m < int(num); m++ {
+			for c := 0; c < n; c++ {
+				for r := 0; r < n; r++ {
+					t[m*n*n+c*n+r] = values[m*n*n+r*n+c]
+				}
+			}
+		}
+		values = t
+	}
+	b.setUniform(loc, n*n, values, nil)
+}
+
+func (b *Backend) UniformMatrix2fv(loc, num int32, t bool, v *float32) {
+	b.uniformMatrix(loc, num, 2, t, v)
+}
+
+func (b *Backend) UniformMatrix3fv(loc, num int32, t bool, v *float32) {
+	b.uniformMatrix(loc, num, 3, t, v)
+}
+
+func (b *Backend) UniformMatrix4fv(loc, num int32, t bool, v *float32) {
+	b.uniformMatrix(loc, num, 4, t, v)
+}
+
+func (b *Backend) GetUniformBlockIndex(id uint32, uniformBlockName *uint8) uint32 {
+	p := b.linkedProgram(id)
+	if p == nil {
+		return gl.INVALID_INDEX
+	}
+	name := cString(uniformBlockName)
+	for i, block := range p.impl.Blocks {
+		if block == name {
+			return uint32(i)
+		}
+	}
+	return gl.INVALID_INDEX
+}
+
+func cString(p *uint8) string {
+	if p == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for ptr := unsafe.Pointer(p); *(*uint8)(ptr) != 0; ptr = unsafe.Add(ptr, 1) {
+		sb.WriteByte(*(*uint8)(ptr))
+	}
+	return sb.String()
+}
+
+func (b *Backend) UniformBlockBinding(id uint32, uniformBlockIndex uint32, uniformBlockBinding uint32) {
+	p := b.linkedProgram(id)
+	if p == nil {
+		return
+	}
+	if int(uniformBlockIndex) >= len(p.blockBindings) || uniformBlockBinding >= MAX_UNIFORM_BUFFER_BINDINGS {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	p.blockBindings[uniformBlockIndex] = uniformBlockBinding
+}
+
+// GetActiveUniform always fails, the uniforms of Go programs aren't declared.
+func (b *Backend) GetActiveUniform(id, index uint32) (string, int32, uint32) {
+	b.setError(gl.INVALID_VALUE)
+	return "", 0, 0
+}
+
+func (b *Backend) GetActiveUniformsiv(id uint32, uniformCount int32, uniformIndices *uint32, pname uint32, params *int32) {
+	b.setError(gl.INVALID_VALUE)
+}
+
+func (b *Backend) GetActiveUniformBlockiv(id, uniformBlockIndex, pname uint32, params *int32) {
+	p := b.linkedProgram(id)
+	if p == nil {
+		return
+	}
+	if int(uniformBlockIndex) >= len(p.blockBindings) {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	switch pname {
+	case gl.UNIFORM_BLOCK_BINDING:
+		*params = int32(p.blockBindings[uniformBlockIndex])
+	case gl.UNIFORM_BLOCK_DATA_SIZE:
+		// the layout of a Go block is up to the stages reading it
+		*params = 0
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+}
+
+func (b *Backend) GetActiveUniformBlockName(id, uniformBlockIndex uint32) string {
+	p := b.linkedProgram(id)
+	if p == nil {
+		return ""
+	}
+	if int(uniformBlockIndex) >= len(p.impl.Blocks) {
+		b.setError(gl.INVALID_VALUE)
+		return ""
+	}
+	return p.impl.Blocks[uniformBlockIndex]
+}
+
+// attributeNames returns the attribute names of a Go program sorted by location.
+func attributeNames(p *Program) []string {
+	names := make([]string, 0, len(p.Attributes))
+	for name := range p.Attributes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		li, lj := p.Attributes[names[i]], p.Attributes[names[j]]
+		if li != lj {
+			return li < lj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// GetActiveAttrib reports every attribute as a vec4, the Go stages get all of them as mgl32.Vec4.
+func (b *Backend) GetActiveAttrib(id, index uint32) (string, int32, uint32) {
+	p := b.linkedProgram(id)
+	if p == nil {
+		return "", 0, 0
+	}
+	names := attributeNames(p.impl)
+	if int(index) >= len(names) {
+		b.setError(gl.INVALID_VALUE)
+		return "", 0, 0
+	}
+	return names[index], 1, gl.FLOAT_VEC4
+}
+
+func (b *Backend) GetAttribLocation(id uint32, name string) int32 {
+	p := b.linkedProgram(id)
+	if p == nil {
+		return -1
+	}
+	if loc, ok := p.impl.Attributes[strings.TrimSuffix(name, "\x00")]; ok {
+		return int32(loc)
+	}
+	return -1
+}
+
+func (b *Backend) BindFragDataLocation(id uint32, color uint32, name string) {
+}
+
+// Stage is what a vertex or fragment function runs with: the uniforms of the program, the bound textures and
+// uniform buffers, and the built-in variables.
+type Stage struct {
+	// VertexID and InstanceID are gl_VertexID and gl_InstanceID of a vertex
+	VertexID   int
+	InstanceID int
+	// FragCoord is gl_FragCoord of a fragment, the window position with the depth in Z and 1/w in W
+	FragCoord mgl32.Vec4
+	// FrontFacing is gl_FrontFacing of a fragment
+	FrontFacing bool
+
+	b    *Backend
+	prog *program
+	// texture calls of the current invocation, helpers only record their coordinates
+	calls  int
+	helper bool
+	coords []mgl32.Vec2
+	quad   *quad
+	// geometry stages pass their vertices and the ends of their strips on
+	emit func(v Vertex)
+	end  func()
+}
+
+// EmitVertex outputs a vertex from a geometry stage, like EmitVertex() in GLSL. The varyings are copied.
+func (s *Stage) EmitVertex(position mgl32.Vec4, varyings []float32) {
+	if s.emit != nil {
+		s.emit(Vertex{Position: position, Varyings: varyings})
+	}
+}
+
+// EndPrimitive closes the strip of a geometry stage, like EndPrimitive() in GLSL.
+func (s *Stage) EndPrimitive() {
+	if s.end != nil {
+		s.end()
+	}
+}
+
+// uniform returns the storage and the element index of a uniform, nil if it was never set.
+func (s *Stage) uniform(name string) (*uniformValue, int) {
+	if uid, ok := s.prog.uniformIds[name]; ok {
+		return s.prog.uniforms[uid], 0
+	}
+	base, element := splitElement(name)
+	if uid, ok := s.prog.uniformIds[base]; ok {
+		return s.prog.uniforms[uid], element
+	}
+	return nil, 0
+}
+
+func (s *Stage) floats(name string, n int) []float32 {
+	u, element := s.uniform(name)
+	if u == nil || u.comps == 0 || (element+1)*u.comps > len(u.floats) {
+		return nil
+	}
+	v := u.floats[element*u.comps : (element+1)*u.comps]
+	if len(v) < n {
+		return nil
+	}
+	return v
+}
+
+// Float returns a float uniform, 0 if it wasn't set.
+func (s *Stage) Float(name string) float32 {
+	if v := s.floats(name, 1); v != nil {
+		return v[0]
+	}
+	return 0
+}
+
+// Int returns an int, bool or sampler uniform, 0 if it wasn't set.
+func (s *Stage) Int(name string) int32 {
+	u, element := s.uniform(name)
+	if u == nil || u.comps == 0 || element*u.comps >= len(u.ints) {
+		return 0
+	}
+	return u.ints[element*u.comps]
+}
+
+func (s *Stage) Bool(name string) bool {
+	return s.Int(name) != 0
+}
+
+func (s *Stage) Vec2(name string) mgl32.Vec2 {
+	var v mgl32.Vec2
+	copy(v[:], s.floats(name, 2))
+	return v
+}
+
+func (s *Stage) Vec3(name string) mgl32.Vec3 {
+	var v mgl32.Vec3
+	copy(v[:], s.floats(name, 3))
+	return v
+}
+
+func (s *Stage) Vec4(name string) mgl32.Vec4 {
+	var v mgl32.Vec4
+	copy(v[:], s.floats(name, 4))
+	return v
+}
+
+func (s *Stage) Mat3(name string) mgl32.Mat3 {
+	var m mgl32.Mat3
+	copy(m[:], s.floats(name, 9))
+	return m
+}
+
+func (s *Stage) Mat4(name string) mgl32.Mat4 {
+	var m mgl32.Mat4
+	copy(m[:], s.floats(name, 16))
+	return m
+}
+
+// Texture samples the 2D texture bound to the unit of the sampler uniform name, like texture() in GLSL. Fragment
+// stages select the mipmap level from the screen space derivatives of uv, vertex stages sample the base level.
+func (s *Stage) Texture(name string, uv mgl32.Vec2) mgl32.Vec4 {
+	call := s.calls
+	s.calls++
+	if s.helper {
+		s.coords = append(s.coords, uv)
+		return mgl32.Vec4{}
+	}
+	unit := s.Int(name)
+	if unit < 0 || unit >= MAX_TEXTURE_UNITS {
+		return mgl32.Vec4{0, 0, 0, 1}
+	}
+	t := s.b.textures[s.b.units[unit]]
+	if t == nil {
+		return mgl32.Vec4{0, 0, 0, 1}
+	}
+	var lod float32
+	if s.quad != nil && t.isComplete() && (mipmapFilter(t.minFilter) || t.minFilter != t.magFilter) {
+		dx, dy := s.quad.gradients(s, call, uv)
+		lod = textureLod(t.levels[0], dx, dy)
+	}
+	return t.sample(uv, lod)
+}
+
+// TextureCube samples the cube map bound to the unit of the sampler uniform name in the direction dir, like texture()
+// with a samplerCube. The base level is sampled.
+func (s *Stage) TextureCube(name string, dir mgl32.Vec3) mgl32.Vec4 {
+	if s.helper {
+		return mgl32.Vec4{}
+	}
+	unit := s.Int(name)
+	if unit < 0 || unit >= MAX_TEXTURE_UNITS {
+		return mgl32.Vec4{0, 0, 0, 1}
+	}
+	t := s.b.textures[s.b.units[unit]]
+	if t == nil || t.target != gl.TEXTURE_CUBE_MAP {
+		return mgl32.Vec4{0, 0, 0, 1}
+	}
+	return t.sampleCube(dir)
+}
+
+// TextureSize returns the size of a mipmap level of the texture of a sampler uniform, like textureSize().
+func (s *Stage) TextureSize(name string, level int) (int, int) {
+	unit := s.Int(name)
+	if unit < 0 || unit >= MAX_TEXTURE_UNITS {
+		return 0, 0
+	}
+	t := s.b.textures[s.b.units[unit]]
+	if t == nil || level < 0 || level >= len(t.levels) || t.levels[level] == nil {
+		return 0, 0
+	}
+	return t.levels[level].width, t.levels[level].height
+}
+
+// textureLod returns the log2 of the texels covered by a pixel, given the derivatives of the texture coordinates.
+func textureLod(base *surface, dx, dy mgl32.Vec2) float32 {
+	w, h := float32(base.width), float32(base.height)
+	rx := mgl32.Vec2{dx[0] * w, dx[1] * h}.Len()
+	ry := mgl32.Vec2{dy[0] * w, dy[1] * h}.Len()
+	rho := rx
+	if ry > rho {
+		rho = ry
+	}
+	if rho <= 0 {
+		return float32(math.Inf(-1))
+	}
+	return float32(math.Log2(float64(rho)))
+}
+
+// Block returns the contents of the buffer bound to a uniform block, nil if the block isn't declared or bound.
+func (s *Stage) Block(name string) []byte {
+	for i, block := range s.prog.impl.Blocks {
+		if block == name {
+			return s.b.uniformBlockData(s.prog.blockBindings[i])
+		}
+	}
+	return nil
+}
+
+// Float32At reads a float at a byte offset of a uniform block, 0 outside of it.
+func Float32At(data []byte, offset int) float32 {
+	if offset < 0 || offset+4 > len(data) {
+		return 0
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(data[offset:]))
+}
+
+// Vec4At reads a std140 vec4, or a vec3 with W set to 0, at a byte offset of a uniform block.
+func Vec4At(data []byte, offset int) mgl32.Vec4 {
+	var v mgl32.Vec4
+	for i := range v {
+		v[i] = Float32At(data, offset+4*i)
+	}
+	return v
+}
+
+// Mat4At reads a std140 mat4 at a byte offset of a uniform block.
+func Mat4At(data []byte, offset int) mgl32.Mat4 {
+	var m mgl32.Mat4
+	for i := range m {
+		m[i] = Float32At(data, offset+4*i)
+	}
+	return m
+}
+
+// Mix is mix() of GLSL.
+func Mix(x, y mgl32.Vec4, a float32) mgl32.Vec4 {
+	return x.Mul(1 - a).Add(y.Mul(a))
+}
+
+// Max is max() of GLSL for floats.
+func Max(x, y float32) float32 {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+// Clamp is clamp() of GLSL for floats.
+func Clamp(x, minVal, maxVal float32) float32 {
+	if x < minVal {
+		return minVal
+	}
+	if x > maxVal {
+		return maxVal
+	}
+	return x
+}
+
+// Pow is pow() of GLSL.
+func Pow(x, y float32) float32 {
+	return float32(math.Pow(float64(x), float64(y)))
+}
+
+// Reflect is reflect() of GLSL, n has to be normalized.
+func Reflect(i, n mgl32.Vec3) mgl32.Vec3 {
+	return i.Sub(n.Mul(2 * n.Dot(i)))
+}
+
+// Mul3 multiplies two vectors component-wise, like * of GLSL.
+func Mul3(x, y mgl32.Vec3) mgl32.Vec3 {
+	return mgl32.Vec3{x[0] * y[0], x[1] * y[1], x[2] * y[2]}
+}
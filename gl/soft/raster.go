@@ -0,0 +1,700 @@
+package soft
+
+import (
+	"learn_opengl/gl"
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// vertex is the output of the vertex stage.
+type vertex struct {
+	clip     mgl32.Vec4
+	varyings []float32
+}
+
+// windowVertex is a vertex after the perspective division and the viewport transformation.
+type windowVertex struct {
+	x, y, z  float64
+	invW     float64
+	varyings []float32
+}
+
+type rasterizer struct {
+	b       *Backend
+	prog    *program
+	color   *surface
+	depth   *surface
+	stencil *surface
+	// pixels that may be written, the scissor box and the viewport clipped to the framebuffer
+	x0, y0, x1, y1 int
+	stage          Stage
+	quad           quad
+	in             []float32
+}
+
+func (b *Backend) DrawArrays(mode uint32, first, count int32) {
+	b.DrawArraysInstanced(mode, first, count, 1)
+}
+
+func (b *Backend) DrawArraysInstanced(mode uint32, first, count, instancecount int32) {
+	if first < 0 || count < 0 || instancecount < 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.draw(mode, int(count), int(instancecount), func(i int) (int, bool) {
+		return int(first) + i, true
+	})
+}
+
+func (b *Backend) DrawElements(mode uint32, count int32, typ uint32, offset int) {
+	b.drawElements(mode, count, typ, offset, 1)
+}
+
+func (b *Backend) DrawElementsInstanced(mode uint32, count int32, xtype uint32, indices unsafe.Pointer, instancecount int32) {
+	// with an element array buffer bound the pointer is an offset into it
+	b.drawElements(mode, count, xtype, int(uintptr(indices)), instancecount)
+}
+
+func (b *Backend) drawElements(mode uint32, count int32, xtype uint32, offset int, instancecount int32) {
+	if count < 0 || instancecount < 0 || offset < 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	if xtype != gl.UNSIGNED_BYTE && xtype != gl.UNSIGNED_SHORT && xtype != gl.UNSIGNED_INT {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	buf := b.buffers[b.vertexArray.elementBuffer]
+	if buf == nil || offset > len(buf.data) {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	data := buf.data[offset:]
+	b.draw(mode, int(count), int(instancecount), func(i int) (int, bool) {
+		return readIndex(data, xtype, i)
+	})
+}
+
+func (b *Backend) draw(mode uint32, count, instances int, index func(i int) (int, bool)) {
+	switch mode {
+	case gl.POINTS, gl.LINES, gl.LINE_STRIP, gl.LINE_LOOP, gl.TRIANGLES, gl.TRIANGLE_STRIP, gl.TRIANGLE_FAN:
+	default:
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	if b.current == nil {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	geometry := b.current.impl.Geometry != nil
+	if geometry && primitiveSize(mode) != primitiveSize(b.current.impl.GeometryInput) {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	r := b.newRasterizer()
+	if r.x0 >= r.x1 || r.y0 >= r.y1 {
+		return
+	}
+
+	vertices := make([]*vertex, count)
+	for instance := 0; instance < instances; instance++ {
+		cache := make(map[int]*vertex)
+		for i := range vertices {
+			idx, ok := index(i)
+			if !ok {
+				b.setError(gl.INVALID_OPERATION)
+				return
+			}
+			v, ok := cache[idx]
+			if !ok {
+				v = r.shadeVertex(idx, instance)
+				cache[idx] = v
+			}
+			vertices[i] = v
+		}
+		if geometry {
+			r.geometry(mode, vertices, instance)
+		} else {
+			r.assemble(mode, vertices)
+		}
+	}
+}
+
+func (b *Backend) newRasterizer() *rasterizer {
+	fb := b.drawFb
+	r := &rasterizer{
+		b:       b,
+		prog:    b.current,
+		color:   fb.colorTarget(),
+		depth:   fb.depthTarget(),
+		stencil: fb.stencilTarget(),
+	}
+	r.x0, r.y0, r.x1, r.y1 = b.clipRect(fb)
+	r.x0 = maxInt(r.x0, int(b.viewport[0]))
+	r.y0 = maxInt(r.y0, int(b.viewport[1]))
+	r.x1 = minInt(r.x1, int(b.viewport[0]+b.viewport[2]))
+	r.y1 = minInt(r.y1, int(b.viewport[1]+b.viewport[3]))
+	r.stage = Stage{b: b, prog: r.prog, quad: &r.quad}
+	r.quad = quad{
+		prog: r.prog.impl,
+		dx:   Stage{b: b, prog: r.prog, helper: true},
+		dy:   Stage{b: b, prog: r.prog, helper: true},
+		inX:  make([]float32, r.prog.impl.fragmentVaryings()),
+		inY:  make([]float32, r.prog.impl.fragmentVaryings()),
+	}
+	r.in = make([]float32, r.prog.impl.fragmentVaryings())
+	return r
+}
+
+// fragmentVaryings returns the number of varyings of the fragment stage.
+func (p *Program) fragmentVaryings() int {
+	if p.Geometry != nil {
+		return p.GeometryVaryings
+	}
+	return p.Varyings
+}
+
+func (r *rasterizer) shadeVertex(idx, instance int) *vertex {
+	var in [MAX_VERTEX_ATTRIBS]mgl32.Vec4
+	for i := range in {
+		a := &r.b.vertexArray.attribs[i]
+		if a.enabled {
+			in[i] = r.b.fetch(a, idx, instance)
+		} else {
+			in[i] = mgl32.Vec4{0, 0, 0, 1}
+		}
+	}
+	v := &vertex{varyings: make([]float32, r.prog.impl.Varyings)}
+	s := Stage{b: r.b, prog: r.prog, VertexID: idx, InstanceID: instance}
+	v.clip = r.prog.impl.Vertex(&s, in[:], v.varyings)
+	return v
+}
+
+// primitives splits the vertices of a draw into points, lines or triangles.
+func primitives(mode uint32, v []*vertex, each func(p ...*vertex)) {
+	switch mode {
+	case gl.POINTS:
+		for i := range v {
+			each(v[i])
+		}
+	case gl.LINES:
+		for i := 0; i+1 < len(v); i += 2 {
+			each(v[i], v[i+1])
+		}
+	case gl.LINE_STRIP, gl.LINE_LOOP:
+		for i := 0; i+1 < len(v); i++ {
+			each(v[i], v[i+1])
+		}
+		if mode == gl.LINE_LOOP && len(v) > 2 {
+			each(v[len(v)-1], v[0])
+		}
+	case gl.TRIANGLES:
+		for i := 0; i+2 < len(v); i += 3 {
+			each(v[i], v[i+1], v[i+2])
+		}
+	case gl.TRIANGLE_STRIP:
+		for i := 0; i+2 < len(v); i++ {
+			// every other triangle is flipped to keep the winding of the strip
+			if i%2 == 0 {
+				each(v[i], v[i+1], v[i+2])
+			} else {
+				each(v[i+1], v[i], v[i+2])
+			}
+		}
+	case gl.TRIANGLE_FAN:
+		for i := 1; i+1 < len(v); i++ {
+			each(v[0], v[i], v[i+1])
+		}
+	}
+}
+
+// primitiveSize returns the number of vertices of the primitives of a mode.
+func primitiveSize(mode uint32) int {
+	switch mode {
+	case gl.POINTS:
+		return 1
+	case gl.LINES, gl.LINE_STRIP, gl.LINE_LOOP:
+		return 2
+	}
+	return 3
+}
+
+func (r *rasterizer) assemble(mode uint32, v []*vertex) {
+	primitives(mode, v, r.primitive)
+}
+
+func (r *rasterizer) primitive(p ...*vertex) {
+	switch len(p) {
+	case 1:
+		r.point(p[0])
+	case 2:
+		r.line(p[0], p[1])
+	case 3:
+		r.triangle(p[0], p[1], p[2])
+	}
+}
+
+// geometry runs the geometry stage on every primitive of a draw and rasterizes the strips it outputs.
+func (r *rasterizer) geometry(mode uint32, v []*vertex, instance int) {
+	impl := r.prog.impl
+	var strip []*vertex
+	s := Stage{b: r.b, prog: r.prog, InstanceID: instance}
+	s.emit = func(out Vertex) {
+		v := &vertex{clip: out.Position, varyings: make([]float32, impl.GeometryVaryings)}
+		copy(v.varyings, out.Varyings)
+		strip = append(strip, v)
+	}
+	s.end = func() {
+		primitives(impl.GeometryOutput, strip, r.primitive)
+		strip = nil
+	}
+	in := make([]Vertex, primitiveSize(impl.GeometryInput))
+	primitives(mode, v, func(p ...*vertex) {
+		for i := range p {
+			in[i] = Vertex{Position: p[i].clip, Varyings: p[i].varyings}
+		}
+		impl.Geometry(&s, in)
+		s.end()
+	})
+}
+
+// lerpVertex interpolates two vertices in clip space.
+func lerpVertex(a, b *vertex, t float32) *vertex {
+	v := &vertex{clip: a.clip.Add(b.clip.Sub(a.clip).Mul(t)), varyings: make([]float32, len(a.varyings))}
+	for i := range v.varyings {
+		v.varyings[i] = a.varyings[i] + (b.varyings[i]-a.varyings[i])*t
+	}
+	return v
+}
+
+// clipPlanes are the near (z >= -w) and far (z <= w) planes, the others are handled by the pixel rectangle.
+var clipPlanes = []func(v mgl32.Vec4) float32{
+	func(v mgl32.Vec4) float32 { return v[2] + v[3] },
+	func(v mgl32.Vec4) float32 { return v[3] - v[2] },
+}
+
+// clipPolygon clips a convex polygon against the near and far planes.
+func clipPolygon(poly []*vertex) []*vertex {
+	for _, plane := range clipPlanes {
+		if len(poly) == 0 {
+			return nil
+		}
+		var out []*vertex
+		for i, a := range poly {
+			b := poly[(i+1)%len(poly)]
+			da, db := plane(a.clip), plane(b.clip)
+			if da >= 0 {
+				out = append(out, a)
+			}
+			if (da >= 0) != (db >= 0) {
+				out = append(out, lerpVertex(a, b, da/(da-db)))
+			}
+		}
+		poly = out
+	}
+	return poly
+}
+
+func (r *rasterizer) toWindow(v *vertex) windowVertex {
+	vp := r.b.viewport
+	w := float64(v.clip[3])
+	return windowVertex{
+		x:        float64(vp[0]) + (float64(v.clip[0])/w+1)*float64(vp[2])/2,
+		y:        float64(vp[1]) + (float64(v.clip[1])/w+1)*float64(vp[3])/2,
+		z:        (float64(v.clip[2])/w + 1) / 2,
+		invW:     1 / w,
+		varyings: v.varyings,
+	}
+}
+
+func (r *rasterizer) triangle(v0, v1, v2 *vertex) {
+	poly := clipPolygon([]*vertex{v0, v1, v2})
+	if len(poly) < 3 {
+		return
+	}
+	w := make([]windowVertex, len(poly))
+	for i, v := range poly {
+		if v.clip[3] <= 0 {
+			return
+		}
+		w[i] = r.toWindow(v)
+	}
+	for i := 1; i+1 < len(w); i++ {
+		r.rasterTriangle(w[0], w[i], w[i+1])
+	}
+}
+
+func edge(a, b *windowVertex, x, y float64) float64 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// topLeft tells whether the edge a->b of a counter clockwise triangle owns the pixels exactly on it.
+func topLeft(a, b *windowVertex) bool {
+	dx, dy := b.x-a.x, b.y-a.y
+	return dy < 0 || dy == 0 && dx < 0
+}
+
+func (r *rasterizer) rasterTriangle(v0, v1, v2 windowVertex) {
+	area := edge(&v0, &v1, v2.x, v2.y)
+	if area == 0 || math.IsNaN(area) {
+		return
+	}
+	// counter clockwise triangles are front facing, back faces are culled
+	front := area > 0
+	if r.b.cullFace && !front {
+		return
+	}
+	if !front {
+		v1, v2 = v2, v1
+		area = -area
+	}
+
+	minX := maxInt(r.x0, int(math.Floor(math.Min(v0.x, math.Min(v1.x, v2.x)))))
+	maxX := minInt(r.x1-1, int(math.Ceil(math.Max(v0.x, math.Max(v1.x, v2.x)))))
+	minY := maxInt(r.y0, int(math.Floor(math.Min(v0.y, math.Min(v1.y, v2.y)))))
+	maxY := minInt(r.y1-1, int(math.Ceil(math.Max(v0.y, math.Max(v1.y, v2.y)))))
+	tl0, tl1, tl2 := topLeft(&v1, &v2), topLeft(&v2, &v0), topLeft(&v0, &v1)
+
+	// interpolate writes the perspective correct varyings at a window position to out and returns the depth and the
+	// interpolated 1/w, positions outside of the triangle are extrapolated
+	interpolate := func(px, py float64, out []float32) (float64, float64) {
+		l0 := edge(&v1, &v2, px, py) / area
+		l1 := edge(&v2, &v0, px, py) / area
+		l2 := 1 - l0 - l1
+		k0, k1, k2 := l0*v0.invW, l1*v1.invW, l2*v2.invW
+		invW := k0 + k1 + k2
+		for i := range out {
+			out[i] = float32((k0*float64(v0.varyings[i]) + k1*float64(v1.varyings[i]) + k2*float64(v2.varyings[i])) / invW)
+		}
+		return l0*v0.z + l1*v1.z + l2*v2.z, invW
+	}
+	r.quad.interp = func(px, py float64, out []float32) {
+		interpolate(px, py, out)
+	}
+
+	for y := minY; y <= maxY; y++ {
+		py := float64(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float64(x) + 0.5
+			e0, e1, e2 := edge(&v1, &v2, px, py), edge(&v2, &v0, px, py), edge(&v0, &v1, px, py)
+			if e0 < 0 || e1 < 0 || e2 < 0 || e0 == 0 && !tl0 || e1 == 0 && !tl1 || e2 == 0 && !tl2 {
+				continue
+			}
+			z, invW := interpolate(px, py, r.in)
+			r.fragment(x, y, z, invW, front, true)
+		}
+	}
+}
+
+// clipLine clips a segment against the near and far planes.
+func clipLine(a, b *vertex) (*vertex, *vertex, bool) {
+	t0, t1 := float32(0), float32(1)
+	for _, plane := range clipPlanes {
+		da, db := plane(a.clip), plane(b.clip)
+		if da < 0 && db < 0 {
+			return nil, nil, false
+		}
+		if da < 0 {
+			if t := da / (da - db); t > t0 {
+				t0 = t
+			}
+		} else if db < 0 {
+			if t := da / (da - db); t < t1 {
+				t1 = t
+			}
+		}
+	}
+	if t0 > t1 {
+		return nil, nil, false
+	}
+	return lerpVertex(a, b, t0), lerpVertex(a, b, t1), true
+}
+
+// line draws a one pixel wide segment, the last pixel is left out so that strips don't draw it twice.
+func (r *rasterizer) line(a, b *vertex) {
+	a, b, ok := clipLine(a, b)
+	if !ok || a.clip[3] <= 0 || b.clip[3] <= 0 {
+		return
+	}
+	wa, wb := r.toWindow(a), r.toWindow(b)
+	steps := int(math.Ceil(math.Max(math.Abs(wb.x-wa.x), math.Abs(wb.y-wa.y))))
+	for i := 0; i < steps; i++ {
+		t := (float64(i) + 0.5) / float64(steps)
+		x := int(math.Floor(wa.x + (wb.x-wa.x)*t))
+		y := int(math.Floor(wa.y + (wb.y-wa.y)*t))
+		if x < r.x0 || x >= r.x1 || y < r.y0 || y >= r.y1 {
+			continue
+		}
+		ka, kb := (1-t)*wa.invW, t*wb.invW
+		invW := ka + kb
+		for j := range r.in {
+			r.in[j] = float32((ka*float64(wa.varyings[j]) + kb*float64(wb.varyings[j])) / invW)
+		}
+		r.fragment(x, y, wa.z+(wb.z-wa.z)*t, invW, true, false)
+	}
+}
+
+// point draws a point as a single pixel.
+func (r *rasterizer) point(v *vertex) {
+	for _, plane := range clipPlanes {
+		if plane(v.clip) < 0 {
+			return
+		}
+	}
+	if v.clip[3] <= 0 {
+		return
+	}
+	w := r.toWindow(v)
+	x, y := int(math.Floor(w.x)), int(math.Floor(w.y))
+	if x < r.x0 || x >= r.x1 || y < r.y0 || y >= r.y1 {
+		return
+	}
+	copy(r.in, v.varyings)
+	r.fragment(x, y, w.z, w.invW, true, false)
+}
+
+// fragment runs the fragment stage and the per fragment operations for the pixel (x, y) with the varyings in r.in.
+// Derivatives are only available for triangles.
+func (r *rasterizer) fragment(x, y int, z, invW float64, front, derivatives bool) {
+	b := r.b
+	depth := float32(z)
+	depthPass := true
+	if b.depthTest && r.depth != nil {
+		depthPass = compare(b.depthFunc, depth, r.depth.depth(x, y))
+	}
+	stencilPass := true
+	var stencil uint8
+	if b.stencilTest && r.stencil != nil {
+		stencil = r.stencil.stencil[y*r.stencil.width+x]
+		ref := uint32(clampStencil(b.stencilRef))
+		stencilPass = compare(b.stencilFunc, float32(ref&b.stencilMask), float32(uint32(stencil)&b.stencilMask))
+	}
+	// a fragment failing the tests only needs to be shaded if a stencil operation writes, a discard prevents it
+	if !stencilPass && b.stencilFail == gl.KEEP || stencilPass && !depthPass && (!b.stencilTest || b.stencilZFail == gl.KEEP) {
+		return
+	}
+
+	s := &r.stage
+	s.FragCoord = mgl32.Vec4{float32(x) + 0.5, float32(y) + 0.5, depth, float32(invW)}
+	s.FrontFacing = front
+	s.calls = 0
+	s.quad = nil
+	if derivatives {
+		s.quad = &r.quad
+		r.quad.reset(float64(x)+0.5, float64(y)+0.5, front)
+	}
+	color, keep := r.prog.impl.Fragment(s, r.in)
+	if !keep {
+		return
+	}
+
+	if !stencilPass {
+		r.stencilOp(x, y, stencil, b.stencilFail)
+		return
+	}
+	if !depthPass {
+		r.stencilOp(x, y, stencil, b.stencilZFail)
+		return
+	}
+	if b.stencilTest && r.stencil != nil {
+		r.stencilOp(x, y, stencil, b.stencilZPass)
+	}
+	if b.depthTest && b.depthMask && r.depth != nil {
+		r.depth.setDepth(x, y, depth)
+	}
+
+	if r.color == nil {
+		return
+	}
+	dst := r.color.at(x, y)
+	if !r.color.format.float {
+		for i := range color {
+			color[i] = clamp01(color[i])
+		}
+	}
+	if b.blend {
+		color = blend(color, dst, b.blendSrc, b.blendDst)
+	}
+	for i := 0; i < 4; i++ {
+		if !b.colorMask[i] {
+			color[i] = dst[i]
+		}
+	}
+	r.color.set(x, y, color)
+}
+
+func clampStencil(ref int32) uint8 {
+	if ref < 0 {
+		return 0
+	}
+	if ref > 0xff {
+		return 0xff
+	}
+	return uint8(ref)
+}
+
+func (r *rasterizer) stencilOp(x, y int, old uint8, op uint32) {
+	if !r.b.stencilTest || r.stencil == nil {
+		return
+	}
+	v := old
+	switch op {
+	case gl.KEEP:
+		return
+	case gl.ZERO:
+		v = 0
+	case gl.REPLACE:
+		v = clampStencil(r.b.stencilRef)
+	case gl.INCR:
+		if v < 0xff {
+			v++
+		}
+	case gl.INCR_WRAP:
+		v++
+	case gl.DECR:
+		if v > 0 {
+			v--
+		}
+	case gl.DECR_WRAP:
+		v--
+	case gl.INVERT:
+		v = ^v
+	}
+	mask := uint8(r.b.stencilWrite)
+	r.stencil.stencil[y*r.stencil.width+x] = old&^mask | v&mask
+}
+
+func compare(fn uint32, v, ref float32) bool {
+	switch fn {
+	case gl.NEVER:
+		return false
+	case gl.LESS:
+		return v < ref
+	case gl.EQUAL:
+		return v == ref
+	case gl.LEQUAL:
+		return v <= ref
+	case gl.GREATER:
+		return v > ref
+	case gl.NOTEQUAL:
+		return v != ref
+	case gl.GEQUAL:
+		return v >= ref
+	}
+	return true
+}
+
+func validCompareFunc(fn uint32) bool {
+	switch fn {
+	case gl.NEVER, gl.LESS, gl.EQUAL, gl.LEQUAL, gl.GREATER, gl.NOTEQUAL, gl.GEQUAL, gl.ALWAYS:
+		return true
+	}
+	return false
+}
+
+func validStencilOp(op uint32) bool {
+	switch op {
+	case gl.KEEP, gl.ZERO, gl.REPLACE, gl.INCR, gl.INCR_WRAP, gl.DECR, gl.DECR_WRAP, gl.INVERT:
+		return true
+	}
+	return false
+}
+
+func validBlendFactor(f uint32) bool {
+	switch f {
+	case gl.ZERO, gl.ONE, gl.SRC_COLOR, gl.ONE_MINUS_SRC_COLOR, gl.DST_COLOR, gl.ONE_MINUS_DST_COLOR,
+		gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA, gl.DST_ALPHA, gl.ONE_MINUS_DST_ALPHA, gl.CONSTANT_COLOR,
+		gl.ONE_MINUS_CONSTANT_COLOR, gl.CONSTANT_ALPHA, gl.ONE_MINUS_CONSTANT_ALPHA, gl.SRC_ALPHA_SATURATE:
+		return true
+	}
+	return false
+}
+
+// blendFactor returns a blend factor, the constant color is (0, 0, 0, 0) since it can't be set.
+func blendFactor(f uint32, src, dst mgl32.Vec4) mgl32.Vec4 {
+	one := mgl32.Vec4{1, 1, 1, 1}
+	switch f {
+	case gl.ONE, gl.ONE_MINUS_CONSTANT_COLOR, gl.ONE_MINUS_CONSTANT_ALPHA:
+		return one
+	case gl.SRC_COLOR:
+		return src
+	case gl.ONE_MINUS_SRC_COLOR:
+		return one.Sub(src)
+	case gl.DST_COLOR:
+		return dst
+	case gl.ONE_MINUS_DST_COLOR:
+		return one.Sub(dst)
+	case gl.SRC_ALPHA:
+		return mgl32.Vec4{src[3], src[3], src[3], src[3]}
+	case gl.ONE_MINUS_SRC_ALPHA:
+		a := 1 - src[3]
+		return mgl32.Vec4{a, a, a, a}
+	case gl.DST_ALPHA:
+		return mgl32.Vec4{dst[3], dst[3], dst[3], dst[3]}
+	case gl.ONE_MINUS_DST_ALPHA:
+		a := 1 - dst[3]
+		return mgl32.Vec4{a, a, a, a}
+	case gl.SRC_ALPHA_SATURATE:
+		f := src[3]
+		if 1-dst[3] < f {
+			f = 1 - dst[3]
+		}
+		return mgl32.Vec4{f, f, f, 1}
+	}
+	return mgl32.Vec4{}
+}
+
+// blend combines the fragment color with the framebuffer color with GL_FUNC_ADD.
+func blend(src, dst mgl32.Vec4, srcFactor, dstFactor uint32) mgl32.Vec4 {
+	sf, df := blendFactor(srcFactor, src, dst), blendFactor(dstFactor, src, dst)
+	var c mgl32.Vec4
+	for i := range c {
+		c[i] = src[i]*sf[i] + dst[i]*df[i]
+	}
+	return c
+}
+
+// quad provides the screen space derivatives of texture coordinates the way a GPU does with its 2x2 pixel quads: the
+// fragment function is run as a helper for the right and the upper neighbour of the pixel, and the coordinates the
+// helpers pass to the same Texture call are subtracted. The helpers only run when a texture needs a mipmap level.
+type quad struct {
+	prog   *Program
+	interp func(px, py float64, out []float32)
+	x, y   float64
+	ran    bool
+	dx, dy Stage
+	inX    []float32
+	inY    []float32
+}
+
+func (q *quad) reset(x, y float64, front bool) {
+	q.x, q.y, q.ran = x, y, false
+	q.dx.FrontFacing, q.dy.FrontFacing = front, front
+}
+
+func (q *quad) run(h *Stage, px, py float64, in []float32) {
+	q.interp(px, py, in)
+	h.FragCoord = mgl32.Vec4{float32(px), float32(py)}
+	h.calls = 0
+	h.coords = h.coords[:0]
+	q.prog.Fragment(h, in)
+}
+
+// gradients returns the derivatives along X and Y of the coordinates of texture call number call.
+func (q *quad) gradients(s *Stage, call int, uv mgl32.Vec2) (mgl32.Vec2, mgl32.Vec2) {
+	if !q.ran {
+		q.ran = true
+		q.run(&q.dx, q.x+1, q.y, q.inX)
+		q.run(&q.dy, q.x, q.y+1, q.inY)
+	}
+	var dx, dy mgl32.Vec2
+	if call < len(q.dx.coords) {
+		dx = q.dx.coords[call].Sub(uv)
+	}
+	if call < len(q.dy.coords) {
+		dy = q.dy.coords[call].Sub(uv)
+	}
+	return dx, dy
+}
@@ -0,0 +1,267 @@
+package soft
+
+import (
+	"learn_opengl/gl"
+	"testing"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const flatVertex, flatFragment = "// flat vs", "// flat fs"
+
+func init() {
+	// draws clip space positions in the color of the uniform "color"
+	RegisterSource(flatVertex, flatFragment, &Program{
+		Attributes: map[string]int{"aPos": 0},
+		Vertex: func(s *Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			return in[0]
+		},
+		Fragment: func(s *Stage, in []float32) (mgl32.Vec4, bool) {
+			return s.Vec4("color"), true
+		},
+	})
+}
+
+// newFlat returns a backend of the given size using the flat program.
+func newFlat(t *testing.T, width, height int) *Backend {
+	b := New(width, height)
+	id := link(b, flatVertex, "", flatFragment)
+	if !b.programs[id].linked {
+		t.Fatalf("link: %v", b.programs[id].log)
+	}
+	b.UseProgram(id)
+	b.ClearColor(0, 0, 0, 1)
+	var vbo uint32
+	b.GenBuffers(1, &vbo)
+	b.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	b.VertexAttribPointer(0, 4, gl.FLOAT, false, 4*4, 0)
+	b.EnableVertexAttribArray(0)
+	return b
+}
+
+// drawFlat draws triangles with the flat program of newFlat.
+func drawFlat(t *testing.T, b *Backend, color mgl32.Vec4, vertices ...mgl32.Vec4) {
+	t.Helper()
+	b.BufferData(gl.ARRAY_BUFFER, len(vertices)*4*4, unsafe.Pointer(&vertices[0]), gl.STREAM_DRAW)
+	b.Uniform4f(b.GetUniformLocation(programId(b), "color"), color[0], color[1], color[2], color[3])
+	b.DrawArrays(gl.TRIANGLES, 0, int32(len(vertices)))
+	if err := b.GetError(); err != gl.NO_ERROR {
+		t.Fatalf("draw: error %#x", err)
+	}
+}
+
+func programId(b *Backend) uint32 {
+	for id, p := range b.programs {
+		if p == b.current {
+			return id
+		}
+	}
+	return 0
+}
+
+// rect returns the two counter clockwise triangles of a rectangle in normalized device coordinates at depth z.
+func rect(x0, y0, x1, y1, z float32) []mgl32.Vec4 {
+	return []mgl32.Vec4{{x0, y0, z, 1}, {x1, y0, z, 1}, {x1, y1, z, 1}, {x0, y0, z, 1}, {x1, y1, z, 1}, {x0, y1, z, 1}}
+}
+
+// pixel returns the color of the default framebuffer at (x, y), counted from the bottom left like GL.
+func pixel(b *Backend, x, y int) [4]uint8 {
+	img := b.Image()
+	c := img.RGBAAt(x, img.Bounds().Dy()-1-y)
+	return [4]uint8{c.R, c.G, c.B, c.A}
+}
+
+var (
+	red   = mgl32.Vec4{1, 0, 0, 1}
+	green = mgl32.Vec4{0, 1, 0, 1}
+	blue  = mgl32.Vec4{0, 0, 1, 1}
+)
+
+func checkRow(t *testing.T, b *Backend, y int, want ...[4]uint8) {
+	t.Helper()
+	for x, w := range want {
+		if got := pixel(b, x, y); got != w {
+			t.Errorf("pixel %v,%v = %v, want %v", x, y, got, w)
+		}
+	}
+}
+
+func TestDepthTest(t *testing.T) {
+	b := newFlat(t, 2, 1)
+	b.Enable(gl.DEPTH_TEST)
+	b.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	drawFlat(t, b, red, rect(-1, -1, 1, 1, 0.5)...)
+	// nearer on the left, farther on the right
+	drawFlat(t, b, green, rect(-1, -1, 0, 1, -0.5)...)
+	drawFlat(t, b, blue, rect(0, -1, 1, 1, 0.9)...)
+	checkRow(t, b, 0, [4]uint8{0, 255, 0, 255}, [4]uint8{255, 0, 0, 255})
+
+	b.DepthFunc(gl.GREATER)
+	drawFlat(t, b, blue, rect(0, -1, 1, 1, 0.9)...)
+	checkRow(t, b, 0, [4]uint8{0, 255, 0, 255}, [4]uint8{0, 0, 255, 255})
+
+	// without depth writes the next draw is still tested against the old depth
+	b.DepthFunc(gl.LESS)
+	b.DepthMask(false)
+	drawFlat(t, b, red, rect(-1, -1, 0, 1, -0.9)...)
+	b.DepthMask(true)
+	drawFlat(t, b, blue, rect(-1, -1, 0, 1, -0.7)...)
+	checkRow(t, b, 0, [4]uint8{0, 0, 255, 255}, [4]uint8{0, 0, 255, 255})
+
+	// the depth test is off by default
+	b.Disable(gl.DEPTH_TEST)
+	drawFlat(t, b, green, rect(0, -1, 1, 1, 1)...)
+	checkRow(t, b, 0, [4]uint8{0, 0, 255, 255}, [4]uint8{0, 255, 0, 255})
+}
+
+func TestStencilTest(t *testing.T) {
+	b := newFlat(t, 4, 1)
+	stencil := b.framebuffers[0].stencilTarget().stencil
+	b.Enable(gl.STENCIL_TEST)
+	b.Clear(gl.COLOR_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+
+	// mark the left half, then draw everywhere else
+	b.StencilFunc(gl.ALWAYS, 1, 0xff)
+	b.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+	drawFlat(t, b, red, rect(-1, -1, 0, 1, 0)...)
+	if want := []uint8{1, 1, 0, 0}; string(stencil) != string(want) {
+		t.Errorf("stencil = %v, want %v", stencil, want)
+	}
+	b.StencilFunc(gl.NOTEQUAL, 1, 0xff)
+	b.StencilMask(0)
+	drawFlat(t, b, green, rect(-1, -1, 1, 1, 0)...)
+	checkRow(t, b, 0, [4]uint8{255, 0, 0, 255}, [4]uint8{255, 0, 0, 255}, [4]uint8{0, 255, 0, 255},
+		[4]uint8{0, 255, 0, 255})
+	if want := []uint8{1, 1, 0, 0}; string(stencil) != string(want) {
+		t.Errorf("stencil after a masked draw = %v, want %v", stencil, want)
+	}
+
+	// saturating and wrapping operations, the depth fail operation runs when only the depth test fails
+	b.StencilMask(0xff)
+	b.StencilFunc(gl.ALWAYS, 0, 0xff)
+	b.StencilOp(gl.KEEP, gl.KEEP, gl.DECR)
+	drawFlat(t, b, red, rect(-1, -1, 1, 1, 0)...)
+	drawFlat(t, b, red, rect(-1, -1, 1, 1, 0)...)
+	if want := []uint8{0, 0, 0, 0}; string(stencil) != string(want) {
+		t.Errorf("stencil after DECR = %v, want %v", stencil, want)
+	}
+	b.StencilOp(gl.KEEP, gl.KEEP, gl.DECR_WRAP)
+	drawFlat(t, b, red, rect(0, -1, 1, 1, 0)...)
+	if want := []uint8{0, 0, 0xff, 0xff}; string(stencil) != string(want) {
+		t.Errorf("stencil after DECR_WRAP = %v, want %v", stencil, want)
+	}
+	b.StencilOp(gl.KEEP, gl.INCR, gl.KEEP)
+	b.Enable(gl.DEPTH_TEST)
+	b.Clear(gl.DEPTH_BUFFER_BIT)
+	drawFlat(t, b, red, rect(-1, -1, 1, 1, 0)...)
+	drawFlat(t, b, red, rect(-1, -1, 1, 1, 0.5)...)
+	if want := []uint8{1, 1, 0xff, 0xff}; string(stencil) != string(want) {
+		t.Errorf("stencil after INCR on depth fail = %v, want %v", stencil, want)
+	}
+}
+
+func TestBlend(t *testing.T) {
+	b := newFlat(t, 1, 1)
+	b.ClearColor(0, 0, 1, 1)
+	b.Clear(gl.COLOR_BUFFER_BIT)
+	b.Enable(gl.BLEND)
+	b.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	drawFlat(t, b, mgl32.Vec4{1, 0, 0, 0.5}, rect(-1, -1, 1, 1, 0)...)
+	checkRow(t, b, 0, [4]uint8{128, 0, 128, 191})
+
+	// additive blending saturates
+	b.BlendFunc(gl.ONE, gl.ONE)
+	drawFlat(t, b, mgl32.Vec4{0.75, 0.5, 0, 0}, rect(-1, -1, 1, 1, 0)...)
+	checkRow(t, b, 0, [4]uint8{255, 128, 128, 191})
+
+	// the fragment color is clamped before blending
+	b.Clear(gl.COLOR_BUFFER_BIT)
+	b.BlendFunc(gl.DST_COLOR, gl.ZERO)
+	drawFlat(t, b, mgl32.Vec4{2, 2, 0.5, 1}, rect(-1, -1, 1, 1, 0)...)
+	checkRow(t, b, 0, [4]uint8{0, 0, 128, 255})
+
+	b.BlendFunc(gl.ONE, 0x1234)
+	if err := b.GetError(); err != gl.INVALID_ENUM {
+		t.Errorf("invalid blend factor: error %#x, want INVALID_ENUM", err)
+	}
+}
+
+func TestCullFace(t *testing.T) {
+	b := newFlat(t, 2, 1)
+	ccw := []mgl32.Vec4{{-1, -1, 0, 1}, {3, -1, 0, 1}, {-1, 3, 0, 1}}
+	cw := []mgl32.Vec4{{3, -1, 0, 1}, {-1, -1, 0, 1}, {-1, 3, 0, 1}}
+	drawFlat(t, b, red, cw...)
+	checkRow(t, b, 0, [4]uint8{255, 0, 0, 255}, [4]uint8{255, 0, 0, 255})
+
+	b.Clear(gl.COLOR_BUFFER_BIT)
+	b.Enable(gl.CULL_FACE)
+	drawFlat(t, b, red, cw...)
+	checkRow(t, b, 0, [4]uint8{0, 0, 0, 255}, [4]uint8{0, 0, 0, 255})
+	drawFlat(t, b, green, ccw...)
+	checkRow(t, b, 0, [4]uint8{0, 255, 0, 255}, [4]uint8{0, 255, 0, 255})
+}
+
+func TestClipping(t *testing.T) {
+	b := newFlat(t, 4, 1)
+	// z goes from -2 to 0 across the screen, the near plane cuts the left half off
+	drawFlat(t, b, red,
+		mgl32.Vec4{-1, -1, -2, 1}, mgl32.Vec4{1, -1, 0, 1}, mgl32.Vec4{1, 1, 0, 1},
+		mgl32.Vec4{-1, -1, -2, 1}, mgl32.Vec4{1, 1, 0, 1}, mgl32.Vec4{-1, 1, -2, 1})
+	black, r := [4]uint8{0, 0, 0, 255}, [4]uint8{255, 0, 0, 255}
+	checkRow(t, b, 0, black, black, r, r)
+
+	// beyond the far plane
+	b.Clear(gl.COLOR_BUFFER_BIT)
+	drawFlat(t, b, red, rect(-1, -1, 1, 1, 1.5)...)
+	checkRow(t, b, 0, black, black, black, black)
+
+	// behind the eye
+	drawFlat(t, b, red, mgl32.Vec4{-1, -1, 0, -1}, mgl32.Vec4{1, -1, 0, -1}, mgl32.Vec4{0, 1, 0, -1})
+	checkRow(t, b, 0, black, black, black, black)
+
+	// far outside of the viewport in x and y, the pixel rectangle bounds the rasterization
+	drawFlat(t, b, green, mgl32.Vec4{-1000, -1000, 0, 1}, mgl32.Vec4{1000, -1000, 0, 1}, mgl32.Vec4{0, 1000, 0, 1})
+	g := [4]uint8{0, 255, 0, 255}
+	checkRow(t, b, 0, g, g, g, g)
+}
+
+func TestClipPolygon(t *testing.T) {
+	v := func(z float32) *vertex {
+		return &vertex{clip: mgl32.Vec4{0, 0, z, 1}, varyings: []float32{z}}
+	}
+	for _, c := range []struct {
+		z    []float32
+		want []float32
+	}{
+		{[]float32{0, 0.5, -0.5}, []float32{0, 0.5, -0.5}},
+		// one vertex behind the near plane makes a quad
+		{[]float32{-3, 0, 0}, []float32{-1, 0, 0, -1}},
+		// two beyond the far plane leave a triangle
+		{[]float32{0, 3, 3}, []float32{0, 1, 1}},
+		{[]float32{-2, -2, -3}, nil},
+	} {
+		var poly []*vertex
+		for _, z := range c.z {
+			poly = append(poly, v(z))
+		}
+		out := clipPolygon(poly)
+		var got []float32
+		for _, o := range out {
+			got = append(got, o.clip[2])
+			if o.varyings[0] != o.clip[2] {
+				t.Errorf("clipPolygon(%v): varying %v isn't interpolated like z %v", c.z, o.varyings[0], o.clip[2])
+			}
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("clipPolygon(%v) = %v, want %v", c.z, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("clipPolygon(%v) = %v, want %v", c.z, got, c.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package soft is a gl.Backend that rasterizes in pure Go, it needs neither a GPU nor a GL driver.
+//
+// It implements the subset of OpenGL 3.3 the samples use: vertex arrays and buffers, 2D textures with mipmaps, cube
+// maps, framebuffers with texture and renderbuffer attachments, the depth and stencil tests, blending, face culling,
+// instancing and geometry stages. Multisampling is ignored.
+//
+// GLSL isn't interpreted, the vertex, geometry and fragment stages are Go functions registered for the shader files
+// they replace, see Register, RegisterGeometry and RegisterSource. The default framebuffer is an RGBA8 color buffer
+// with a depth and a stencil buffer, Image returns its contents. Rendering is deterministic, the same calls always
+// produce the same pixels.
+//
+//	b := soft.New(800, 600)
+//	gl.SetBackend(b)
+//	... render with the gl package as usual
+//	img := b.Image()
+package soft
+
+import (
+	"image"
+	"learn_opengl/gl"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	MAX_VERTEX_ATTRIBS          = 16
+	MAX_TEXTURE_UNITS           = 32
+	MAX_UNIFORM_BUFFER_BINDINGS = 36
+)
+
+// Backend renders the GL calls of the gl package into memory. It is not safe for concurrent use, like a GL context
+// it belongs to one goroutine.
+type Backend struct {
+	err    uint32
+	nextId uint32
+
+	buffers       map[uint32]*buffer
+	vertexArrays  map[uint32]*vertexArray
+	textures      map[uint32]*texture
+	framebuffers  map[uint32]*framebuffer
+	renderbuffers map[uint32]*renderbuffer
+	shaders       map[uint32]*shader
+	programs      map[uint32]*program
+
+	// bindings
+	arrayBuffer   uint32
+	uniformBuffer uint32
+	vertexArray   *vertexArray
+	drawFb        *framebuffer
+	readFb        *framebuffer
+	renderbuffer  uint32
+	current       *program
+	activeTexture int
+	units         [MAX_TEXTURE_UNITS]uint32
+	uniformBlocks [MAX_UNIFORM_BUFFER_BINDINGS]bufferRange
+
+	// state
+	viewport     [4]int32
+	scissor      [4]int32
+	clearColor   mgl32.Vec4
+	depthTest    bool
+	depthMask    bool
+	depthFunc    uint32
+	stencilTest  bool
+	stencilFunc  uint32
+	stencilRef   int32
+	stencilMask  uint32
+	stencilWrite uint32
+	stencilFail  uint32
+	stencilZFail uint32
+	stencilZPass uint32
+	blend        bool
+	blendSrc     uint32
+	blendDst     uint32
+	colorMask    [4]bool
+	cullFace     bool
+	scissorTest  bool
+	packAlign    int32
+	unpackAlign  int32
+}
+
+var _ gl.Backend = (*Backend)(nil)
+var _ gl.ShaderFileBackend = (*Backend)(nil)
+
+// New returns a backend whose default framebuffer has the given size.
+func New(width, height int) *Backend {
+	b := &Backend{
+		buffers:       make(map[uint32]*buffer),
+		vertexArrays:  map[uint32]*vertexArray{0: {}},
+		textures:      make(map[uint32]*texture),
+		framebuffers:  make(map[uint32]*framebuffer),
+		renderbuffers: make(map[uint32]*renderbuffer),
+		shaders:       make(map[uint32]*shader),
+		programs:      make(map[uint32]*program),
+		depthMask:     true,
+		depthFunc:     gl.LESS,
+		stencilFunc:   gl.ALWAYS,
+		stencilMask:   0xff,
+		stencilWrite:  0xff,
+		stencilFail:   gl.KEEP,
+		stencilZFail:  gl.KEEP,
+		stencilZPass:  gl.KEEP,
+		blendSrc:      gl.ONE,
+		blendDst:      gl.ZERO,
+		colorMask:     [4]bool{true, true, true, true},
+		packAlign:     4,
+		unpackAlign:   4,
+	}
+	b.vertexArray = b.vertexArrays[0]
+	b.framebuffers[0] = newDefaultFramebuffer(width, height)
+	b.drawFb = b.framebuffers[0]
+	b.readFb = b.framebuffers[0]
+	b.viewport = [4]int32{0, 0, int32(width), int32(height)}
+	b.scissor = b.viewport
+	return b
+}
+
+// Size returns the size of the default framebuffer.
+func (b *Backend) Size() (int, int) {
+	s := b.framebuffers[0].color.surface()
+	return s.width, s.height
+}
+
+// Resize reallocates the default framebuffer, its contents are lost.
+func (b *Backend) Resize(width, height int) {
+	fb := newDefaultFramebuffer(width, height)
+	*b.framebuffers[0] = *fb
+}
+
+// Image returns the color buffer of the default framebuffer, the top row first.
+func (b *Backend) Image() *image.RGBA {
+	return surfaceImage(b.framebuffers[0].color.surface())
+}
+
+func (b *Backend) newId() uint32 {
+	b.nextId++
+	return b.nextId
+}
+
+// setError keeps the first error until GetError is called, like GL does.
+func (b *Backend) setError(err uint32) {
+	if b.err == gl.NO_ERROR {
+		b.err = err
+	}
+}
+
+func (b *Backend) Init() error {
+	return nil
+}
+
+func (b *Backend) GetError() uint32 {
+	err := b.err
+	b.err = gl.NO_ERROR
+	return err
+}
+
+func (b *Backend) Viewport(x, y, width, height int32) {
+	if width < 0 || height < 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.viewport = [4]int32{x, y, width, height}
+}
+
+func (b *Backend) ClearColor(r, g, bl, a float32) {
+	b.clearColor = mgl32.Vec4{clamp01(r), clamp01(g), clamp01(bl), clamp01(a)}
+}
+
+func (b *Backend) Clear(mask uint32) {
+	if mask&^(gl.COLOR_BUFFER_BIT|gl.DEPTH_BUFFER_BIT|gl.STENCIL_BUFFER_BIT) != 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.drawFb.clear(b, mask)
+}
+
+func (b *Backend) setCapability(flag uint32, enable bool) {
+	switch flag {
+	case gl.DEPTH_TEST:
+		b.depthTest = enable
+	case gl.STENCIL_TEST:
+		b.stencilTest = enable
+	case gl.BLEND:
+		b.blend = enable
+	case gl.CULL_FACE:
+		b.cullFace = enable
+	case gl.SCISSOR_TEST:
+		b.scissorTest = enable
+	case gl.MULTISAMPLE:
+		// every framebuffer has a single sample
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+}
+
+func (b *Backend) Disable(flag uint32) {
+	b.setCapability(flag, false)
+}
+
+func (b *Backend) Enable(flag uint32) {
+	b.setCapability(flag, true)
+}
+
+func (b *Backend) Scissor(x, y, w, h int32) {
+	if w < 0 || h < 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	b.scissor = [4]int32{x, y, w, h}
+}
+
+func (b *Backend) DepthMask(flag bool) {
+	b.depthMask = flag
+}
+
+func (b *Backend) ColorMask(r, g, bl, a bool) {
+	b.colorMask = [4]bool{r, g, bl, a}
+}
+
+func (b *Backend) BlendFunc(src, dst uint32) {
+	if !validBlendFactor(src) || !validBlendFactor(dst) {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	b.blendSrc, b.blendDst = src, dst
+}
+
+func (b *Backend) DepthFunc(fn uint32) {
+	if !validCompareFunc(fn) {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	b.depthFunc = fn
+}
+
+func (b *Backend) StencilMask(mask uint32) {
+	b.stencilWrite = mask
+}
+
+func (b *Backend) StencilFunc(xfunc uint32, ref int32, mask uint32) {
+	if !validCompareFunc(xfunc) {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	b.stencilFunc, b.stencilRef, b.stencilMask = xfunc, ref, mask
+}
+
+func (b *Backend) StencilOp(fail, zfail, zpass uint32) {
+	if !validStencilOp(fail) || !validStencilOp(zfail) || !validStencilOp(zpass) {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	b.stencilFail, b.stencilZFail, b.stencilZPass = fail, zfail, zpass
+}
+
+func (b *Backend) PixelStorei(pname uint32, param int32) {
+	if param != 1 && param != 2 && param != 4 && param != 8 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	switch pname {
+	case gl.PACK_ALIGNMENT:
+		b.packAlign = param
+	case gl.UNPACK_ALIGNMENT:
+		b.unpackAlign = param
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+}
+
+func (b *Backend) Finish() {
+}
+
+// ids returns the n object names pointed to by p.
+func ids(n int32, p *uint32) []uint32 {
+	if n <= 0 || p == nil {
+		return nil
+	}
+	return unsafe.Slice(p, n)
+}
+
+func clamp01(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
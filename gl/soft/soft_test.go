@@ -0,0 +1,152 @@
+package soft
+
+import (
+	"learn_opengl/gl"
+	"testing"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestCubeCoords(t *testing.T) {
+	for _, c := range []struct {
+		dir  mgl32.Vec3
+		face int
+		uv   mgl32.Vec2
+	}{
+		{mgl32.Vec3{1, 0, 0}, 0, mgl32.Vec2{0.5, 0.5}},
+		{mgl32.Vec3{-1, 0, 0}, 1, mgl32.Vec2{0.5, 0.5}},
+		{mgl32.Vec3{0, 1, 0}, 2, mgl32.Vec2{0.5, 0.5}},
+		{mgl32.Vec3{0, -1, 0}, 3, mgl32.Vec2{0.5, 0.5}},
+		{mgl32.Vec3{0, 0, 1}, 4, mgl32.Vec2{0.5, 0.5}},
+		{mgl32.Vec3{0, 0, -1}, 5, mgl32.Vec2{0.5, 0.5}},
+		// +X is seen from the inside, -Z is to the left and -Y at the bottom of the image
+		{mgl32.Vec3{1, -0.5, -0.5}, 0, mgl32.Vec2{0.75, 0.75}},
+		{mgl32.Vec3{0.5, 0.5, 1}, 4, mgl32.Vec2{0.75, 0.25}},
+	} {
+		face, uv := cubeCoords(c.dir)
+		if face != c.face || uv != c.uv {
+			t.Errorf("cubeCoords(%v) = %v, %v, want %v, %v", c.dir, face, uv, c.face, c.uv)
+		}
+	}
+}
+
+func TestTextureCube(t *testing.T) {
+	b := New(1, 1)
+	var id uint32
+	b.GenTextures(1, &id)
+	b.BindTexture(gl.TEXTURE_CUBE_MAP, id)
+	for i := 0; i < 6; i++ {
+		pixel := []uint8{uint8(40 * i), 0, 0}
+		b.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), 0, gl.RGB, 1, 1, 0, gl.RGB, gl.UNSIGNED_BYTE,
+			unsafe.Pointer(&pixel[0]))
+	}
+	b.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	b.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	if err := b.GetError(); err != gl.NO_ERROR {
+		t.Fatalf("error %#x", err)
+	}
+	cube := b.textures[id]
+	if cube.faces[0].minFilter != gl.LINEAR || cube.faces[5].wrapS != gl.CLAMP_TO_EDGE {
+		t.Errorf("the parameters of the cube map are not applied to its faces")
+	}
+	if got := cube.sampleCube(mgl32.Vec3{0, 0, -2}); got != (mgl32.Vec4{200.0 / 255, 0, 0, 1}) {
+		t.Errorf("sampleCube -Z = %v", got)
+	}
+
+	// a 2D texture has no faces
+	var flat uint32
+	b.GenTextures(1, &flat)
+	b.BindTexture(gl.TEXTURE_2D, flat)
+	b.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X, 0, gl.RGB, 1, 1, 0, gl.RGB, gl.UNSIGNED_BYTE, nil)
+	if err := b.GetError(); err != gl.INVALID_OPERATION {
+		t.Errorf("face of a 2D texture: error %#x, want INVALID_OPERATION", err)
+	}
+}
+
+// link links a program from shader sources registered with RegisterSource.
+func link(b *Backend, vertexSource, geometrySource, fragmentSource string) uint32 {
+	id := b.CreateProgram()
+	for _, s := range []struct {
+		xtype  uint32
+		source string
+	}{{gl.VERTEX_SHADER, vertexSource}, {gl.GEOMETRY_SHADER, geometrySource}, {gl.FRAGMENT_SHADER, fragmentSource}} {
+		if s.source == "" {
+			continue
+		}
+		shader := b.CreateShader(s.xtype)
+		b.ShaderSource(shader, s.source+"\x00")
+		b.AttachShader(id, shader)
+	}
+	b.LinkProgram(id)
+	return id
+}
+
+func TestGeometryStage(t *testing.T) {
+	const vertexSource, geometrySource, fragmentSource = "// point vs", "// point gs", "// point fs"
+	// a point expands to a quad covering the left half of the viewport, its color goes through both stages
+	registeredSources[stages{vertexSource, geometrySource, fragmentSource}] = &Program{
+		Attributes: map[string]int{"aPos": 0},
+		Varyings:   1,
+		Vertex: func(s *Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0] = 0.5
+			return in[0]
+		},
+		Geometry: func(s *Stage, in []Vertex) {
+			p := in[0].Position
+			c := []float32{0, in[0].Varyings[0]}
+			s.EmitVertex(p.Add(mgl32.Vec4{-1, -1, 0, 0}), c)
+			s.EmitVertex(p.Add(mgl32.Vec4{0, -1, 0, 0}), c)
+			s.EmitVertex(p.Add(mgl32.Vec4{-1, 1, 0, 0}), c)
+			s.EmitVertex(p.Add(mgl32.Vec4{0, 1, 0, 0}), c)
+			s.EndPrimitive()
+		},
+		GeometryInput:    gl.POINTS,
+		GeometryOutput:   gl.TRIANGLE_STRIP,
+		GeometryVaryings: 2,
+		Fragment: func(s *Stage, in []float32) (mgl32.Vec4, bool) {
+			return mgl32.Vec4{in[0], in[1], 0, 1}, true
+		},
+	}
+	defer delete(registeredSources, stages{vertexSource, geometrySource, fragmentSource})
+
+	b := New(4, 2)
+	if id := link(b, vertexSource, "", fragmentSource); b.programs[id].linked {
+		t.Errorf("the program links without its geometry stage")
+	}
+	id := link(b, vertexSource, geometrySource, fragmentSource)
+	if !b.programs[id].linked {
+		t.Fatalf("link: %v", b.programs[id].log)
+	}
+	b.UseProgram(id)
+
+	point := []float32{0, 0, 0}
+	var vbo uint32
+	b.GenBuffers(1, &vbo)
+	b.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	b.BufferData(gl.ARRAY_BUFFER, len(point)*4, unsafe.Pointer(&point[0]), gl.STATIC_DRAW)
+	b.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, 0)
+	b.EnableVertexAttribArray(0)
+	b.DrawArrays(gl.POINTS, 0, 1)
+	if err := b.GetError(); err != gl.NO_ERROR {
+		t.Fatalf("draw: error %#x", err)
+	}
+	img := b.Image()
+	for x := 0; x < 4; x++ {
+		want := [4]uint8{0, 0, 0, 255}
+		if x < 2 {
+			want = [4]uint8{0, 128, 0, 255}
+		}
+		for y := 0; y < 2; y++ {
+			c := img.RGBAAt(x, y)
+			if [4]uint8{c.R, c.G, c.B, c.A} != want {
+				t.Errorf("pixel %v,%v = %v, want %v", x, y, c, want)
+			}
+		}
+	}
+
+	b.DrawArrays(gl.TRIANGLES, 0, 3)
+	if err := b.GetError(); err != gl.INVALID_OPERATION {
+		t.Errorf("triangles into a point geometry stage: error %#x, want INVALID_OPERATION", err)
+	}
+}
@@ -0,0 +1,699 @@
+package soft
+
+import (
+	"encoding/binary"
+	"image"
+	"learn_opengl/gl"
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// format is what the backend keeps of an internal format.
+type format struct {
+	// channels is the number of color components kept, the others read as 0 and alpha as 1
+	channels int
+	// float formats are neither clamped nor quantized
+	float   bool
+	srgb    bool
+	depth   bool
+	stencil bool
+}
+
+func parseFormat(internal uint32) (format, bool) {
+	switch internal {
+	case gl.RED, gl.R8:
+		return format{channels: 1}, true
+	case gl.R16F, gl.R32F:
+		return format{channels: 1, float: true}, true
+	case gl.RG, gl.RG8:
+		return format{channels: 2}, true
+	case gl.RG16F, gl.RG32F:
+		return format{channels: 2, float: true}, true
+	case gl.RGB, gl.RGB8:
+		return format{channels: 3}, true
+	case gl.RGB16F, gl.RGB32F:
+		return format{channels: 3, float: true}, true
+	case gl.RGBA, gl.RGBA8:
+		return format{channels: 4}, true
+	case gl.RGBA16F, gl.RGBA32F:
+		return format{channels: 4, float: true}, true
+	case gl.SRGB, gl.SRGB8:
+		return format{channels: 3, srgb: true}, true
+	case gl.SRGB8_ALPHA8:
+		return format{channels: 4, srgb: true}, true
+	case gl.DEPTH_COMPONENT, gl.DEPTH_COMPONENT16, gl.DEPTH_COMPONENT24, gl.DEPTH_COMPONENT32F:
+		return format{channels: 1, depth: true}, true
+	case gl.DEPTH_STENCIL, gl.DEPTH24_STENCIL8, gl.DEPTH32F_STENCIL8:
+		return format{channels: 1, depth: true, stencil: true}, true
+	case gl.STENCIL_INDEX8:
+		return format{stencil: true}, true
+	}
+	return format{}, false
+}
+
+// surface is an image of a texture level or a renderbuffer.
+type surface struct {
+	width  int
+	height int
+	format format
+	// pix holds 4 components per pixel, the bottom row first like GL. Depth is kept in the first component
+	pix []float32
+	// stencil values of the formats with a stencil component
+	stencil []uint8
+}
+
+func newSurface(width, height int, f format) *surface {
+	s := &surface{width: width, height: height, format: f, pix: make([]float32, 4*width*height)}
+	if f.stencil {
+		s.stencil = make([]uint8, width*height)
+	}
+	if !f.depth {
+		for i := 3; i < len(s.pix); i += 4 {
+			s.pix[i] = 1
+		}
+	}
+	return s
+}
+
+func (s *surface) at(x, y int) mgl32.Vec4 {
+	i := 4 * (y*s.width + x)
+	return mgl32.Vec4{s.pix[i], s.pix[i+1], s.pix[i+2], s.pix[i+3]}
+}
+
+// set stores a color, converted to the format of the surface.
+func (s *surface) set(x, y int, c mgl32.Vec4) {
+	i := 4 * (y*s.width + x)
+	for j := 0; j < 4; j++ {
+		v := c[j]
+		if j >= s.format.channels {
+			v = 0
+			if j == 3 {
+				v = 1
+			}
+		} else if !s.format.float {
+			v = quantize(v)
+		}
+		s.pix[i+j] = v
+	}
+}
+
+func (s *surface) depth(x, y int) float32 {
+	return s.pix[4*(y*s.width+x)]
+}
+
+func (s *surface) setDepth(x, y int, d float32) {
+	s.pix[4*(y*s.width+x)] = clamp01(d)
+}
+
+// quantize rounds a normalized value to 8 bits, the precision of the fixed point formats.
+func quantize(v float32) float32 {
+	return float32(math.Round(float64(clamp01(v))*255)) / 255
+}
+
+func srgbToLinear(v float32) float32 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return float32(math.Pow(float64((v+0.055)/1.055), 2.4))
+}
+
+// surfaceImage converts the colors of a surface to an image with the top row first.
+func surfaceImage(s *surface) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, s.width, s.height))
+	for y := 0; y < s.height; y++ {
+		row := img.Pix[(s.height-1-y)*img.Stride:]
+		for x := 0; x < s.width; x++ {
+			c := s.at(x, y)
+			for j := 0; j < 4; j++ {
+				row[4*x+j] = uint8(math.Round(float64(clamp01(c[j])) * 255))
+			}
+		}
+	}
+	return img
+}
+
+type texture struct {
+	target    uint32
+	levels    []*surface
+	minFilter int32
+	magFilter int32
+	wrapS     int32
+	wrapT     int32
+	border    mgl32.Vec4
+	// complete is valid until the levels or the filters change
+	complete      bool
+	completeValid bool
+	// faces of a cube map, each one a 2D texture with the parameters of the cube map
+	faces [6]*texture
+}
+
+func (b *Backend) GenTextures(n int32, textures *uint32) {
+	for i, s := 0, ids(n, textures); i < len(s); i++ {
+		s[i] = b.newId()
+		b.textures[s[i]] = &texture{
+			minFilter: gl.NEAREST_MIPMAP_LINEAR,
+			magFilter: gl.LINEAR,
+			wrapS:     gl.REPEAT,
+			wrapT:     gl.REPEAT,
+		}
+	}
+}
+
+func (b *Backend) DeleteTextures(n int32, textures *uint32) {
+	for _, id := range ids(n, textures) {
+		if _, ok := b.textures[id]; !ok || id == 0 {
+			continue
+		}
+		delete(b.textures, id)
+		for i := range b.units {
+			if b.units[i] == id {
+				b.units[i] = 0
+			}
+		}
+	}
+}
+
+func (b *Backend) ActiveTexture(unit uint32) {
+	if unit < gl.TEXTURE0 || unit >= gl.TEXTURE0+MAX_TEXTURE_UNITS {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	b.activeTexture = int(unit - gl.TEXTURE0)
+}
+
+// textureTarget reports whether target is supported, multisample textures are kept as regular 2D textures.
+func textureTarget(target uint32) bool {
+	return target == gl.TEXTURE_2D || target == gl.TEXTURE_2D_MULTISAMPLE || target == gl.TEXTURE_CUBE_MAP
+}
+
+// cubeFace returns the index of a cube map face target, -1 for the other targets.
+func cubeFace(target uint32) int {
+	if target >= gl.TEXTURE_CUBE_MAP_POSITIVE_X && target <= gl.TEXTURE_CUBE_MAP_NEGATIVE_Z {
+		return int(target - gl.TEXTURE_CUBE_MAP_POSITIVE_X)
+	}
+	return -1
+}
+
+func (b *Backend) BindTexture(target uint32, id uint32) {
+	if !textureTarget(target) {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	t, ok := b.textures[id]
+	if !ok && id != 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	if ok {
+		if t.target == 0 {
+			t.target = target
+		} else if t.target != target {
+			b.setError(gl.INVALID_OPERATION)
+			return
+		}
+	}
+	b.units[b.activeTexture] = id
+}
+
+// boundTexture returns the texture bound to the active unit, or the face of the cube map for a face target.
+func (b *Backend) boundTexture(target uint32) *texture {
+	face := cubeFace(target)
+	if face >= 0 {
+		target = gl.TEXTURE_CUBE_MAP
+	}
+	if !textureTarget(target) {
+		b.setError(gl.INVALID_ENUM)
+		return nil
+	}
+	t := b.textures[b.units[b.activeTexture]]
+	if t == nil || face >= 0 && t.target != gl.TEXTURE_CUBE_MAP {
+		b.setError(gl.INVALID_OPERATION)
+		return nil
+	}
+	if face >= 0 {
+		return t.face(face)
+	}
+	return t
+}
+
+// face returns a face of a cube map, it is created on first use.
+func (t *texture) face(i int) *texture {
+	if t.faces[i] == nil {
+		t.faces[i] = &texture{}
+		t.syncFaces()
+	}
+	return t.faces[i]
+}
+
+// syncFaces copies the parameters of a cube map to its faces.
+func (t *texture) syncFaces() {
+	for _, f := range t.faces {
+		if f != nil {
+			f.target = gl.TEXTURE_2D
+			f.minFilter, f.magFilter = t.minFilter, t.magFilter
+			f.wrapS, f.wrapT = t.wrapS, t.wrapT
+			f.border = t.border
+			f.completeValid = false
+		}
+	}
+}
+
+func (t *texture) setLevel(level int, s *surface) {
+	for len(t.levels) <= level {
+		t.levels = append(t.levels, nil)
+	}
+	t.levels[level] = s
+	t.completeValid = false
+}
+
+func (b *Backend) TexImage2D(target uint32, level int32, internalFormat int32, width, height, border int32, xformat, xtype uint32, pixels unsafe.Pointer) {
+	t := b.boundTexture(target)
+	if t == nil {
+		return
+	}
+	f, ok := parseFormat(uint32(internalFormat))
+	if !ok {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	if level < 0 || width < 0 || height < 0 || border != 0 {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	s := newSurface(int(width), int(height), f)
+	if pixels != nil {
+		if !b.unpack(s, 0, 0, int(width), int(height), xformat, xtype, pixels) {
+			return
+		}
+	}
+	t.setLevel(int(level), s)
+}
+
+func (b *Backend) TexSubImage2D(target uint32, level int32, xOffset, yOffset, width, height int32, xformat, xtype uint32, pixels unsafe.Pointer) {
+	t := b.boundTexture(target)
+	if t == nil {
+		return
+	}
+	if level < 0 || int(level) >= len(t.levels) || t.levels[level] == nil {
+		b.setError(gl.INVALID_OPERATION)
+		return
+	}
+	s := t.levels[level]
+	if xOffset < 0 || yOffset < 0 || width < 0 || height < 0 ||
+		int(xOffset+width) > s.width || int(yOffset+height) > s.height {
+		b.setError(gl.INVALID_VALUE)
+		return
+	}
+	if pixels != nil {
+		b.unpack(s, int(xOffset), int(yOffset), int(width), int(height), xformat, xtype, pixels)
+	}
+	t.completeValid = false
+}
+
+// pixelLayout returns the number of components and the size of a pixel in the client memory.
+func pixelLayout(xformat, xtype uint32) (int, int, bool) {
+	var n int
+	switch xformat {
+	case gl.RED, gl.DEPTH_COMPONENT:
+		n = 1
+	case gl.RG:
+		n = 2
+	case gl.RGB:
+		n = 3
+	case gl.RGBA:
+		n = 4
+	case gl.DEPTH_STENCIL:
+		if xtype != gl.UNSIGNED_INT_24_8 {
+			return 0, 0, false
+		}
+		return 2, 4, true
+	default:
+		return 0, 0, false
+	}
+	switch xtype {
+	case gl.UNSIGNED_BYTE:
+		return n, n, true
+	case gl.UNSIGNED_SHORT, gl.HALF_FLOAT:
+		return n, 2 * n, true
+	case gl.UNSIGNED_INT, gl.FLOAT:
+		return n, 4 * n, true
+	}
+	return 0, 0, false
+}
+
+func rowStride(rowBytes int, align int32) int {
+	a := int(align)
+	return (rowBytes + a - 1) / a * a
+}
+
+// unpack copies pixels from the client memory into a region of s, with the rows padded to UNPACK_ALIGNMENT.
+func (b *Backend) unpack(s *surface, x0, y0, width, height int, xformat, xtype uint32, pixels unsafe.Pointer) bool {
+	n, pixelSize, ok := pixelLayout(xformat, xtype)
+	if !ok {
+		b.setError(gl.INVALID_ENUM)
+		return false
+	}
+	if width == 0 || height == 0 {
+		return true
+	}
+	stride := rowStride(width*pixelSize, b.unpackAlign)
+	data := unsafe.Slice((*byte)(pixels), stride*(height-1)+width*pixelSize)
+	for y := 0; y < height; y++ {
+		row := data[y*stride:]
+		for x := 0; x < width; x++ {
+			p := row[x*pixelSize:]
+			if xformat == gl.DEPTH_STENCIL {
+				v := binary.LittleEndian.Uint32(p)
+				s.setDepth(x0+x, y0+y, float32(v>>8)/0xffffff)
+				if s.stencil != nil {
+					s.stencil[(y0+y)*s.width+x0+x] = uint8(v)
+				}
+				continue
+			}
+			c := mgl32.Vec4{0, 0, 0, 1}
+			for j := 0; j < n; j++ {
+				c[j] = component(p, xtype, j)
+			}
+			if s.format.srgb {
+				for j := 0; j < 3; j++ {
+					c[j] = srgbToLinear(c[j])
+				}
+			}
+			if s.format.depth {
+				s.setDepth(x0+x, y0+y, c[0])
+			} else if s.format.srgb {
+				// decoded values keep their precision
+				i := 4 * ((y0+y)*s.width + x0 + x)
+				copy(s.pix[i:i+4], c[:])
+				if s.format.channels < 4 {
+					s.pix[i+3] = 1
+				}
+			} else {
+				s.set(x0+x, y0+y, c)
+			}
+		}
+	}
+	return true
+}
+
+// component reads component j of a pixel, normalized for the integer types.
+func component(p []byte, xtype uint32, j int) float32 {
+	switch xtype {
+	case gl.UNSIGNED_BYTE:
+		return float32(p[j]) / 255
+	case gl.UNSIGNED_SHORT:
+		return float32(binary.LittleEndian.Uint16(p[2*j:])) / 65535
+	case gl.HALF_FLOAT:
+		return halfToFloat(binary.LittleEndian.Uint16(p[2*j:]))
+	case gl.UNSIGNED_INT:
+		return float32(float64(binary.LittleEndian.Uint32(p[4*j:])) / 4294967295)
+	case gl.FLOAT:
+		return math.Float32frombits(binary.LittleEndian.Uint32(p[4*j:]))
+	}
+	return 0
+}
+
+func halfToFloat(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := int32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch {
+	case exp == 0 && mant == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// subnormal
+		return float32(math.Ldexp(float64(mant), -24)) * (1 - 2*float32(h>>15))
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | uint32(exp-15+127)<<23 | mant<<13)
+}
+
+func (b *Backend) TexParameteri(target, pname uint32, param int32) {
+	t := b.boundTexture(target)
+	if t == nil {
+		return
+	}
+	switch pname {
+	case gl.TEXTURE_MIN_FILTER:
+		switch param {
+		case gl.NEAREST, gl.LINEAR, gl.NEAREST_MIPMAP_NEAREST, gl.LINEAR_MIPMAP_NEAREST,
+			gl.NEAREST_MIPMAP_LINEAR, gl.LINEAR_MIPMAP_LINEAR:
+			t.minFilter = param
+		default:
+			b.setError(gl.INVALID_ENUM)
+		}
+	case gl.TEXTURE_MAG_FILTER:
+		if param != gl.NEAREST && param != gl.LINEAR {
+			b.setError(gl.INVALID_ENUM)
+			return
+		}
+		t.magFilter = param
+	case gl.TEXTURE_WRAP_S, gl.TEXTURE_WRAP_T, gl.TEXTURE_WRAP_R:
+		switch param {
+		case gl.REPEAT, gl.CLAMP_TO_EDGE, gl.CLAMP_TO_BORDER, gl.MIRRORED_REPEAT:
+		default:
+			b.setError(gl.INVALID_ENUM)
+			return
+		}
+		if pname == gl.TEXTURE_WRAP_S {
+			t.wrapS = param
+		} else if pname == gl.TEXTURE_WRAP_T {
+			t.wrapT = param
+		}
+	case gl.TEXTURE_BASE_LEVEL, gl.TEXTURE_MAX_LEVEL, gl.TEXTURE_COMPARE_MODE:
+		// not supported, the defaults apply
+	default:
+		b.setError(gl.INVALID_ENUM)
+	}
+	t.completeValid = false
+	t.syncFaces()
+}
+
+func (b *Backend) TexParameterfv(target uint32, pname uint32, params *float32) {
+	t := b.boundTexture(target)
+	if t == nil {
+		return
+	}
+	if pname != gl.TEXTURE_BORDER_COLOR {
+		b.setError(gl.INVALID_ENUM)
+		return
+	}
+	copy(t.border[:], unsafe.Slice(params, 4))
+	t.syncFaces()
+}
+
+func (b *Backend) GenerateMipmap(target uint32) {
+	t := b.boundTexture(target)
+	if t == nil {
+		return
+	}
+	if target != gl.TEXTURE_CUBE_MAP {
+		if !t.generateMipmap() {
+			b.setError(gl.INVALID_OPERATION)
+		}
+		return
+	}
+	for _, f := range t.faces {
+		if f == nil || !f.generateMipmap() {
+			b.setError(gl.INVALID_OPERATION)
+			return
+		}
+	}
+}
+
+// generateMipmap computes the levels below the base level, it fails without a color base level.
+func (t *texture) generateMipmap() bool {
+	if len(t.levels) == 0 || t.levels[0] == nil || t.levels[0].format.depth {
+		return false
+	}
+	t.levels = t.levels[:1]
+	for src := t.levels[0]; src.width > 1 || src.height > 1; {
+		dst := newSurface(maxInt(src.width/2, 1), maxInt(src.height/2, 1), src.format)
+		for y := 0; y < dst.height; y++ {
+			for x := 0; x < dst.width; x++ {
+				// average the 2x2 block, the last row or column of an odd size is repeated
+				x0, y0 := minInt(2*x, src.width-1), minInt(2*y, src.height-1)
+				x1, y1 := minInt(2*x+1, src.width-1), minInt(2*y+1, src.height-1)
+				c := src.at(x0, y0).Add(src.at(x1, y0)).Add(src.at(x0, y1)).Add(src.at(x1, y1)).Mul(0.25)
+				if src.format.srgb {
+					i := 4 * (y*dst.width + x)
+					copy(dst.pix[i:i+4], c[:])
+				} else {
+					dst.set(x, y, c)
+				}
+			}
+		}
+		t.levels = append(t.levels, dst)
+		src = dst
+	}
+	t.completeValid = false
+	return true
+}
+
+func mipmapFilter(filter int32) bool {
+	return filter != gl.NEAREST && filter != gl.LINEAR
+}
+
+// isComplete reports whether the texture can be sampled: it needs a base level and, with a mipmap filter, every
+// level down to 1x1 with halved sizes.
+func (t *texture) isComplete() bool {
+	if t.completeValid {
+		return t.complete
+	}
+	t.completeValid = true
+	t.complete = false
+	if len(t.levels) == 0 || t.levels[0] == nil || t.levels[0].width == 0 || t.levels[0].height == 0 {
+		return false
+	}
+	if mipmapFilter(t.minFilter) {
+		w, h := t.levels[0].width, t.levels[0].height
+		for level := 1; w > 1 || h > 1; level++ {
+			w, h = maxInt(w/2, 1), maxInt(h/2, 1)
+			if level >= len(t.levels) || t.levels[level] == nil ||
+				t.levels[level].width != w || t.levels[level].height != h {
+				return false
+			}
+		}
+	}
+	t.complete = true
+	return true
+}
+
+// sample filters the texture at uv, lod is the log2 of the texel to pixel ratio.
+func (t *texture) sample(uv mgl32.Vec2, lod float32) mgl32.Vec4 {
+	if !t.isComplete() {
+		return mgl32.Vec4{0, 0, 0, 1}
+	}
+	if lod <= 0 {
+		return t.filter(t.levels[0], uv, t.magFilter)
+	}
+	last := float32(len(t.levels) - 1)
+	switch t.minFilter {
+	case gl.NEAREST, gl.LINEAR:
+		return t.filter(t.levels[0], uv, t.minFilter)
+	case gl.NEAREST_MIPMAP_NEAREST, gl.LINEAR_MIPMAP_NEAREST:
+		level := int(math.Min(float64(lod+0.5), float64(last)))
+		filter := int32(gl.NEAREST)
+		if t.minFilter == gl.LINEAR_MIPMAP_NEAREST {
+			filter = gl.LINEAR
+		}
+		return t.filter(t.levels[level], uv, filter)
+	default:
+		filter := int32(gl.NEAREST)
+		if t.minFilter == gl.LINEAR_MIPMAP_LINEAR {
+			filter = gl.LINEAR
+		}
+		if lod >= last {
+			return t.filter(t.levels[int(last)], uv, filter)
+		}
+		level := int(lod)
+		f := lod - float32(level)
+		c0 := t.filter(t.levels[level], uv, filter)
+		c1 := t.filter(t.levels[level+1], uv, filter)
+		return c0.Mul(1 - f).Add(c1.Mul(f))
+	}
+}
+
+// sampleCube samples the face of a cube map in the direction dir, from its base level.
+func (t *texture) sampleCube(dir mgl32.Vec3) mgl32.Vec4 {
+	face, uv := cubeCoords(dir)
+	if t.faces[face] == nil {
+		return mgl32.Vec4{0, 0, 0, 1}
+	}
+	return t.faces[face].sample(uv, 0)
+}
+
+// cubeCoords returns the face a direction points to and the texture coordinates on it, following the table of the
+// GL specification.
+func cubeCoords(dir mgl32.Vec3) (int, mgl32.Vec2) {
+	x, y, z := dir[0], dir[1], dir[2]
+	ax, ay, az := abs32(x), abs32(y), abs32(z)
+	var face int
+	var sc, tc, ma float32
+	switch {
+	case ax >= ay && ax >= az && x >= 0:
+		face, sc, tc, ma = 0, -z, -y, ax
+	case ax >= ay && ax >= az:
+		face, sc, tc, ma = 1, z, -y, ax
+	case ay >= az && y >= 0:
+		face, sc, tc, ma = 2, x, z, ay
+	case ay >= az:
+		face, sc, tc, ma = 3, x, -z, ay
+	case z >= 0:
+		face, sc, tc, ma = 4, x, -y, az
+	default:
+		face, sc, tc, ma = 5, -x, -y, az
+	}
+	if ma == 0 {
+		return 0, mgl32.Vec2{0.5, 0.5}
+	}
+	return face, mgl32.Vec2{(sc/ma + 1) / 2, (tc/ma + 1) / 2}
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func (t *texture) filter(s *surface, uv mgl32.Vec2, filter int32) mgl32.Vec4 {
+	u := uv[0] * float32(s.width)
+	v := uv[1] * float32(s.height)
+	if filter == gl.NEAREST {
+		return t.texel(s, int(math.Floor(float64(u))), int(math.Floor(float64(v))))
+	}
+	u -= 0.5
+	v -= 0.5
+	x0, y0 := math.Floor(float64(u)), math.Floor(float64(v))
+	a, b := u-float32(x0), v-float32(y0)
+	i, j := int(x0), int(y0)
+	c00, c10 := t.texel(s, i, j), t.texel(s, i+1, j)
+	c01, c11 := t.texel(s, i, j+1), t.texel(s, i+1, j+1)
+	return c00.Mul((1 - a) * (1 - b)).Add(c10.Mul(a * (1 - b))).Add(c01.Mul((1 - a) * b)).Add(c11.Mul(a * b))
+}
+
+// texel returns the texel at integer coordinates after wrapping them.
+func (t *texture) texel(s *surface, x, y int) mgl32.Vec4 {
+	x, okX := wrap(x, s.width, t.wrapS)
+	y, okY := wrap(y, s.height, t.wrapT)
+	if !okX || !okY {
+		return t.border
+	}
+	return s.at(x, y)
+}
+
+// wrap applies a wrap mode to a texel coordinate, it returns false for the border of CLAMP_TO_BORDER.
+func wrap(i, size int, mode int32) (int, bool) {
+	switch mode {
+	case gl.CLAMP_TO_EDGE:
+		return minInt(maxInt(i, 0), size-1), true
+	case gl.CLAMP_TO_BORDER:
+		return i, i >= 0 && i < size
+	case gl.MIRRORED_REPEAT:
+		period := 2 * size
+		i = (i%period + period) % period
+		if i >= size {
+			i = period - 1 - i
+		}
+		return i, true
+	}
+	return (i%size + size) % size, true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
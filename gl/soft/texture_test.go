@@ -0,0 +1,185 @@
+package soft
+
+import (
+	"learn_opengl/gl"
+	"testing"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const texturedVertex, texturedFragment = "// textured vs", "// textured fs"
+
+func init() {
+	RegisterSource(texturedVertex, texturedFragment, &Program{
+		Attributes: map[string]int{"aPos": 0, "aTexCoord": 1},
+		Varyings:   2,
+		Vertex: func(s *Stage, in []mgl32.Vec4, out []float32) mgl32.Vec4 {
+			out[0], out[1] = in[1][0], in[1][1]
+			return in[0]
+		},
+		Fragment: func(s *Stage, in []float32) (mgl32.Vec4, bool) {
+			return s.Texture("tex", mgl32.Vec2{in[0], in[1]}), true
+		},
+	})
+}
+
+// solidLevel uploads a mipmap level of the bound 2D texture filled with one color.
+func solidLevel(b *Backend, level int32, size int, c [4]uint8) {
+	pix := make([]uint8, 4*size*size)
+	for i := 0; i < len(pix); i += 4 {
+		copy(pix[i:], c[:])
+	}
+	b.TexImage2D(gl.TEXTURE_2D, level, gl.RGBA, int32(size), int32(size), 0, gl.RGBA, gl.UNSIGNED_BYTE,
+		unsafe.Pointer(&pix[0]))
+}
+
+func TestMipmapLevels(t *testing.T) {
+	b := New(1, 1)
+	var id uint32
+	b.GenTextures(1, &id)
+	b.BindTexture(gl.TEXTURE_2D, id)
+	solidLevel(b, 0, 4, [4]uint8{255, 0, 0, 255})
+	solidLevel(b, 1, 2, [4]uint8{0, 255, 0, 255})
+	tex := b.textures[id]
+	uv := mgl32.Vec2{0.3, 0.6}
+
+	// the default filter needs all the levels
+	if got := tex.sample(uv, 1); got != (mgl32.Vec4{0, 0, 0, 1}) {
+		t.Errorf("sample of an incomplete texture = %v, want black", got)
+	}
+	solidLevel(b, 2, 1, [4]uint8{0, 0, 255, 255})
+	if err := b.GetError(); err != gl.NO_ERROR {
+		t.Fatalf("error %#x", err)
+	}
+
+	r, g, bl := mgl32.Vec4{1, 0, 0, 1}, mgl32.Vec4{0, 1, 0, 1}, mgl32.Vec4{0, 0, 1, 1}
+	for _, c := range []struct {
+		filter int32
+		lod    float32
+		want   mgl32.Vec4
+	}{
+		// magnification always samples the base level
+		{gl.NEAREST_MIPMAP_NEAREST, -1, r},
+		{gl.NEAREST_MIPMAP_NEAREST, 0.4, r},
+		{gl.NEAREST_MIPMAP_NEAREST, 0.6, g},
+		{gl.LINEAR_MIPMAP_NEAREST, 1.2, g},
+		{gl.NEAREST_MIPMAP_NEAREST, 5, bl},
+		{gl.NEAREST_MIPMAP_LINEAR, 0.5, mgl32.Vec4{0.5, 0.5, 0, 1}},
+		{gl.LINEAR_MIPMAP_LINEAR, 1.25, mgl32.Vec4{0, 0.75, 0.25, 1}},
+		{gl.LINEAR_MIPMAP_LINEAR, 2, bl},
+		// without a mipmap filter the base level is minified
+		{gl.LINEAR, 3, r},
+	} {
+		b.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, c.filter)
+		if got := tex.sample(uv, c.lod); !got.ApproxEqualThreshold(c.want, 1e-6) {
+			t.Errorf("sample with filter %#x at lod %v = %v, want %v", c.filter, c.lod, got, c.want)
+		}
+	}
+}
+
+func TestGenerateMipmap(t *testing.T) {
+	b := New(1, 1)
+	var id uint32
+	b.GenTextures(1, &id)
+	b.BindTexture(gl.TEXTURE_2D, id)
+	// a 4x2 level of two white and two black columns
+	pix := make([]uint8, 4*4*2)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			copy(pix[4*(4*y+x):], []uint8{255, 255, 255, 255})
+		}
+	}
+	b.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, 4, 2, 0, gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&pix[0]))
+	b.GenerateMipmap(gl.TEXTURE_2D)
+	if err := b.GetError(); err != gl.NO_ERROR {
+		t.Fatalf("error %#x", err)
+	}
+	tex := b.textures[id]
+	sizes := [][2]int{{4, 2}, {2, 1}, {1, 1}}
+	if len(tex.levels) != len(sizes) {
+		t.Fatalf("%v levels, want %v", len(tex.levels), len(sizes))
+	}
+	for i, s := range sizes {
+		if tex.levels[i].width != s[0] || tex.levels[i].height != s[1] {
+			t.Errorf("level %v is %vx%v, want %vx%v", i, tex.levels[i].width, tex.levels[i].height, s[0], s[1])
+		}
+	}
+	if got := tex.levels[1].at(0, 0); got != (mgl32.Vec4{1, 1, 1, 1}) {
+		t.Errorf("level 1 at 0,0 = %v, want white", got)
+	}
+	if got := tex.levels[2].at(0, 0); !got.ApproxEqualThreshold(mgl32.Vec4{0.5, 0.5, 0.5, 0.5}, 1e-2) {
+		t.Errorf("level 2 = %v, want gray", got)
+	}
+
+	var empty uint32
+	b.GenTextures(1, &empty)
+	b.BindTexture(gl.TEXTURE_2D, empty)
+	b.GenerateMipmap(gl.TEXTURE_2D)
+	if err := b.GetError(); err != gl.INVALID_OPERATION {
+		t.Errorf("GenerateMipmap without a base level: error %#x, want INVALID_OPERATION", err)
+	}
+}
+
+func TestMipmapDraw(t *testing.T) {
+	// a 16x16 checker on 4x4 pixels: the derivatives select the 4x4 level, whose texels average the checker to gray
+	b := New(4, 4)
+	id := link(b, texturedVertex, "", texturedFragment)
+	if !b.programs[id].linked {
+		t.Fatalf("link: %v", b.programs[id].log)
+	}
+	b.UseProgram(id)
+
+	var tex uint32
+	b.GenTextures(1, &tex)
+	b.BindTexture(gl.TEXTURE_2D, tex)
+	pix := make([]uint8, 4*16*16)
+	for i := range pix {
+		if x, y := i/4%16, i/4/16; (x+y)%2 == 0 || i%4 == 3 {
+			pix[i] = 255
+		}
+	}
+	b.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, 16, 16, 0, gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&pix[0]))
+	b.GenerateMipmap(gl.TEXTURE_2D)
+
+	quad := []float32{
+		-1, -1, 0, 1, 0, 0,
+		1, -1, 0, 1, 1, 0,
+		1, 1, 0, 1, 1, 1,
+		-1, -1, 0, 1, 0, 0,
+		1, 1, 0, 1, 1, 1,
+		-1, 1, 0, 1, 0, 1,
+	}
+	var vbo uint32
+	b.GenBuffers(1, &vbo)
+	b.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	b.BufferData(gl.ARRAY_BUFFER, len(quad)*4, unsafe.Pointer(&quad[0]), gl.STATIC_DRAW)
+	b.VertexAttribPointer(0, 4, gl.FLOAT, false, 6*4, 0)
+	b.EnableVertexAttribArray(0)
+	b.VertexAttribPointer(1, 2, gl.FLOAT, false, 6*4, 4*4)
+	b.EnableVertexAttribArray(1)
+
+	draw := func(filter int32) [][4]uint8 {
+		b.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, filter)
+		b.DrawArrays(gl.TRIANGLES, 0, 6)
+		if err := b.GetError(); err != gl.NO_ERROR {
+			t.Fatalf("draw: error %#x", err)
+		}
+		var row [][4]uint8
+		for x := 0; x < 4; x++ {
+			row = append(row, pixel(b, x, 1))
+		}
+		return row
+	}
+	for x, c := range draw(gl.LINEAR_MIPMAP_NEAREST) {
+		if c != [4]uint8{128, 128, 128, 255} {
+			t.Errorf("mipmapped pixel %v,1 = %v, want gray", x, c)
+		}
+	}
+	// without mipmaps the nearest texel is either black or white
+	for x, c := range draw(gl.NEAREST) {
+		if c != [4]uint8{0, 0, 0, 255} && c != [4]uint8{255, 255, 255, 255} {
+			t.Errorf("pixel %v,1 = %v, want black or white", x, c)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package gl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	typeVec2 = reflect.TypeOf(mgl32.Vec2{})
+	typeVec3 = reflect.TypeOf(mgl32.Vec3{})
+	typeVec4 = reflect.TypeOf(mgl32.Vec4{})
+	typeMat2 = reflect.TypeOf(mgl32.Mat2{})
+	typeMat3 = reflect.TypeOf(mgl32.Mat3{})
+	typeMat4 = reflect.TypeOf(mgl32.Mat4{})
+)
+
+// Std140Field is a basic member of a std140 block, arrays and structs are broken down into their elements.
+type Std140Field struct {
+	// Name is the GLSL path of the member, e.g. "lights[1].position"
+	Name   string
+	Offset int
+	Size   int
+	Type   reflect.Type
+}
+
+// Std140Layout is the std140 memory layout of a Go struct mirroring a GLSL uniform block.
+type Std140Layout struct {
+	typ reflect.Type
+	// Size is the size of the block in bytes
+	Size int
+	// Fields lists the basic members in declaration order
+	Fields []Std140Field
+}
+
+// NewStd140Layout computes the std140 layout of a struct type. Members are named by their `glsl:"name"` tag, like
+// for Shader.SetStruct; untagged and unexported fields and fields tagged `glsl:"-"` are not part of the block.
+//
+// Supported member types are float32, int32, uint32, bool, mgl32.Vec2/3/4, mgl32.Mat2/3/4, nested structs and fixed
+// size arrays of those. The std140 rules apply: vec3 is aligned like vec4, array elements and matrix columns are
+// padded to 16 bytes and structs are aligned and padded to 16 bytes.
+func NewStd140Layout(t reflect.Type) (*Std140Layout, error) {
+	if t == nil {
+		return nil, fmt.Errorf("gl: std140 layout of nil type")
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("gl: std140 layout of non struct type %v", t)
+	}
+	l := &Std140Layout{typ: t}
+	size, _, err := l.layout(t, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	l.Size = size
+	return l, nil
+}
+
+func roundUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
+
+// std140Size returns the size and base alignment of a type.
+func std140Size(t reflect.Type) (int, int, error) {
+	switch t {
+	case typeVec2:
+		return 8, 8, nil
+	case typeVec3:
+		return 12, 16, nil
+	case typeVec4:
+		return 16, 16, nil
+	case typeMat2:
+		return 2 * 16, 16, nil
+	case typeMat3:
+		return 3 * 16, 16, nil
+	case typeMat4:
+		return 4 * 16, 16, nil
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Int32, reflect.Uint32, reflect.Bool:
+		return 4, 4, nil
+	case reflect.Array:
+		stride, align, err := std140ArrayStride(t)
+		if err != nil {
+			return 0, 0, err
+		}
+		return stride * t.Len(), align, nil
+	case reflect.Struct:
+		offset, maxAlign := 0, 0
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !std140Member(f) {
+				continue
+			}
+			size, align, err := std140Size(f.Type)
+			if err != nil {
+				return 0, 0, err
+			}
+			offset = roundUp(offset, align) + size
+			if align > maxAlign {
+				maxAlign = align
+			}
+		}
+		// an empty struct is aligned like any other
+		align := roundUp(maxAlign, 16)
+		if align == 0 {
+			align = 16
+		}
+		return roundUp(offset, align), align, nil
+	}
+	return 0, 0, fmt.Errorf("gl: unsupported std140 type %v", t)
+}
+
+func std140ArrayStride(t reflect.Type) (int, int, error) {
+	size, align, err := std140Size(t.Elem())
+	if err != nil {
+		return 0, 0, err
+	}
+	align = roundUp(align, 16)
+	return roundUp(size, align), align, nil
+}
+
+func std140Member(f reflect.StructField) bool {
+	tag := f.Tag.Get("glsl")
+	return f.PkgPath == "" && tag != "" && tag != "-"
+}
+
+// layout appends the basic members of t placed at offset and returns the size and alignment of t.
+func (l *Std140Layout) layout(t reflect.Type, name string, offset int) (int, int, error) {
+	size, align, err := std140Size(t)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch {
+	case t.Kind() == reflect.Struct:
+		memberOffset := 0
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !std140Member(f) {
+				continue
+			}
+			memberName := f.Tag.Get("glsl")
+			if name != "" {
+				memberName = name + "." + memberName
+			}
+			_, memberAlign, _ := std140Size(f.Type)
+			memberOffset = roundUp(memberOffset, memberAlign)
+			memberSize, _, err := l.layout(f.Type, memberName, offset+memberOffset)
+			if err != nil {
+				return 0, 0, err
+			}
+			memberOffset += memberSize
+		}
+	case t.Kind() == reflect.Array && !isStd140Basic(t):
+		stride, _, _ := std140ArrayStride(t)
+		for i := 0; i < t.Len(); i++ {
+			if _, _, err := l.layout(t.Elem(), name+"["+strconv.Itoa(i)+"]", offset+i*stride); err != nil {
+				return 0, 0, err
+			}
+		}
+	default:
+		l.Fields = append(l.Fields, Std140Field{Name: name, Offset: offset, Size: size, Type: t})
+	}
+	return size, align, nil
+}
+
+func isStd140Basic(t reflect.Type) bool {
+	switch t {
+	case typeVec2, typeVec3, typeVec4, typeMat2, typeMat3, typeMat4:
+		return true
+	}
+	return false
+}
+
+// Field returns the basic member with the given GLSL path.
+func (l *Std140Layout) Field(name string) (Std140Field, bool) {
+	for _, f := range l.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Std140Field{}, false
+}
+
+// Encode writes value, which must be of the type the layout was computed for, into dst.
+func (l *Std140Layout) Encode(dst []byte, value interface{}) error {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fmt.Errorf("gl: std140 layout of %v can't encode nil", l.typ)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return fmt.Errorf("gl: std140 layout of %v can't encode nil", l.typ)
+	}
+	if v.Type() != l.typ {
+		return fmt.Errorf("gl: std140 layout of %v can't encode %v", l.typ, v.Type())
+	}
+	if len(dst) < l.Size {
+		return fmt.Errorf("gl: std140 buffer of %v bytes is smaller than the block size %v", len(dst), l.Size)
+	}
+	encodeStd140(dst, v)
+	return nil
+}
+
+// EncodeField writes the value of one basic member into dst.
+func (l *Std140Layout) EncodeField(dst []byte, name string, value interface{}) error {
+	f, ok := l.Field(name)
+	if !ok {
+		return fmt.Errorf("gl: std140 layout of %v has no member %v", l.typ, name)
+	}
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return fmt.Errorf("gl: nil value for std140 member %v", name)
+	}
+	if v.Type() != f.Type {
+		return fmt.Errorf("gl: std140 member %v is %v, not %v", name, f.Type, v.Type())
+	}
+	encodeStd140(dst[f.Offset:], v)
+	return nil
+}
+
+func encodeStd140(dst []byte, v reflect.Value) {
+	t := v.Type()
+	switch t {
+	case typeVec2, typeVec3, typeVec4:
+		for i := 0; i < v.Len(); i++ {
+			putFloat32(dst[i*4:], float32(v.Index(i).Float()))
+		}
+		return
+	case typeMat2, typeMat3, typeMat4:
+		// column major, every column padded to a vec4
+		n := 2
+		if t == typeMat3 {
+			n = 3
+		} else if t == typeMat4 {
+			n = 4
+		}
+		for c := 0; c < n; c++ {
+			for r := 0; r < n; r++ {
+				putFloat32(dst[c*16+r*4:], float32(v.Index(c*n+r).Float()))
+			}
+		}
+		return
+	}
+	switch t.Kind() {
+	case reflect.Float32:
+		putFloat32(dst, float32(v.Float()))
+	case reflect.Int32:
+		binary.LittleEndian.PutUint32(dst, uint32(int32(v.Int())))
+	case reflect.Uint32:
+		binary.LittleEndian.PutUint32(dst, uint32(v.Uint()))
+	case reflect.Bool:
+		var b uint32
+		if v.Bool() {
+			b = 1
+		}
+		binary.LittleEndian.PutUint32(dst, b)
+	case reflect.Array:
+		stride, _, _ := std140ArrayStride(t)
+		for i := 0; i < v.Len(); i++ {
+			encodeStd140(dst[i*stride:], v.Index(i))
+		}
+	case reflect.Struct:
+		offset := 0
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !std140Member(f) {
+				continue
+			}
+			size, align, _ := std140Size(f.Type)
+			offset = roundUp(offset, align)
+			encodeStd140(dst[offset:], v.Field(i))
+			offset += size
+		}
+	}
+}
+
+func putFloat32(dst []byte, f float32) {
+	binary.LittleEndian.PutUint32(dst, math.Float32bits(f))
+}
@@ -0,0 +1,219 @@
+package gl
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type std140Light struct {
+	Position mgl32.Vec3 `glsl:"position"`
+	Constant float32    `glsl:"constant"`
+	Color    mgl32.Vec3 `glsl:"color"`
+}
+
+type std140Block struct {
+	Scale    float32        `glsl:"scale"`
+	Normal   mgl32.Vec3     `glsl:"normal"`
+	Weights  [3]float32     `glsl:"weights"`
+	Enabled  bool           `glsl:"enabled"`
+	Rotation mgl32.Mat3     `glsl:"rotation"`
+	Lights   [2]std140Light `glsl:"lights"`
+	Offset   mgl32.Vec2     `glsl:"offset"`
+	Count    int32          `glsl:"count"`
+	Untagged float32
+	Hidden   float32 `glsl:"-"`
+	private  float32
+}
+
+func TestStd140Offsets(t *testing.T) {
+	l, err := NewStd140Layout(reflect.TypeOf(std140Block{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name         string
+		offset, size int
+	}{
+		{"scale", 0, 4},
+		// vec3 is aligned to 16 bytes
+		{"normal", 16, 12},
+		// array elements are padded to 16 bytes
+		{"weights[0]", 32, 4},
+		{"weights[1]", 48, 4},
+		{"weights[2]", 64, 4},
+		{"enabled", 80, 4},
+		// mat3 is 3 columns padded to vec4
+		{"rotation", 96, 48},
+		// structs are aligned to 16 bytes and padded to a multiple of 16
+		{"lights[0].position", 144, 12},
+		{"lights[0].constant", 156, 4},
+		{"lights[0].color", 160, 12},
+		{"lights[1].position", 176, 12},
+		{"lights[1].constant", 188, 4},
+		{"lights[1].color", 192, 12},
+		{"offset", 208, 8},
+		{"count", 216, 4},
+	}
+	if len(l.Fields) != len(want) {
+		t.Fatalf("%v fields, want %v: %+v", len(l.Fields), len(want), l.Fields)
+	}
+	for i, w := range want {
+		f := l.Fields[i]
+		if f.Name != w.name || f.Offset != w.offset || f.Size != w.size {
+			t.Errorf("field %v is %v at %v, size %v, want %v at %v, size %v", i, f.Name, f.Offset, f.Size, w.name,
+				w.offset, w.size)
+		}
+	}
+	if l.Size != 224 {
+		t.Errorf("Size = %v, want 224", l.Size)
+	}
+}
+
+func TestStd140Encode(t *testing.T) {
+	l, err := NewStd140Layout(reflect.TypeOf(std140Block{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := std140Block{
+		Scale:    1,
+		Normal:   mgl32.Vec3{2, 3, 4},
+		Weights:  [3]float32{5, 6, 7},
+		Enabled:  true,
+		Rotation: mgl32.Mat3{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		Count:    -2,
+		Untagged: 13,
+		Hidden:   14,
+	}
+	b.Lights[1].Color = mgl32.Vec3{10, 11, 12}
+	dst := make([]byte, l.Size)
+	if err = l.Encode(dst, &b); err != nil {
+		t.Fatal(err)
+	}
+	float := func(offset int) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(dst[offset:]))
+	}
+	for offset, want := range map[int]float32{
+		0: 1, 16: 2, 20: 3, 24: 4, 32: 5, 48: 6, 64: 7,
+		96: 1, 100: 2, 104: 3, 108: 0, 112: 4, 116: 5, 120: 6, 128: 7, 132: 8, 136: 9,
+		192: 10, 196: 11, 200: 12,
+	} {
+		if got := float(offset); got != want {
+			t.Errorf("float at %v = %v, want %v", offset, got, want)
+		}
+	}
+	if got := binary.LittleEndian.Uint32(dst[80:]); got != 1 {
+		t.Errorf("bool encoded as %v", got)
+	}
+	if got := int32(binary.LittleEndian.Uint32(dst[216:])); got != -2 {
+		t.Errorf("int32 encoded as %v", got)
+	}
+
+	for offset := 220; offset < l.Size; offset += 4 {
+		if got := float(offset); got != 0 {
+			t.Errorf("padding at %v = %v, want 0", offset, got)
+		}
+	}
+
+	if err = l.EncodeField(dst, "lights[0].constant", float32(3)); err != nil {
+		t.Fatal(err)
+	}
+	if float(156) != 3 {
+		t.Errorf("EncodeField wrote %v", float(156))
+	}
+	if err = l.EncodeField(dst, "lights[0].constant", int32(3)); err == nil {
+		t.Errorf("EncodeField of the wrong type succeeded")
+	}
+	if err = l.Encode(dst, std140Light{}); err == nil {
+		t.Errorf("Encode of the wrong type succeeded")
+	}
+	if _, ok := l.Field("Untagged"); ok {
+		t.Errorf("untagged field is a member of the block")
+	}
+}
+
+func TestStd140Nil(t *testing.T) {
+	if _, err := NewStd140Layout(nil); err == nil {
+		t.Errorf("layout of a nil type succeeded")
+	}
+	l, err := NewStd140Layout(reflect.TypeOf(std140Light{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]byte, l.Size)
+	if err = l.Encode(dst, nil); err == nil {
+		t.Errorf("Encode of nil succeeded")
+	}
+	if err = l.Encode(dst, (*std140Light)(nil)); err == nil {
+		t.Errorf("Encode of a nil pointer succeeded")
+	}
+	if err = l.EncodeField(dst, "constant", nil); err == nil {
+		t.Errorf("EncodeField of nil succeeded")
+	}
+}
+
+func TestStd140Errors(t *testing.T) {
+	if _, err := NewStd140Layout(reflect.TypeOf(float32(0))); err == nil {
+		t.Errorf("layout of a float succeeded")
+	}
+	if _, err := NewStd140Layout(reflect.TypeOf(struct {
+		S string `glsl:"s"`
+	}{})); err == nil {
+		t.Errorf("layout of a string member succeeded")
+	}
+	l, err := NewStd140Layout(reflect.TypeOf(struct {
+		Empty struct{} `glsl:"empty"`
+	}{}))
+	if err != nil || l.Size != 0 {
+		t.Errorf("layout of an empty struct: %v, %v", l, err)
+	}
+}
+
+func TestUniformBufferEmpty(t *testing.T) {
+	defer SetBackend(SetBackend(NewRecordingBackend()))
+	if _, err := NewUniformBuffer("Empty", 0, struct{}{}); err == nil {
+		t.Errorf("NewUniformBuffer of an empty block succeeded")
+	}
+	if _, err := NewUniformBuffer("Hidden", 0, struct {
+		hidden float32
+	}{}); err == nil {
+		t.Errorf("NewUniformBuffer of a block without exported members succeeded")
+	}
+	if _, err := NewUniformBuffer("Untagged", 0, struct {
+		Untagged float32
+	}{}); err == nil {
+		t.Errorf("NewUniformBuffer of a block without tagged members succeeded")
+	}
+	u, err := NewUniformBuffer("Light", 0, std140Light{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Delete()
+}
+
+func TestUniformBufferNil(t *testing.T) {
+	defer SetBackend(SetBackend(NewRecordingBackend()))
+	if _, err := NewUniformBuffer("B", 0, nil); err == nil {
+		t.Errorf("NewUniformBuffer of nil succeeded")
+	}
+	if _, err := NewUniformBuffer("B", 0, (*std140Light)(nil)); err == nil {
+		t.Errorf("NewUniformBuffer of a nil pointer succeeded")
+	}
+	if _, ok := uniformBuffers["B"]; ok {
+		t.Errorf("failed NewUniformBuffer registered the block")
+	}
+	u, err := NewUniformBuffer("B", 0, &std140Light{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Delete()
+	if err = u.Set((*std140Light)(nil)); err == nil {
+		t.Errorf("Set of a nil pointer succeeded")
+	}
+	if err = u.SetField("constant", nil); err == nil {
+		t.Errorf("SetField of nil succeeded")
+	}
+}
@@ -1,47 +1,125 @@
 package gl
 
 import (
+	"fmt"
+	"learn_opengl/async"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"unsafe"
 
 	"github.com/huoshan017/go-stbi"
 )
 
+const (
+	// size in texels and in checker cells of the texture standing in for a missing one
+	MISSING_TEXTURE_SIZE  = 64
+	MISSING_TEXTURE_CELLS = 8
+)
+
 func TextureFromFile(path, directory string, gamma bool) uint32 {
-	var filename = path
-	filename = directory + "/" + filename
+	textureId, err := LoadTextureFromFile(path, directory, gamma)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return textureId
+}
 
-	var textureId uint32
-	GenTextures(1, &textureId)
+// LoadTextureFromFile loads the image at path, relative to directory, into a mipmapped 2D texture. Unlike
+// TextureFromFile it returns an error instead of exiting.
+func LoadTextureFromFile(path, directory string, gamma bool) (uint32, error) {
+	image, err := DecodeTextureImage(path, directory)
+	if err != nil {
+		return 0, err
+	}
+	return UploadTextureImage(image)
+}
 
-	errCode := GetError()
-	if errCode != 0 {
-		log.Fatalf("fatal!!! get error %v", errCode)
+// TextureImage is an image decoded for a texture but not uploaded yet. Decoding makes no GL call, it can run on any
+// goroutine.
+type TextureImage struct {
+	Path          string
+	Width, Height int
+	// Format is RED, RG, RGB or RGBA following the channels of the file, grey and alpha are red and green
+	Format int32
+	Pix    []uint8
+}
+
+// DecodeTextureImage decodes the image at path, relative to directory.
+func DecodeTextureImage(path, directory string) (*TextureImage, error) {
+	filename := filepath.Join(directory, path)
+
+	var nChannels int32
+	image, err := stbi.Load(filename, &nChannels, 0)
+	if err != nil {
+		return nil, fmt.Errorf("gl: texture failed to load at path %v: %w", filename, err)
 	}
 
-	if textureId == 0 {
-		log.Fatalf("fatal!!! generate texture id zero")
+	format, err := channelsFormat(nChannels)
+	if err != nil {
+		return nil, fmt.Errorf("gl: texture %v has %w", filename, err)
 	}
 
+	return &TextureImage{
+		Path:   filename,
+		Width:  image.Rect.Dx(),
+		Height: image.Rect.Dy(),
+		Format: format,
+		Pix:    image.Pix,
+	}, nil
+}
+
+// DecodeTextureImageBytes decodes an image file held in memory, name only shows in errors.
+func DecodeTextureImageBytes(data []byte, name string) (*TextureImage, error) {
 	var nChannels int32
-	image, err := stbi.Load(filename, &nChannels, 0)
+	image, err := stbi.LoadMemory(data, &nChannels, 0)
 	if err != nil {
-		log.Fatalf("Texture failed to load at path %v with directory %v, err %v", path, directory, err)
+		return nil, fmt.Errorf("gl: texture %v failed to decode: %w", name, err)
 	}
+	format, err := channelsFormat(nChannels)
+	if err != nil {
+		return nil, fmt.Errorf("gl: texture %v: %w", name, err)
+	}
+	return &TextureImage{
+		Path:   name,
+		Width:  image.Rect.Dx(),
+		Height: image.Rect.Dy(),
+		Format: format,
+		Pix:    image.Pix,
+	}, nil
+}
 
-	var format int32
-	if nChannels == 1 {
-		format = RED
-	} else if nChannels == 3 {
-		format = RGB
-	} else if nChannels == 4 {
-		format = RGBA
+func channelsFormat(nChannels int32) (int32, error) {
+	switch nChannels {
+	case 1:
+		return RED, nil
+	case 2:
+		// grey and alpha
+		return RG, nil
+	case 3:
+		return RGB, nil
+	case 4:
+		return RGBA, nil
+	}
+	return 0, fmt.Errorf("unsupported %v channels", nChannels)
+}
+
+// UploadTextureImage creates a mipmapped 2D texture from image, on the GL thread.
+func UploadTextureImage(image *TextureImage) (uint32, error) {
+	if len(image.Pix) == 0 {
+		return 0, fmt.Errorf("gl: texture %v is empty", image.Path)
+	}
+	textureId, err := genTexture()
+	if err != nil {
+		return 0, err
 	}
 
 	BindTexture(TEXTURE_2D, textureId)
-	width := image.Rect.Dx()
-	height := image.Rect.Dy()
-	TexImage2D(TEXTURE_2D, 0, format, int32(width), int32(height), 0, uint32(format), UNSIGNED_BYTE, unsafe.Pointer(&image.Pix[0]))
+	// the rows of the decoded images aren't padded to 4 bytes
+	PixelStorei(UNPACK_ALIGNMENT, 1)
+	TexImage2D(TEXTURE_2D, 0, image.Format, int32(image.Width), int32(image.Height), 0, uint32(image.Format), UNSIGNED_BYTE, unsafe.Pointer(&image.Pix[0]))
+	PixelStorei(UNPACK_ALIGNMENT, 4)
 	GenerateMipmap(TEXTURE_2D)
 
 	TexParameteri(TEXTURE_2D, TEXTURE_WRAP_S, REPEAT)
@@ -49,5 +127,99 @@ func TextureFromFile(path, directory string, gamma bool) uint32 {
 	TexParameteri(TEXTURE_2D, TEXTURE_MIN_FILTER, LINEAR_MIPMAP_LINEAR)
 	TexParameteri(TEXTURE_2D, TEXTURE_MAG_FILTER, LINEAR)
 
-	return textureId
+	return textureId, nil
+}
+
+// LoadTextureAsync is LoadTextureFromFile decoding on a worker of l, the texture is created by l.Update on the GL
+// thread.
+func LoadTextureAsync(l *async.Loader, path, directory string) *async.Future[uint32] {
+	f := async.NewFuture[uint32]()
+	f.AddSteps(2)
+	l.Go(func() {
+		image, err := DecodeTextureImage(path, directory)
+		if err != nil {
+			f.Resolve(0, err)
+			return
+		}
+		f.Step()
+		l.Main(func() {
+			textureId, err := UploadTextureImage(image)
+			f.Step()
+			f.Resolve(textureId, err)
+		})
+	})
+	return f
+}
+
+// FindTexture looks up a texture named by a model file in dirs, first as is and then by its base name, since
+// exporters often write absolute or Windows paths. It returns the path and directory to load it with.
+func FindTexture(name string, dirs []string) (path, directory string, err error) {
+	if name == "" {
+		return "", "", fmt.Errorf("empty texture name in a model of %v", strings.Join(dirs, ", "))
+	}
+	name = filepath.FromSlash(strings.ReplaceAll(name, "\\", "/"))
+	candidates := []string{name}
+	if base := filepath.Base(name); base != name {
+		candidates = append(candidates, base)
+	}
+	for _, candidate := range candidates {
+		if filepath.IsAbs(candidate) {
+			if _, err := os.Stat(candidate); err == nil {
+				return filepath.Base(candidate), filepath.Dir(candidate), nil
+			}
+			continue
+		}
+		for _, dir := range dirs {
+			if _, err := os.Stat(filepath.Join(dir, candidate)); err == nil {
+				return candidate, dir, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("texture %v not found in %v", name, strings.Join(dirs, ", "))
+}
+
+// MissingTexture creates a magenta and black checker texture that stands in for a texture which failed to load, it
+// is hard to miss on screen.
+func MissingTexture() (uint32, error) {
+	textureId, err := genTexture()
+	if err != nil {
+		return 0, err
+	}
+
+	pixels := make([]uint8, MISSING_TEXTURE_SIZE*MISSING_TEXTURE_SIZE*4)
+	cell := MISSING_TEXTURE_SIZE / MISSING_TEXTURE_CELLS
+	for y := 0; y < MISSING_TEXTURE_SIZE; y++ {
+		for x := 0; x < MISSING_TEXTURE_SIZE; x++ {
+			p := pixels[(y*MISSING_TEXTURE_SIZE+x)*4:]
+			if (x/cell+y/cell)%2 == 0 {
+				p[0], p[2] = 0xff, 0xff
+			}
+			p[3] = 0xff
+		}
+	}
+
+	BindTexture(TEXTURE_2D, textureId)
+	TexImage2D(TEXTURE_2D, 0, RGBA, MISSING_TEXTURE_SIZE, MISSING_TEXTURE_SIZE, 0, RGBA, UNSIGNED_BYTE, unsafe.Pointer(&pixels[0]))
+	GenerateMipmap(TEXTURE_2D)
+
+	TexParameteri(TEXTURE_2D, TEXTURE_WRAP_S, REPEAT)
+	TexParameteri(TEXTURE_2D, TEXTURE_WRAP_T, REPEAT)
+	TexParameteri(TEXTURE_2D, TEXTURE_MIN_FILTER, LINEAR_MIPMAP_NEAREST)
+	TexParameteri(TEXTURE_2D, TEXTURE_MAG_FILTER, NEAREST)
+
+	return textureId, nil
+}
+
+func genTexture() (uint32, error) {
+	var textureId uint32
+	GenTextures(1, &textureId)
+
+	if errCode := GetError(); errCode != 0 {
+		return 0, fmt.Errorf("gl: failed to generate a texture, error %v", errCode)
+	}
+	if textureId == 0 {
+		return 0, fmt.Errorf("gl: generated texture id zero")
+	}
+	trackObject("texture", textureId)
+	return textureId, nil
 }
@@ -0,0 +1,74 @@
+package gl
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+// greyAlphaPNG encodes a PNG of color type 4, grey and alpha, which image/png can't write.
+func greyAlphaPNG(t *testing.T, width, height int, pix []byte) []byte {
+	var out bytes.Buffer
+	out.WriteString("\x89PNG\r\n\x1a\n")
+	chunk := func(typ string, data []byte) {
+		binary.Write(&out, binary.BigEndian, uint32(len(data)))
+		body := append([]byte(typ), data...)
+		out.Write(body)
+		binary.Write(&out, binary.BigEndian, crc32.ChecksumIEEE(body))
+	}
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header, uint32(width))
+	binary.BigEndian.PutUint32(header[4:], uint32(height))
+	header[8], header[9] = 8, 4
+	chunk("IHDR", header)
+
+	var raw bytes.Buffer
+	for y := 0; y < height; y++ {
+		// no filter
+		raw.WriteByte(0)
+		raw.Write(pix[y*width*2 : (y+1)*width*2])
+	}
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	if _, err := w.Write(raw.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	chunk("IDAT", compressed.Bytes())
+	chunk("IEND", nil)
+	return out.Bytes()
+}
+
+func TestDecodeGreyAlpha(t *testing.T) {
+	pix := []byte{
+		10, 255, 20, 128, 30, 0,
+		40, 255, 50, 64, 60, 32,
+	}
+	image, err := DecodeTextureImageBytes(greyAlphaPNG(t, 3, 2, pix), "grey.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.Format != RG || image.Width != 3 || image.Height != 2 {
+		t.Fatalf("decoded format %#x, %vx%v", image.Format, image.Width, image.Height)
+	}
+	if !bytes.Equal(image.Pix, pix) {
+		t.Errorf("pixels %v, want %v", image.Pix, pix)
+	}
+
+	rb := NewRecordingBackend()
+	defer SetBackend(SetBackend(rb))
+	if _, err = UploadTextureImage(image); err != nil {
+		t.Fatal(err)
+	}
+	uploads := rb.CallsTo("TexImage2D")
+	if len(uploads) != 1 || uploads[0].Args[2].(int32) != RG || uploads[0].Args[6].(uint32) != RG {
+		t.Errorf("uploaded with %v", uploads)
+	}
+	// the rows of 6 bytes aren't aligned to 4
+	alignments := rb.CallsTo("PixelStorei")
+	if len(alignments) == 0 || alignments[0].Args[1].(int32) != 1 {
+		t.Errorf("unpack alignment %v", alignments)
+	}
+}
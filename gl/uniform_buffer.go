@@ -0,0 +1,191 @@
+package gl
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+var (
+	// uniform buffers by block name, bound to the matching blocks of every shader linked after their creation
+	uniformBuffers = make(map[string]*UniformBuffer)
+	// uniform blocks of the live programs, to bind the uniform buffers created after them
+	programBlocks = make(map[uint32][]UniformBlockInfo)
+)
+
+// UniformBuffer is a uniform buffer object whose contents mirror a Go struct laid out with the std140 rules, see
+// NewStd140Layout. Changes are kept on the CPU until Flush uploads the modified byte range.
+type UniformBuffer struct {
+	id         uint32
+	blockName  string
+	binding    uint32
+	layout     *Std140Layout
+	data       []byte
+	scratch    []byte
+	dirtyStart int
+	dirtyEnd   int
+}
+
+// NewUniformBuffer creates a buffer for the uniform block blockName with the layout of value's type, uploads value
+// and binds the buffer to the binding point. Every Shader with an active block of that name, already linked or not,
+// gets the block bound to the binding point.
+func NewUniformBuffer(blockName string, binding uint32, value interface{}) (*UniformBuffer, error) {
+	if value == nil {
+		return nil, fmt.Errorf("gl: nil value for uniform block %v", blockName)
+	}
+	layout, err := NewStd140Layout(reflect.TypeOf(value))
+	if err != nil {
+		return nil, err
+	}
+	if layout.Size == 0 {
+		return nil, fmt.Errorf("gl: uniform block %v has no members", blockName)
+	}
+	u := &UniformBuffer{
+		blockName: blockName,
+		binding:   binding,
+		layout:    layout,
+		data:      make([]byte, layout.Size),
+		scratch:   make([]byte, layout.Size),
+	}
+	if err = layout.Encode(u.data, value); err != nil {
+		return nil, err
+	}
+
+	GenBuffers(1, &u.id)
+	trackObject("buffer", u.id)
+	BindBuffer(UNIFORM_BUFFER, u.id)
+	BufferData(UNIFORM_BUFFER, layout.Size, unsafe.Pointer(&u.data[0]), DYNAMIC_DRAW)
+	BindBuffer(UNIFORM_BUFFER, 0)
+	u.Bind()
+
+	uniformBuffers[blockName] = u
+	for program, blocks := range programBlocks {
+		for _, block := range blocks {
+			if block.Name == blockName {
+				UniformBlockBinding(program, block.Index, binding)
+			}
+		}
+	}
+	return u, nil
+}
+
+func (u *UniformBuffer) Id() uint32 {
+	return u.id
+}
+
+func (u *UniformBuffer) BlockName() string {
+	return u.blockName
+}
+
+func (u *UniformBuffer) Binding() uint32 {
+	return u.binding
+}
+
+func (u *UniformBuffer) Layout() *Std140Layout {
+	return u.layout
+}
+
+// Set replaces the whole contents, only the bytes that actually changed are uploaded by the next Flush.
+func (u *UniformBuffer) Set(value interface{}) error {
+	if err := u.layout.Encode(u.scratch, value); err != nil {
+		return err
+	}
+	start := 0
+	for start < len(u.data) && u.data[start] == u.scratch[start] {
+		start++
+	}
+	if start == len(u.data) {
+		return nil
+	}
+	end := len(u.data)
+	for u.data[end-1] == u.scratch[end-1] {
+		end--
+	}
+	copy(u.data[start:end], u.scratch[start:end])
+	u.markDirty(start, end)
+	return nil
+}
+
+// SetField replaces one basic member given by its GLSL path, e.g. "view" or "lights[1].position".
+func (u *UniformBuffer) SetField(name string, value interface{}) error {
+	f, ok := u.layout.Field(name)
+	if !ok {
+		return fmt.Errorf("gl: uniform block %v has no member %v", u.blockName, name)
+	}
+	old := append(u.scratch[:0], u.data[f.Offset:f.Offset+f.Size]...)
+	if err := u.layout.EncodeField(u.data, name, value); err != nil {
+		return err
+	}
+	if !bytes.Equal(old, u.data[f.Offset:f.Offset+f.Size]) {
+		u.markDirty(f.Offset, f.Offset+f.Size)
+	}
+	return nil
+}
+
+func (u *UniformBuffer) markDirty(start, end int) {
+	if u.dirtyStart == u.dirtyEnd {
+		u.dirtyStart, u.dirtyEnd = start, end
+		return
+	}
+	if start < u.dirtyStart {
+		u.dirtyStart = start
+	}
+	if end > u.dirtyEnd {
+		u.dirtyEnd = end
+	}
+}
+
+// Flush uploads the range modified since the last Flush.
+func (u *UniformBuffer) Flush() {
+	if u.dirtyStart == u.dirtyEnd {
+		return
+	}
+	BindBuffer(UNIFORM_BUFFER, u.id)
+	BufferSubData(UNIFORM_BUFFER, u.dirtyStart, u.dirtyEnd-u.dirtyStart, unsafe.Pointer(&u.data[u.dirtyStart]))
+	BindBuffer(UNIFORM_BUFFER, 0)
+	u.dirtyStart, u.dirtyEnd = 0, 0
+}
+
+// Delete frees the buffer and stops binding it to new shaders, a nil buffer is ignored.
+func (u *UniformBuffer) Delete() {
+	if u == nil || u.id == 0 {
+		return
+	}
+	DeleteBuffers(1, &u.id)
+	untrackObject("buffer", u.id)
+	if uniformBuffers[u.blockName] == u {
+		delete(uniformBuffers, u.blockName)
+	}
+	u.id = 0
+}
+
+// Bind binds the buffer to its binding point.
+func (u *UniformBuffer) Bind() {
+	BindBufferBase(UNIFORM_BUFFER, u.binding, u.id)
+}
+
+// BindShader binds the block of the shader named like the buffer to the binding point, it reports whether the
+// shader has such a block.
+func (u *UniformBuffer) BindShader(s *Shader) bool {
+	for _, block := range s.blocks {
+		if block.Name == u.blockName {
+			UniformBlockBinding(s.id, block.Index, u.binding)
+			return true
+		}
+	}
+	return false
+}
+
+// bindUniformBlocks binds the blocks of a newly linked program to the uniform buffers of the same name.
+func bindUniformBlocks(s *Shader) {
+	if len(s.blocks) == 0 {
+		return
+	}
+	programBlocks[s.id] = s.blocks
+	for _, block := range s.blocks {
+		if u, ok := uniformBuffers[block.Name]; ok {
+			UniformBlockBinding(s.id, block.Index, u.binding)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package gl
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// VertexAttribute describes an attribute of the vertices of a mesh, read by the vertex shader input at Location.
+type VertexAttribute struct {
+	Location uint32
+	// Components is 1 to 4, always 4 for INT_2_10_10_10_REV and UNSIGNED_INT_2_10_10_10_REV
+	Components int32
+	// Type is FLOAT, HALF_FLOAT, BYTE, UNSIGNED_BYTE, SHORT, UNSIGNED_SHORT, INT, UNSIGNED_INT or one of the packed
+	// 2_10_10_10 types
+	Type uint32
+	// Normalized maps integer types to [0, 1], or [-1, 1] for signed ones
+	Normalized bool
+	// Integer keeps integer types as integers, for ivec and uvec inputs
+	Integer bool
+	// Stream indexes the buffer holding the attribute, Offset is its position in the vertices of that buffer
+	Stream int
+	Offset int
+}
+
+// size returns the bytes taken by the attribute in a vertex.
+func (a *VertexAttribute) size() int {
+	switch a.Type {
+	case BYTE, UNSIGNED_BYTE:
+		return int(a.Components)
+	case SHORT, UNSIGNED_SHORT, HALF_FLOAT:
+		return 2 * int(a.Components)
+	case INT_2_10_10_10_REV, UNSIGNED_INT_2_10_10_10_REV:
+		return 4
+	}
+	return 4 * int(a.Components)
+}
+
+// VertexLayout describes vertices interleaved in a single stream or split among several, one buffer each.
+type VertexLayout struct {
+	// Strides holds the size of a vertex in every stream
+	Strides    []int32
+	Attributes []VertexAttribute
+}
+
+// DefaultVertexLayout returns the layout of Vertex, with the position at location 0, the normal at 1, the texture
+// coordinates at 2, the tangent at 3, the bitangent at 4, the bone ids at 5 and the weights at 6.
+func DefaultVertexLayout() VertexLayout {
+	var v Vertex
+	return VertexLayout{
+		Strides: []int32{int32(unsafe.Sizeof(v))},
+		Attributes: []VertexAttribute{
+			{Location: 0, Components: 3, Type: FLOAT, Offset: int(unsafe.Offsetof(v.Position))},
+			{Location: 1, Components: 3, Type: FLOAT, Offset: int(unsafe.Offsetof(v.Normal))},
+			{Location: 2, Components: 2, Type: FLOAT, Offset: int(unsafe.Offsetof(v.TexCoords))},
+			{Location: 3, Components: 3, Type: FLOAT, Offset: int(unsafe.Offsetof(v.Tangent))},
+			{Location: 4, Components: 3, Type: FLOAT, Offset: int(unsafe.Offsetof(v.Bitangent))},
+			{Location: 5, Components: MAX_BONE_INFLUENCE, Type: INT, Integer: true, Offset: int(unsafe.Offsetof(v.BoneIds))},
+			{Location: 6, Components: MAX_BONE_INFLUENCE, Type: FLOAT, Offset: int(unsafe.Offsetof(v.Weights))},
+		},
+	}
+}
+
+// CompactVertex is a vertex without tangent space nor bones in 20 bytes instead of the 88 of Vertex: a packed
+// normal and half float texture coordinates.
+type CompactVertex struct {
+	Position mgl32.Vec3
+	// Normal is packed by PackInt2101010
+	Normal uint32
+	// TexCoords are packed by PackHalf
+	TexCoords [2]uint16
+}
+
+// CompactVertexLayout returns the layout of CompactVertex, with the locations of DefaultVertexLayout: the position
+// at 0, the normal at 1 and the texture coordinates at 2. Locations 3 and above stay free, e.g. for instance data.
+func CompactVertexLayout() VertexLayout {
+	var v CompactVertex
+	return VertexLayout{
+		Strides: []int32{int32(unsafe.Sizeof(v))},
+		Attributes: []VertexAttribute{
+			{Location: 0, Components: 3, Type: FLOAT, Offset: int(unsafe.Offsetof(v.Position))},
+			{Location: 1, Components: 4, Type: INT_2_10_10_10_REV, Normalized: true, Offset: int(unsafe.Offsetof(v.Normal))},
+			{Location: 2, Components: 2, Type: HALF_FLOAT, Offset: int(unsafe.Offsetof(v.TexCoords))},
+		},
+	}
+}
+
+// CompactVertices packs the position, normal and texture coordinates of vertices.
+func CompactVertices(vertices []Vertex) []CompactVertex {
+	compact := make([]CompactVertex, len(vertices))
+	for i := range vertices {
+		v := &vertices[i]
+		compact[i] = CompactVertex{
+			Position:  v.Position,
+			Normal:    PackInt2101010(v.Normal.Vec4(0)),
+			TexCoords: [2]uint16{PackHalf(v.TexCoords[0]), PackHalf(v.TexCoords[1])},
+		}
+	}
+	return compact
+}
+
+// VertexBytes returns the memory of vertices, for the streams of NewMeshWithLayout.
+func VertexBytes[T any](vertices []T) []byte {
+	if len(vertices) == 0 {
+		return nil
+	}
+	var v T
+	return unsafe.Slice((*byte)(unsafe.Pointer(&vertices[0])), len(vertices)*int(unsafe.Sizeof(v)))
+}
+
+// Validate checks that the attributes fit in their streams and that streams hold the same number of vertices,
+// which it returns.
+func (l *VertexLayout) Validate(streams [][]byte) (int, error) {
+	if len(streams) == 0 || len(streams) != len(l.Strides) {
+		return 0, fmt.Errorf("gl: %v streams for %v strides", len(streams), len(l.Strides))
+	}
+	for i := range l.Attributes {
+		a := &l.Attributes[i]
+		if a.Stream < 0 || a.Stream >= len(streams) {
+			return 0, fmt.Errorf("gl: attribute %v reads stream %v of %v", a.Location, a.Stream, len(streams))
+		}
+		if a.Components < 1 || a.Components > 4 {
+			return 0, fmt.Errorf("gl: attribute %v has %v components", a.Location, a.Components)
+		}
+		if (a.Type == INT_2_10_10_10_REV || a.Type == UNSIGNED_INT_2_10_10_10_REV) && a.Components != 4 {
+			return 0, fmt.Errorf("gl: packed attribute %v needs 4 components", a.Location)
+		}
+		if a.Offset < 0 || a.Offset+a.size() > int(l.Strides[a.Stream]) {
+			return 0, fmt.Errorf("gl: attribute %v exceeds the stride %v of stream %v", a.Location, l.Strides[a.Stream], a.Stream)
+		}
+	}
+	count := -1
+	for i, stream := range streams {
+		stride := int(l.Strides[i])
+		if stride <= 0 || len(stream)%stride != 0 {
+			return 0, fmt.Errorf("gl: stream %v of %v bytes isn't made of vertices of %v bytes", i, len(stream), stride)
+		}
+		if count >= 0 && len(stream)/stride != count {
+			return 0, fmt.Errorf("gl: stream %v holds %v vertices, %v expected", i, len(stream)/stride, count)
+		}
+		count = len(stream) / stride
+	}
+	return count, nil
+}
+
+// Apply sets the attribute pointers of the bound vertex array to buffers, one per stream, and enables them.
+func (l *VertexLayout) Apply(buffers []uint32) {
+	for i := range l.Attributes {
+		a := &l.Attributes[i]
+		BindBuffer(ARRAY_BUFFER, buffers[a.Stream])
+		EnableVertexAttribArray(a.Location)
+		if a.Integer {
+			VertexAttribIPointer(a.Location, a.Components, a.Type, l.Strides[a.Stream], a.Offset)
+		} else {
+			VertexAttribPointer(a.Location, a.Components, a.Type, a.Normalized, l.Strides[a.Stream], a.Offset)
+		}
+	}
+}
+
+// PackHalf converts f to an IEEE 754 half precision float for HALF_FLOAT attributes, rounding to nearest even.
+func PackHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127 + 15
+	mantissa := bits & 0x7fffff
+	switch {
+	case bits&0x7fffffff > 0x7f800000:
+		// NaN
+		return sign | 0x7e00
+	case exp >= 0x1f:
+		// too large, infinity
+		return sign | 0x7c00
+	case exp <= 0:
+		// subnormal or zero
+		if exp < -10 {
+			return sign
+		}
+		mantissa |= 0x800000
+		shift := uint(14 - exp)
+		half := uint16(mantissa >> shift)
+		rest := mantissa & (1<<shift - 1)
+		if rest > 1<<(shift-1) || rest == 1<<(shift-1) && half&1 != 0 {
+			half++
+		}
+		return sign | half
+	}
+	half := uint16(exp)<<10 | uint16(mantissa>>13)
+	rest := mantissa & 0x1fff
+	// a carry into the exponent rounds up to the next power of two, or to infinity
+	if rest > 0x1000 || rest == 0x1000 && half&1 != 0 {
+		half++
+	}
+	return sign | half
+}
+
+// PackInt2101010 packs v, clamped to [-1, 1], for normalized INT_2_10_10_10_REV attributes: x, y and z in the low
+// 10 bit fields, w in the top 2 bits.
+func PackInt2101010(v mgl32.Vec4) uint32 {
+	var p uint32
+	for i, bits := range [4]uint{10, 10, 10, 2} {
+		max := float32(int(1)<<(bits-1) - 1)
+		value := int32(math.Round(float64(mgl32.Clamp(v[i], -1, 1) * max)))
+		p |= uint32(value) & (1<<bits - 1) << (10 * uint(i))
+	}
+	return p
+}
+
+// PackUnorm8 packs v, clamped to [0, 1], for 4 normalized UNSIGNED_BYTE components, e.g. colors.
+func PackUnorm8(v mgl32.Vec4) [4]uint8 {
+	var p [4]uint8
+	for i := range v {
+		p[i] = uint8(math.Round(float64(mgl32.Clamp(v[i], 0, 1) * 255)))
+	}
+	return p
+}
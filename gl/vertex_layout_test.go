@@ -0,0 +1,97 @@
+package gl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestPackHalf(t *testing.T) {
+	for _, c := range []struct {
+		f    float32
+		want uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{0.1, 0x2e66},
+		{65504, 0x7bff},
+		// rounding to nearest even, a tie rounds to the even mantissa
+		{1 + 1.0/2048, 0x3c00},
+		{1 + 3.0/2048, 0x3c02},
+		{1 + 1.0/2048 + 1.0/65536, 0x3c01},
+		// overflow
+		{65520, 0x7c00},
+		{1e10, 0x7c00},
+		{-1e10, 0xfc00},
+		{float32(math.Inf(1)), 0x7c00},
+		{float32(math.Inf(-1)), 0xfc00},
+		// smallest normal and denormals
+		{1.0 / (1 << 14), 0x0400},
+		{1.0/(1<<14) - 1.0/(1<<24), 0x03ff},
+		{1.0 / (1 << 24), 0x0001},
+		{-1.0 / (1 << 24), 0x8001},
+		{3.0 / (1 << 26), 0x0001},
+		// half of the smallest denormal is a tie with 0
+		{1.0 / (1 << 25), 0x0000},
+		{1.0 / (1 << 30), 0x0000},
+		// rounding up the largest denormal gives the smallest normal
+		{1.0/(1<<14) - 1.0/(1<<26), 0x0400},
+	} {
+		if got := PackHalf(c.f); got != c.want {
+			t.Errorf("PackHalf(%v) = %#04x, want %#04x", c.f, got, c.want)
+		}
+	}
+	for _, nan := range []float32{float32(math.NaN()), math.Float32frombits(0x7f800001), math.Float32frombits(0xffc00000)} {
+		if got := PackHalf(nan); got&0x7c00 != 0x7c00 || got&0x3ff == 0 {
+			t.Errorf("PackHalf(%#08x) = %#04x, want a NaN", math.Float32bits(nan), got)
+		}
+	}
+}
+
+func TestPackInt2101010(t *testing.T) {
+	for _, c := range []struct {
+		v    mgl32.Vec4
+		want [4]int32
+	}{
+		{mgl32.Vec4{0, 0, 0, 0}, [4]int32{0, 0, 0, 0}},
+		{mgl32.Vec4{1, -1, 0.5, 1}, [4]int32{511, -511, 256, 1}},
+		{mgl32.Vec4{-0.5, 0.25, -0.001, -1}, [4]int32{-256, 128, -1, -1}},
+		// clamped to [-1, 1]
+		{mgl32.Vec4{2, -3, 100, -2}, [4]int32{511, -511, 511, -1}},
+	} {
+		p := PackInt2101010(c.v)
+		// sign extend the fields
+		got := [4]int32{
+			int32(p<<22) >> 22,
+			int32(p<<12) >> 22,
+			int32(p<<2) >> 22,
+			int32(p) >> 30,
+		}
+		if got != c.want {
+			t.Errorf("PackInt2101010(%v) = %#08x, fields %v, want %v", c.v, p, got, c.want)
+		}
+	}
+	if got := PackInt2101010(mgl32.Vec4{1, -1, 0, 1}); got != 0x1ff|0x201<<10|1<<30 {
+		t.Errorf("PackInt2101010 = %#08x, want %#08x", got, 0x1ff|0x201<<10|1<<30)
+	}
+}
+
+func TestPackUnorm8(t *testing.T) {
+	for _, c := range []struct {
+		v    mgl32.Vec4
+		want [4]uint8
+	}{
+		{mgl32.Vec4{0, 1, 0.5, 0.2}, [4]uint8{0, 255, 128, 51}},
+		{mgl32.Vec4{1.0 / 255, 254.0 / 255, 0.499 / 255, 0.501 / 255}, [4]uint8{1, 254, 0, 1}},
+		// clamped to [0, 1]
+		{mgl32.Vec4{-1, 2, -0.001, 1e9}, [4]uint8{0, 255, 0, 255}},
+	} {
+		if got := PackUnorm8(c.v); got != c.want {
+			t.Errorf("PackUnorm8(%v) = %v, want %v", c.v, got, c.want)
+		}
+	}
+}
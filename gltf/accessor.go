@@ -0,0 +1,215 @@
+package gltf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+const (
+	// component types of accessors
+	BYTE           = 5120
+	UNSIGNED_BYTE  = 5121
+	SHORT          = 5122
+	UNSIGNED_SHORT = 5123
+	UNSIGNED_INT   = 5125
+	FLOAT          = 5126
+)
+
+var componentSizes = map[int]int{
+	BYTE:           1,
+	UNSIGNED_BYTE:  1,
+	SHORT:          2,
+	UNSIGNED_SHORT: 2,
+	UNSIGNED_INT:   4,
+	FLOAT:          4,
+}
+
+var typeComponents = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+	"MAT2":   4,
+	"MAT3":   9,
+	"MAT4":   16,
+}
+
+// readFloats reads an accessor as floats, normalized integers are mapped to [0, 1] or [-1, 1]. It returns the values
+// of the elements one after the other and the number of components per element.
+func (d *decoder) readFloats(index int) ([]float32, int, error) {
+	a, components, err := d.accessor(index)
+	if err != nil {
+		return nil, 0, err
+	}
+	// check that the elements fit before allocating for them
+	var data []byte
+	var stride int
+	if a.BufferView != nil {
+		if data, stride, err = d.elements(a, components, *a.BufferView, a.ByteOffset); err != nil {
+			return nil, 0, fmt.Errorf("gltf: accessor %v: %w", index, err)
+		}
+	}
+	values := make([]float32, a.Count*components)
+	if data != nil {
+		convert(a, components, data, stride, a.Count, values)
+	}
+	// without buffer view the values are zeros, possibly replaced by sparse ones
+	if a.Sparse != nil {
+		if err = d.readSparse(a, components, values); err != nil {
+			return nil, 0, fmt.Errorf("gltf: accessor %v: %w", index, err)
+		}
+	}
+	return values, components, nil
+}
+
+// readUints reads an accessor of integers, e.g. indices or joints.
+func (d *decoder) readUints(index int) ([]uint32, int, error) {
+	a, components, err := d.accessor(index)
+	if err != nil {
+		return nil, 0, err
+	}
+	if a.ComponentType == FLOAT || a.Normalized {
+		return nil, 0, fmt.Errorf("gltf: accessor %v doesn't hold integers", index)
+	}
+	if a.Sparse != nil {
+		return nil, 0, fmt.Errorf("gltf: sparse integer accessor %v not supported", index)
+	}
+	if a.BufferView == nil {
+		return make([]uint32, a.Count*components), components, nil
+	}
+	data, stride, err := d.elements(a, components, *a.BufferView, a.ByteOffset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gltf: accessor %v: %w", index, err)
+	}
+	// the floats are exact up to 2^24, read the integers directly instead
+	values := make([]uint32, a.Count*components)
+	size := componentSizes[a.ComponentType]
+	for i := 0; i < a.Count; i++ {
+		for c := 0; c < components; c++ {
+			b := data[i*stride+c*size:]
+			switch a.ComponentType {
+			case UNSIGNED_BYTE, BYTE:
+				values[i*components+c] = uint32(b[0])
+			case UNSIGNED_SHORT, SHORT:
+				values[i*components+c] = uint32(binary.LittleEndian.Uint16(b))
+			case UNSIGNED_INT:
+				values[i*components+c] = binary.LittleEndian.Uint32(b)
+			}
+		}
+	}
+	return values, components, nil
+}
+
+func (d *decoder) accessor(index int) (*accessor, int, error) {
+	if index < 0 || index >= len(d.doc.Accessors) {
+		return nil, 0, fmt.Errorf("gltf: accessor %v out of range", index)
+	}
+	a := &d.doc.Accessors[index]
+	components, ok := typeComponents[a.Type]
+	if !ok {
+		return nil, 0, fmt.Errorf("gltf: accessor %v has unknown type %q", index, a.Type)
+	}
+	if _, ok = componentSizes[a.ComponentType]; !ok {
+		return nil, 0, fmt.Errorf("gltf: accessor %v has unknown component type %v", index, a.ComponentType)
+	}
+	if a.Count < 0 {
+		return nil, 0, fmt.Errorf("gltf: accessor %v has a negative count", index)
+	}
+	return a, components, nil
+}
+
+// elements returns the bytes of the elements of an accessor in a buffer view and the stride between two elements,
+// after checking that count elements fit.
+func (d *decoder) elements(a *accessor, components, view, offset int) ([]byte, int, error) {
+	data, stride, err := d.bufferView(view)
+	if err != nil {
+		return nil, 0, err
+	}
+	elementSize := componentSizes[a.ComponentType] * components
+	if stride == 0 {
+		stride = elementSize
+	}
+	if offset < 0 || stride < elementSize {
+		return nil, 0, fmt.Errorf("invalid offset %v or stride %v", offset, stride)
+	}
+	if a.Count > 0 && offset+(a.Count-1)*stride+elementSize > len(data) {
+		return nil, 0, fmt.Errorf("%v elements exceed buffer view %v", a.Count, view)
+	}
+	return data[offset:], stride, nil
+}
+
+// convert converts count elements of the accessor a, given by elements, to floats into values.
+func convert(a *accessor, components int, data []byte, stride, count int, values []float32) {
+	size := componentSizes[a.ComponentType]
+	for i := 0; i < count; i++ {
+		for c := 0; c < components; c++ {
+			values[i*components+c] = component(data[i*stride+c*size:], a.ComponentType, a.Normalized)
+		}
+	}
+}
+
+// readSparse replaces the elements listed by the sparse storage of a.
+func (d *decoder) readSparse(a *accessor, components int, values []float32) error {
+	s := a.Sparse
+	if s.Count < 0 {
+		return fmt.Errorf("negative sparse count")
+	}
+	indices := accessor{ComponentType: s.Indices.ComponentType, Count: s.Count, Type: "SCALAR"}
+	if _, ok := componentSizes[indices.ComponentType]; !ok {
+		return fmt.Errorf("unknown sparse index type %v", indices.ComponentType)
+	}
+	indexData, indexStride, err := d.elements(&indices, 1, s.Indices.BufferView, s.Indices.ByteOffset)
+	if err != nil {
+		return err
+	}
+	stored := *a
+	stored.Count = s.Count
+	valueData, valueStride, err := d.elements(&stored, components, s.Values.BufferView, s.Values.ByteOffset)
+	if err != nil {
+		return err
+	}
+	sparseValues := make([]float32, s.Count*components)
+	convert(a, components, valueData, valueStride, s.Count, sparseValues)
+	for i := 0; i < s.Count; i++ {
+		target := int(component(indexData[i*indexStride:], indices.ComponentType, false))
+		if target < 0 || target >= a.Count {
+			return fmt.Errorf("sparse index %v out of range", target)
+		}
+		copy(values[target*components:(target+1)*components], sparseValues[i*components:])
+	}
+	return nil
+}
+
+func component(b []byte, componentType int, normalized bool) float32 {
+	switch componentType {
+	case BYTE:
+		v := float32(int8(b[0]))
+		if normalized {
+			return float32(math.Max(float64(v)/127, -1))
+		}
+		return v
+	case UNSIGNED_BYTE:
+		v := float32(b[0])
+		if normalized {
+			return v / 255
+		}
+		return v
+	case SHORT:
+		v := float32(int16(binary.LittleEndian.Uint16(b)))
+		if normalized {
+			return float32(math.Max(float64(v)/32767, -1))
+		}
+		return v
+	case UNSIGNED_SHORT:
+		v := float32(binary.LittleEndian.Uint16(b))
+		if normalized {
+			return v / 65535
+		}
+		return v
+	case UNSIGNED_INT:
+		return float32(binary.LittleEndian.Uint32(b))
+	default:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+}
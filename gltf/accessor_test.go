@@ -0,0 +1,116 @@
+package gltf
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testDecoder returns the decoder of a test file with its buffers loaded.
+func testDecoder(t *testing.T, name string) *decoder {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &decoder{}
+	if err = json.Unmarshal(data, &d.doc); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.loadBuffers(); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func checkFloats(t *testing.T, what string, got []float32, want ...float32) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%v: %v, want %v", what, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%v: %v, want %v", what, got, want)
+		}
+	}
+}
+
+func TestStridedAccessor(t *testing.T) {
+	d := testDecoder(t, "embedded.gltf")
+	// positions and normals are interleaved with a stride of 24 bytes
+	positions, components, err := d.readFloats(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if components != 3 {
+		t.Errorf("%v components", components)
+	}
+	checkFloats(t, "positions", positions, 0, 0, 0, 1, 0, 0, 0, 1, 0, 1, 1, 0)
+	normals, _, err := d.readFloats(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFloats(t, "normals", normals, 0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1)
+
+	indices, _, err := d.readUints(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indices) != 4 || indices[3] != 3 {
+		t.Errorf("indices %v", indices)
+	}
+	if _, _, err = d.readUints(0); err == nil {
+		t.Errorf("read floats as integers")
+	}
+	joints, components, err := d.readUints(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if components != 4 || joints[0] != 0 || joints[8] != 1 {
+		t.Errorf("joints %v", joints)
+	}
+}
+
+func TestSparseAccessor(t *testing.T) {
+	d := testDecoder(t, "embedded.gltf")
+	// the strided positions with element 2 replaced
+	values, _, err := d.readFloats(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFloats(t, "sparse over a view", values, 0, 0, 0, 1, 0, 0, 5, 5, 5, 1, 1, 0)
+	// zeros with element 2 replaced
+	if values, _, err = d.readFloats(5); err != nil {
+		t.Fatal(err)
+	}
+	checkFloats(t, "sparse without view", values, 0, 0, 0, 0, 0, 0, 5, 5, 5)
+
+	// a sparse index past the count
+	d.doc.Accessors[5].Count = 2
+	if _, _, err = d.readFloats(5); err == nil {
+		t.Errorf("sparse index out of range read")
+	}
+}
+
+func TestNormalizedComponents(t *testing.T) {
+	for _, test := range []struct {
+		componentType int
+		bytes         []byte
+		want          float32
+	}{
+		{UNSIGNED_BYTE, []byte{255}, 1},
+		{UNSIGNED_BYTE, []byte{51}, 0.2},
+		{BYTE, []byte{0x81}, -1},
+		{BYTE, []byte{0x80}, -1},
+		{UNSIGNED_SHORT, []byte{0xff, 0xff}, 1},
+		{SHORT, []byte{0x01, 0x80}, -1},
+	} {
+		if got := component(test.bytes, test.componentType, true); got != test.want {
+			t.Errorf("component %v of type %v = %v, want %v", test.bytes, test.componentType, got, test.want)
+		}
+	}
+	if got := component([]byte{200}, UNSIGNED_BYTE, false); got != 200 {
+		t.Errorf("unnormalized byte %v", got)
+	}
+}
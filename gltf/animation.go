@@ -0,0 +1,188 @@
+package gltf
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// target paths of animation channels, weights of morph targets aren't applied
+	PATH_TRANSLATION = "translation"
+	PATH_ROTATION    = "rotation"
+	PATH_SCALE       = "scale"
+	PATH_WEIGHTS     = "weights"
+
+	// interpolations of animation samplers
+	INTERPOLATION_LINEAR       = "LINEAR"
+	INTERPOLATION_STEP         = "STEP"
+	INTERPOLATION_CUBIC_SPLINE = "CUBICSPLINE"
+)
+
+// Animation animates the translation, rotation or scale of nodes. Times are in seconds.
+type Animation struct {
+	Name     string
+	Channels []Channel
+	// Duration is the time of the last keyframe
+	Duration float32
+}
+
+// Channel holds the keyframes of one property of a node. Values holds Components values per keyframe, three per
+// keyframe for cubic splines: in-tangent, value and out-tangent.
+type Channel struct {
+	// Node indexes Scene.Nodes
+	Node          int
+	Path          string
+	Interpolation string
+	Times         []float32
+	Values        []float32
+	Components    int
+}
+
+func (d *decoder) animation(index int) (Animation, error) {
+	a := d.doc.Animations[index]
+	animation := Animation{Name: a.Name}
+	for i, c := range a.Channels {
+		if c.Target.Node == nil {
+			// targets of extensions
+			continue
+		}
+		if *c.Target.Node < 0 || *c.Target.Node >= len(d.doc.Nodes) || c.Sampler < 0 || c.Sampler >= len(a.Samplers) {
+			return Animation{}, fmt.Errorf("gltf: animation %v channel %v is invalid", index, i)
+		}
+		sampler := a.Samplers[c.Sampler]
+		channel := Channel{Node: *c.Target.Node, Path: c.Target.Path, Interpolation: sampler.Interpolation}
+		if channel.Interpolation == "" {
+			channel.Interpolation = INTERPOLATION_LINEAR
+		}
+		var err error
+		if channel.Times, _, err = d.readFloats(sampler.Input); err != nil {
+			return Animation{}, err
+		}
+		if channel.Values, channel.Components, err = d.readFloats(sampler.Output); err != nil {
+			return Animation{}, err
+		}
+		keys := len(channel.Times)
+		if channel.Interpolation == INTERPOLATION_CUBIC_SPLINE {
+			keys *= 3
+		}
+		if channel.Path == PATH_WEIGHTS {
+			// the weights of all the morph targets are in a single scalar output
+			if keys > 0 {
+				channel.Components = len(channel.Values) / keys
+			}
+		} else if len(channel.Values) != keys*channel.Components {
+			return Animation{}, fmt.Errorf("gltf: animation %v channel %v has %v values for %v keyframes", index, i, len(channel.Values)/channel.Components, keys)
+		}
+		if n := len(channel.Times); n > 0 && channel.Times[n-1] > animation.Duration {
+			animation.Duration = channel.Times[n-1]
+		}
+		animation.Channels = append(animation.Channels, channel)
+	}
+	return animation, nil
+}
+
+// Sample returns the value of the channel at time t, clamped to the first and last keyframes.
+func (c *Channel) Sample(t float32) []float32 {
+	n := c.Components
+	value := make([]float32, n)
+	if len(c.Times) == 0 || n == 0 {
+		return value
+	}
+	// key returns keyframe i, the value of a cubic spline keyframe sits between its tangents
+	key := func(i int) []float32 {
+		if c.Interpolation == INTERPOLATION_CUBIC_SPLINE {
+			return c.Values[(3*i+1)*n : (3*i+2)*n]
+		}
+		return c.Values[i*n : (i+1)*n]
+	}
+	last := len(c.Times) - 1
+	i := sort.Search(len(c.Times), func(i int) bool { return c.Times[i] > t }) - 1
+	if i < 0 {
+		copy(value, key(0))
+		return value
+	}
+	if i >= last {
+		copy(value, key(last))
+		return value
+	}
+	dt := c.Times[i+1] - c.Times[i]
+	factor := float32(0)
+	if dt > 0 {
+		factor = (t - c.Times[i]) / dt
+	}
+
+	switch c.Interpolation {
+	case INTERPOLATION_STEP:
+		copy(value, key(i))
+	case INTERPOLATION_CUBIC_SPLINE:
+		// Hermite spline with the out-tangent of key i and the in-tangent of key i+1, scaled by the interval
+		p0, p1 := key(i), key(i+1)
+		m0 := c.Values[(3*i+2)*n : (3*i+3)*n]
+		m1 := c.Values[(3*(i+1))*n : (3*(i+1)+1)*n]
+		s := factor
+		s2, s3 := s*s, s*s*s
+		for j := 0; j < n; j++ {
+			value[j] = (2*s3-3*s2+1)*p0[j] + (s3-2*s2+s)*dt*m0[j] + (-2*s3+3*s2)*p1[j] + (s3-s2)*dt*m1[j]
+		}
+		if c.Path == PATH_ROTATION && n == 4 {
+			q := mgl32.Quat{W: value[3], V: mgl32.Vec3{value[0], value[1], value[2]}}.Normalize()
+			value[0], value[1], value[2], value[3] = q.V[0], q.V[1], q.V[2], q.W
+		}
+	default:
+		a, b := key(i), key(i+1)
+		if c.Path == PATH_ROTATION && n == 4 {
+			qa := mgl32.Quat{W: a[3], V: mgl32.Vec3{a[0], a[1], a[2]}}
+			qb := mgl32.Quat{W: b[3], V: mgl32.Vec3{b[0], b[1], b[2]}}
+			// take the shortest path, mgl32.QuatSlerp doesn't
+			if qa.Dot(qb) < 0 {
+				qb = qb.Scale(-1)
+			}
+			q := mgl32.QuatSlerp(qa, qb, factor).Normalize()
+			value[0], value[1], value[2], value[3] = q.V[0], q.V[1], q.V[2], q.W
+		} else {
+			for j := 0; j < n; j++ {
+				value[j] = a[j] + (b[j]-a[j])*factor
+			}
+		}
+	}
+	return value
+}
+
+// Animate poses the nodes of the scene at time t of animation, in seconds. The caller loops t if needed.
+func (s *Scene) Animate(animation *Animation, t float32) {
+	for i := range animation.Channels {
+		c := &animation.Channels[i]
+		node := s.Nodes[c.Node]
+		switch c.Path {
+		case PATH_TRANSLATION:
+			if c.Components == 3 {
+				v := c.Sample(t)
+				node.Translation = mgl32.Vec3{v[0], v[1], v[2]}
+			}
+		case PATH_ROTATION:
+			if c.Components == 4 {
+				v := c.Sample(t)
+				node.Rotation = mgl32.Quat{W: v[3], V: mgl32.Vec3{v[0], v[1], v[2]}}
+			}
+		case PATH_SCALE:
+			if c.Components == 3 {
+				v := c.Sample(t)
+				node.Scale = mgl32.Vec3{v[0], v[1], v[2]}
+			}
+		}
+	}
+}
+
+// JointMatrices returns the matrices that move the vertices of a mesh bound to skin from the bind pose to the current
+// pose of the joints, indexed by bone id. The vertices end up in scene space, the skinned mesh is drawn without the
+// transform of its node.
+func (s *Scene) JointMatrices(skin int) []mgl32.Mat4 {
+	sk := &s.Skins[skin]
+	matrices := make([]mgl32.Mat4, len(sk.Joints))
+	for i, joint := range sk.Joints {
+		matrices[i] = s.Nodes[joint].WorldTransform().Mul4(sk.InverseBindMatrices[i])
+	}
+	return matrices
+}
@@ -0,0 +1,90 @@
+package gltf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func checkSample(t *testing.T, c *Channel, time float32, want ...float32) {
+	t.Helper()
+	got := c.Sample(time)
+	if len(got) != len(want) {
+		t.Fatalf("%v at %v: %v, want %v", c.Interpolation, time, got, want)
+	}
+	for i := range got {
+		if math.Abs(float64(got[i]-want[i])) > 1e-5 {
+			t.Errorf("%v at %v: %v, want %v", c.Interpolation, time, got, want)
+			return
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	s := readTestFile(t, "embedded.gltf")
+	a := &s.Animations[0]
+	if a.Name != "move" || a.Duration != 2 || len(a.Channels) != 4 {
+		t.Fatalf("animation %v of %vs with %v channels", a.Name, a.Duration, len(a.Channels))
+	}
+
+	linear := &a.Channels[0]
+	if linear.Interpolation != INTERPOLATION_LINEAR || linear.Path != PATH_TRANSLATION || linear.Node != 0 {
+		t.Errorf("channel 0 %+v", linear)
+	}
+	checkSample(t, linear, -1, 0, 0, 0)
+	checkSample(t, linear, 0.5, 0.5, 0, 0)
+	checkSample(t, linear, 1.5, 1, 1, 0)
+	checkSample(t, linear, 5, 1, 2, 0)
+
+	step := &a.Channels[1]
+	checkSample(t, step, 0.99, 1, 1, 1)
+	checkSample(t, step, 1, 2, 2, 2)
+	checkSample(t, step, 1.5, 2, 2, 2)
+	checkSample(t, step, 2, 3, 3, 3)
+
+	// rotations are interpolated spherically, 45° and 135° around z
+	rotation := &a.Channels[2]
+	sin, cos := float32(math.Sin(math.Pi/8)), float32(math.Cos(math.Pi/8))
+	checkSample(t, rotation, 0.5, 0, 0, sin, cos)
+	checkSample(t, rotation, 1.5, 0, 0, cos, sin)
+
+	// the tangents are zero, the spline eases in and out
+	cubic := &a.Channels[3]
+	if cubic.Interpolation != INTERPOLATION_CUBIC_SPLINE || len(cubic.Values) != 27 {
+		t.Fatalf("cubic channel %+v", cubic)
+	}
+	checkSample(t, cubic, 0, 0, 0, 0)
+	checkSample(t, cubic, 0.25, 0.15625, 0, 0)
+	checkSample(t, cubic, 0.5, 0.5, 0, 0)
+	checkSample(t, cubic, 1.5, 1, 0, 0)
+}
+
+func TestSampleShortestRotation(t *testing.T) {
+	half := float32(math.Sqrt(0.5))
+	// the second key is the 90° rotation around z with the opposite sign
+	c := Channel{
+		Path:          PATH_ROTATION,
+		Interpolation: INTERPOLATION_LINEAR,
+		Times:         []float32{0, 1},
+		Values:        []float32{0, 0, 0, 1, 0, 0, -half, -half},
+		Components:    4,
+	}
+	v := c.Sample(0.5)
+	q := mgl32.Quat{W: v[3], V: mgl32.Vec3{v[0], v[1], v[2]}}
+	if p := q.Rotate(mgl32.Vec3{1, 0, 0}); !near(p, mgl32.Vec3{half, half, 0}) {
+		t.Errorf("halfway rotation moved x to %v", p)
+	}
+}
+
+func TestAnimate(t *testing.T) {
+	s := readTestFile(t, "embedded.gltf")
+	s.Animate(&s.Animations[0], 1.5)
+	root := s.Nodes[0]
+	if root.Translation != (mgl32.Vec3{1, 1, 0}) || root.Scale != (mgl32.Vec3{2, 2, 2}) {
+		t.Errorf("root at %v, scale %v", root.Translation, root.Scale)
+	}
+	if fan := s.Nodes[5]; fan.Translation != (mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("fan at %v", fan.Translation)
+	}
+}
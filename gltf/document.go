@@ -0,0 +1,157 @@
+package gltf
+
+// the JSON schema of glTF 2.0, restricted to what the importer reads. Indices are pointers where the index is
+// optional, since 0 is a valid one.
+
+type document struct {
+	Asset       asset        `json:"asset"`
+	Scene       *int         `json:"scene"`
+	Scenes      []scene      `json:"scenes"`
+	Nodes       []node       `json:"nodes"`
+	Meshes      []mesh       `json:"meshes"`
+	Accessors   []accessor   `json:"accessors"`
+	BufferViews []bufferView `json:"bufferViews"`
+	Buffers     []buffer     `json:"buffers"`
+	Materials   []material   `json:"materials"`
+	Textures    []texture    `json:"textures"`
+	Images      []image      `json:"images"`
+	Skins       []skin       `json:"skins"`
+	Animations  []animation  `json:"animations"`
+
+	ExtensionsRequired []string `json:"extensionsRequired"`
+}
+
+type asset struct {
+	Version    string `json:"version"`
+	MinVersion string `json:"minVersion"`
+}
+
+type scene struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+type node struct {
+	Name        string       `json:"name"`
+	Children    []int        `json:"children"`
+	Matrix      *[16]float32 `json:"matrix"`
+	Translation *[3]float32  `json:"translation"`
+	Rotation    *[4]float32  `json:"rotation"`
+	Scale       *[3]float32  `json:"scale"`
+	Mesh        *int         `json:"mesh"`
+	Skin        *int         `json:"skin"`
+}
+
+type mesh struct {
+	Name       string      `json:"name"`
+	Primitives []primitive `json:"primitives"`
+}
+
+type primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+type accessor struct {
+	BufferView    *int    `json:"bufferView"`
+	ByteOffset    int     `json:"byteOffset"`
+	ComponentType int     `json:"componentType"`
+	Normalized    bool    `json:"normalized"`
+	Count         int     `json:"count"`
+	Type          string  `json:"type"`
+	Sparse        *sparse `json:"sparse"`
+}
+
+type sparse struct {
+	Count   int `json:"count"`
+	Indices struct {
+		BufferView    int `json:"bufferView"`
+		ByteOffset    int `json:"byteOffset"`
+		ComponentType int `json:"componentType"`
+	} `json:"indices"`
+	Values struct {
+		BufferView int `json:"bufferView"`
+		ByteOffset int `json:"byteOffset"`
+	} `json:"values"`
+}
+
+type bufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type buffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type material struct {
+	Name                 string                `json:"name"`
+	PBRMetallicRoughness *pbrMetallicRoughness `json:"pbrMetallicRoughness"`
+	NormalTexture        *textureInfo          `json:"normalTexture"`
+	OcclusionTexture     *textureInfo          `json:"occlusionTexture"`
+	EmissiveTexture      *textureInfo          `json:"emissiveTexture"`
+	EmissiveFactor       *[3]float32           `json:"emissiveFactor"`
+	AlphaMode            string                `json:"alphaMode"`
+	AlphaCutoff          *float32              `json:"alphaCutoff"`
+	DoubleSided          bool                  `json:"doubleSided"`
+}
+
+type pbrMetallicRoughness struct {
+	BaseColorFactor          *[4]float32  `json:"baseColorFactor"`
+	BaseColorTexture         *textureInfo `json:"baseColorTexture"`
+	MetallicFactor           *float32     `json:"metallicFactor"`
+	RoughnessFactor          *float32     `json:"roughnessFactor"`
+	MetallicRoughnessTexture *textureInfo `json:"metallicRoughnessTexture"`
+}
+
+// textureInfo references a texture from a material, Scale is the scale of a normal texture and Strength the
+// strength of an occlusion texture.
+type textureInfo struct {
+	Index    int      `json:"index"`
+	TexCoord int      `json:"texCoord"`
+	Scale    *float32 `json:"scale"`
+	Strength *float32 `json:"strength"`
+}
+
+type texture struct {
+	Source *int `json:"source"`
+}
+
+type image struct {
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int   `json:"bufferView"`
+}
+
+type skin struct {
+	Name                string `json:"name"`
+	InverseBindMatrices *int   `json:"inverseBindMatrices"`
+	Skeleton            *int   `json:"skeleton"`
+	Joints              []int  `json:"joints"`
+}
+
+type animation struct {
+	Name     string             `json:"name"`
+	Channels []animationChannel `json:"channels"`
+	Samplers []animationSampler `json:"samplers"`
+}
+
+type animationChannel struct {
+	Sampler int `json:"sampler"`
+	Target  struct {
+		Node *int   `json:"node"`
+		Path string `json:"path"`
+	} `json:"target"`
+}
+
+type animationSampler struct {
+	Input         int    `json:"input"`
+	Output        int    `json:"output"`
+	Interpolation string `json:"interpolation"`
+}
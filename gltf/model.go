@@ -0,0 +1,218 @@
+package gltf
+
+import (
+	"fmt"
+	"learn_opengl/gl"
+	"log"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// LoadOptions configures LoadModel.
+type LoadOptions struct {
+	// MissingTexture replaces the images that fail to decode with a magenta checker instead of failing
+	MissingTexture bool
+	// Logger receives what is loaded, nil discards it
+	Logger *log.Logger
+}
+
+// Model is a glTF scene uploaded to GL. The images are decoded as they are stored, glTF's texture coordinates
+// expect stbi.SetFlipVerticallyOnLoad(false).
+type Model struct {
+	scene  *Scene
+	meshes []gl.Mesh
+	// textures holds the texture of every image of the scene
+	textures []gl.Texture
+	// missingTexture is the checker standing in for the images that failed to decode, 0 until needed
+	missingTexture uint32
+}
+
+var _ gl.Model = (*Model)(nil)
+
+func NewModel(path string) *Model {
+	model, err := LoadModel(path, LoadOptions{Logger: log.Default()})
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return model
+}
+
+// LoadModel imports the .gltf or .glb file at path and uploads its meshes and images. Unlike NewModel it returns an
+// error instead of exiting, whatever was uploaded is released on error.
+func LoadModel(path string, opts LoadOptions) (*Model, error) {
+	scene, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Model{scene: scene}
+	if err = m.upload(opts); err != nil {
+		m.Release()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Scene returns the imported scene, its nodes may be moved or animated between draws.
+func (m *Model) Scene() *Scene {
+	return m.scene
+}
+
+func (m *Model) Meshes() []gl.Mesh {
+	return m.meshes
+}
+
+// Draw draws the meshes of every node with the model matrix model times the world transform of the node, uploaded to
+// gl.MODEL_UNIFORM. Skinned meshes are drawn with model alone, their joint matrices already place them.
+func (m *Model) Draw(shader *gl.Shader, model mgl32.Mat4) {
+	for _, root := range m.scene.Roots {
+		m.drawNode(root, shader, model, model)
+	}
+}
+
+func (m *Model) drawNode(node *Node, shader *gl.Shader, model, parent mgl32.Mat4) {
+	world := parent.Mul4(node.Transform())
+	for _, i := range node.Meshes {
+		if m.scene.Meshes[i].Skinned && node.Skin >= 0 {
+			shader.SetMat4(gl.MODEL_UNIFORM, &model)
+		} else {
+			shader.SetMat4(gl.MODEL_UNIFORM, &world)
+		}
+		m.meshes[i].Draw(shader)
+	}
+	for _, child := range node.Children {
+		m.drawNode(child, shader, model, world)
+	}
+}
+
+// Bounds returns the bounding box of the meshes placed by their nodes, skinned meshes in their bind pose.
+func (m *Model) Bounds() gl.AABB {
+	b, _ := m.rootsBounds()
+	return b
+}
+
+// BoundingSphere returns a sphere containing the meshes placed by their nodes, see Bounds.
+func (m *Model) BoundingSphere() gl.BoundingSphere {
+	_, s := m.rootsBounds()
+	return s
+}
+
+// NodeBounds returns the bounding box and a bounding sphere of the meshes of node and its descendants, in the space
+// of the model.
+func (m *Model) NodeBounds(node *Node) (gl.AABB, gl.BoundingSphere) {
+	parent := mgl32.Ident4()
+	if node.Parent != nil {
+		parent = node.Parent.WorldTransform()
+	}
+	return m.nodeBounds(node, parent)
+}
+
+func (m *Model) rootsBounds() (gl.AABB, gl.BoundingSphere) {
+	b, s := gl.EmptyAABB(), gl.EmptyAABB().Sphere()
+	for _, root := range m.scene.Roots {
+		rootBounds, rootSphere := m.nodeBounds(root, mgl32.Ident4())
+		b, s = b.Union(rootBounds), s.Union(rootSphere)
+	}
+	return b, s
+}
+
+// nodeBounds places the meshes like drawNode with the identity model matrix.
+func (m *Model) nodeBounds(node *Node, parent mgl32.Mat4) (gl.AABB, gl.BoundingSphere) {
+	world := parent.Mul4(node.Transform())
+	b, s := gl.EmptyAABB(), gl.EmptyAABB().Sphere()
+	for _, i := range node.Meshes {
+		transform := world
+		if m.scene.Meshes[i].Skinned && node.Skin >= 0 {
+			transform = mgl32.Ident4()
+		}
+		b = b.Union(m.meshes[i].Bounds().Transform(transform))
+		s = s.Union(m.meshes[i].BoundingSphere().Transform(transform))
+	}
+	for _, child := range node.Children {
+		childBounds, childSphere := m.nodeBounds(child, world)
+		b, s = b.Union(childBounds), s.Union(childSphere)
+	}
+	return b, s
+}
+
+// Release frees the buffers of every mesh and every texture, the model must not be drawn afterwards. A nil model is
+// ignored.
+func (m *Model) Release() {
+	if m == nil {
+		return
+	}
+	for i := range m.meshes {
+		m.meshes[i].Delete()
+	}
+	for i := range m.textures {
+		if m.textures[i].Id() != m.missingTexture {
+			m.textures[i].Delete()
+		}
+	}
+	if m.missingTexture != 0 {
+		gl.NewTexture(m.missingTexture, "", "").Delete()
+		m.missingTexture = 0
+	}
+	m.meshes = nil
+	m.textures = nil
+}
+
+func (m *Model) upload(opts LoadOptions) error {
+	// images only used by unsupported extensions are uploaded as well, they are rare
+	for i := range m.scene.Images {
+		textureId, err := m.uploadImage(i, opts)
+		if err != nil {
+			return err
+		}
+		path := m.scene.Images[i].URI
+		if path == "" {
+			path = m.scene.Images[i].Name
+		}
+		m.textures = append(m.textures, gl.NewTexture(textureId, "", path))
+	}
+	for i := range m.scene.Meshes {
+		mesh := &m.scene.Meshes[i]
+		material := gl.DefaultMaterial()
+		if mesh.Material >= 0 {
+			material = m.scene.Materials[mesh.Material].Material
+			for _, texture := range m.scene.Materials[mesh.Material].Maps {
+				material.Textures = append(material.Textures, gl.NewTexture(m.textures[texture.Image].Id(), texture.Type, m.textures[texture.Image].Path()))
+			}
+		}
+		if len(mesh.Indices) == 0 {
+			return fmt.Errorf("gltf: mesh %v %q has no triangles", i, mesh.Name)
+		}
+		if opts.Logger != nil {
+			opts.Logger.Printf("mesh %v len(vertices)=%v len(indices)=%v len(textures)=%v", mesh.Name, len(mesh.Vertices), len(mesh.Indices), len(material.Textures))
+		}
+		m.meshes = append(m.meshes, gl.NewMeshWithMaterial(mesh.Vertices, mesh.Indices, material))
+	}
+	return nil
+}
+
+func (m *Model) uploadImage(index int, opts LoadOptions) (uint32, error) {
+	image := &m.scene.Images[index]
+	name := image.URI
+	if name == "" {
+		name = fmt.Sprintf("image %v %q", index, image.Name)
+	}
+	decoded, err := gl.DecodeTextureImageBytes(image.Data, name)
+	if err == nil {
+		var textureId uint32
+		if textureId, err = gl.UploadTextureImage(decoded); err == nil {
+			return textureId, nil
+		}
+	}
+	if !opts.MissingTexture {
+		return 0, err
+	}
+	if opts.Logger != nil {
+		opts.Logger.Printf("%v, using the missing texture", err)
+	}
+	// the checker is shared by the images that failed and released once
+	if m.missingTexture == 0 {
+		if m.missingTexture, err = gl.MissingTexture(); err != nil {
+			return 0, err
+		}
+	}
+	return m.missingTexture, nil
+}
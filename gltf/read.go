@@ -0,0 +1,174 @@
+// Package gltf imports glTF 2.0 models, .gltf with external or base64 buffers and binary .glb, in Go. Importing makes
+// no GL call: it yields the meshes as gl.Vertex data, the node hierarchy, metallic-roughness materials, images,
+// skins and animations. LoadModel uploads them.
+package gltf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// header of a binary glTF file and the types of its chunks
+	GLB_MAGIC      = 0x46546C67 // "glTF"
+	GLB_VERSION    = 2
+	GLB_CHUNK_JSON = 0x4E4F534A // "JSON"
+	GLB_CHUNK_BIN  = 0x004E4942 // "BIN\0"
+)
+
+// ReadFile imports the .gltf or .glb file at path, the buffers and images it references are read relative to its
+// directory.
+func ReadFile(path string) (*Scene, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gltf: %w", err)
+	}
+	dir := filepath.Dir(path)
+	return Decode(data, func(uri string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(uri)))
+	})
+}
+
+// Decode imports a glTF file held in data, JSON or binary. readURI reads the external buffers and images given their
+// unescaped relative URI, nil fails on them; data URIs are decoded without it.
+func Decode(data []byte, readURI func(uri string) ([]byte, error)) (*Scene, error) {
+	d := &decoder{readURI: readURI}
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == GLB_MAGIC {
+		var err error
+		if data, d.bin, err = splitGLB(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(data, &d.doc); err != nil {
+		return nil, fmt.Errorf("gltf: %w", err)
+	}
+	if !strings.HasPrefix(d.doc.Asset.Version, "2.") {
+		return nil, fmt.Errorf("gltf: unsupported version %q", d.doc.Asset.Version)
+	}
+	if len(d.doc.ExtensionsRequired) > 0 {
+		return nil, fmt.Errorf("gltf: unsupported required extensions %v", d.doc.ExtensionsRequired)
+	}
+	if err := d.loadBuffers(); err != nil {
+		return nil, err
+	}
+	return d.scene()
+}
+
+// splitGLB returns the JSON and the binary chunk of a .glb file.
+func splitGLB(data []byte) (jsonChunk, binChunk []byte, err error) {
+	if len(data) < 12 {
+		return nil, nil, fmt.Errorf("gltf: truncated glb header")
+	}
+	if version := binary.LittleEndian.Uint32(data[4:]); version != GLB_VERSION {
+		return nil, nil, fmt.Errorf("gltf: unsupported glb version %v", version)
+	}
+	length := int(binary.LittleEndian.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("gltf: glb length %v beyond the %v bytes of the file", length, len(data))
+	}
+	for offset := 12; offset+8 <= length; {
+		chunkLength := int(binary.LittleEndian.Uint32(data[offset:]))
+		chunkType := binary.LittleEndian.Uint32(data[offset+4:])
+		offset += 8
+		if chunkLength < 0 || offset+chunkLength > length {
+			return nil, nil, fmt.Errorf("gltf: truncated glb chunk")
+		}
+		chunk := data[offset : offset+chunkLength]
+		switch {
+		case chunkType == GLB_CHUNK_JSON && jsonChunk == nil:
+			jsonChunk = chunk
+		case chunkType == GLB_CHUNK_BIN && binChunk == nil:
+			binChunk = chunk
+		}
+		// unknown chunks are skipped, chunks are padded to 4 bytes
+		offset += (chunkLength + 3) &^ 3
+	}
+	if jsonChunk == nil {
+		return nil, nil, fmt.Errorf("gltf: glb without JSON chunk")
+	}
+	return jsonChunk, binChunk, nil
+}
+
+type decoder struct {
+	doc     document
+	bin     []byte
+	buffers [][]byte
+	readURI func(uri string) ([]byte, error)
+}
+
+func (d *decoder) loadBuffers() error {
+	d.buffers = make([][]byte, len(d.doc.Buffers))
+	for i, b := range d.doc.Buffers {
+		var data []byte
+		if b.URI == "" {
+			// the buffer without URI of a .glb is its binary chunk
+			if i != 0 || d.bin == nil {
+				return fmt.Errorf("gltf: buffer %v has no data", i)
+			}
+			data = d.bin
+		} else {
+			var err error
+			if data, err = d.readData(b.URI); err != nil {
+				return fmt.Errorf("gltf: buffer %v: %w", i, err)
+			}
+		}
+		if len(data) < b.ByteLength {
+			return fmt.Errorf("gltf: buffer %v holds %v bytes, %v expected", i, len(data), b.ByteLength)
+		}
+		d.buffers[i] = data[:b.ByteLength]
+	}
+	return nil
+}
+
+// readData decodes a data URI or reads an external file.
+func (d *decoder) readData(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, fmt.Errorf("unsupported data URI")
+		}
+		return base64.StdEncoding.DecodeString(uri[comma+1:])
+	}
+	if d.readURI == nil {
+		return nil, fmt.Errorf("no reader for %v", uri)
+	}
+	path, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, err
+	}
+	return d.readURI(path)
+}
+
+// mimeType returns the MIME type of a data URI, "" for other URIs.
+func mimeType(uri string) string {
+	if !strings.HasPrefix(uri, "data:") {
+		return ""
+	}
+	typ := uri[len("data:"):]
+	if i := strings.IndexAny(typ, ";,"); i >= 0 {
+		typ = typ[:i]
+	}
+	return typ
+}
+
+// bufferView returns the bytes of a buffer view.
+func (d *decoder) bufferView(index int) ([]byte, int, error) {
+	if index < 0 || index >= len(d.doc.BufferViews) {
+		return nil, 0, fmt.Errorf("gltf: buffer view %v out of range", index)
+	}
+	view := d.doc.BufferViews[index]
+	if view.Buffer < 0 || view.Buffer >= len(d.buffers) {
+		return nil, 0, fmt.Errorf("gltf: buffer view %v references buffer %v", index, view.Buffer)
+	}
+	buf := d.buffers[view.Buffer]
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset+view.ByteLength > len(buf) {
+		return nil, 0, fmt.Errorf("gltf: buffer view %v exceeds its buffer", index)
+	}
+	return buf[view.ByteOffset : view.ByteOffset+view.ByteLength], view.ByteStride, nil
+}
@@ -0,0 +1,118 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// the test files hold the same scene with the buffer and the image stored in the three ways glTF allows
+var testFiles = []string{"embedded.gltf", "external.gltf", "binary.glb"}
+
+func readTestFile(t *testing.T, name string) *Scene {
+	t.Helper()
+	s, err := ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestReadFormats(t *testing.T) {
+	for _, name := range testFiles {
+		s := readTestFile(t, name)
+		// the points primitive is skipped
+		if len(s.Meshes) != 4 || len(s.Nodes) != 6 || len(s.Skins) != 1 || len(s.Animations) != 1 {
+			t.Errorf("%v: %v meshes, %v nodes, %v skins, %v animations", name, len(s.Meshes), len(s.Nodes),
+				len(s.Skins), len(s.Animations))
+			continue
+		}
+		if len(s.Images) != 1 {
+			t.Fatalf("%v: %v images", name, len(s.Images))
+		}
+		image := s.Images[0]
+		// the type of an external image isn't given by the file
+		wantType := map[string]string{"embedded.gltf": "image/png", "binary.glb": "image/png"}[name]
+		if image.MimeType != wantType {
+			t.Errorf("%v: image type %q, want %q", name, image.MimeType, wantType)
+		}
+		// only the external image keeps its file name, to look it up with the other textures
+		if wantURI := map[string]string{"external.gltf": "texture.png"}[name]; image.URI != wantURI {
+			t.Errorf("%v: image URI %q, want %q", name, image.URI, wantURI)
+		}
+		decoded, err := png.Decode(bytes.NewReader(image.Data))
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if r, g, b, _ := decoded.At(0, 0).RGBA(); r>>8 != 255 || g>>8 != 128 || b>>8 != 64 {
+			t.Errorf("%v: image color %v %v %v", name, r>>8, g>>8, b>>8)
+		}
+
+		m := s.Materials[0]
+		if len(m.Maps) != 1 || m.Maps[0].Type != "texture_diffuse" || m.Maps[0].Image != 0 {
+			t.Errorf("%v: material maps %+v", name, m.Maps)
+		}
+		if m.Diffuse[1] != 0.5 || m.Metallic != 0 || m.Roughness != 1 {
+			t.Errorf("%v: material %+v", name, m.Material)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, test := range []struct {
+		name, json, err string
+	}{
+		{"version", `{"asset": {"version": "1.0"}}`, "unsupported version"},
+		{"extension", `{"asset": {"version": "2.0"}, "extensionsRequired": ["KHR_draco_mesh_compression"]}`,
+			"required extensions"},
+		{"external buffer", `{"asset": {"version": "2.0"}, "buffers": [{"byteLength": 4, "uri": "a.bin"}]}`,
+			"no reader"},
+		{"short buffer", `{"asset": {"version": "2.0"}, "buffers": [{"byteLength": 8, "uri": "data:;base64,AAAA"}]}`,
+			"holds 3 bytes"},
+		{"count", `{"asset": {"version": "2.0"},
+			"buffers": [{"byteLength": 12, "uri": "data:;base64,AAAAAAAAAAAAAAAA"}],
+			"bufferViews": [{"buffer": 0, "byteLength": 12}],
+			"accessors": [{"bufferView": 0, "componentType": 5126, "count": 2, "type": "VEC3"}],
+			"meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}]}`, "exceed buffer view"},
+		// would allocate terabytes if the count wasn't checked against the view first
+		{"huge count", `{"asset": {"version": "2.0"},
+			"buffers": [{"byteLength": 12, "uri": "data:;base64,AAAAAAAAAAAAAAAA"}],
+			"bufferViews": [{"buffer": 0, "byteLength": 12}],
+			"accessors": [{"bufferView": 0, "componentType": 5126, "count": 1000000000000, "type": "VEC3"}],
+			"meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}]}`, "exceed buffer view"},
+		{"index", `{"asset": {"version": "2.0"},
+			"buffers": [{"byteLength": 12, "uri": "data:;base64,BQAAAAAAAAAAAAAA"}],
+			"bufferViews": [{"buffer": 0, "byteLength": 12}],
+			"accessors": [{"bufferView": 0, "componentType": 5126, "count": 1, "type": "VEC3"},
+				{"bufferView": 0, "componentType": 5125, "count": 3, "type": "SCALAR"}],
+			"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1}]}]}`, "out of range"},
+		{"cycle", `{"asset": {"version": "2.0"}, "nodes": [{"children": [1]}, {"children": [0]}]}`, "cycle"},
+	} {
+		_, err := Decode([]byte(test.json), nil)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%v: error %v, want %q", test.name, err, test.err)
+		}
+	}
+
+	glb := func(length uint32, chunks ...[]byte) []byte {
+		var b bytes.Buffer
+		binary.Write(&b, binary.LittleEndian, [3]uint32{GLB_MAGIC, GLB_VERSION, length})
+		for _, c := range chunks {
+			b.Write(c)
+		}
+		return b.Bytes()
+	}
+	for name, data := range map[string][]byte{
+		"truncated header": glb(12)[:8],
+		"length":           glb(100),
+		"truncated chunk":  glb(20, []byte{100, 0, 0, 0, 'J', 'S', 'O', 'N'}),
+		"no json":          glb(12),
+	} {
+		if _, err := Decode(data, nil); err == nil {
+			t.Errorf("%v: no error", name)
+		}
+	}
+}
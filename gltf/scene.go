@@ -0,0 +1,538 @@
+package gltf
+
+import (
+	"fmt"
+	"learn_opengl/geometry"
+	"learn_opengl/gl"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// primitive modes, the others are points and lines which aren't imported
+	MODE_TRIANGLES      = 4
+	MODE_TRIANGLE_STRIP = 5
+	MODE_TRIANGLE_FAN   = 6
+
+	// alpha modes of materials
+	ALPHA_OPAQUE = "OPAQUE"
+	ALPHA_MASK   = "MASK"
+	ALPHA_BLEND  = "BLEND"
+)
+
+// Scene is the content of a glTF file.
+type Scene struct {
+	// Meshes holds a mesh per primitive of the glTF meshes
+	Meshes    []Mesh
+	Materials []Material
+	Images    []Image
+	// Nodes holds every node in the order of the file, Roots those of the default scene
+	Nodes      []*Node
+	Roots      []*Node
+	Skins      []Skin
+	Animations []Animation
+}
+
+// Mesh is a triangulated primitive, ready for gl.NewMeshWithMaterial.
+type Mesh struct {
+	Name     string
+	Vertices []gl.Vertex
+	Indices  []uint32
+	// Material indexes Scene.Materials, -1 for the default material
+	Material int
+	// Skinned meshes have joints and weights, the bone ids of their vertices index the joints of the skin of
+	// their node
+	Skinned bool
+}
+
+// Material is a metallic-roughness material. The base color is the diffuse color and opacity of the embedded
+// gl.Material, which has no textures: Maps references the images.
+type Material struct {
+	gl.Material
+	// AlphaMode is ALPHA_OPAQUE, ALPHA_MASK or ALPHA_BLEND, fragments under AlphaCutoff are discarded in mask mode
+	AlphaMode         string
+	AlphaCutoff       float32
+	NormalScale       float32
+	OcclusionStrength float32
+	Maps              []TextureMap
+}
+
+// TextureMap is an image used by a material, Type is the sampler prefix of gl.Material.Textures.
+type TextureMap struct {
+	Type string
+	// Image indexes Scene.Images, TexCoord is the set of texture coordinates, only set 0 is imported
+	Image    int
+	TexCoord int
+}
+
+// Image is an encoded image, PNG or JPEG, read from a file, a data URI or a buffer view.
+type Image struct {
+	Name     string
+	MimeType string
+	// URI is the file of the image, empty for embedded ones
+	URI  string
+	Data []byte
+}
+
+// Node is a node of the scene hierarchy. Its transform is Matrix when the file gives one, the translation, rotation
+// and scale otherwise; animations change the latter.
+type Node struct {
+	Name        string
+	Index       int
+	Matrix      *mgl32.Mat4
+	Translation mgl32.Vec3
+	Rotation    mgl32.Quat
+	Scale       mgl32.Vec3
+	// Meshes indexes Scene.Meshes, the primitives of the mesh of the node
+	Meshes []int
+	// Skin indexes Scene.Skins, -1 if the node isn't skinned
+	Skin     int
+	Parent   *Node
+	Children []*Node
+}
+
+// Transform returns the local transform, relative to the parent node.
+func (n *Node) Transform() mgl32.Mat4 {
+	if n.Matrix != nil {
+		return *n.Matrix
+	}
+	t := mgl32.Translate3D(n.Translation.X(), n.Translation.Y(), n.Translation.Z())
+	s := mgl32.Scale3D(n.Scale.X(), n.Scale.Y(), n.Scale.Z())
+	return t.Mul4(n.Rotation.Normalize().Mat4()).Mul4(s)
+}
+
+// WorldTransform returns the transform of the node relative to the root of the scene.
+func (n *Node) WorldTransform() mgl32.Mat4 {
+	transform := n.Transform()
+	for p := n.Parent; p != nil; p = p.Parent {
+		transform = p.Transform().Mul4(transform)
+	}
+	return transform
+}
+
+// Skin binds meshes to joints, the bone id i of a vertex refers to Joints[i].
+type Skin struct {
+	Name string
+	// Joints index Scene.Nodes
+	Joints              []int
+	InverseBindMatrices []mgl32.Mat4
+	// Skeleton indexes the common root of the joints, -1 if not given
+	Skeleton int
+}
+
+func (d *decoder) scene() (*Scene, error) {
+	s := &Scene{}
+	var err error
+	if err = d.images(s); err != nil {
+		return nil, err
+	}
+	for i := range d.doc.Materials {
+		material, err := d.material(i)
+		if err != nil {
+			return nil, err
+		}
+		s.Materials = append(s.Materials, material)
+	}
+	// the primitives of glTF mesh i are Meshes[meshes[i]...]
+	meshes := make([][]int, len(d.doc.Meshes))
+	for i, m := range d.doc.Meshes {
+		for j := range m.Primitives {
+			mesh, ok, err := d.primitive(&m.Primitives[j])
+			if err != nil {
+				return nil, fmt.Errorf("gltf: mesh %v primitive %v: %w", i, j, err)
+			}
+			if !ok {
+				continue
+			}
+			mesh.Name = m.Name
+			meshes[i] = append(meshes[i], len(s.Meshes))
+			s.Meshes = append(s.Meshes, mesh)
+		}
+	}
+	if err = d.nodes(s, meshes); err != nil {
+		return nil, err
+	}
+	for i := range d.doc.Skins {
+		skin, err := d.skin(i)
+		if err != nil {
+			return nil, err
+		}
+		s.Skins = append(s.Skins, skin)
+	}
+	for i := range d.doc.Animations {
+		animation, err := d.animation(i)
+		if err != nil {
+			return nil, err
+		}
+		s.Animations = append(s.Animations, animation)
+	}
+	return s, nil
+}
+
+func (d *decoder) images(s *Scene) error {
+	for i, img := range d.doc.Images {
+		image := Image{Name: img.Name, MimeType: img.MimeType}
+		var err error
+		switch {
+		case img.BufferView != nil:
+			image.Data, _, err = d.bufferView(*img.BufferView)
+		case img.URI != "":
+			if typ := mimeType(img.URI); typ == "" {
+				image.URI = img.URI
+			} else if image.MimeType == "" {
+				image.MimeType = typ
+			}
+			image.Data, err = d.readData(img.URI)
+		default:
+			err = fmt.Errorf("no data")
+		}
+		if err != nil {
+			return fmt.Errorf("gltf: image %v: %w", i, err)
+		}
+		s.Images = append(s.Images, image)
+	}
+	return nil
+}
+
+func (d *decoder) material(index int) (Material, error) {
+	m := d.doc.Materials[index]
+	material := Material{
+		Material:          gl.DefaultMaterial(),
+		AlphaMode:         ALPHA_OPAQUE,
+		AlphaCutoff:       0.5,
+		NormalScale:       1,
+		OcclusionStrength: 1,
+	}
+	material.Name = m.Name
+	material.Metallic = 1
+	material.TwoSided = m.DoubleSided
+	if m.AlphaMode != "" {
+		material.AlphaMode = m.AlphaMode
+	}
+	if m.AlphaCutoff != nil {
+		material.AlphaCutoff = *m.AlphaCutoff
+	}
+	if m.EmissiveFactor != nil {
+		material.Emissive = mgl32.Vec3(*m.EmissiveFactor)
+	}
+
+	addMap := func(typ string, info *textureInfo) error {
+		if info == nil {
+			return nil
+		}
+		if info.Index < 0 || info.Index >= len(d.doc.Textures) {
+			return fmt.Errorf("gltf: material %v references texture %v", index, info.Index)
+		}
+		source := d.doc.Textures[info.Index].Source
+		if source == nil || *source < 0 || *source >= len(d.doc.Images) {
+			// the image may be given by an unsupported extension only
+			return nil
+		}
+		material.Maps = append(material.Maps, TextureMap{Type: typ, Image: *source, TexCoord: info.TexCoord})
+		return nil
+	}
+
+	if pbr := m.PBRMetallicRoughness; pbr != nil {
+		if pbr.BaseColorFactor != nil {
+			c := *pbr.BaseColorFactor
+			material.Diffuse = mgl32.Vec3{c[0], c[1], c[2]}
+			material.Opacity = c[3]
+		}
+		if pbr.MetallicFactor != nil {
+			material.Metallic = *pbr.MetallicFactor
+		}
+		if pbr.RoughnessFactor != nil {
+			material.Roughness = *pbr.RoughnessFactor
+		}
+		if err := addMap("texture_diffuse", pbr.BaseColorTexture); err != nil {
+			return Material{}, err
+		}
+		if err := addMap("texture_metallicRoughness", pbr.MetallicRoughnessTexture); err != nil {
+			return Material{}, err
+		}
+	}
+	if m.NormalTexture != nil && m.NormalTexture.Scale != nil {
+		material.NormalScale = *m.NormalTexture.Scale
+	}
+	if m.OcclusionTexture != nil && m.OcclusionTexture.Strength != nil {
+		material.OcclusionStrength = *m.OcclusionTexture.Strength
+	}
+	if err := addMap("texture_normal", m.NormalTexture); err != nil {
+		return Material{}, err
+	}
+	if err := addMap("texture_occlusion", m.OcclusionTexture); err != nil {
+		return Material{}, err
+	}
+	if err := addMap("texture_emissive", m.EmissiveTexture); err != nil {
+		return Material{}, err
+	}
+	return material, nil
+}
+
+// primitive reads the vertices and indices of a primitive, ok is false for points and lines.
+func (d *decoder) primitive(p *primitive) (mesh Mesh, ok bool, err error) {
+	mode := MODE_TRIANGLES
+	if p.Mode != nil {
+		mode = *p.Mode
+	}
+	if mode != MODE_TRIANGLES && mode != MODE_TRIANGLE_STRIP && mode != MODE_TRIANGLE_FAN {
+		return Mesh{}, false, nil
+	}
+	position, ok := p.Attributes["POSITION"]
+	if !ok {
+		return Mesh{}, false, nil
+	}
+	positions, components, err := d.readFloats(position)
+	if err != nil {
+		return Mesh{}, false, err
+	}
+	if components != 3 {
+		return Mesh{}, false, fmt.Errorf("positions with %v components", components)
+	}
+	count := len(positions) / 3
+	vertices := make([]gl.Vertex, count)
+	for i := range vertices {
+		vertices[i].Position = mgl32.Vec3{positions[3*i], positions[3*i+1], positions[3*i+2]}
+		for j := 0; j < gl.MAX_BONE_INFLUENCE; j++ {
+			vertices[i].BoneIds[j] = -1
+		}
+	}
+
+	// attribute reads the floats of an attribute, nil if the primitive doesn't have it
+	attribute := func(name string, minComponents int) ([]float32, int, error) {
+		index, ok := p.Attributes[name]
+		if !ok {
+			return nil, 0, nil
+		}
+		values, components, err := d.readFloats(index)
+		if err != nil {
+			return nil, 0, err
+		}
+		if components < minComponents || len(values) != count*components {
+			return nil, 0, fmt.Errorf("attribute %v doesn't match the positions", name)
+		}
+		return values, components, nil
+	}
+
+	normals, _, err := attribute("NORMAL", 3)
+	if err != nil {
+		return Mesh{}, false, err
+	}
+	texCoords, _, err := attribute("TEXCOORD_0", 2)
+	if err != nil {
+		return Mesh{}, false, err
+	}
+	tangents, _, err := attribute("TANGENT", 4)
+	if err != nil {
+		return Mesh{}, false, err
+	}
+	weights, _, err := attribute("WEIGHTS_0", 4)
+	if err != nil {
+		return Mesh{}, false, err
+	}
+	for i := range vertices {
+		v := &vertices[i]
+		if normals != nil {
+			v.Normal = mgl32.Vec3{normals[3*i], normals[3*i+1], normals[3*i+2]}
+		}
+		if texCoords != nil {
+			// glTF's texture coordinates start at the top left, like assimp's with flipped UVs
+			v.TexCoords = mgl32.Vec2{texCoords[2*i], texCoords[2*i+1]}
+		}
+		if tangents != nil {
+			v.Tangent = mgl32.Vec3{tangents[4*i], tangents[4*i+1], tangents[4*i+2]}
+			// the w component is the handedness of the bitangent
+			v.Bitangent = v.Normal.Cross(v.Tangent).Mul(tangents[4*i+3])
+		}
+	}
+	if joints, ok := p.Attributes["JOINTS_0"]; ok && weights != nil {
+		ids, components, err := d.readUints(joints)
+		if err != nil {
+			return Mesh{}, false, err
+		}
+		if components != 4 || len(ids) != count*4 {
+			return Mesh{}, false, fmt.Errorf("joints don't match the positions")
+		}
+		for i := range vertices {
+			for j := 0; j < gl.MAX_BONE_INFLUENCE; j++ {
+				if w := weights[4*i+j]; w > 0 {
+					vertices[i].BoneIds[j] = int32(ids[4*i+j])
+					vertices[i].Weights[j] = w
+				}
+			}
+		}
+		mesh.Skinned = true
+	}
+
+	var indices []uint32
+	if p.Indices != nil {
+		if indices, _, err = d.readUints(*p.Indices); err != nil {
+			return Mesh{}, false, err
+		}
+		for _, index := range indices {
+			if int(index) >= count {
+				return Mesh{}, false, fmt.Errorf("index %v out of range, %v vertices", index, count)
+			}
+		}
+	} else {
+		indices = make([]uint32, count)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+	indices = triangulate(indices, mode)
+
+	mesh.Material = -1
+	if p.Material != nil {
+		if *p.Material < 0 || *p.Material >= len(d.doc.Materials) {
+			return Mesh{}, false, fmt.Errorf("material %v out of range", *p.Material)
+		}
+		mesh.Material = *p.Material
+	}
+	if normals == nil {
+		// the specification asks for flat normals
+		vertices, indices = geometry.FlatNormals(vertices, indices)
+	}
+	if tangents == nil && texCoords != nil {
+		// and for MikkTSpace tangents
+		vertices, indices = geometry.GenerateTangents(vertices, indices)
+	}
+	mesh.Vertices = vertices
+	mesh.Indices = indices
+	return mesh, true, nil
+}
+
+// triangulate turns strips and fans into a list of triangles.
+func triangulate(indices []uint32, mode int) []uint32 {
+	if mode == MODE_TRIANGLES {
+		return indices[:len(indices)/3*3]
+	}
+	var triangles []uint32
+	for i := 2; i < len(indices); i++ {
+		switch {
+		case mode == MODE_TRIANGLE_FAN:
+			triangles = append(triangles, indices[0], indices[i-1], indices[i])
+		case i%2 == 0:
+			triangles = append(triangles, indices[i-2], indices[i-1], indices[i])
+		default:
+			// every other triangle of a strip is flipped to keep the winding
+			triangles = append(triangles, indices[i-1], indices[i-2], indices[i])
+		}
+	}
+	return triangles
+}
+
+func (d *decoder) nodes(s *Scene, meshes [][]int) error {
+	s.Nodes = make([]*Node, len(d.doc.Nodes))
+	for i, n := range d.doc.Nodes {
+		node := &Node{
+			Name:     n.Name,
+			Index:    i,
+			Rotation: mgl32.QuatIdent(),
+			Scale:    mgl32.Vec3{1, 1, 1},
+			Skin:     -1,
+		}
+		if n.Matrix != nil {
+			// column major, like mgl32
+			m := mgl32.Mat4(*n.Matrix)
+			node.Matrix = &m
+		}
+		if n.Translation != nil {
+			node.Translation = mgl32.Vec3(*n.Translation)
+		}
+		if n.Rotation != nil {
+			r := *n.Rotation
+			node.Rotation = mgl32.Quat{W: r[3], V: mgl32.Vec3{r[0], r[1], r[2]}}
+		}
+		if n.Scale != nil {
+			node.Scale = mgl32.Vec3(*n.Scale)
+		}
+		if n.Mesh != nil {
+			if *n.Mesh < 0 || *n.Mesh >= len(meshes) {
+				return fmt.Errorf("gltf: node %v references mesh %v", i, *n.Mesh)
+			}
+			node.Meshes = meshes[*n.Mesh]
+		}
+		if n.Skin != nil {
+			if *n.Skin < 0 || *n.Skin >= len(d.doc.Skins) {
+				return fmt.Errorf("gltf: node %v references skin %v", i, *n.Skin)
+			}
+			node.Skin = *n.Skin
+		}
+		s.Nodes[i] = node
+	}
+	for i, n := range d.doc.Nodes {
+		for _, child := range n.Children {
+			if child < 0 || child >= len(s.Nodes) || s.Nodes[child].Parent != nil || child == i {
+				return fmt.Errorf("gltf: node %v has invalid child %v", i, child)
+			}
+			s.Nodes[child].Parent = s.Nodes[i]
+			s.Nodes[i].Children = append(s.Nodes[i].Children, s.Nodes[child])
+		}
+	}
+	// a cycle would leave nodes without root
+	for i, node := range s.Nodes {
+		steps := 0
+		for p := node.Parent; p != nil; p = p.Parent {
+			if steps++; steps > len(s.Nodes) {
+				return fmt.Errorf("gltf: node %v is part of a cycle", i)
+			}
+		}
+	}
+
+	switch {
+	case len(d.doc.Scenes) > 0:
+		index := 0
+		if d.doc.Scene != nil {
+			index = *d.doc.Scene
+		}
+		if index < 0 || index >= len(d.doc.Scenes) {
+			return fmt.Errorf("gltf: scene %v out of range", index)
+		}
+		for _, root := range d.doc.Scenes[index].Nodes {
+			if root < 0 || root >= len(s.Nodes) || s.Nodes[root].Parent != nil {
+				return fmt.Errorf("gltf: invalid root node %v", root)
+			}
+			s.Roots = append(s.Roots, s.Nodes[root])
+		}
+	default:
+		// without scene, every node without parent is a root
+		for _, node := range s.Nodes {
+			if node.Parent == nil {
+				s.Roots = append(s.Roots, node)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *decoder) skin(index int) (Skin, error) {
+	sk := d.doc.Skins[index]
+	skin := Skin{Name: sk.Name, Joints: sk.Joints, Skeleton: -1}
+	for _, joint := range sk.Joints {
+		if joint < 0 || joint >= len(d.doc.Nodes) {
+			return Skin{}, fmt.Errorf("gltf: skin %v has invalid joint %v", index, joint)
+		}
+	}
+	if sk.Skeleton != nil {
+		skin.Skeleton = *sk.Skeleton
+	}
+	skin.InverseBindMatrices = make([]mgl32.Mat4, len(sk.Joints))
+	if sk.InverseBindMatrices == nil {
+		for i := range skin.InverseBindMatrices {
+			skin.InverseBindMatrices[i] = mgl32.Ident4()
+		}
+		return skin, nil
+	}
+	values, components, err := d.readFloats(*sk.InverseBindMatrices)
+	if err != nil {
+		return Skin{}, err
+	}
+	if components != 16 || len(values) < 16*len(sk.Joints) {
+		return Skin{}, fmt.Errorf("gltf: skin %v has %v inverse bind matrices for %v joints", index, len(values)/16, len(sk.Joints))
+	}
+	for i := range skin.InverseBindMatrices {
+		copy(skin.InverseBindMatrices[i][:], values[16*i:16*i+16])
+	}
+	return skin, nil
+}
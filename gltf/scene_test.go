@@ -0,0 +1,150 @@
+package gltf
+
+import (
+	"learn_opengl/gl"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestTriangulate(t *testing.T) {
+	for _, test := range []struct {
+		mode int
+		want []uint32
+	}{
+		{MODE_TRIANGLES, []uint32{0, 1, 2}},
+		// every other triangle of a strip is flipped
+		{MODE_TRIANGLE_STRIP, []uint32{0, 1, 2, 2, 1, 3, 2, 3, 4}},
+		{MODE_TRIANGLE_FAN, []uint32{0, 1, 2, 0, 2, 3, 0, 3, 4}},
+	} {
+		got := triangulate([]uint32{0, 1, 2, 3, 4}, test.mode)
+		if len(got) != len(test.want) {
+			t.Errorf("mode %v: %v, want %v", test.mode, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("mode %v: %v, want %v", test.mode, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+// near tells whether the vectors are equal up to rounding.
+func near(a, b mgl32.Vec3) bool {
+	return a.Sub(b).Len() < 1e-5
+}
+
+// checkFacing checks that every triangle of the mesh faces normal, i.e. is counter-clockwise seen from it.
+func checkFacing(t *testing.T, name string, mesh *Mesh, normal mgl32.Vec3, triangles int) {
+	t.Helper()
+	if len(mesh.Indices) != 3*triangles {
+		t.Errorf("%v: %v indices, want %v", name, len(mesh.Indices), 3*triangles)
+	}
+	for i := 0; i+2 < len(mesh.Indices); i += 3 {
+		a := mesh.Vertices[mesh.Indices[i]].Position
+		b := mesh.Vertices[mesh.Indices[i+1]].Position
+		c := mesh.Vertices[mesh.Indices[i+2]].Position
+		if n := b.Sub(a).Cross(c.Sub(a)); n.Dot(normal) <= 0 {
+			t.Errorf("%v: triangle %v has normal %v", name, i/3, n)
+		}
+	}
+}
+
+func TestPrimitiveModes(t *testing.T) {
+	s := readTestFile(t, "embedded.gltf")
+	z := mgl32.Vec3{0, 0, 1}
+	checkFacing(t, "strip", &s.Meshes[0], z, 2)
+	checkFacing(t, "fan", &s.Meshes[1], z, 2)
+
+	// the fan has no normals, they are flat
+	for _, v := range s.Meshes[1].Vertices {
+		if !near(v.Normal, z) {
+			t.Errorf("fan normal %v", v.Normal)
+		}
+	}
+	// the strip has texture coordinates but no tangents, they follow u
+	for _, v := range s.Meshes[0].Vertices {
+		if !near(v.Tangent, mgl32.Vec3{1, 0, 0}) || !near(v.Bitangent, mgl32.Vec3{0, 1, 0}) {
+			t.Errorf("strip tangent %v, bitangent %v", v.Tangent, v.Bitangent)
+		}
+	}
+	if s.Meshes[0].Material != 0 || s.Meshes[1].Material != -1 {
+		t.Errorf("materials %v, %v", s.Meshes[0].Material, s.Meshes[1].Material)
+	}
+}
+
+func TestNodes(t *testing.T) {
+	s := readTestFile(t, "embedded.gltf")
+	var roots []string
+	for _, n := range s.Roots {
+		roots = append(roots, n.Name)
+	}
+	if len(roots) != 3 || roots[0] != "root" || roots[1] != "joint0" || roots[2] != "fan" {
+		t.Errorf("roots %v", roots)
+	}
+	root, matrix := s.Nodes[0], s.Nodes[1]
+	if matrix.Parent != root || len(root.Children) != 2 || root.Children[1] != s.Nodes[2] {
+		t.Errorf("hierarchy of %v", root.Name)
+	}
+	if len(s.Nodes[2].Meshes) != 1 || len(s.Nodes[5].Meshes) != 3 {
+		t.Errorf("node meshes %v, %v", s.Nodes[2].Meshes, s.Nodes[5].Meshes)
+	}
+
+	// scale by 2, rotate 90° around y, then translate by (1, 2, 3)
+	if p := root.Transform().Mul4x1(mgl32.Vec4{1, 0, 0, 1}).Vec3(); !near(p, mgl32.Vec3{1, 2, 1}) {
+		t.Errorf("TRS transform moved (1, 0, 0) to %v", p)
+	}
+	// the matrix of the child is applied first
+	if p := matrix.WorldTransform().Mul4x1(mgl32.Vec4{0, 0, 0, 1}).Vec3(); !near(p, mgl32.Vec3{11, 2, 3}) {
+		t.Errorf("world transform moved the origin to %v", p)
+	}
+}
+
+func TestSkin(t *testing.T) {
+	s := readTestFile(t, "embedded.gltf")
+	skin := s.Skins[0]
+	if skin.Name != "skeleton" || len(skin.Joints) != 2 || skin.Joints[1] != 4 || skin.Skeleton != 3 {
+		t.Errorf("skin %+v", skin)
+	}
+	if !skin.InverseBindMatrices[1].ApproxEqual(mgl32.Translate3D(0, -1, 0)) {
+		t.Errorf("inverse bind matrix %v", skin.InverseBindMatrices[1])
+	}
+	if s.Nodes[2].Skin != 0 || s.Nodes[0].Skin != -1 {
+		t.Errorf("node skins %v, %v", s.Nodes[2].Skin, s.Nodes[0].Skin)
+	}
+
+	mesh := &s.Meshes[0]
+	if !mesh.Skinned || s.Meshes[1].Skinned {
+		t.Errorf("skinned %v, %v", mesh.Skinned, s.Meshes[1].Skinned)
+	}
+	for _, v := range mesh.Vertices {
+		// the bottom vertices follow joint 0, the top ones joint 1
+		want := [gl.MAX_BONE_INFLUENCE]int32{0, -1, -1, -1}
+		if v.Position.Y() > 0.5 {
+			want[0] = 1
+		}
+		if v.BoneIds != want || v.Weights[0] != 1 {
+			t.Errorf("vertex at %v has bones %v, weights %v", v.Position, v.BoneIds, v.Weights)
+		}
+	}
+
+	// in the bind pose the joints don't move the vertices
+	for i, m := range s.JointMatrices(0) {
+		if !m.ApproxEqual(mgl32.Ident4()) {
+			t.Errorf("joint %v in bind pose: %v", i, m)
+		}
+	}
+	// at 1s joint 1 is rotated 90° around z, around its origin at (0, 1, 0)
+	s.Animate(&s.Animations[0], 1)
+	m := s.JointMatrices(0)[1]
+	for _, test := range [][2]mgl32.Vec3{
+		{{0, 1, 0}, {0, 1, 0}},
+		{{1, 1, 0}, {0, 2, 0}},
+	} {
+		if p := m.Mul4x1(test[0].Vec4(1)).Vec3(); !near(p, test[1]) {
+			t.Errorf("joint 1 moved %v to %v, want %v", test[0], p, test[1])
+		}
+	}
+}
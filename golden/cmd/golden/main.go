@@ -0,0 +1,93 @@
+// Command golden renders every numbered sample headlessly and compares the last frame against its reference PNG in
+// golden/testdata. Run it from the repository root:
+//
+//	go run ./golden/cmd/golden            compare all samples
+//	go run ./golden/cmd/golden -update    regenerate the references
+//	go run ./golden/cmd/golden -run 2.lighting
+//
+// Without a GPU run it under a virtual X server with Mesa's llvmpipe, e.g.
+// LIBGL_ALWAYS_SOFTWARE=1 xvfb-run go run ./golden/cmd/golden. Samples that don't use the app runner can't be
+// rendered headlessly and are reported as skipped.
+//
+// Without Mesa either, -software builds the samples with the soft tag and renders them with gl/soft, against the
+// references in golden/testdata/soft. Build the command itself with the tag too, so that it doesn't need GLFW:
+//
+//	go run -tags soft ./golden/cmd/golden -software
+//
+// go test ./golden runs the same comparison, see golden/samples_test.go.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"learn_opengl/golden"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	update    = flag.Bool("update", false, "write the rendered frames as the new references")
+	run       = flag.String("run", "", "only render the samples whose directory matches this regular expression")
+	frames    = flag.Int("frames", 1, "number of frames to render, the last one is compared")
+	threshold = flag.Int("threshold", golden.DEFAULT_THRESHOLD, "largest per channel difference (0-255) counted as equal")
+	percent   = flag.Float64("percent", golden.DEFAULT_MAX_DIFF_PERCENT, "percentage of pixels allowed to differ")
+	testdata  = flag.String("testdata", "", "directory of the reference images, golden/testdata or golden/testdata/soft with -software")
+	out       = flag.String("out", filepath.Join(os.TempDir(), "learn_opengl_golden"), "directory of the frames and diff images")
+	timeout   = flag.Duration("timeout", golden.DEFAULT_TIMEOUT, "time limit of a sample, including its build")
+	osmesa    = flag.Bool("osmesa", false, "create the contexts with OSMesa")
+	software  = flag.Bool("software", false, "render with the pure-Go gl/soft backend")
+)
+
+func main() {
+	flag.Parse()
+	if _, err := os.Stat("go.mod"); err != nil {
+		log.Fatalf("golden: run from the repository root")
+	}
+	if *threshold < 0 || *threshold > 255 {
+		log.Fatalf("golden: threshold %v is out of range", *threshold)
+	}
+	if *frames <= 0 {
+		log.Fatalf("golden: frames must be positive")
+	}
+	var filter *regexp.Regexp
+	if *run != "" {
+		filter = regexp.MustCompile(*run)
+	}
+	opts := golden.Options{
+		Root:      ".",
+		Testdata:  *testdata,
+		Out:       *out,
+		Frames:    *frames,
+		Timeout:   *timeout,
+		Tolerance: golden.Tolerance{Threshold: uint8(*threshold), MaxDiffPercent: *percent},
+		Update:    *update,
+		OSMesa:    *osmesa,
+		Software:  *software,
+	}
+
+	samples, err := golden.FindSamples(".", filter)
+	if err != nil {
+		log.Fatalf("golden: %v", err)
+	}
+	if err = os.MkdirAll(filepath.Join(*out, "bin"), 0755); err != nil {
+		log.Fatalf("golden: %v", err)
+	}
+
+	counts := make(map[golden.Status]int)
+	for _, sample := range samples {
+		s, msg := golden.Check(sample, opts)
+		counts[s]++
+		fmt.Printf("%-7v %v", s, sample)
+		if msg != "" {
+			fmt.Printf(": %v", msg)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%v passed, %v failed, %v skipped, %v updated\n", counts[golden.PASS], counts[golden.FAIL],
+		counts[golden.SKIP], counts[golden.UPDATED])
+	if counts[golden.FAIL] > 0 {
+		os.Exit(1)
+	}
+}
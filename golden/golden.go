@@ -0,0 +1,108 @@
+// Package golden compares rendered frames against reference images.
+package golden
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+const (
+	// channel differences up to this value are not counted, drivers round differently
+	DEFAULT_THRESHOLD = 2
+	// a frame passes when at most this percentage of its pixels differ
+	DEFAULT_MAX_DIFF_PERCENT = 0.1
+)
+
+// Tolerance decides whether two images are perceptually the same.
+type Tolerance struct {
+	// Threshold is the largest difference of a color channel (0-255) that still counts as equal
+	Threshold uint8
+	// MaxDiffPercent is the percentage of pixels (0-100) that may exceed Threshold
+	MaxDiffPercent float64
+}
+
+func DefaultTolerance() Tolerance {
+	return Tolerance{Threshold: DEFAULT_THRESHOLD, MaxDiffPercent: DEFAULT_MAX_DIFF_PERCENT}
+}
+
+// Result of the comparison of two images.
+type Result struct {
+	// SizeMismatch is set when the images don't have the same size, nothing else is compared then
+	SizeMismatch bool
+	// Width and Height of the rendered image, WantWidth and WantHeight of the reference
+	Width      int
+	Height     int
+	WantWidth  int
+	WantHeight int
+	// DiffPixels is the number of pixels with a channel difference above the threshold
+	DiffPixels  int
+	DiffPercent float64
+	// MaxDelta is the largest channel difference of all pixels
+	MaxDelta uint8
+	// Diff shows the reference in dimmed gray with the differing pixels in red, brighter for larger differences
+	Diff *image.RGBA
+}
+
+func (r Result) Passed(t Tolerance) bool {
+	return !r.SizeMismatch && r.DiffPercent <= t.MaxDiffPercent
+}
+
+func (r Result) String() string {
+	if r.SizeMismatch {
+		return fmt.Sprintf("size mismatch, got %vx%v, want %vx%v", r.Width, r.Height, r.WantWidth, r.WantHeight)
+	}
+	return fmt.Sprintf("%v pixels (%.3f%%) differ, max channel delta %v", r.DiffPixels, r.DiffPercent, r.MaxDelta)
+}
+
+// Compare compares got against the reference want. Alpha is ignored, frames are read back opaque.
+func Compare(got, want image.Image, t Tolerance) Result {
+	gb, wb := got.Bounds(), want.Bounds()
+	r := Result{Width: gb.Dx(), Height: gb.Dy(), WantWidth: wb.Dx(), WantHeight: wb.Dy()}
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		r.SizeMismatch = true
+		return r
+	}
+
+	r.Diff = image.NewRGBA(image.Rect(0, 0, r.Width, r.Height))
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			g := color.NRGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.NRGBA)
+			w := color.NRGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.NRGBA)
+			delta := maxUint8(absDiff(g.R, w.R), absDiff(g.G, w.G), absDiff(g.B, w.B))
+			if delta > r.MaxDelta {
+				r.MaxDelta = delta
+			}
+			if delta > t.Threshold {
+				r.DiffPixels++
+				// scale the delta so that small differences are still clearly visible
+				red := 128 + int(delta)/2
+				r.Diff.SetRGBA(x, y, color.RGBA{R: uint8(red), A: 0xff})
+			} else {
+				gray := uint8((299*int(w.R) + 587*int(w.G) + 114*int(w.B)) / 1000 / 4)
+				r.Diff.SetRGBA(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 0xff})
+			}
+		}
+	}
+	if n := r.Width * r.Height; n > 0 {
+		r.DiffPercent = float64(r.DiffPixels) * 100 / float64(n)
+	}
+	return r
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func maxUint8(values ...uint8) uint8 {
+	var m uint8
+	for _, v := range values {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,111 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solid(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCompareIdentical(t *testing.T) {
+	img := solid(4, 4, color.RGBA{10, 20, 30, 0xff})
+	r := Compare(img, img, DefaultTolerance())
+	if !r.Passed(DefaultTolerance()) || r.DiffPixels != 0 || r.MaxDelta != 0 || r.DiffPercent != 0 {
+		t.Errorf("Compare of identical images = %v", r)
+	}
+	if r.Diff == nil || r.Diff.Bounds() != img.Bounds() {
+		t.Fatalf("Diff is %v, want an image of %v", r.Diff, img.Bounds())
+	}
+	// equal pixels show the reference as dimmed gray
+	if got := r.Diff.RGBAAt(0, 0); got.R != got.G || got.G != got.B || got.R >= 10 {
+		t.Errorf("Diff pixel of an equal pixel = %v", got)
+	}
+}
+
+func TestCompareThreshold(t *testing.T) {
+	want := solid(10, 10, color.RGBA{100, 100, 100, 0xff})
+	got := solid(10, 10, color.RGBA{100, 100, 100, 0xff})
+	// a channel difference at the threshold is equal
+	got.SetRGBA(0, 0, color.RGBA{102, 98, 100, 0xff})
+	tolerance := Tolerance{Threshold: 2, MaxDiffPercent: 0}
+	r := Compare(got, want, tolerance)
+	if !r.Passed(tolerance) || r.DiffPixels != 0 || r.MaxDelta != 2 {
+		t.Errorf("Compare within the threshold = %v", r)
+	}
+
+	// one pixel above the threshold is 1% of the image
+	got.SetRGBA(5, 5, color.RGBA{100, 100, 103, 0xff})
+	r = Compare(got, want, tolerance)
+	if r.Passed(tolerance) || r.DiffPixels != 1 || r.DiffPercent != 1 || r.MaxDelta != 3 {
+		t.Errorf("Compare above the threshold = %v", r)
+	}
+	if c := r.Diff.RGBAAt(5, 5); c.R < 128 || c.G != 0 || c.B != 0 {
+		t.Errorf("Diff pixel of a differing pixel = %v, want red", c)
+	}
+	if c := r.Diff.RGBAAt(0, 0); c.R != c.G {
+		t.Errorf("Diff pixel of an equal pixel = %v, want gray", c)
+	}
+}
+
+func TestCompareMaxDiffPercent(t *testing.T) {
+	want := solid(10, 10, color.RGBA{0, 0, 0, 0xff})
+	got := solid(10, 10, color.RGBA{0, 0, 0, 0xff})
+	for x := 0; x < 5; x++ {
+		got.SetRGBA(x, 0, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	}
+	for _, c := range []struct {
+		percent float64
+		passed  bool
+	}{
+		{10, true},
+		{5, true},
+		{4.9, false},
+		{0, false},
+	} {
+		tolerance := Tolerance{Threshold: DEFAULT_THRESHOLD, MaxDiffPercent: c.percent}
+		r := Compare(got, want, tolerance)
+		if r.DiffPixels != 5 || r.DiffPercent != 5 || r.MaxDelta != 0xff {
+			t.Fatalf("Compare = %v, want 5 pixels (5%%) with delta 255", r)
+		}
+		if r.Passed(tolerance) != c.passed {
+			t.Errorf("5%% differing pixels passed %v with MaxDiffPercent %v, want %v", r.Passed(tolerance), c.percent,
+				c.passed)
+		}
+	}
+}
+
+func TestCompareSizeMismatch(t *testing.T) {
+	r := Compare(solid(4, 3, color.RGBA{}), solid(3, 4, color.RGBA{}), Tolerance{Threshold: 0xff, MaxDiffPercent: 100})
+	if !r.SizeMismatch || r.Passed(Tolerance{Threshold: 0xff, MaxDiffPercent: 100}) || r.Diff != nil {
+		t.Errorf("Compare of different sizes = %+v", r)
+	}
+	if r.Width != 4 || r.Height != 3 || r.WantWidth != 3 || r.WantHeight != 4 {
+		t.Errorf("sizes = %vx%v and %vx%v, want 4x3 and 3x4", r.Width, r.Height, r.WantWidth, r.WantHeight)
+	}
+	if s := r.String(); s != "size mismatch, got 4x3, want 3x4" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestCompareOffsetBounds(t *testing.T) {
+	// images with a non-zero origin are compared pixel by pixel from their minimum
+	want := solid(4, 4, color.RGBA{50, 50, 50, 0xff})
+	got := solid(6, 6, color.RGBA{50, 50, 50, 0xff}).SubImage(image.Rect(2, 2, 6, 6)).(*image.RGBA)
+	got.SetRGBA(2, 2, color.RGBA{200, 50, 50, 0xff})
+	r := Compare(got, want, DefaultTolerance())
+	if r.SizeMismatch || r.DiffPixels != 1 {
+		t.Fatalf("Compare = %v, want 1 differing pixel", r)
+	}
+	if c := r.Diff.RGBAAt(0, 0); c.G != 0 || c.R < 128 {
+		t.Errorf("Diff pixel at the origin = %v, want red", c)
+	}
+}
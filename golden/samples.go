@@ -0,0 +1,254 @@
+package golden
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"image"
+	"image/png"
+	"io"
+	"learn_opengl/app"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// time limit of a sample, including its build
+	DEFAULT_TIMEOUT = 2 * time.Minute
+)
+
+// SOFTWARE_NOT_COVERED lists the samples the software backend can't render in time, with the reason. Every other
+// sample built on the app runner is rendered with gl/soft, a sample missing the Go version of a shader fails to link.
+var SOFTWARE_NOT_COVERED = map[string]string{
+	"3.model_loading_1_model_loading":                 "imports assimp, which needs its C++ library",
+	"4.advanced_opengl_10.2_asteroids":                "imports assimp, which needs its C++ library",
+	"4.advanced_opengl_10.3_asteroids_instanced":      "its 100000 rocks take minutes a frame to rasterize",
+	"4.advanced_opengl_9.2_geometry_shader_exploding": "imports assimp, which needs its C++ library",
+	"4.advanced_opengl_9.3_geometry_shader_normals":   "imports assimp, which needs its C++ library",
+	"8.guest_2020_skeletal_animation":                 "imports assimp, which needs its C++ library",
+}
+
+var sampleDir = regexp.MustCompile(`^\d+\.`)
+
+type Status int
+
+const (
+	PASS Status = iota
+	FAIL
+	SKIP
+	UPDATED
+)
+
+func (s Status) String() string {
+	return [...]string{"PASS", "FAIL", "SKIP", "UPDATED"}[s]
+}
+
+// Options of the sample runs of Check.
+type Options struct {
+	// Root is the repository root, the samples are its numbered directories
+	Root string
+	// Testdata is the directory of the reference images, Root/golden/testdata or Root/golden/testdata/soft with
+	// Software if empty
+	Testdata string
+	// Out receives the binaries, the frames and the diff images
+	Out string
+	// Frames to render, the last one is compared. 1 if zero
+	Frames int
+	// Timeout of a sample including its build, DEFAULT_TIMEOUT if zero
+	Timeout   time.Duration
+	Tolerance Tolerance
+	// Update writes the rendered frames as the new references
+	Update bool
+	// OSMesa creates the contexts with OSMesa
+	OSMesa bool
+	// Software builds the samples with the soft tag and renders them with gl/soft
+	Software bool
+}
+
+func (o *Options) setDefaults() {
+	if o.Testdata == "" {
+		// the rasterizer of gl/soft doesn't match a GPU pixel for pixel, it has its own references
+		o.Testdata = filepath.Join(o.Root, "golden", "testdata")
+		if o.Software {
+			o.Testdata = filepath.Join(o.Testdata, "soft")
+		}
+	}
+	if o.Frames == 0 {
+		o.Frames = 1
+	}
+	if o.Timeout == 0 {
+		o.Timeout = DEFAULT_TIMEOUT
+	}
+}
+
+// FindSamples returns the numbered sample directories of root whose name matches filter, every one if nil.
+func FindSamples(root string, filter *regexp.Regexp) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var samples []string
+	for _, e := range entries {
+		if !e.IsDir() || !sampleDir.MatchString(e.Name()) {
+			continue
+		}
+		if filter != nil && !filter.MatchString(e.Name()) {
+			continue
+		}
+		samples = append(samples, e.Name())
+	}
+	sort.Strings(samples)
+	return samples, nil
+}
+
+// imports reports whether the sample imports the package path. Only samples built on the app runner honour the
+// headless environment.
+func imports(dir, path string) (bool, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, nil, parser.ImportsOnly)
+	if err != nil {
+		return false, err
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, imp := range f.Imports {
+				if p, _ := strconv.Unquote(imp.Path.Value); p == path {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// Check renders the sample and compares its last frame against the reference, or replaces the reference with
+// Options.Update. The message tells why it failed or was skipped.
+func Check(sample string, opts Options) (Status, string) {
+	opts.setDefaults()
+	ok, err := imports(filepath.Join(opts.Root, sample), "learn_opengl/app")
+	if err != nil {
+		return FAIL, err.Error()
+	}
+	if !ok {
+		return SKIP, "not built on the app runner"
+	}
+	if reason, ok := SOFTWARE_NOT_COVERED[sample]; ok && opts.Software {
+		return SKIP, reason
+	}
+
+	frame, err := render(sample, &opts)
+	if err != nil {
+		return FAIL, err.Error()
+	}
+	reference := filepath.Join(opts.Testdata, sample+".png")
+	if opts.Update {
+		if err = os.MkdirAll(opts.Testdata, 0755); err != nil {
+			return FAIL, err.Error()
+		}
+		if err = copyFile(reference, frame); err != nil {
+			return FAIL, err.Error()
+		}
+		return UPDATED, ""
+	}
+
+	got, err := loadPNG(frame)
+	if err != nil {
+		return FAIL, err.Error()
+	}
+	want, err := loadPNG(reference)
+	if os.IsNotExist(err) {
+		return FAIL, "no reference image, run with -update"
+	} else if err != nil {
+		return FAIL, err.Error()
+	}
+	result := Compare(got, want, opts.Tolerance)
+	if result.Passed(opts.Tolerance) {
+		return PASS, ""
+	}
+	msg := result.String()
+	if result.Diff != nil {
+		diff := filepath.Join(opts.Out, sample+".diff.png")
+		if err = app.SavePNG(diff, result.Diff); err != nil {
+			return FAIL, fmt.Sprintf("%v, %v", msg, err)
+		}
+		msg = fmt.Sprintf("%v, see %v", msg, diff)
+	}
+	return FAIL, msg
+}
+
+// render builds the sample and runs it headlessly in its own directory, where it finds its shaders. It returns the
+// path of the last frame.
+func render(sample string, opts *Options) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	bin, err := filepath.Abs(filepath.Join(opts.Out, "bin", sample))
+	if err != nil {
+		return "", err
+	}
+	args := []string{"build", "-o", bin}
+	if opts.Software {
+		// leave out GLFW and go-gl, the machine may have neither their C libraries nor a GL driver
+		args = append(args, "-tags", "soft")
+	}
+	build := exec.CommandContext(ctx, "go", append(args, "./"+sample)...)
+	build.Dir = opts.Root
+	if output, err := build.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("build failed: %v\n%s", err, output)
+	}
+
+	dir, err := filepath.Abs(filepath.Join(opts.Out, sample))
+	if err != nil {
+		return "", err
+	}
+	if err = os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, bin)
+	cmd.Dir = filepath.Join(opts.Root, sample)
+	cmd.Env = append(os.Environ(),
+		app.ENV_HEADLESS+"="+dir,
+		app.ENV_FRAMES+"="+strconv.Itoa(opts.Frames),
+		app.ENV_CAPTURE+"=-1",
+		app.ENV_OSMESA+"="+strconv.FormatBool(opts.OSMesa),
+		app.ENV_SOFTWARE+"="+strconv.FormatBool(opts.Software),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return "", fmt.Errorf("run failed: %v\n%s", err, output)
+	}
+	return filepath.Join(dir, fmt.Sprintf(app.DEFAULT_HEADLESS_PATTERN, opts.Frames-1)), nil
+}
+
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	w, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, in); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,6 @@
+//go:build !soft
+
+package golden
+
+// the samples render with the GL driver
+const software = false
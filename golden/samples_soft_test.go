@@ -0,0 +1,6 @@
+//go:build soft
+
+package golden
+
+// the samples leave out GLFW as well, they render with gl/soft
+const software = true
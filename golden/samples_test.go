@@ -0,0 +1,48 @@
+package golden
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+var (
+	update = flag.Bool("update", false, "write the frames rendered by TestSamples as the new references")
+	osmesa = flag.Bool("osmesa", false, "create the contexts of TestSamples with OSMesa")
+)
+
+// TestSamples renders every sample and compares it against its reference, like the golden command. Built with the
+// soft tag it renders with gl/soft, otherwise it needs a display or OSMesa. Select samples with -run TestSamples/name.
+func TestSamples(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and renders every sample")
+	}
+	if !software && !*osmesa && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		t.Skip("no display for a GL context, run under xvfb-run, with -osmesa or with -tags soft")
+	}
+	samples, err := FindSamples("..", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	if err = os.Mkdir(out+"/bin", 0755); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Root: "..", Out: out, Tolerance: DefaultTolerance(), Update: *update, OSMesa: *osmesa,
+		Software: software}
+	for _, sample := range samples {
+		sample := sample
+		t.Run(sample, func(t *testing.T) {
+			// the samples are separate processes
+			t.Parallel()
+			switch s, msg := Check(sample, opts); s {
+			case FAIL:
+				t.Error(msg)
+			case SKIP:
+				t.Skip(msg)
+			case UPDATED:
+				t.Log("updated the reference")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package obj
+
+import (
+	"learn_opengl/geometry"
+	"learn_opengl/gl"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// buildMesh triangulates the current faces and turns their corners into vertices, the corners sharing a position,
+// texture coordinate and normal share a vertex.
+func (p *parser) buildMesh() Mesh {
+	// n-gons become fans of triangles, the faces of OBJ files are planar and convex
+	var triangles []corner
+	for _, face := range p.faces {
+		for i := 1; i+1 < len(face); i++ {
+			triangles = append(triangles, face[0], face[i], face[i+1])
+		}
+	}
+
+	// generated normals are computed per position, so that the faces sharing a position are smooth
+	var smoothNormals map[int]mgl32.Vec3
+	if p.process&PROCESS_GEN_SMOOTH_NORMALS != 0 {
+		smoothNormals = generateNormals(triangles, p.positions)
+	}
+
+	var mesh Mesh
+	hasTexCoords := false
+	vertexIndex := make(map[corner]uint32)
+	for _, c := range triangles {
+		// a generated normal only depends on the position, the key of the corner doesn't need it
+		index, ok := vertexIndex[c]
+		if !ok {
+			vertex := p.vertex(c, smoothNormals)
+			hasTexCoords = hasTexCoords || c.vt >= 0
+			mesh.Vertices = append(mesh.Vertices, vertex)
+			index = uint32(len(mesh.Vertices) - 1)
+			vertexIndex[c] = index
+		}
+		mesh.Indices = append(mesh.Indices, index)
+	}
+
+	if hasTexCoords && p.process&PROCESS_CALC_TANGENT_SPACE != 0 {
+		mesh.Vertices, mesh.Indices = geometry.GenerateTangents(mesh.Vertices, mesh.Indices)
+	}
+	return mesh
+}
+
+func (p *parser) vertex(c corner, smoothNormals map[int]mgl32.Vec3) gl.Vertex {
+	var vertex gl.Vertex
+	for i := 0; i < gl.MAX_BONE_INFLUENCE; i++ {
+		vertex.BoneIds[i] = -1
+	}
+	vertex.Position = p.positions[c.v]
+	if c.vt >= 0 {
+		vertex.TexCoords = p.texCoords[c.vt]
+		if p.process&PROCESS_FLIP_UVS != 0 {
+			vertex.TexCoords[1] = 1 - vertex.TexCoords[1]
+		}
+	}
+	if c.vn >= 0 {
+		vertex.Normal = p.normals[c.vn]
+	} else if smoothNormals != nil {
+		vertex.Normal = smoothNormals[c.v]
+	}
+	return vertex
+}
+
+// generateNormals sums the normals of the triangles around every position, weighted by their area, and normalizes
+// them.
+func generateNormals(triangles []corner, positions []mgl32.Vec3) map[int]mgl32.Vec3 {
+	normals := make(map[int]mgl32.Vec3)
+	for i := 0; i+2 < len(triangles); i += 3 {
+		a, b, c := triangles[i].v, triangles[i+1].v, triangles[i+2].v
+		// the length of the cross product is twice the area
+		n := positions[b].Sub(positions[a]).Cross(positions[c].Sub(positions[a]))
+		normals[a] = normals[a].Add(n)
+		normals[b] = normals[b].Add(n)
+		normals[c] = normals[c].Add(n)
+	}
+	for v, n := range normals {
+		if n.Len() > 0 {
+			normals[v] = n.Normalize()
+		}
+	}
+	return normals
+}
@@ -0,0 +1,175 @@
+package obj
+
+import (
+	"fmt"
+	"learn_opengl/gl"
+	"log"
+	"path/filepath"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// LoadOptions configures LoadModel, the zero value loads like assimp.LoadModel with its zero options.
+type LoadOptions struct {
+	// Process are the processing steps, DEFAULT_PROCESS if zero
+	Process Process
+	// TexturePaths are searched in order for the textures that aren't found relative to the model file
+	TexturePaths []string
+	// MissingTexture replaces the textures that fail to load with a magenta checker instead of failing
+	MissingTexture bool
+	// Logger receives what is loaded, nil discards it
+	Logger *log.Logger
+	// Compact uploads gl.CompactVertex instead of gl.Vertex, without tangents nor bones, see
+	// gl.CompactVertexLayout
+	Compact bool
+}
+
+// Model is an OBJ file uploaded to GL, an alternative to assimp.Model without cgo.
+type Model struct {
+	meshes        []gl.Mesh
+	textureLoaded []gl.Texture
+	// missingTexture is the checker standing in for the textures that failed to load, 0 until needed
+	missingTexture uint32
+}
+
+var _ gl.Model = (*Model)(nil)
+
+func NewModel(path string) *Model {
+	model, err := LoadModel(path, LoadOptions{Logger: log.Default()})
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return model
+}
+
+// LoadModel parses the OBJ file at path and uploads its meshes and textures. Unlike NewModel it returns an error
+// instead of exiting, whatever was uploaded is released on error.
+func LoadModel(path string, opts LoadOptions) (*Model, error) {
+	if opts.Process == 0 {
+		opts.Process = DEFAULT_PROCESS
+	}
+	scene, err := ReadFile(path, opts.Process)
+	if err != nil {
+		return nil, err
+	}
+	m := &Model{}
+	if err = m.upload(scene, filepath.Dir(path), opts); err != nil {
+		m.Release()
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Model) Meshes() []gl.Mesh {
+	return m.meshes
+}
+
+func (m *Model) TextureLoaded() []gl.Texture {
+	return m.textureLoaded
+}
+
+// Bounds returns the bounding box of the meshes.
+func (m *Model) Bounds() gl.AABB {
+	b := gl.EmptyAABB()
+	for i := range m.meshes {
+		b = b.Union(m.meshes[i].Bounds())
+	}
+	return b
+}
+
+// BoundingSphere returns a sphere containing the meshes.
+func (m *Model) BoundingSphere() gl.BoundingSphere {
+	s := gl.EmptyAABB().Sphere()
+	for i := range m.meshes {
+		s = s.Union(m.meshes[i].BoundingSphere())
+	}
+	return s
+}
+
+// Draw draws every mesh with the model matrix model, uploaded to gl.MODEL_UNIFORM.
+func (m *Model) Draw(shader *gl.Shader, model mgl32.Mat4) {
+	shader.SetMat4(gl.MODEL_UNIFORM, &model)
+	for i := range m.meshes {
+		m.meshes[i].Draw(shader)
+	}
+}
+
+// Release frees the buffers of every mesh and each loaded texture exactly once. A nil model is ignored.
+func (m *Model) Release() {
+	if m == nil {
+		return
+	}
+	for i := range m.meshes {
+		m.meshes[i].Delete()
+	}
+	for i := range m.textureLoaded {
+		m.textureLoaded[i].Delete()
+	}
+	if m.missingTexture != 0 {
+		gl.NewTexture(m.missingTexture, "", "").Delete()
+		m.missingTexture = 0
+	}
+	m.meshes = nil
+	m.textureLoaded = nil
+}
+
+func (m *Model) upload(scene *Scene, directory string, opts LoadOptions) error {
+	dirs := append([]string{directory}, opts.TexturePaths...)
+	// every texture is loaded once for the entire model
+	textureIds := make(map[string]uint32)
+	for _, mesh := range scene.Meshes {
+		material := mesh.Material.Material
+		material.Textures = nil
+		for _, texture := range mesh.Material.Maps {
+			textureId, ok := textureIds[texture.Name]
+			if !ok {
+				var err error
+				if textureId, err = m.loadTexture(texture, dirs, opts); err != nil {
+					return err
+				}
+				textureIds[texture.Name] = textureId
+			}
+			material.Textures = append(material.Textures, gl.NewTexture(textureId, texture.Type, texture.Name))
+		}
+		if opts.Logger != nil {
+			opts.Logger.Printf("mesh %v len(vertices)=%v len(indices)=%v len(textures)=%v", mesh.Name, len(mesh.Vertices), len(mesh.Indices), len(material.Textures))
+		}
+		if opts.Compact {
+			vertices := gl.VertexBytes(gl.CompactVertices(mesh.Vertices))
+			compact, err := gl.LoadMeshWithLayout(gl.CompactVertexLayout(), [][]byte{vertices}, mesh.Indices, material)
+			if err != nil {
+				return err
+			}
+			m.meshes = append(m.meshes, compact)
+		} else {
+			m.meshes = append(m.meshes, gl.NewMeshWithMaterial(mesh.Vertices, mesh.Indices, material))
+		}
+	}
+	return nil
+}
+
+func (m *Model) loadTexture(texture TextureMap, dirs []string, opts LoadOptions) (uint32, error) {
+	path, directory, err := gl.FindTexture(texture.Name, dirs)
+	if err != nil {
+		err = fmt.Errorf("obj: %w", err)
+	} else {
+		var textureId uint32
+		if textureId, err = gl.LoadTextureFromFile(path, directory, false); err == nil {
+			m.textureLoaded = append(m.textureLoaded, gl.NewTexture(textureId, texture.Type, texture.Name))
+			return textureId, nil
+		}
+	}
+	if !opts.MissingTexture {
+		return 0, err
+	}
+	if opts.Logger != nil {
+		opts.Logger.Printf("%v, using the missing texture", err)
+	}
+	// the checker isn't stored with the loaded textures, it is shared and released once
+	if m.missingTexture == 0 {
+		if m.missingTexture, err = gl.MissingTexture(); err != nil {
+			return 0, err
+		}
+	}
+	return m.missingTexture, nil
+}
@@ -0,0 +1,149 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"learn_opengl/gl"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Material is a material of an MTL library. The embedded gl.Material has no textures, Maps names them.
+type Material struct {
+	gl.Material
+	Maps []TextureMap
+}
+
+// TextureMap is a texture named by a material, Type is the sampler prefix of gl.Material.Textures.
+type TextureMap struct {
+	Type string
+	Name string
+}
+
+// texture types of the map statements, they follow the assimp loader: a bump map is a normal map and an ambient map
+// a height map
+var mapTypes = map[string]string{
+	"map_Kd":   "texture_diffuse",
+	"map_Ks":   "texture_specular",
+	"map_Bump": "texture_normal",
+	"map_bump": "texture_normal",
+	"bump":     "texture_normal",
+	"map_Ka":   "texture_height",
+}
+
+// DecodeMTL parses an MTL library, the materials are returned in the order of the file.
+func DecodeMTL(r io.Reader) ([]*Material, error) {
+	var (
+		materials []*Material
+		current   *Material
+		line      int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "newmtl" {
+			current = &Material{Material: gl.DefaultMaterial()}
+			current.Name = strings.Join(fields[1:], " ")
+			materials = append(materials, current)
+			continue
+		}
+		if current == nil {
+			// statements before the first material have nothing to apply to
+			continue
+		}
+		if err := current.parseStatement(fields[0], fields[1:]); err != nil {
+			return nil, fmt.Errorf("obj: mtl line %v: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("obj: %w", err)
+	}
+	return materials, nil
+}
+
+func (m *Material) parseStatement(keyword string, args []string) error {
+	switch keyword {
+	case "Ka":
+		return parseColor(args, &m.Ambient)
+	case "Kd":
+		return parseColor(args, &m.Diffuse)
+	case "Ks":
+		return parseColor(args, &m.Specular)
+	case "Ke":
+		return parseColor(args, &m.Emissive)
+	case "Ns":
+		v, err := parseFloats(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		if v[0] > 0 {
+			m.Shininess = v[0]
+		}
+	case "d":
+		v, err := parseFloats(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		m.Opacity = v[0]
+	case "Tr":
+		v, err := parseFloats(args, 1, 1)
+		if err != nil {
+			return err
+		}
+		m.Opacity = 1 - v[0]
+	default:
+		if typ, ok := mapTypes[keyword]; ok && len(args) > 0 {
+			// the options, e.g. -bm 1.0, come before the file name, which may contain spaces
+			m.Maps = append(m.Maps, TextureMap{Type: typ, Name: mapName(args)})
+		}
+	}
+	return nil
+}
+
+func parseColor(args []string, color *mgl32.Vec3) error {
+	// a single value is a gray
+	v, err := parseFloats(args, 1, 3)
+	if err != nil {
+		return err
+	}
+	if len(args) < 3 {
+		v[1], v[2] = v[0], v[0]
+	}
+	*color = mgl32.Vec3{v[0], v[1], v[2]}
+	return nil
+}
+
+// number of values following each option of a map statement
+var mapOptions = map[string]int{
+	"-blendu": 1, "-blendv": 1, "-boost": 1, "-mm": 2, "-o": 3, "-s": 3, "-t": 3,
+	"-texres": 1, "-clamp": 1, "-bm": 1, "-imfchan": 1, "-type": 1, "-cc": 1,
+}
+
+func mapName(args []string) string {
+	i := 0
+	for i < len(args) {
+		n, ok := mapOptions[args[i]]
+		if !ok {
+			break
+		}
+		i++
+		// -o, -s and -t take 1 to 3 values
+		for j := 0; j < n && i < len(args)-1; j++ {
+			if _, err := parseFloats(args[i:i+1], 1, 1); err != nil && j > 0 {
+				break
+			}
+			i++
+		}
+	}
+	return strings.Join(args[i:], " ")
+}
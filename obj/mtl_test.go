@@ -0,0 +1,82 @@
+package obj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestMapName(t *testing.T) {
+	for _, test := range []struct {
+		statement, want string
+	}{
+		{"texture.png", "texture.png"},
+		{"my texture.png", "my texture.png"},
+		{"-o 1 2 3 texture.png", "texture.png"},
+		// -o takes 1 to 3 values
+		{"-o 0.5 texture.png", "texture.png"},
+		{"-o 0.5 0.5 my texture.png", "my texture.png"},
+		{"-o 1 2 3 -s 4 5 6 -bm 0.2 my texture.png", "my texture.png"},
+		{"-clamp on -blendu off texture.png", "texture.png"},
+		{"-imfchan l -type sphere -mm 0 1 texture.png", "texture.png"},
+		// a name looking like a number is kept
+		{"-bm 1.5 2.png", "2.png"},
+	} {
+		if got := mapName(strings.Fields(test.statement)); got != test.want {
+			t.Errorf("mapName(%q) = %q, want %q", test.statement, got, test.want)
+		}
+	}
+}
+
+func TestDecodeMTL(t *testing.T) {
+	materials, err := DecodeMTL(strings.NewReader(`
+Kd 0 0 0 # before any material, ignored
+newmtl first material
+Ka 0.5
+Kd 0.1 0.2 0.3 # a comment
+Ns 0
+Tr 0.25
+map_Kd -s 2 2 2 diffuse map.png
+map_Ks specular.png
+bump normal.png
+map_Ka ambient.png
+newmtl second
+Ke 1 1 0
+Ns 10
+d 0.5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(materials) != 2 {
+		t.Fatalf("%v materials", len(materials))
+	}
+	first, second := materials[0], materials[1]
+	if first.Name != "first material" || first.Ambient != (mgl32.Vec3{0.5, 0.5, 0.5}) ||
+		first.Diffuse != (mgl32.Vec3{0.1, 0.2, 0.3}) || first.Opacity != 0.75 {
+		t.Errorf("first %+v", first.Material)
+	}
+	// a shininess of 0 keeps the default
+	if first.Shininess != 32 || second.Shininess != 10 || second.Opacity != 0.5 || second.Emissive != (mgl32.Vec3{1, 1, 0}) {
+		t.Errorf("second %+v", second.Material)
+	}
+	want := []TextureMap{
+		{"texture_diffuse", "diffuse map.png"},
+		{"texture_specular", "specular.png"},
+		{"texture_normal", "normal.png"},
+		{"texture_height", "ambient.png"},
+	}
+	if len(first.Maps) != len(want) {
+		t.Fatalf("maps %+v", first.Maps)
+	}
+	for i := range want {
+		if first.Maps[i] != want[i] {
+			t.Errorf("map %v is %+v, want %+v", i, first.Maps[i], want[i])
+		}
+	}
+
+	if _, err = DecodeMTL(strings.NewReader("newmtl a\nKd x\n")); err == nil {
+		t.Errorf("invalid color parsed")
+	}
+}
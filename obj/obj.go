@@ -0,0 +1,250 @@
+// Package obj reads Wavefront OBJ files and their MTL material libraries in Go, without assimp. Parsing makes no GL
+// call, it yields the same vertices, indices and materials as assimp.Model; LoadModel uploads them.
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"learn_opengl/gl"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Process selects the processing of the parsed meshes, like the assimp post processing steps of the same names.
+type Process uint
+
+const (
+	// PROCESS_FLIP_UVS flips the v texture coordinate, v becomes 1-v
+	PROCESS_FLIP_UVS Process = 1 << iota
+	// PROCESS_GEN_SMOOTH_NORMALS generates the normals the file doesn't give, averaged over the faces sharing a
+	// position
+	PROCESS_GEN_SMOOTH_NORMALS
+	// PROCESS_CALC_TANGENT_SPACE computes the tangents and bitangents of the meshes with texture coordinates
+	PROCESS_CALC_TANGENT_SPACE
+
+	// same steps as assimp.DEFAULT_POST_PROCESS, the faces are always triangulated
+	DEFAULT_PROCESS = PROCESS_FLIP_UVS | PROCESS_GEN_SMOOTH_NORMALS | PROCESS_CALC_TANGENT_SPACE
+)
+
+// Scene is the content of an OBJ file.
+type Scene struct {
+	// Meshes holds a mesh per object or group and material, in the order of the file
+	Meshes []Mesh
+	// Materials are those of the material libraries, by name
+	Materials map[string]*Material
+}
+
+// Mesh is a triangulated mesh with a single material, ready for gl.NewMeshWithMaterial.
+type Mesh struct {
+	// Name of the object or group
+	Name     string
+	Vertices []gl.Vertex
+	Indices  []uint32
+	// Material is the material used by the faces, a default one if the file names none or an unknown one
+	Material *Material
+}
+
+// ReadFile parses the OBJ file at path, the material libraries are read relative to its directory.
+func ReadFile(path string, process Process) (*Scene, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("obj: %w", err)
+	}
+	defer f.Close()
+	dir := filepath.Dir(path)
+	return Decode(f, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(name, "\\", "/"))))
+	}, process)
+}
+
+// Decode parses an OBJ file from r. openMTL opens the material libraries named by mtllib statements, nil skips them;
+// a library that fails to open is skipped as well, its materials become default ones.
+func Decode(r io.Reader, openMTL func(name string) (io.ReadCloser, error), process Process) (*Scene, error) {
+	p := parser{
+		scene:   &Scene{Materials: make(map[string]*Material)},
+		openMTL: openMTL,
+		process: process,
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		p.line++
+		if err := p.parseLine(scanner.Text()); err != nil {
+			return nil, fmt.Errorf("obj: line %v: %w", p.line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("obj: %w", err)
+	}
+	p.flush()
+	return p.scene, nil
+}
+
+// corner is a face corner, the indices of its position, texture coordinate and normal. -1 marks a missing one.
+type corner struct {
+	v, vt, vn int
+}
+
+type parser struct {
+	scene   *Scene
+	openMTL func(name string) (io.ReadCloser, error)
+	process Process
+	line    int
+
+	positions []mgl32.Vec3
+	texCoords []mgl32.Vec2
+	normals   []mgl32.Vec3
+
+	// faces of the current object and material, flushed into a mesh when either changes
+	name     string
+	material string
+	faces    [][]corner
+}
+
+func (p *parser) parseLine(line string) error {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	args := fields[1:]
+	switch fields[0] {
+	case "v":
+		v, err := parseFloats(args, 3, 3)
+		if err != nil {
+			return err
+		}
+		p.positions = append(p.positions, mgl32.Vec3{v[0], v[1], v[2]})
+	case "vt":
+		v, err := parseFloats(args, 1, 2)
+		if err != nil {
+			return err
+		}
+		p.texCoords = append(p.texCoords, mgl32.Vec2{v[0], v[1]})
+	case "vn":
+		v, err := parseFloats(args, 3, 3)
+		if err != nil {
+			return err
+		}
+		p.normals = append(p.normals, mgl32.Vec3{v[0], v[1], v[2]})
+	case "f":
+		return p.parseFace(args)
+	case "o", "g":
+		p.flush()
+		p.name = strings.Join(args, " ")
+	case "usemtl":
+		p.flush()
+		p.material = strings.Join(args, " ")
+	case "mtllib":
+		return p.loadMaterials(strings.Join(args, " "))
+	}
+	// smoothing groups, lines, points and the other statements are ignored
+	return nil
+}
+
+func (p *parser) parseFace(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("face with %v vertices", len(args))
+	}
+	face := make([]corner, len(args))
+	for i, arg := range args {
+		parts := strings.Split(arg, "/")
+		if len(parts) > 3 {
+			return fmt.Errorf("invalid face vertex %q", arg)
+		}
+		c := corner{v: -1, vt: -1, vn: -1}
+		var err error
+		if c.v, err = resolveIndex(parts[0], len(p.positions)); err != nil {
+			return err
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			if c.vt, err = resolveIndex(parts[1], len(p.texCoords)); err != nil {
+				return err
+			}
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			if c.vn, err = resolveIndex(parts[2], len(p.normals)); err != nil {
+				return err
+			}
+		}
+		face[i] = c
+	}
+	p.faces = append(p.faces, face)
+	return nil
+}
+
+// resolveIndex converts a 1-based index, or a negative one counting back from the last element, to a 0-based index.
+func resolveIndex(s string, count int) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index %q", s)
+	}
+	if i < 0 {
+		i += count
+	} else {
+		i--
+	}
+	if i < 0 || i >= count {
+		return 0, fmt.Errorf("index %v out of range, %v elements", s, count)
+	}
+	return i, nil
+}
+
+func parseFloats(args []string, min, max int) ([]float32, error) {
+	if len(args) < min {
+		return nil, fmt.Errorf("expected %v values, got %v", min, len(args))
+	}
+	values := make([]float32, max)
+	for i := 0; i < max && i < len(args); i++ {
+		f, err := strconv.ParseFloat(args[i], 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", args[i])
+		}
+		values[i] = float32(f)
+	}
+	return values, nil
+}
+
+func (p *parser) loadMaterials(name string) error {
+	if p.openMTL == nil {
+		return nil
+	}
+	r, err := p.openMTL(name)
+	if err != nil {
+		// like assimp, a missing library leaves the default material
+		return nil
+	}
+	defer r.Close()
+	materials, err := DecodeMTL(r)
+	if err != nil {
+		return err
+	}
+	for _, material := range materials {
+		p.scene.Materials[material.Name] = material
+	}
+	return nil
+}
+
+// flush turns the faces of the current object and material into a mesh.
+func (p *parser) flush() {
+	if len(p.faces) == 0 {
+		return
+	}
+	material, ok := p.scene.Materials[p.material]
+	if !ok {
+		material = &Material{Material: gl.DefaultMaterial()}
+		material.Name = p.material
+	}
+	mesh := p.buildMesh()
+	mesh.Name = p.name
+	mesh.Material = material
+	p.scene.Meshes = append(p.scene.Meshes, mesh)
+	p.faces = nil
+}
@@ -0,0 +1,218 @@
+package obj
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func decode(t *testing.T, source string, process Process) *Scene {
+	t.Helper()
+	s, err := Decode(strings.NewReader(source), nil, process)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// positions returns the positions of the corners of the triangles of mesh.
+func positions(mesh *Mesh) []mgl32.Vec3 {
+	var p []mgl32.Vec3
+	for _, i := range mesh.Indices {
+		p = append(p, mesh.Vertices[i].Position)
+	}
+	return p
+}
+
+func TestTriangulateNgons(t *testing.T) {
+	s := decode(t, `
+v 0 0 0
+v 1 0 0
+v 2 1 0
+v 1 2 0
+v 0 1 0
+f 1 2 3 4 5
+f 1 2 3
+`, 0)
+	if len(s.Meshes) != 1 {
+		t.Fatalf("%v meshes", len(s.Meshes))
+	}
+	mesh := &s.Meshes[0]
+	// the pentagon becomes a fan of 3 triangles around its first corner
+	want := []int{0, 1, 2, 0, 2, 3, 0, 3, 4, 0, 1, 2}
+	got := positions(mesh)
+	if len(got) != len(want) {
+		t.Fatalf("%v corners, want %v", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i] != s.Meshes[0].Vertices[w].Position {
+			t.Errorf("corner %v at %v, want vertex %v", i, got[i], w)
+		}
+	}
+	// the winding of the face is kept
+	for i := 0; i < len(got); i += 3 {
+		if n := got[i+1].Sub(got[i]).Cross(got[i+2].Sub(got[i])); n.Z() <= 0 {
+			t.Errorf("triangle %v faces %v", i/3, n)
+		}
+	}
+	if _, err := Decode(strings.NewReader("v 0 0 0\nv 1 0 0\nf 1 2\n"), nil, 0); err == nil {
+		t.Errorf("face of 2 vertices parsed")
+	}
+}
+
+func TestNegativeIndices(t *testing.T) {
+	s := decode(t, `
+v 0 0 0
+v 1 0 0
+v 0 1 0
+vt 0 0
+vt 1 0
+vt 0 1
+f -3/-3 -2/-2 -1/-1
+v 5 5 5
+f -4 -3 -1
+`, 0)
+	got := positions(&s.Meshes[0])
+	want := []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 0}, {1, 0, 0}, {5, 5, 5}}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("corners %v, want %v", got, want)
+		}
+	}
+	if uv := s.Meshes[0].Vertices[s.Meshes[0].Indices[2]].TexCoords; uv != (mgl32.Vec2{0, 1}) {
+		t.Errorf("texture coordinates %v", uv)
+	}
+	for _, source := range []string{"v 0 0 0\nf -2 1 1\n", "v 0 0 0\nf 1 1 2\n", "v 0 0 0\nf 0 1 1\n", "v 0 0 0\nf 1/x 1 1\n"} {
+		if _, err := Decode(strings.NewReader(source), nil, 0); err == nil {
+			t.Errorf("%q parsed", source)
+		}
+	}
+}
+
+func TestDeduplicateCorners(t *testing.T) {
+	s := decode(t, `
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vt 0 0
+vt 1 1
+vn 0 0 1
+f 1/1/1 2/1/1 3/1/1
+f 1/1/1 3/1/1 4/1/1
+f 1/2/1 3/1/1 4/1/1
+`, 0)
+	mesh := &s.Meshes[0]
+	// the corners sharing position, texture coordinates and normal share a vertex, corner 1/2/1 doesn't
+	if len(mesh.Vertices) != 5 || len(mesh.Indices) != 9 {
+		t.Errorf("%v vertices, %v indices", len(mesh.Vertices), len(mesh.Indices))
+	}
+	if mesh.Indices[3] != mesh.Indices[0] || mesh.Indices[6] == mesh.Indices[0] {
+		t.Errorf("indices %v", mesh.Indices)
+	}
+	for _, v := range mesh.Vertices {
+		if v.BoneIds != [4]int32{-1, -1, -1, -1} {
+			t.Errorf("bone ids %v", v.BoneIds)
+		}
+	}
+}
+
+func TestSmoothNormals(t *testing.T) {
+	// two triangles of the same area at a right angle, sharing the edge 2-3
+	source := `
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 1 0 -1
+f 1 2 3
+f 2 4 3
+`
+	s := decode(t, source, PROCESS_GEN_SMOOTH_NORMALS)
+	for _, v := range s.Meshes[0].Vertices {
+		var want mgl32.Vec3
+		switch {
+		case v.Position.X() == 0:
+			want = mgl32.Vec3{0, 0, 1}
+		case v.Position.Z() == -1:
+			want = mgl32.Vec3{1, 0, 0}
+		default:
+			// the normals of the faces meeting on the edge are averaged
+			want = mgl32.Vec3{1, 0, 1}.Normalize()
+		}
+		if v.Normal.Sub(want).Len() > 1e-5 {
+			t.Errorf("normal at %v is %v, want %v", v.Position, v.Normal, want)
+		}
+	}
+
+	// the normals of the file are kept, no normals are generated without the step
+	s = decode(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\nvn 0 1 0\nf 1//1 2//1 3\n", PROCESS_GEN_SMOOTH_NORMALS)
+	vertices := s.Meshes[0].Vertices
+	if vertices[0].Normal != (mgl32.Vec3{0, 1, 0}) || vertices[2].Normal != (mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("normals %v, %v", vertices[0].Normal, vertices[2].Normal)
+	}
+	s = decode(t, "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n", 0)
+	if n := s.Meshes[0].Vertices[0].Normal; n != (mgl32.Vec3{}) {
+		t.Errorf("generated normal %v", n)
+	}
+}
+
+func TestFlipUVs(t *testing.T) {
+	source := "v 0 0 0\nv 1 0 0\nv 0 1 0\nvt 0.25 0.125\nvt 1 0\nvt 0.5\nf 1/1 2/2 3/3\n"
+	for _, test := range []struct {
+		process Process
+		want    [3]mgl32.Vec2
+	}{
+		{0, [3]mgl32.Vec2{{0.25, 0.125}, {1, 0}, {0.5, 0}}},
+		{PROCESS_FLIP_UVS, [3]mgl32.Vec2{{0.25, 0.875}, {1, 1}, {0.5, 1}}},
+	} {
+		s := decode(t, source, test.process)
+		for i, want := range test.want {
+			if got := s.Meshes[0].Vertices[s.Meshes[0].Indices[i]].TexCoords; got != want {
+				t.Errorf("process %v: corner %v has %v, want %v", test.process, i, got, want)
+			}
+		}
+	}
+}
+
+func TestMissingMaterialLibrary(t *testing.T) {
+	s, err := ReadFile(filepath.Join("testdata", "quad.obj"), DEFAULT_PROCESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Materials) != 1 || len(s.Meshes) != 2 {
+		t.Fatalf("%v materials, %v meshes", len(s.Materials), len(s.Meshes))
+	}
+	brick := s.Meshes[0].Material
+	if s.Meshes[0].Name != "quad" || brick != s.Materials["brick wall"] {
+		t.Errorf("mesh %q has material %q", s.Meshes[0].Name, brick.Name)
+	}
+	if len(brick.Maps) != 2 || brick.Maps[0].Name != "brick wall.png" || brick.Maps[1].Type != "texture_normal" {
+		t.Errorf("maps %+v", brick.Maps)
+	}
+	// an unknown material is a default one with the name
+	unknown := s.Meshes[1].Material
+	if unknown.Name != "unknown" || unknown.Diffuse != (mgl32.Vec3{1, 1, 1}) || len(unknown.Maps) != 0 {
+		t.Errorf("unknown material %+v", unknown)
+	}
+	// the quad has texture coordinates, the tangents follow u
+	for _, v := range s.Meshes[0].Vertices {
+		if v.Tangent.Sub(mgl32.Vec3{1, 0, 0}).Len() > 1e-5 {
+			t.Errorf("tangent %v", v.Tangent)
+		}
+	}
+
+	// a library that fails to open is skipped
+	failing := func(name string) (io.ReadCloser, error) {
+		return nil, errors.New("no such file")
+	}
+	if s, err = Decode(strings.NewReader("mtllib a.mtl\nusemtl a\nv 0 0 0\nf 1 1 1\n"), failing, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Materials) != 0 || s.Meshes[0].Material.Name != "a" {
+		t.Errorf("materials %v", s.Materials)
+	}
+}